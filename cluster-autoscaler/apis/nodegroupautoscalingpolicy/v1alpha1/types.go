@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the v1alpha1 version of the NodeGroupAutoscalingPolicy API.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// GroupName is the API group of NodeGroupAutoscalingPolicy.
+	GroupName = "autoscaling.x-k8s.io"
+	// Version is the API version implemented by this package.
+	Version = "v1alpha1"
+	// Kind is the kind of NodeGroupAutoscalingPolicy objects.
+	Kind = "NodeGroupAutoscalingPolicy"
+	// Resource is the plural resource name of NodeGroupAutoscalingPolicy objects.
+	Resource = "nodegroupautoscalingpolicies"
+)
+
+// SchemeGroupVersionResource identifies the NodeGroupAutoscalingPolicy resource.
+var SchemeGroupVersionResource = schema.GroupVersionResource{Group: GroupName, Version: Version, Resource: Resource}
+
+// NodeGroupAutoscalingPolicy is a cluster-scoped object overriding Cluster Autoscaler
+// options for the node groups it selects.
+type NodeGroupAutoscalingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the node group selector and the overridden options.
+	Spec NodeGroupAutoscalingPolicySpec `json:"spec"`
+}
+
+// NodeGroupAutoscalingPolicySpec holds the node group selector and the overridden options.
+// Options left unset fall back to the command line flags.
+type NodeGroupAutoscalingPolicySpec struct {
+	// NodeGroups are regular expressions matched against node group ids.
+	NodeGroups []string `json:"nodeGroups"`
+	// ScaleDownEnabled enables or disables scale-down of the node groups.
+	// +optional
+	ScaleDownEnabled *bool `json:"scaleDownEnabled,omitempty"`
+	// ScaleDownUtilizationThreshold is the utilization below which a node can be considered for scale-down.
+	// +optional
+	ScaleDownUtilizationThreshold *float64 `json:"scaleDownUtilizationThreshold,omitempty"`
+	// ScaleDownUnneededTime is how long a node should be unneeded before it is eligible for scale-down.
+	// +optional
+	ScaleDownUnneededTime *metav1.Duration `json:"scaleDownUnneededTime,omitempty"`
+	// ScaleDownUnreadyTime is how long an unready node should be unneeded before it is eligible for scale-down.
+	// +optional
+	ScaleDownUnreadyTime *metav1.Duration `json:"scaleDownUnreadyTime,omitempty"`
+	// MaxNodeProvisionTime is the maximum time to wait for a node to be provisioned.
+	// +optional
+	MaxNodeProvisionTime *metav1.Duration `json:"maxNodeProvisionTime,omitempty"`
+	// MaxDisruptedNodesPercentage is the maximum percentage of nodes of the node group removed at the same time.
+	// +optional
+	MaxDisruptedNodesPercentage *float64 `json:"maxDisruptedNodesPercentage,omitempty"`
+	// ExpanderPriority is used by the priority expander instead of the priority configmap.
+	// +optional
+	ExpanderPriority *int `json:"expanderPriority,omitempty"`
+}
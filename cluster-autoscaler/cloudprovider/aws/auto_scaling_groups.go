@@ -257,6 +257,56 @@ func (m *asgCache) DeleteInstances(instances []*AwsInstanceRef) error {
 	return nil
 }
 
+// CreateAsg creates an ASG and registers it. Returns the registered ASG.
+func (m *asgCache) CreateAsg(asg *asg, input *autoscaling.CreateAutoScalingGroupInput) (*asg, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	klog.V(0).Infof("Creating asg %s", asg.Name)
+	if _, err := m.service.CreateAutoScalingGroup(input); err != nil {
+		return nil, err
+	}
+
+	// The ASG is discovered by its tags on refresh, until then it has no instances.
+	m.asgToInstances[asg.AwsRef] = []AwsInstanceRef{}
+	return m.register(asg), nil
+}
+
+// DeleteAsg deletes an ASG without instances and unregisters it.
+func (m *asgCache) DeleteAsg(asg *asg) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if asg.curSize > 0 || len(m.asgToInstances[asg.AwsRef]) > 0 {
+		return fmt.Errorf("can't delete asg %s, which still has instances", asg.Name)
+	}
+	klog.V(0).Infof("Deleting asg %s", asg.Name)
+	params := &autoscaling.DeleteAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(asg.Name),
+		ForceDelete:          aws.Bool(false),
+	}
+	if _, err := m.service.DeleteAutoScalingGroup(params); err != nil {
+		return err
+	}
+
+	m.unregister(asg)
+	delete(m.asgToInstances, asg.AwsRef)
+	return nil
+}
+
+// Exists tells whether the ASG is registered.
+func (m *asgCache) Exists(ref AwsRef) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, asg := range m.registeredAsgs {
+		if asg.AwsRef == ref {
+			return true
+		}
+	}
+	return false
+}
+
 // Fetch automatically discovered ASGs. These ASGs should be unregistered if
 // they no longer exist in AWS.
 func (m *asgCache) fetchAutoAsgNames() ([]string, error) {
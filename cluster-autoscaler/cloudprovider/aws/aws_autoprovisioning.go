@@ -0,0 +1,256 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// autoprovisionedTagKey is the tag of ASGs created by node autoprovisioning, its value
+	// is the name of the cluster.
+	autoprovisionedTagKey = "k8s.io/cluster-autoscaler/autoprovisioned"
+
+	nodeTemplateLabelTagPrefix     = "k8s.io/cluster-autoscaler/node-template/label/"
+	nodeTemplateTaintTagPrefix     = "k8s.io/cluster-autoscaler/node-template/taint/"
+	nodeTemplateResourcesTagPrefix = "k8s.io/cluster-autoscaler/node-template/resources/"
+
+	defaultLaunchTemplateVersion  = "$Default"
+	defaultAutoprovisionedMaxSize = 100
+)
+
+// AutoprovisioningConfig is the configuration of ASGs created by node autoprovisioning.
+type AutoprovisioningConfig struct {
+	// AvailabilityZones of the ASGs. The first one is used for node templates.
+	AvailabilityZones []string `json:"availabilityZones"`
+	// Subnets of the ASGs. If set, they have to be in the availability zones.
+	Subnets []string `json:"subnets"`
+	// MaxSize of the ASGs.
+	MaxSize int `json:"maxSize"`
+	// Tags added to the ASGs and their instances.
+	Tags map[string]string `json:"tags"`
+	// InstanceTypes that can be autoprovisioned.
+	InstanceTypes []AutoprovisioningInstanceType `json:"instanceTypes"`
+}
+
+// AutoprovisioningInstanceType is an instance type that can be autoprovisioned.
+type AutoprovisioningInstanceType struct {
+	// InstanceType is the EC2 instance type set in the launch template.
+	InstanceType string `json:"instanceType"`
+	// LaunchTemplate used to create instances of the instance type.
+	LaunchTemplate LaunchTemplate `json:"launchTemplate"`
+	// GPUType is the value of the GPU label of nodes with GPUs.
+	GPUType string `json:"gpuType"`
+}
+
+// LaunchTemplate is a reference to a version of an EC2 launch template.
+type LaunchTemplate struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ParseAutoprovisioningConfig parses and validates the autoprovisioning configuration.
+func ParseAutoprovisioningConfig(data []byte) (*AutoprovisioningConfig, error) {
+	config := &AutoprovisioningConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse autoprovisioning config: %v", err)
+	}
+	if len(config.AvailabilityZones) == 0 {
+		return nil, fmt.Errorf("no availability zones in autoprovisioning config")
+	}
+	if config.MaxSize < 0 {
+		return nil, fmt.Errorf("autoprovisioning max size must be non-negative, got %d", config.MaxSize)
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = defaultAutoprovisionedMaxSize
+	}
+	if len(config.InstanceTypes) == 0 {
+		return nil, fmt.Errorf("no instance types in autoprovisioning config")
+	}
+	seen := make(map[string]bool)
+	for i := range config.InstanceTypes {
+		t := &config.InstanceTypes[i]
+		instanceType, found := InstanceTypes[t.InstanceType]
+		if !found {
+			return nil, fmt.Errorf("unknown EC2 instance type %q", t.InstanceType)
+		}
+		if seen[t.InstanceType] {
+			return nil, fmt.Errorf("instance type %s configured more than once", t.InstanceType)
+		}
+		seen[t.InstanceType] = true
+		if t.LaunchTemplate.Name == "" {
+			return nil, fmt.Errorf("no launch template for instance type %s", t.InstanceType)
+		}
+		if t.LaunchTemplate.Version == "" {
+			t.LaunchTemplate.Version = defaultLaunchTemplateVersion
+		}
+		if t.GPUType != "" {
+			if instanceType.GPU == 0 {
+				return nil, fmt.Errorf("GPU type set for instance type %s without GPUs", t.InstanceType)
+			}
+			if _, found := availableGPUTypes[t.GPUType]; !found {
+				return nil, fmt.Errorf("unknown GPU type %q for instance type %s", t.GPUType, t.InstanceType)
+			}
+		}
+	}
+	return config, nil
+}
+
+// autoprovisioningDiscoverySpec returns the node group auto-discovery spec of the ASGs
+// autoprovisioned for the given cluster.
+func autoprovisioningDiscoverySpec(clusterName string) string {
+	return fmt.Sprintf("asg:tag=%s=%s", autoprovisionedTagKey, clusterName)
+}
+
+func (m *AwsManager) getAutoprovisioningInstanceType(name string) *AutoprovisioningInstanceType {
+	if m.autoprovisioning == nil {
+		return nil
+	}
+	for i := range m.autoprovisioning.InstanceTypes {
+		if m.autoprovisioning.InstanceTypes[i].InstanceType == name {
+			return &m.autoprovisioning.InstanceTypes[i]
+		}
+	}
+	return nil
+}
+
+// isAutoprovisioned tells whether the ASG was autoprovisioned for this cluster.
+func (m *AwsManager) isAutoprovisioned(asg *asg) bool {
+	if m.autoprovisioning == nil {
+		return false
+	}
+	for _, tag := range asg.Tags {
+		if aws.StringValue(tag.Key) == autoprovisionedTagKey {
+			return aws.StringValue(tag.Value) == m.clusterName
+		}
+	}
+	return false
+}
+
+// buildAutoprovisionedAsg builds an ASG that doesn't exist yet. Labels, taints and extra
+// resources are stored in node template tags, which are propagated to instances.
+func (m *AwsManager) buildAutoprovisionedAsg(instanceTypeName string, labels map[string]string, systemLabels map[string]string,
+	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (*asg, error) {
+	if m.autoprovisioning == nil {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	t := m.getAutoprovisioningInstanceType(instanceTypeName)
+	if t == nil {
+		return nil, cloudprovider.ErrIllegalConfiguration
+	}
+
+	name := fmt.Sprintf("%s-nap-%s-%d", m.clusterName, instanceTypeName, rand.Int63())
+	tags := make(map[string]string)
+	for key, value := range m.autoprovisioning.Tags {
+		tags[key] = value
+	}
+	nodeLabels := cloudprovider.JoinStringMaps(systemLabels, labels)
+	if t.GPUType != "" {
+		nodeLabels[GPULabel] = t.GPUType
+	}
+	for key, value := range nodeLabels {
+		tags[nodeTemplateLabelTagPrefix+key] = value
+	}
+	for _, taint := range taints {
+		tags[nodeTemplateTaintTagPrefix+taint.Key] = fmt.Sprintf("%s:%s", taint.Value, taint.Effect)
+	}
+	for resourceName, quantity := range extraResources {
+		switch resourceName {
+		case gpu.ResourceNvidiaGPU:
+			if quantity.Value() > InstanceTypes[instanceTypeName].GPU {
+				return nil, cloudprovider.ErrIllegalConfiguration
+			}
+		case string(apiv1.ResourceEphemeralStorage):
+			tags[nodeTemplateResourcesTagPrefix+resourceName] = quantity.String()
+		default:
+			return nil, cloudprovider.ErrIllegalConfiguration
+		}
+	}
+
+	asgTags := make([]*autoscaling.TagDescription, 0, len(tags)+1)
+	asgTags = append(asgTags, buildAsgTag(name, autoprovisionedTagKey, m.clusterName, false))
+	for key, value := range tags {
+		asgTags = append(asgTags, buildAsgTag(name, key, value, true))
+	}
+
+	return &asg{
+		AwsRef:                AwsRef{Name: name},
+		minSize:               0,
+		maxSize:               m.autoprovisioning.MaxSize,
+		curSize:               0,
+		AvailabilityZones:     m.autoprovisioning.AvailabilityZones,
+		LaunchTemplateName:    t.LaunchTemplate.Name,
+		LaunchTemplateVersion: t.LaunchTemplate.Version,
+		Tags:                  asgTags,
+	}, nil
+}
+
+func buildAsgTag(asgName, key, value string, propagateAtLaunch bool) *autoscaling.TagDescription {
+	return &autoscaling.TagDescription{
+		Key:               aws.String(key),
+		Value:             aws.String(value),
+		PropagateAtLaunch: aws.Bool(propagateAtLaunch),
+		ResourceId:        aws.String(asgName),
+		ResourceType:      aws.String("auto-scaling-group"),
+	}
+}
+
+// createAsg creates the ASG on AWS side and registers it.
+func (m *AwsManager) createAsg(asg *asg) (*asg, error) {
+	tags := make([]*autoscaling.Tag, 0, len(asg.Tags))
+	for _, tag := range asg.Tags {
+		tags = append(tags, &autoscaling.Tag{
+			Key:               tag.Key,
+			Value:             tag.Value,
+			PropagateAtLaunch: tag.PropagateAtLaunch,
+			ResourceId:        tag.ResourceId,
+			ResourceType:      tag.ResourceType,
+		})
+	}
+	input := &autoscaling.CreateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(asg.Name),
+		MinSize:              aws.Int64(int64(asg.minSize)),
+		MaxSize:              aws.Int64(int64(asg.maxSize)),
+		DesiredCapacity:      aws.Int64(int64(asg.curSize)),
+		LaunchTemplate: &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: aws.String(asg.LaunchTemplateName),
+			Version:            aws.String(asg.LaunchTemplateVersion),
+		},
+		Tags: tags,
+	}
+	if len(m.autoprovisioning.Subnets) > 0 {
+		input.VPCZoneIdentifier = aws.String(strings.Join(m.autoprovisioning.Subnets, ","))
+	} else {
+		input.AvailabilityZones = aws.StringSlice(asg.AvailabilityZones)
+	}
+	return m.asgCache.CreateAsg(asg, input)
+}
+
+// deleteAsg deletes the ASG on AWS side and unregisters it.
+func (m *AwsManager) deleteAsg(asg *asg) error {
+	return m.asgCache.DeleteAsg(asg)
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+)
+
+const testAutoprovisioningConfig = `
+availabilityZones: [us-east-1a, us-east-1b]
+tags:
+  kubernetes.io/cluster/test: owned
+instanceTypes:
+- instanceType: m5.large
+  launchTemplate: {name: nodes-m5, version: "2"}
+- instanceType: p3.2xlarge
+  launchTemplate: {name: nodes-gpu}
+  gpuType: nvidia-tesla-v100
+`
+
+func TestParseAutoprovisioningConfig(t *testing.T) {
+	config, err := ParseAutoprovisioningConfig([]byte(testAutoprovisioningConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultAutoprovisionedMaxSize, config.MaxSize)
+	assert.Equal(t, LaunchTemplate{Name: "nodes-m5", Version: "2"}, config.InstanceTypes[0].LaunchTemplate)
+	assert.Equal(t, LaunchTemplate{Name: "nodes-gpu", Version: defaultLaunchTemplateVersion}, config.InstanceTypes[1].LaunchTemplate)
+
+	testCases := map[string]string{
+		"unknown key":           `{availabilityZones: [a], instanceTypes: [{instanceType: m5.large, launchTemplate: {name: lt}}], subnet: a}`,
+		"no zones":              `{instanceTypes: [{instanceType: m5.large, launchTemplate: {name: lt}}]}`,
+		"no instance types":     `{availabilityZones: [a]}`,
+		"negative max size":     `{availabilityZones: [a], maxSize: -1, instanceTypes: [{instanceType: m5.large, launchTemplate: {name: lt}}]}`,
+		"unknown instance type": `{availabilityZones: [a], instanceTypes: [{instanceType: m0.tiny, launchTemplate: {name: lt}}]}`,
+		"duplicate instance type": `{availabilityZones: [a], instanceTypes: [{instanceType: m5.large, launchTemplate: {name: a}},
+			{instanceType: m5.large, launchTemplate: {name: b}}]}`,
+		"no launch template":   `{availabilityZones: [a], instanceTypes: [{instanceType: m5.large}]}`,
+		"GPU type without GPU": `{availabilityZones: [a], instanceTypes: [{instanceType: m5.large, launchTemplate: {name: lt}, gpuType: nvidia-tesla-v100}]}`,
+		"unknown GPU type":     `{availabilityZones: [a], instanceTypes: [{instanceType: p3.2xlarge, launchTemplate: {name: lt}, gpuType: voodoo}]}`,
+	}
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAutoprovisioningConfig([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func newTestAutoprovisioningProvider(t *testing.T, service *AutoScalingMock) *awsCloudProvider {
+	config, err := ParseAutoprovisioningConfig([]byte(testAutoprovisioningConfig))
+	assert.NoError(t, err)
+	m := newTestAwsManagerWithAsgs(t, service, []string{"1:5:test-asg"})
+	m.autoprovisioning = config
+	m.clusterName = "test"
+	ec2Service := &EC2Mock{}
+	ec2Service.On("DescribeLaunchTemplateVersions", &ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateName: aws.String("nodes-gpu"),
+		Versions:           []*string{aws.String(defaultLaunchTemplateVersion)},
+	}).Return(&ec2.DescribeLaunchTemplateVersionsOutput{
+		LaunchTemplateVersions: []*ec2.LaunchTemplateVersion{
+			{LaunchTemplateData: &ec2.ResponseLaunchTemplateData{InstanceType: aws.String("p3.2xlarge")}},
+		},
+	})
+	m.ec2Service = ec2Wrapper{ec2Service}
+	return testProvider(t, m)
+}
+
+func TestGetAvailableMachineTypes(t *testing.T) {
+	provider := testProvider(t, newTestAwsManagerWithAsgs(t, &AutoScalingMock{}, []string{"1:5:test-asg"}))
+	machineTypes, err := provider.GetAvailableMachineTypes()
+	assert.NoError(t, err)
+	assert.Empty(t, machineTypes)
+	_, err = provider.NewNodeGroup("m5.large", nil, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+
+	provider = newTestAutoprovisioningProvider(t, &AutoScalingMock{})
+	machineTypes, err = provider.GetAvailableMachineTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m5.large", "p3.2xlarge"}, machineTypes)
+}
+
+func TestNewNodeGroup(t *testing.T) {
+	provider := newTestAutoprovisioningProvider(t, &AutoScalingMock{})
+
+	_, err := provider.NewNodeGroup("t2.micro", nil, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+	_, err = provider.NewNodeGroup("m5.large", nil, nil, nil,
+		map[string]resource.Quantity{gpu.ResourceNvidiaGPU: resource.MustParse("1")})
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+	_, err = provider.NewNodeGroup("m5.large", nil, nil, nil,
+		map[string]resource.Quantity{"example.com/dongle": resource.MustParse("1")})
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+
+	nodeGroup, err := provider.NewNodeGroup("p3.2xlarge",
+		map[string]string{"pool": "gpu"}, map[string]string{"team": "ml"},
+		[]apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}},
+		map[string]resource.Quantity{gpu.ResourceNvidiaGPU: resource.MustParse("1")})
+	assert.NoError(t, err)
+	assert.Contains(t, nodeGroup.Id(), "test-nap-p3.2xlarge-")
+	assert.False(t, nodeGroup.Exist())
+	assert.True(t, nodeGroup.Autoprovisioned())
+	assert.Equal(t, 0, nodeGroup.MinSize())
+	assert.Equal(t, defaultAutoprovisionedMaxSize, nodeGroup.MaxSize())
+	assert.Equal(t, 1, len(provider.NodeGroups()))
+
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	assert.NoError(t, err)
+	node := nodeInfo.Node()
+	assert.Equal(t, "gpu", node.Labels["pool"])
+	assert.Equal(t, "ml", node.Labels["team"])
+	assert.Equal(t, "nvidia-tesla-v100", node.Labels[GPULabel])
+	assert.Equal(t, "us-east-1a", node.Labels[apiv1.LabelZoneFailureDomain])
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}, node.Spec.Taints)
+	gpus := node.Status.Capacity[gpu.ResourceNvidiaGPU]
+	assert.Equal(t, int64(1), gpus.Value())
+}
+
+func TestCreateAndDeleteNodeGroup(t *testing.T) {
+	service := &AutoScalingMock{}
+	provider := newTestAutoprovisioningProvider(t, service)
+	nodeGroup, err := provider.NewNodeGroup("m5.large", map[string]string{"pool": "batch"}, nil, nil, nil)
+	assert.NoError(t, err)
+	name := nodeGroup.Id()
+
+	service.On("CreateAutoScalingGroup", mock.MatchedBy(func(input *autoscaling.CreateAutoScalingGroupInput) bool {
+		tags := make(map[string]string)
+		for _, tag := range input.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		return aws.StringValue(input.AutoScalingGroupName) == name &&
+			aws.Int64Value(input.MinSize) == 0 &&
+			aws.Int64Value(input.DesiredCapacity) == 0 &&
+			aws.StringValue(input.LaunchTemplate.LaunchTemplateName) == "nodes-m5" &&
+			aws.StringValue(input.LaunchTemplate.Version) == "2" &&
+			assert.ObjectsAreEqual([]string{"us-east-1a", "us-east-1b"}, aws.StringValueSlice(input.AvailabilityZones)) &&
+			assert.ObjectsAreEqual(map[string]string{
+				autoprovisionedTagKey:               "test",
+				"kubernetes.io/cluster/test":        "owned",
+				nodeTemplateLabelTagPrefix + "pool": "batch",
+			}, tags)
+	})).Return(&autoscaling.CreateAutoScalingGroupOutput{}, nil).Once()
+
+	created, err := nodeGroup.Create()
+	assert.NoError(t, err)
+	assert.True(t, created.Exist())
+	assert.True(t, created.Autoprovisioned())
+	assert.Equal(t, 2, len(provider.NodeGroups()))
+	nodes, err := created.Nodes()
+	assert.NoError(t, err)
+	assert.Empty(t, nodes)
+	_, err = created.Create()
+	assert.Equal(t, cloudprovider.ErrAlreadyExist, err)
+
+	// Explicitly configured ASGs are not autoprovisioned.
+	assert.Error(t, provider.NodeGroups()[0].Delete())
+
+	service.On("DeleteAutoScalingGroup", &autoscaling.DeleteAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(name),
+		ForceDelete:          aws.Bool(false),
+	}).Return(&autoscaling.DeleteAutoScalingGroupOutput{}, nil).Once()
+
+	assert.NoError(t, created.Delete())
+	assert.False(t, created.Exist())
+	assert.Equal(t, 1, len(provider.NodeGroups()))
+	service.AssertExpectations(t)
+}
+
+func TestDeleteNodeGroupWithInstances(t *testing.T) {
+	service := &AutoScalingMock{}
+	provider := newTestAutoprovisioningProvider(t, service)
+	nodeGroup, err := provider.NewNodeGroup("m5.large", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	service.On("CreateAutoScalingGroup", mock.Anything).Return(&autoscaling.CreateAutoScalingGroupOutput{}, nil)
+	created, err := nodeGroup.Create()
+	assert.NoError(t, err)
+
+	service.On("SetDesiredCapacity", mock.Anything).Return(&autoscaling.SetDesiredCapacityOutput{})
+	assert.NoError(t, created.IncreaseSize(1))
+	assert.Error(t, created.Delete())
+	assert.True(t, created.Exist())
+	service.AssertNotCalled(t, "DeleteAutoScalingGroup", mock.Anything)
+}
@@ -19,6 +19,7 @@ package aws
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
@@ -52,13 +53,15 @@ var (
 type awsCloudProvider struct {
 	awsManager      *AwsManager
 	resourceLimiter *cloudprovider.ResourceLimiter
+	pricingModel    *AwsPriceModel
 }
 
 // BuildAwsCloudProvider builds CloudProvider implementation for AWS.
-func BuildAwsCloudProvider(awsManager *AwsManager, resourceLimiter *cloudprovider.ResourceLimiter) (cloudprovider.CloudProvider, error) {
+func BuildAwsCloudProvider(awsManager *AwsManager, resourceLimiter *cloudprovider.ResourceLimiter, pricingModel *AwsPriceModel) (cloudprovider.CloudProvider, error) {
 	aws := &awsCloudProvider{
 		awsManager:      awsManager,
 		resourceLimiter: resourceLimiter,
+		pricingModel:    pricingModel,
 	}
 	return aws, nil
 }
@@ -122,19 +125,35 @@ func (aws *awsCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.N
 
 // Pricing returns pricing model for this cloud provider or error if not available.
 func (aws *awsCloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
-	return nil, cloudprovider.ErrNotImplemented
+	if aws.pricingModel == nil {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	return aws.pricingModel, nil
 }
 
 // GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
 func (aws *awsCloudProvider) GetAvailableMachineTypes() ([]string, error) {
-	return []string{}, nil
+	machineTypes := []string{}
+	if aws.awsManager.autoprovisioning != nil {
+		for _, t := range aws.awsManager.autoprovisioning.InstanceTypes {
+			machineTypes = append(machineTypes, t.InstanceType)
+		}
+	}
+	return machineTypes, nil
 }
 
 // NewNodeGroup builds a theoretical node group based on the node definition provided. The node group is not automatically
 // created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
 func (aws *awsCloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
 	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	asg, err := aws.awsManager.buildAutoprovisionedAsg(machineType, labels, systemLabels, taints, extraResources)
+	if err != nil {
+		return nil, err
+	}
+	return &AwsNodeGroup{
+		asg:        asg,
+		awsManager: aws.awsManager,
+	}, nil
 }
 
 // GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
@@ -199,23 +218,36 @@ func (ng *AwsNodeGroup) TargetSize() (int, error) {
 // Exist checks if the node group really exists on the cloud provider side. Allows to tell the
 // theoretical node group from the real one.
 func (ng *AwsNodeGroup) Exist() bool {
-	return true
+	return ng.awsManager.asgCache.Exists(ng.asg.AwsRef)
 }
 
 // Create creates the node group on the cloud provider side.
 func (ng *AwsNodeGroup) Create() (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrAlreadyExist
+	if ng.Exist() {
+		return nil, cloudprovider.ErrAlreadyExist
+	}
+	asg, err := ng.awsManager.createAsg(ng.asg)
+	if err != nil {
+		return nil, err
+	}
+	return &AwsNodeGroup{
+		asg:        asg,
+		awsManager: ng.awsManager,
+	}, nil
 }
 
 // Autoprovisioned returns true if the node group is autoprovisioned.
 func (ng *AwsNodeGroup) Autoprovisioned() bool {
-	return false
+	return ng.awsManager.isAutoprovisioned(ng.asg)
 }
 
 // Delete deletes the node group on the cloud provider side.
 // This will be executed only for autoprovisioned node groups, once their size drops to 0.
 func (ng *AwsNodeGroup) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	if !ng.Autoprovisioned() {
+		return fmt.Errorf("asg %s is not autoprovisioned and can't be deleted", ng.asg.Name)
+	}
+	return ng.awsManager.deleteAsg(ng.asg)
 }
 
 // IncreaseSize increases Asg size
@@ -346,12 +378,35 @@ func BuildAWS(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscover
 		defer config.Close()
 	}
 
+	var autoprovisioning *AutoprovisioningConfig
+	if opts.NodeAutoprovisioningEnabled && opts.AWSAutoprovisioningConfig != "" {
+		if opts.ClusterName == "" {
+			klog.Fatalf("Cluster name must be set with --cluster-name for node autoprovisioning on AWS")
+		}
+		data, err := ioutil.ReadFile(opts.AWSAutoprovisioningConfig)
+		if err != nil {
+			klog.Fatalf("Couldn't read AWS autoprovisioning configuration %s: %v", opts.AWSAutoprovisioningConfig, err)
+		}
+		autoprovisioning, err = ParseAutoprovisioningConfig(data)
+		if err != nil {
+			klog.Fatalf("Failed to parse AWS autoprovisioning configuration: %v", err)
+		}
+		// Autoprovisioned ASGs are discovered by their tags.
+		do.NodeGroupAutoDiscoverySpecs = append(do.NodeGroupAutoDiscoverySpecs, autoprovisioningDiscoverySpec(opts.ClusterName))
+	}
+
 	manager, err := CreateAwsManager(config, do)
 	if err != nil {
 		klog.Fatalf("Failed to create AWS Manager: %v", err)
 	}
+	manager.autoprovisioning = autoprovisioning
+	manager.clusterName = opts.ClusterName
+
+	if opts.AWSSpotPriceDiscount < 0 || opts.AWSSpotPriceDiscount >= 1 {
+		klog.Fatalf("Invalid AWS spot price discount %v, must be at least 0 and less than 1", opts.AWSSpotPriceDiscount)
+	}
 
-	provider, err := BuildAwsCloudProvider(manager, rl)
+	provider, err := BuildAwsCloudProvider(manager, rl, NewAwsPriceModel(opts.AWSSpotPriceDiscount))
 	if err != nil {
 		klog.Fatalf("Failed to create AWS cloud provider: %v", err)
 	}
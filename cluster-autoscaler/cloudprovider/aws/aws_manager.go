@@ -59,6 +59,11 @@ type AwsManager struct {
 	ec2Service         ec2Wrapper
 	asgCache           *asgCache
 	lastRefresh        time.Time
+
+	// autoprovisioning is the configuration of autoprovisioned ASGs, nil if node
+	// autoprovisioning is disabled.
+	autoprovisioning *AutoprovisioningConfig
+	clusterName      string
 }
 
 type asgTemplate struct {
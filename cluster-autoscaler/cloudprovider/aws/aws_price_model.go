@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/pricing"
+)
+
+// AwsPriceModel implements PricingModel interface for AWS using the on-demand prices
+// from InstancePrices.
+type AwsPriceModel struct {
+	spotDiscount float64
+}
+
+// defaultPriceRegion is used for nodes from regions missing in InstancePrices.
+const defaultPriceRegion = "us-east-1"
+
+// NewAwsPriceModel creates a price model discounting the price of spot nodes by spotDiscount,
+// e.g. 0.7 for spot instances costing 30% of on-demand instances.
+func NewAwsPriceModel(spotDiscount float64) *AwsPriceModel {
+	return &AwsPriceModel{spotDiscount: spotDiscount}
+}
+
+// NodePrice returns a price of running the given node for a given period of time.
+// All prices are in USD.
+func (model *AwsPriceModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	price := 0.0
+	if pricePerHour, found := instancePrice(node.Labels[apiv1.LabelInstanceType], node.Labels[apiv1.LabelZoneRegion]); found {
+		price = pricePerHour * pricing.GetHours(startTime, endTime)
+	} else {
+		// The default resource prices add up to the price of m5 instances in us-east-1,
+		// so they underestimate other regions and instance families.
+		price = pricing.DefaultResourcePrices.ResourcesPrice(node.Status.Capacity, startTime, endTime)
+	}
+	if capacitytype.GetCapacityType(node) == capacitytype.Spot {
+		price = price * (1 - model.spotDiscount)
+	}
+	return price, nil
+}
+
+// PodPrice returns a theoretical minimum price of running a pod for a given
+// period of time on a perfectly matching machine.
+func (model *AwsPriceModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return pricing.DefaultResourcePrices.PodPrice(pod, startTime, endTime), nil
+}
+
+// instancePrice returns the hourly price of the instance type in the region, or in the
+// default region if the region is unknown.
+func instancePrice(instanceType, region string) (float64, bool) {
+	if instanceType == "" {
+		return 0, false
+	}
+	if prices, found := InstancePrices[region]; found {
+		price, found := prices[instanceType]
+		return price, found
+	}
+	price, found := InstancePrices[defaultPriceRegion][instanceType]
+	return price, found
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/pricing"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPricedNode(name, instanceTypeName, region string, cpu int64, mem int64) *apiv1.Node {
+	node := BuildTestNode(name, cpu, mem)
+	node.Labels = buildGenericLabels(&asgTemplate{
+		InstanceType: &instanceType{InstanceType: instanceTypeName},
+		Region:       region,
+		Zone:         region + "a",
+	}, name)
+	return node
+}
+
+func TestGetNodePrice(t *testing.T) {
+	model := NewAwsPriceModel(0.7)
+	now := time.Now()
+
+	// Known instance type and region.
+	m5 := buildPricedNode("m5", "m5.2xlarge", "us-east-1", 8000, 32*units.GiB)
+	price, err := model.NodePrice(m5, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.384, price, 1e-9)
+
+	price, err = model.NodePrice(m5, now, now.Add(90*time.Minute))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.576, price, 1e-9)
+
+	// Prices differ between regions.
+	euM5 := buildPricedNode("eu-m5", "m5.2xlarge", "eu-west-1", 8000, 32*units.GiB)
+	euPrice, err := model.NodePrice(euM5, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, euPrice > 0.384)
+
+	// Unknown regions use the default region prices.
+	otherM5 := buildPricedNode("other-m5", "m5.2xlarge", "xx-nowhere-1", 8000, 32*units.GiB)
+	otherPrice, err := model.NodePrice(otherM5, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.384, otherPrice, 1e-9)
+
+	// Spot nodes are discounted.
+	spotM5 := buildPricedNode("spot-m5", "m5.2xlarge", "us-east-1", 8000, 32*units.GiB)
+	spotM5.Labels["eks.amazonaws.com/capacityType"] = "SPOT"
+	spotPrice, err := model.NodePrice(spotM5, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.384*0.3, spotPrice, 1e-9)
+
+	// Unknown instance types are priced by their resources.
+	custom := buildPricedNode("custom", "x9.2xlarge", "us-east-1", 8000, 32*units.GiB)
+	customPrice, err := model.NodePrice(custom, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 8*pricing.DefaultResourcePrices.CPU+32*pricing.DefaultResourcePrices.Memory, customPrice, 1e-9)
+	custom.Status.Capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(1, resource.DecimalSI)
+	customGpuPrice, err := model.NodePrice(custom, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, customPrice+pricing.DefaultResourcePrices.GPU, customGpuPrice, 1e-9)
+}
+
+func TestGetPodPrice(t *testing.T) {
+	model := NewAwsPriceModel(0)
+	now := time.Now()
+
+	pod1 := BuildTestPod("a1", 100, 500*units.MiB)
+	pod2 := BuildTestPod("a2", 2*100, 2*500*units.MiB)
+
+	price1, err := model.PodPrice(pod1, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	price2, err := model.PodPrice(pod2, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*price1, price2, 1e-9)
+}
+
+func TestInstancePricesUseKnownInstanceTypes(t *testing.T) {
+	assert.NotEmpty(t, InstancePrices[defaultPriceRegion])
+	for region, prices := range InstancePrices {
+		for instanceType, price := range prices {
+			_, found := InstanceTypes[instanceType]
+			assert.True(t, found, "unknown instance type %s in %s", instanceType, region)
+			assert.True(t, price > 0, "no price of %s in %s", instanceType, region)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+// InstancePrices maps regions to hourly on-demand prices in USD of ec2 instance types
+// running Linux on shared tenancy.
+//
+// The table is maintained by hand and covers only common instance families in the regions
+// with most clusters, copied from the AWS price list (https://aws.amazon.com/ec2/pricing/on-demand/).
+// Nodes from other regions are priced as in us-east-1, which is cheaper than most of them, and
+// other instance types by their resources, see AwsPriceModel. Add regions or instance types
+// here if the price expander needs them.
+var InstancePrices = map[string]map[string]float64{
+	"eu-central-1": {
+		"c4.2xlarge":   0.454,
+		"c4.4xlarge":   0.909,
+		"c4.8xlarge":   1.817,
+		"c4.large":     0.114,
+		"c4.xlarge":    0.227,
+		"c5.18xlarge":  3.492,
+		"c5.2xlarge":   0.388,
+		"c5.4xlarge":   0.776,
+		"c5.9xlarge":   1.746,
+		"c5.large":     0.097,
+		"c5.xlarge":    0.194,
+		"g3.16xlarge":  5.7,
+		"g3.4xlarge":   1.425,
+		"g3.8xlarge":   2.85,
+		"i3.16xlarge":  5.952,
+		"i3.2xlarge":   0.744,
+		"i3.4xlarge":   1.488,
+		"i3.8xlarge":   2.976,
+		"i3.large":     0.186,
+		"i3.xlarge":    0.372,
+		"m4.10xlarge":  2.4,
+		"m4.16xlarge":  3.84,
+		"m4.2xlarge":   0.48,
+		"m4.4xlarge":   0.96,
+		"m4.large":     0.12,
+		"m4.xlarge":    0.24,
+		"m5.12xlarge":  2.76,
+		"m5.24xlarge":  5.52,
+		"m5.2xlarge":   0.46,
+		"m5.4xlarge":   0.92,
+		"m5.large":     0.115,
+		"m5.xlarge":    0.23,
+		"m5a.12xlarge": 2.496,
+		"m5a.24xlarge": 4.992,
+		"m5a.2xlarge":  0.416,
+		"m5a.4xlarge":  0.832,
+		"m5a.large":    0.104,
+		"m5a.xlarge":   0.208,
+		"p2.16xlarge":  21.216,
+		"p2.8xlarge":   10.608,
+		"p2.xlarge":    1.326,
+		"p3.16xlarge":  30.584,
+		"p3.2xlarge":   3.823,
+		"p3.8xlarge":   15.292,
+		"r4.16xlarge":  5.12,
+		"r4.2xlarge":   0.64,
+		"r4.4xlarge":   1.28,
+		"r4.8xlarge":   2.56,
+		"r4.large":     0.16,
+		"r4.xlarge":    0.32,
+		"r5.12xlarge":  3.648,
+		"r5.24xlarge":  7.296,
+		"r5.2xlarge":   0.608,
+		"r5.4xlarge":   1.216,
+		"r5.large":     0.152,
+		"r5.xlarge":    0.304,
+		"t2.2xlarge":   0.4288,
+		"t2.large":     0.1072,
+		"t2.medium":    0.0536,
+		"t2.micro":     0.0134,
+		"t2.nano":      0.0067,
+		"t2.small":     0.0268,
+		"t2.xlarge":    0.2144,
+		"t3.2xlarge":   0.384,
+		"t3.large":     0.096,
+		"t3.medium":    0.048,
+		"t3.micro":     0.012,
+		"t3.nano":      0.006,
+		"t3.small":     0.024,
+		"t3.xlarge":    0.192,
+	},
+	"eu-west-1": {
+		"c4.2xlarge":   0.453,
+		"c4.4xlarge":   0.905,
+		"c4.8xlarge":   1.811,
+		"c4.large":     0.113,
+		"c4.xlarge":    0.226,
+		"c5.18xlarge":  3.456,
+		"c5.2xlarge":   0.384,
+		"c5.4xlarge":   0.768,
+		"c5.9xlarge":   1.728,
+		"c5.large":     0.096,
+		"c5.xlarge":    0.192,
+		"g3.16xlarge":  4.84,
+		"g3.4xlarge":   1.21,
+		"g3.8xlarge":   2.42,
+		"i3.16xlarge":  5.504,
+		"i3.2xlarge":   0.688,
+		"i3.4xlarge":   1.376,
+		"i3.8xlarge":   2.752,
+		"i3.large":     0.172,
+		"i3.xlarge":    0.344,
+		"m4.10xlarge":  2.22,
+		"m4.16xlarge":  3.552,
+		"m4.2xlarge":   0.444,
+		"m4.4xlarge":   0.888,
+		"m4.large":     0.111,
+		"m4.xlarge":    0.222,
+		"m5.12xlarge":  2.568,
+		"m5.24xlarge":  5.136,
+		"m5.2xlarge":   0.428,
+		"m5.4xlarge":   0.856,
+		"m5.large":     0.107,
+		"m5.xlarge":    0.214,
+		"m5a.12xlarge": 2.304,
+		"m5a.24xlarge": 4.608,
+		"m5a.2xlarge":  0.384,
+		"m5a.4xlarge":  0.768,
+		"m5a.large":    0.096,
+		"m5a.xlarge":   0.192,
+		"p2.16xlarge":  15.552,
+		"p2.8xlarge":   7.776,
+		"p2.xlarge":    0.972,
+		"p3.16xlarge":  26.44,
+		"p3.2xlarge":   3.305,
+		"p3.8xlarge":   13.22,
+		"r4.16xlarge":  4.736,
+		"r4.2xlarge":   0.592,
+		"r4.4xlarge":   1.184,
+		"r4.8xlarge":   2.368,
+		"r4.large":     0.148,
+		"r4.xlarge":    0.296,
+		"r5.12xlarge":  3.384,
+		"r5.24xlarge":  6.768,
+		"r5.2xlarge":   0.564,
+		"r5.4xlarge":   1.128,
+		"r5.large":     0.141,
+		"r5.xlarge":    0.282,
+		"t2.2xlarge":   0.4032,
+		"t2.large":     0.1008,
+		"t2.medium":    0.0504,
+		"t2.micro":     0.0126,
+		"t2.nano":      0.0063,
+		"t2.small":     0.0252,
+		"t2.xlarge":    0.2016,
+		"t3.2xlarge":   0.3648,
+		"t3.large":     0.0912,
+		"t3.medium":    0.0456,
+		"t3.micro":     0.0114,
+		"t3.nano":      0.0057,
+		"t3.small":     0.0228,
+		"t3.xlarge":    0.1824,
+	},
+	"us-east-1": {
+		"c4.2xlarge":   0.398,
+		"c4.4xlarge":   0.796,
+		"c4.8xlarge":   1.591,
+		"c4.large":     0.1,
+		"c4.xlarge":    0.199,
+		"c5.18xlarge":  3.06,
+		"c5.2xlarge":   0.34,
+		"c5.4xlarge":   0.68,
+		"c5.9xlarge":   1.53,
+		"c5.large":     0.085,
+		"c5.xlarge":    0.17,
+		"g3.16xlarge":  4.56,
+		"g3.4xlarge":   1.14,
+		"g3.8xlarge":   2.28,
+		"i3.16xlarge":  4.992,
+		"i3.2xlarge":   0.624,
+		"i3.4xlarge":   1.248,
+		"i3.8xlarge":   2.496,
+		"i3.large":     0.156,
+		"i3.xlarge":    0.312,
+		"m4.10xlarge":  2,
+		"m4.16xlarge":  3.2,
+		"m4.2xlarge":   0.4,
+		"m4.4xlarge":   0.8,
+		"m4.large":     0.1,
+		"m4.xlarge":    0.2,
+		"m5.12xlarge":  2.304,
+		"m5.24xlarge":  4.608,
+		"m5.2xlarge":   0.384,
+		"m5.4xlarge":   0.768,
+		"m5.large":     0.096,
+		"m5.xlarge":    0.192,
+		"m5a.12xlarge": 2.064,
+		"m5a.24xlarge": 4.128,
+		"m5a.2xlarge":  0.344,
+		"m5a.4xlarge":  0.688,
+		"m5a.large":    0.086,
+		"m5a.xlarge":   0.172,
+		"p2.16xlarge":  14.4,
+		"p2.8xlarge":   7.2,
+		"p2.xlarge":    0.9,
+		"p3.16xlarge":  24.48,
+		"p3.2xlarge":   3.06,
+		"p3.8xlarge":   12.24,
+		"r4.16xlarge":  4.256,
+		"r4.2xlarge":   0.532,
+		"r4.4xlarge":   1.064,
+		"r4.8xlarge":   2.128,
+		"r4.large":     0.133,
+		"r4.xlarge":    0.266,
+		"r5.12xlarge":  3.024,
+		"r5.24xlarge":  6.048,
+		"r5.2xlarge":   0.504,
+		"r5.4xlarge":   1.008,
+		"r5.large":     0.126,
+		"r5.xlarge":    0.252,
+		"t2.2xlarge":   0.3712,
+		"t2.large":     0.0928,
+		"t2.medium":    0.0464,
+		"t2.micro":     0.0116,
+		"t2.nano":      0.0058,
+		"t2.small":     0.0232,
+		"t2.xlarge":    0.1856,
+		"t3.2xlarge":   0.3328,
+		"t3.large":     0.0832,
+		"t3.medium":    0.0416,
+		"t3.micro":     0.0104,
+		"t3.nano":      0.0052,
+		"t3.small":     0.0208,
+		"t3.xlarge":    0.1664,
+	},
+	"us-east-2": {
+		"c4.2xlarge":   0.398,
+		"c4.4xlarge":   0.796,
+		"c4.8xlarge":   1.591,
+		"c4.large":     0.1,
+		"c4.xlarge":    0.199,
+		"c5.18xlarge":  3.06,
+		"c5.2xlarge":   0.34,
+		"c5.4xlarge":   0.68,
+		"c5.9xlarge":   1.53,
+		"c5.large":     0.085,
+		"c5.xlarge":    0.17,
+		"g3.16xlarge":  4.56,
+		"g3.4xlarge":   1.14,
+		"g3.8xlarge":   2.28,
+		"i3.16xlarge":  4.992,
+		"i3.2xlarge":   0.624,
+		"i3.4xlarge":   1.248,
+		"i3.8xlarge":   2.496,
+		"i3.large":     0.156,
+		"i3.xlarge":    0.312,
+		"m4.10xlarge":  2,
+		"m4.16xlarge":  3.2,
+		"m4.2xlarge":   0.4,
+		"m4.4xlarge":   0.8,
+		"m4.large":     0.1,
+		"m4.xlarge":    0.2,
+		"m5.12xlarge":  2.304,
+		"m5.24xlarge":  4.608,
+		"m5.2xlarge":   0.384,
+		"m5.4xlarge":   0.768,
+		"m5.large":     0.096,
+		"m5.xlarge":    0.192,
+		"m5a.12xlarge": 2.064,
+		"m5a.24xlarge": 4.128,
+		"m5a.2xlarge":  0.344,
+		"m5a.4xlarge":  0.688,
+		"m5a.large":    0.086,
+		"m5a.xlarge":   0.172,
+		"p2.16xlarge":  14.4,
+		"p2.8xlarge":   7.2,
+		"p2.xlarge":    0.9,
+		"p3.16xlarge":  24.48,
+		"p3.2xlarge":   3.06,
+		"p3.8xlarge":   12.24,
+		"r4.16xlarge":  4.256,
+		"r4.2xlarge":   0.532,
+		"r4.4xlarge":   1.064,
+		"r4.8xlarge":   2.128,
+		"r4.large":     0.133,
+		"r4.xlarge":    0.266,
+		"r5.12xlarge":  3.024,
+		"r5.24xlarge":  6.048,
+		"r5.2xlarge":   0.504,
+		"r5.4xlarge":   1.008,
+		"r5.large":     0.126,
+		"r5.xlarge":    0.252,
+		"t2.2xlarge":   0.3712,
+		"t2.large":     0.0928,
+		"t2.medium":    0.0464,
+		"t2.micro":     0.0116,
+		"t2.nano":      0.0058,
+		"t2.small":     0.0232,
+		"t2.xlarge":    0.1856,
+		"t3.2xlarge":   0.3328,
+		"t3.large":     0.0832,
+		"t3.medium":    0.0416,
+		"t3.micro":     0.0104,
+		"t3.nano":      0.0052,
+		"t3.small":     0.0208,
+		"t3.xlarge":    0.1664,
+	},
+	"us-west-2": {
+		"c4.2xlarge":   0.398,
+		"c4.4xlarge":   0.796,
+		"c4.8xlarge":   1.591,
+		"c4.large":     0.1,
+		"c4.xlarge":    0.199,
+		"c5.18xlarge":  3.06,
+		"c5.2xlarge":   0.34,
+		"c5.4xlarge":   0.68,
+		"c5.9xlarge":   1.53,
+		"c5.large":     0.085,
+		"c5.xlarge":    0.17,
+		"g3.16xlarge":  4.56,
+		"g3.4xlarge":   1.14,
+		"g3.8xlarge":   2.28,
+		"i3.16xlarge":  4.992,
+		"i3.2xlarge":   0.624,
+		"i3.4xlarge":   1.248,
+		"i3.8xlarge":   2.496,
+		"i3.large":     0.156,
+		"i3.xlarge":    0.312,
+		"m4.10xlarge":  2,
+		"m4.16xlarge":  3.2,
+		"m4.2xlarge":   0.4,
+		"m4.4xlarge":   0.8,
+		"m4.large":     0.1,
+		"m4.xlarge":    0.2,
+		"m5.12xlarge":  2.304,
+		"m5.24xlarge":  4.608,
+		"m5.2xlarge":   0.384,
+		"m5.4xlarge":   0.768,
+		"m5.large":     0.096,
+		"m5.xlarge":    0.192,
+		"m5a.12xlarge": 2.064,
+		"m5a.24xlarge": 4.128,
+		"m5a.2xlarge":  0.344,
+		"m5a.4xlarge":  0.688,
+		"m5a.large":    0.086,
+		"m5a.xlarge":   0.172,
+		"p2.16xlarge":  14.4,
+		"p2.8xlarge":   7.2,
+		"p2.xlarge":    0.9,
+		"p3.16xlarge":  24.48,
+		"p3.2xlarge":   3.06,
+		"p3.8xlarge":   12.24,
+		"r4.16xlarge":  4.256,
+		"r4.2xlarge":   0.532,
+		"r4.4xlarge":   1.064,
+		"r4.8xlarge":   2.128,
+		"r4.large":     0.133,
+		"r4.xlarge":    0.266,
+		"r5.12xlarge":  3.024,
+		"r5.24xlarge":  6.048,
+		"r5.2xlarge":   0.504,
+		"r5.4xlarge":   1.008,
+		"r5.large":     0.126,
+		"r5.xlarge":    0.252,
+		"t2.2xlarge":   0.3712,
+		"t2.large":     0.0928,
+		"t2.medium":    0.0464,
+		"t2.micro":     0.0116,
+		"t2.nano":      0.0058,
+		"t2.small":     0.0232,
+		"t2.xlarge":    0.1856,
+		"t3.2xlarge":   0.3328,
+		"t3.large":     0.0832,
+		"t3.medium":    0.0416,
+		"t3.micro":     0.0104,
+		"t3.nano":      0.0052,
+		"t3.small":     0.0208,
+		"t3.xlarge":    0.1664,
+	},
+}
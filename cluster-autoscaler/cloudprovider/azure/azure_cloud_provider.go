@@ -49,6 +49,7 @@ var (
 type AzureCloudProvider struct {
 	azureManager    *AzureManager
 	resourceLimiter *cloudprovider.ResourceLimiter
+	pricingModel    *AzurePriceModel
 }
 
 // BuildAzureCloudProvider creates new AzureCloudProvider
@@ -56,6 +57,7 @@ func BuildAzureCloudProvider(azureManager *AzureManager, resourceLimiter *cloudp
 	azure := &AzureCloudProvider{
 		azureManager:    azureManager,
 		resourceLimiter: resourceLimiter,
+		pricingModel:    NewAzurePriceModel(azureManager.config.LowPriorityDiscount),
 	}
 
 	return azure, nil
@@ -105,7 +107,10 @@ func (azure *AzureCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovid
 
 // Pricing returns pricing model for this cloud provider or error if not available.
 func (azure *AzureCloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
-	return nil, cloudprovider.ErrNotImplemented
+	if azure.pricingModel == nil {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	return azure.pricingModel, nil
 }
 
 // GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
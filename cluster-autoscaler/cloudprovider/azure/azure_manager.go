@@ -79,6 +79,9 @@ type Config struct {
 	ClusterName string `json:"clusterName" yaml:"clusterName"`
 	//Config only for AKS
 	NodeResourceGroup string `json:"nodeResourceGroup" yaml:"nodeResourceGroup"`
+
+	// LowPriorityDiscount is the fraction of the regular price saved by low-priority VMs, used by the pricing model.
+	LowPriorityDiscount float64 `json:"lowPriorityDiscount" yaml:"lowPriorityDiscount"`
 }
 
 // TrimSpace removes all leading and trailing white spaces.
@@ -128,6 +131,14 @@ func CreateAzureManager(configReader io.Reader, discoveryOpts cloudprovider.Node
 				return nil, err
 			}
 		}
+
+		lowPriorityDiscountFromEnv := os.Getenv("ARM_LOW_PRIORITY_DISCOUNT")
+		if len(lowPriorityDiscountFromEnv) > 0 {
+			cfg.LowPriorityDiscount, err = strconv.ParseFloat(lowPriorityDiscountFromEnv, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	cfg.TrimSpace()
 
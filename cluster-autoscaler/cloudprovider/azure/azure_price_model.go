@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/pricing"
+)
+
+// AzurePriceModel implements PricingModel interface for Azure. It works for nodes of both
+// scale sets and agent pools.
+type AzurePriceModel struct {
+	lowPriorityDiscount float64
+}
+
+const (
+	// scaleSetPriorityLabel is set to lowPriorityValue on nodes of low-priority scale sets.
+	scaleSetPriorityLabel = "kubernetes.azure.com/scalesetpriority"
+	lowPriorityValue      = "low"
+)
+
+var (
+	// vmSizePrices are hourly pay-as-you-go prices in USD of Linux VM sizes in eastus, copied by
+	// hand from https://azure.microsoft.com/pricing/details/virtual-machines/linux/. Only common
+	// VM sizes are listed, other ones are priced by their resources.
+	vmSizePrices = map[string]float64{
+		"Standard_B1ms":     0.0207,
+		"Standard_B1s":      0.0104,
+		"Standard_B2ms":     0.0832,
+		"Standard_B2s":      0.0416,
+		"Standard_B4ms":     0.166,
+		"Standard_B8ms":     0.333,
+		"Standard_D16_v3":   0.768,
+		"Standard_D16s_v3":  0.768,
+		"Standard_D1_v2":    0.073,
+		"Standard_D2_v2":    0.146,
+		"Standard_D2_v3":    0.096,
+		"Standard_D2s_v3":   0.096,
+		"Standard_D32_v3":   1.536,
+		"Standard_D32s_v3":  1.536,
+		"Standard_D3_v2":    0.293,
+		"Standard_D4_v2":    0.585,
+		"Standard_D4_v3":    0.192,
+		"Standard_D4s_v3":   0.192,
+		"Standard_D5_v2":    1.17,
+		"Standard_D64_v3":   3.072,
+		"Standard_D64s_v3":  3.072,
+		"Standard_D8_v3":    0.384,
+		"Standard_D8s_v3":   0.384,
+		"Standard_DS1_v2":   0.073,
+		"Standard_DS2_v2":   0.146,
+		"Standard_DS3_v2":   0.293,
+		"Standard_DS4_v2":   0.585,
+		"Standard_DS5_v2":   1.17,
+		"Standard_E16_v3":   1.008,
+		"Standard_E16s_v3":  1.008,
+		"Standard_E2_v3":    0.126,
+		"Standard_E2s_v3":   0.126,
+		"Standard_E32_v3":   2.016,
+		"Standard_E32s_v3":  2.016,
+		"Standard_E4_v3":    0.252,
+		"Standard_E4s_v3":   0.252,
+		"Standard_E64_v3":   3.629,
+		"Standard_E64s_v3":  3.629,
+		"Standard_E8_v3":    0.504,
+		"Standard_E8s_v3":   0.504,
+		"Standard_F16s_v2":  0.677,
+		"Standard_F2s_v2":   0.085,
+		"Standard_F32s_v2":  1.353,
+		"Standard_F4s_v2":   0.169,
+		"Standard_F64s_v2":  2.706,
+		"Standard_F72s_v2":  3.045,
+		"Standard_F8s_v2":   0.338,
+		"Standard_NC12":     1.8,
+		"Standard_NC12s_v3": 6.12,
+		"Standard_NC24":     3.6,
+		"Standard_NC24s_v3": 12.24,
+		"Standard_NC6":      0.9,
+		"Standard_NC6s_v3":  3.06,
+	}
+
+	// regionPriceMultipliers adjust vmSizePrices to other regions. They are approximate ratios of
+	// Dsv3 prices in the region to their eastus prices, taken from the same page as vmSizePrices,
+	// and should be updated together with it. Regions missing here are priced like eastus.
+	regionPriceMultipliers = map[string]float64{
+		"eastus":         1.0,
+		"eastus2":        1.0,
+		"westus2":        1.0,
+		"centralus":      1.04,
+		"northcentralus": 1.04,
+		"southcentralus": 1.04,
+		"westus":         1.1,
+		"canadacentral":  1.08,
+		"northeurope":    1.04,
+		"westeurope":     1.11,
+		"uksouth":        1.1,
+		"francecentral":  1.15,
+		"southeastasia":  1.16,
+		"eastasia":       1.3,
+		"centralindia":   1.1,
+		"japaneast":      1.25,
+		"koreacentral":   1.2,
+		"australiaeast":  1.26,
+		"brazilsouth":    1.55,
+	}
+)
+
+// NewAzurePriceModel creates a price model discounting the price of nodes of low-priority
+// scale sets by lowPriorityDiscount, e.g. 0.8 for low-priority VMs costing 20% of regular ones.
+func NewAzurePriceModel(lowPriorityDiscount float64) *AzurePriceModel {
+	return &AzurePriceModel{lowPriorityDiscount: lowPriorityDiscount}
+}
+
+// NodePrice returns a price of running the given node for a given period of time.
+// All prices are in USD.
+func (model *AzurePriceModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	hours := pricing.GetHours(startTime, endTime)
+	prices := pricing.DefaultResourcePrices
+	price := 0.0
+	vmSize := node.Labels[apiv1.LabelInstanceType]
+	if pricePerHour, found := vmSizePrices[vmSize]; found {
+		price = pricePerHour * hours
+	} else if vmType, found := InstanceTypes[vmSize]; found {
+		price = (float64(vmType.VCPU)*prices.CPU +
+			float64(vmType.MemoryMb)/1024*prices.Memory +
+			float64(vmType.GPU)*prices.GPU) * hours
+	} else {
+		price = prices.ResourcesPrice(node.Status.Capacity, startTime, endTime)
+	}
+	if multiplier, found := regionPriceMultipliers[strings.ToLower(node.Labels[apiv1.LabelZoneRegion])]; found {
+		price = price * multiplier
+	}
+	if capacitytype.GetCapacityType(node) == capacitytype.Spot {
+		price = price * (1 - model.lowPriorityDiscount)
+	}
+	return price, nil
+}
+
+// PodPrice returns a theoretical minimum price of running a pod for a given
+// period of time on a perfectly matching machine.
+func (model *AzurePriceModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return pricing.DefaultResourcePrices.PodPrice(pod, startTime, endTime), nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2018-10-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/pricing"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+)
+
+func buildScaleSetTemplate(vmSize, location string, priority compute.VirtualMachinePriorityTypes) compute.VirtualMachineScaleSet {
+	return compute.VirtualMachineScaleSet{
+		Sku:      &compute.Sku{Name: to.StringPtr(vmSize)},
+		Location: to.StringPtr(location),
+		VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{Priority: priority},
+		},
+	}
+}
+
+func buildPricedNode(name string, template compute.VirtualMachineScaleSet) *apiv1.Node {
+	node := BuildTestNode(name, 4000, 16*units.GiB)
+	node.Labels = buildGenericLabels(template, name)
+	return node
+}
+
+func TestGetNodePrice(t *testing.T) {
+	model := NewAzurePriceModel(0.8)
+	now := time.Now()
+
+	regular := buildPricedNode("regular", buildScaleSetTemplate("Standard_D4s_v3", "EastUS", compute.Regular))
+	price, err := model.NodePrice(regular, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.192, price, 1e-9)
+
+	// Regions are priced with multipliers.
+	westEurope := buildPricedNode("west-europe", buildScaleSetTemplate("Standard_D4s_v3", "westeurope", compute.Regular))
+	price, err = model.NodePrice(westEurope, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.192*1.11, price, 1e-9)
+
+	// Low-priority scale sets are discounted.
+	lowPriority := buildPricedNode("low", buildScaleSetTemplate("Standard_D4s_v3", "eastus", compute.Low))
+	assert.Equal(t, lowPriorityValue, lowPriority.Labels[scaleSetPriorityLabel])
+	price, err = model.NodePrice(lowPriority, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.192*0.2, price, 1e-9)
+
+	// So are spot scale sets and nodes labeled as spot.
+	spot := buildPricedNode("spot", buildScaleSetTemplate("Standard_D4s_v3", "eastus", compute.Regular))
+	spot.Labels[scaleSetPriorityLabel] = "Spot"
+	price, err = model.NodePrice(spot, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.192*0.2, price, 1e-9)
+	regular.Labels[capacitytype.CapacityTypeLabel] = "spot"
+	price, err = model.NodePrice(regular, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.192*0.2, price, 1e-9)
+
+	// VM sizes without a price are priced by their resources.
+	vmType := InstanceTypes["Standard_D4_v2_Promo"]
+	assert.NotNil(t, vmType)
+	promo := buildPricedNode("promo", buildScaleSetTemplate("Standard_D4_v2_Promo", "eastus", compute.Regular))
+	price, err = model.NodePrice(promo, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(vmType.VCPU)*pricing.DefaultResourcePrices.CPU+float64(vmType.MemoryMb)/1024*pricing.DefaultResourcePrices.Memory, price, 1e-9)
+
+	// Agent pool nodes of unknown sizes are priced by their capacity.
+	unknown := BuildTestNode("unknown", 4000, 16*units.GiB)
+	unknown.Labels = map[string]string{apiv1.LabelInstanceType: "Standard_X4"}
+	price, err = model.NodePrice(unknown, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 4*pricing.DefaultResourcePrices.CPU+16*pricing.DefaultResourcePrices.Memory, price, 1e-9)
+}
+
+func TestGetPodPrice(t *testing.T) {
+	model := NewAzurePriceModel(0)
+	now := time.Now()
+
+	pod1 := BuildTestPod("a1", 100, 500*units.MiB)
+	pod2 := BuildTestPod("a2", 2*100, 2*500*units.MiB)
+
+	price1, err := model.PodPrice(pod1, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	price2, err := model.PodPrice(pod2, now, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*price1, price2, 1e-9)
+}
+
+func TestVMSizePricesUseKnownInstanceTypes(t *testing.T) {
+	for vmSize := range vmSizePrices {
+		_, found := InstanceTypes[vmSize]
+		assert.True(t, found, "unknown VM size %s", vmSize)
+	}
+}
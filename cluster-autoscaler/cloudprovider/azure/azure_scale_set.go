@@ -374,6 +374,9 @@ func buildGenericLabels(template compute.VirtualMachineScaleSet, nodeName string
 	result[kubeletapis.LabelOS] = buildInstanceOS(template)
 	result[apiv1.LabelInstanceType] = *template.Sku.Name
 	result[apiv1.LabelZoneRegion] = strings.ToLower(*template.Location)
+	if template.VirtualMachineProfile != nil && template.VirtualMachineProfile.Priority == compute.Low {
+		result[scaleSetPriorityLabel] = lowPriorityValue
+	}
 
 	if template.Zones != nil && len(*template.Zones) > 0 {
 		failureDomains := make([]string, len(*template.Zones))
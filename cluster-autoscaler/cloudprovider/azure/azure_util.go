@@ -526,6 +526,10 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("subscription ID not set")
 	}
 
+	if cfg.LowPriorityDiscount < 0 || cfg.LowPriorityDiscount >= 1 {
+		return fmt.Errorf("low priority discount %v must be at least 0 and less than 1", cfg.LowPriorityDiscount)
+	}
+
 	if cfg.UseManagedIdentityExtension {
 		return nil
 	}
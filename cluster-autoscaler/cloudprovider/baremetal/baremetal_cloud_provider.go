@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"io/ioutil"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"k8s.io/klog"
+)
+
+const (
+	// ProviderName is the cloud provider name for bare-metal machine pools.
+	ProviderName = "baremetal"
+)
+
+// baremetalCloudProvider implements CloudProvider for fixed pools of pre-registered
+// machines, which are powered on and off by hooks.
+type baremetalCloudProvider struct {
+	manager         *baremetalManager
+	resourceLimiter *cloudprovider.ResourceLimiter
+}
+
+func newBaremetalCloudProvider(manager *baremetalManager, rl *cloudprovider.ResourceLimiter) *baremetalCloudProvider {
+	return &baremetalCloudProvider{
+		manager:         manager,
+		resourceLimiter: rl,
+	}
+}
+
+// Name returns name of the cloud provider.
+func (b *baremetalCloudProvider) Name() string {
+	return ProviderName
+}
+
+// NodeGroups returns all node groups configured for this cloud provider.
+func (b *baremetalCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	nodeGroups := make([]cloudprovider.NodeGroup, 0, len(b.manager.pools))
+	for _, pool := range b.manager.pools {
+		nodeGroups = append(nodeGroups, &poolNodeGroup{pool: pool, manager: b.manager})
+	}
+	return nodeGroups
+}
+
+// NodeGroupForNode returns the node group for the given node, nil if the node
+// should not be processed by cluster autoscaler, or non-nil error if such
+// occurred. Nodes of machines in standby are not processed.
+func (b *baremetalCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	pool := b.manager.poolForNode(node)
+	if pool == nil {
+		return nil, nil
+	}
+	return &poolNodeGroup{pool: pool, manager: b.manager}, nil
+}
+
+// Pricing returns pricing model for this cloud provider or error if not available.
+func (b *baremetalCloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
+// Implementation optional.
+func (b *baremetalCloudProvider) GetAvailableMachineTypes() ([]string, error) {
+	return []string{}, nil
+}
+
+// NewNodeGroup builds a theoretical node group based on the node definition provided. The node group is not automatically
+// created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
+// Implementation optional.
+func (b *baremetalCloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
+	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
+func (b *baremetalCloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimiter, error) {
+	return b.resourceLimiter, nil
+}
+
+// GPULabel returns the label added to nodes with GPU resource. Bare-metal nodes don't
+// have one.
+func (b *baremetalCloudProvider) GPULabel() string {
+	return ""
+}
+
+// GetAvailableGPUTypes return all available GPU types cloud provider supports.
+func (b *baremetalCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
+	return map[string]struct{}{}
+}
+
+// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
+func (b *baremetalCloudProvider) Cleanup() error {
+	return nil
+}
+
+// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
+// In particular the list of node groups returned by NodeGroups can change as a result of CloudProvider.Refresh().
+func (b *baremetalCloudProvider) Refresh() error {
+	return b.manager.refresh()
+}
+
+// BuildBaremetal builds the bare-metal cloud provider from the pools in the cloud config file.
+func BuildBaremetal(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+	if opts.CloudConfig == "" {
+		klog.Fatalf("No config file provided, please specify it via the --cloud-config flag")
+	}
+	data, err := ioutil.ReadFile(opts.CloudConfig)
+	if err != nil {
+		klog.Fatalf("Couldn't read cloud provider configuration %s: %v", opts.CloudConfig, err)
+	}
+	baremetalConfig, err := ParseConfig(data)
+	if err != nil {
+		klog.Fatalf("Invalid cloud provider configuration %s: %v", opts.CloudConfig, err)
+	}
+	if do.StaticDiscoverySpecified() || do.AutoDiscoverySpecified() {
+		klog.Warningf("Node group discovery flags are ignored by the %s cloud provider, pools are read from --cloud-config", ProviderName)
+	}
+	restConfig, err := clientcmd.BuildConfigFromFlags("", opts.KubeConfigPath)
+	if err != nil {
+		klog.Fatalf("Failed to build Kubernetes client configuration: %v", err)
+	}
+	manager := newBaremetalManager(baremetalConfig, kube_client.NewForConfigOrDie(restConfig), opts.ConfigNamespace)
+	if err := manager.refresh(); err != nil {
+		klog.Fatalf("Failed to read state of bare-metal machines: %v", err)
+	}
+	return newBaremetalCloudProvider(manager, rl)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultHookTimeout = time.Minute
+
+// Config is the configuration of the bare-metal cloud provider.
+type Config struct {
+	// Pools are the node groups of pre-registered machines.
+	Pools []PoolConfig `json:"pools"`
+}
+
+// PoolConfig describes a fixed pool of machines forming a node group.
+type PoolConfig struct {
+	// Name is the id of the node group.
+	Name string `json:"name"`
+	// MinSize is the minimum number of powered on machines.
+	MinSize int `json:"minSize"`
+	// MaxSize is the maximum number of powered on machines.
+	MaxSize int `json:"maxSize"`
+	// Machines of the pool. Their names are the names of their nodes.
+	Machines []MachineConfig `json:"machines,omitempty"`
+	// NodeSelector is a label selector of the nodes of additional machines of the pool.
+	NodeSelector string `json:"nodeSelector,omitempty"`
+	// PowerOn is the hook run to power on a machine.
+	PowerOn *Hook `json:"powerOn,omitempty"`
+	// PowerOff is the hook run to power off a machine. Machines are only cordoned
+	// on scale down, and uncordoned on scale up, if it's not set.
+	PowerOff *Hook `json:"powerOff,omitempty"`
+	// HookTimeout is the timeout of a single hook run. Defaults to 1m.
+	HookTimeout string `json:"hookTimeout,omitempty"`
+
+	nodeSelector labels.Selector
+	hookTimeout  time.Duration
+}
+
+// MachineConfig describes a machine of a pool.
+type MachineConfig struct {
+	// Name of the node of the machine.
+	Name string `json:"name"`
+	// Address of the machine, e.g. of its baseboard management controller. Passed to hooks.
+	Address string `json:"address,omitempty"`
+}
+
+// Hook is either an HTTP webhook or a command changing the power state of a machine.
+type Hook struct {
+	// URL the request with the hookRequest is POSTed to. A response other than 2xx is an error.
+	URL string `json:"url,omitempty"`
+	// Headers added to the webhook request, e.g. for authorization.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Command is run with the arguments expanded as Go templates of the hookRequest,
+	// e.g. ["ipmitool", "-H", "{{.Address}}", "power", "on"]. A non-zero exit code is an error.
+	Command []string `json:"command,omitempty"`
+}
+
+// ParseConfig parses and validates a YAML or JSON bare-metal config.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse bare-metal config: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the config and parses its selectors and timeouts.
+func (c *Config) Validate() error {
+	if len(c.Pools) == 0 {
+		return fmt.Errorf("bare-metal config has no pools")
+	}
+	pools := make(map[string]bool)
+	machines := make(map[string]string)
+	for i := range c.Pools {
+		pool := &c.Pools[i]
+		if pool.Name == "" {
+			return fmt.Errorf("pool %d has no name", i)
+		}
+		if errs := validation.IsConfigMapKey(pool.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid pool name %q: %s", pool.Name, strings.Join(errs, ", "))
+		}
+		if pools[pool.Name] {
+			return fmt.Errorf("duplicate pool %s", pool.Name)
+		}
+		pools[pool.Name] = true
+		if pool.MinSize < 0 || pool.MaxSize < pool.MinSize {
+			return fmt.Errorf("pool %s has invalid size limits - min:%d max:%d", pool.Name, pool.MinSize, pool.MaxSize)
+		}
+		if len(pool.Machines) == 0 && pool.NodeSelector == "" {
+			return fmt.Errorf("pool %s has neither machines nor a node selector", pool.Name)
+		}
+		for _, machine := range pool.Machines {
+			if machine.Name == "" {
+				return fmt.Errorf("pool %s has a machine without name", pool.Name)
+			}
+			if other, found := machines[machine.Name]; found {
+				return fmt.Errorf("machine %s is in pools %s and %s", machine.Name, other, pool.Name)
+			}
+			machines[machine.Name] = pool.Name
+		}
+		if pool.NodeSelector != "" {
+			selector, err := labels.Parse(pool.NodeSelector)
+			if err != nil {
+				return fmt.Errorf("pool %s has invalid node selector: %v", pool.Name, err)
+			}
+			pool.nodeSelector = selector
+		}
+		if pool.PowerOn == nil && pool.PowerOff != nil {
+			return fmt.Errorf("pool %s has a powerOff hook without powerOn hook", pool.Name)
+		}
+		for _, hook := range []*Hook{pool.PowerOn, pool.PowerOff} {
+			if err := hook.validate(); err != nil {
+				return fmt.Errorf("pool %s: %v", pool.Name, err)
+			}
+		}
+		pool.hookTimeout = defaultHookTimeout
+		if pool.HookTimeout != "" {
+			timeout, err := time.ParseDuration(pool.HookTimeout)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("pool %s has invalid hookTimeout %q", pool.Name, pool.HookTimeout)
+			}
+			pool.hookTimeout = timeout
+		}
+	}
+	return nil
+}
+
+func (h *Hook) validate() error {
+	if h == nil {
+		return nil
+	}
+	if (h.URL == "") == (len(h.Command) == 0) {
+		return fmt.Errorf("hook must have either url or command")
+	}
+	if h.URL != "" {
+		if _, err := url.Parse(h.URL); err != nil {
+			return fmt.Errorf("invalid hook url: %v", err)
+		}
+		return nil
+	}
+	if len(h.Headers) > 0 {
+		return fmt.Errorf("command hook can't have headers")
+	}
+	if _, err := commandTemplates(h.Command); err != nil {
+		return err
+	}
+	return nil
+}
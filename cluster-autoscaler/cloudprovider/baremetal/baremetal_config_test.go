@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+pools:
+- name: rack-a
+  minSize: 1
+  maxSize: 2
+  machines:
+  - name: rack-a-01
+    address: 10.0.0.1
+  - name: rack-a-02
+  powerOn:
+    url: https://bmc-gateway/power
+    headers:
+      Authorization: Bearer token
+  powerOff:
+    url: https://bmc-gateway/power
+  hookTimeout: 30s
+- name: rack-b
+  maxSize: 10
+  nodeSelector: rack=b
+  powerOn:
+    command: ["ipmitool", "-H", "{{.Address}}", "power", "on"]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(config.Pools))
+
+	rackA := config.Pools[0]
+	assert.Equal(t, "rack-a", rackA.Name)
+	assert.Equal(t, []MachineConfig{{Name: "rack-a-01", Address: "10.0.0.1"}, {Name: "rack-a-02"}}, rackA.Machines)
+	assert.Equal(t, "Bearer token", rackA.PowerOn.Headers["Authorization"])
+	assert.Equal(t, 30*time.Second, rackA.hookTimeout)
+	assert.Nil(t, rackA.nodeSelector)
+
+	rackB := config.Pools[1]
+	assert.Equal(t, defaultHookTimeout, rackB.hookTimeout)
+	assert.True(t, rackB.nodeSelector.Matches(labelsOf("rack", "b")))
+	assert.False(t, rackB.nodeSelector.Matches(labelsOf("rack", "a")))
+	assert.Nil(t, rackB.PowerOff)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	testCases := map[string]string{
+		"no pools":     `pools: []`,
+		"unknown key":  `{pools: [{name: a, maxSize: 1, machines: [{name: m}], size: 1}]}`,
+		"no name":      `{pools: [{maxSize: 1, machines: [{name: m}]}]}`,
+		"invalid name": `{pools: [{name: "a/b", maxSize: 1, machines: [{name: m}]}]}`,
+		"duplicate pool": `{pools: [{name: a, maxSize: 1, machines: [{name: m1}]},
+			{name: a, maxSize: 1, machines: [{name: m2}]}]}`,
+		"max below min":    `{pools: [{name: a, minSize: 2, maxSize: 1, machines: [{name: m}]}]}`,
+		"no machines":      `{pools: [{name: a, maxSize: 1}]}`,
+		"unnamed machine":  `{pools: [{name: a, maxSize: 1, machines: [{address: 10.0.0.1}]}]}`,
+		"shared machine":   `{pools: [{name: a, maxSize: 1, machines: [{name: m}]}, {name: b, maxSize: 1, machines: [{name: m}]}]}`,
+		"invalid selector": `{pools: [{name: a, maxSize: 1, nodeSelector: "a=(b"}]}`,
+		"only powerOff":    `{pools: [{name: a, maxSize: 1, machines: [{name: m}], powerOff: {url: "http://x"}}]}`,
+		"empty hook":       `{pools: [{name: a, maxSize: 1, machines: [{name: m}], powerOn: {}}]}`,
+		"url and command":  `{pools: [{name: a, maxSize: 1, machines: [{name: m}], powerOn: {url: "http://x", command: [on]}}]}`,
+		"command headers":  `{pools: [{name: a, maxSize: 1, machines: [{name: m}], powerOn: {command: [on], headers: {a: b}}}]}`,
+		"invalid template": `{pools: [{name: a, maxSize: 1, machines: [{name: m}], powerOn: {command: ["{{.Address"]}}]}`,
+		"invalid timeout":  `{pools: [{name: a, maxSize: 1, machines: [{name: m}], hookTimeout: 1}]}`,
+	}
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
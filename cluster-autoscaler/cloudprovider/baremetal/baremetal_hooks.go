@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	powerOnAction  = "powerOn"
+	powerOffAction = "powerOff"
+
+	// maxHookOutput is the maximum length of hook output included in errors.
+	maxHookOutput = 1024
+)
+
+// hookRequest is the body of webhook requests and the data command hook arguments are expanded with.
+type hookRequest struct {
+	Action  string `json:"action"`
+	Pool    string `json:"pool"`
+	Machine string `json:"machine"`
+	Address string `json:"address,omitempty"`
+}
+
+func commandTemplates(command []string) ([]*template.Template, error) {
+	templates := make([]*template.Template, 0, len(command))
+	for i, arg := range command {
+		tmpl, err := template.New(fmt.Sprintf("arg%d", i)).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hook command argument %q: %v", arg, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// run runs the hook for the request and waits until it completes or the timeout passes.
+func (h *Hook) run(request hookRequest, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	klog.V(2).Infof("Running %s hook of machine %s in pool %s", request.Action, request.Machine, request.Pool)
+	if h.URL != "" {
+		return h.runWebhook(ctx, request)
+	}
+	return h.runCommand(ctx, request)
+}
+
+func (h *Hook) runWebhook(ctx context.Context, request hookRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	for key, value := range h.Headers {
+		httpRequest.Header.Set(key, value)
+	}
+	response, err := http.DefaultClient.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("%s webhook of machine %s failed: %v", request.Action, request.Machine, err)
+	}
+	defer response.Body.Close()
+	output, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxHookOutput))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook of machine %s returned %s: %s", request.Action, request.Machine, response.Status, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (h *Hook) runCommand(ctx context.Context, request hookRequest) error {
+	templates, err := commandTemplates(h.Command)
+	if err != nil {
+		return err
+	}
+	args := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		var arg bytes.Buffer
+		if err := tmpl.Execute(&arg, request); err != nil {
+			return fmt.Errorf("failed to expand hook command argument: %v", err)
+		}
+		args = append(args, arg.String())
+	}
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		if len(output) > maxHookOutput {
+			output = output[:maxHookOutput]
+		}
+		return fmt.Errorf("%s command of machine %s failed: %v: %s", request.Action, request.Machine, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
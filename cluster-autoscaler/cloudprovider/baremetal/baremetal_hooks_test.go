@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	var received []hookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		request := hookRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		received = append(received, request)
+		if request.Machine == "broken" {
+			http.Error(w, "BMC unreachable", http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	hook := &Hook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	request := hookRequest{Action: powerOnAction, Pool: "rack-a", Machine: "rack-a-01", Address: "10.0.0.1"}
+	assert.NoError(t, hook.run(request, time.Minute))
+	assert.Equal(t, []hookRequest{request}, received)
+
+	err := hook.run(hookRequest{Action: powerOffAction, Pool: "rack-a", Machine: "broken"}, time.Minute)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "BMC unreachable")
+}
+
+func TestCommandHook(t *testing.T) {
+	hook := &Hook{Command: []string{"sh", "-c", `test "$0 $1 $2" = "powerOn rack-a-01 10.0.0.1"`, "{{.Action}}", "{{.Machine}}", "{{.Address}}"}}
+	assert.NoError(t, hook.run(hookRequest{Action: powerOnAction, Pool: "rack-a", Machine: "rack-a-01", Address: "10.0.0.1"}, time.Minute))
+	assert.Error(t, hook.run(hookRequest{Action: powerOnAction, Pool: "rack-a", Machine: "rack-a-02", Address: "10.0.0.2"}, time.Minute))
+
+	failing := &Hook{Command: []string{"sh", "-c", "echo no power >&2; exit 1"}}
+	err := failing.run(hookRequest{Action: powerOffAction, Machine: "rack-a-01"}, time.Minute)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no power")
+
+	slow := &Hook{Command: []string{"sleep", "10"}}
+	assert.Error(t, slow.run(hookRequest{Action: powerOffAction, Machine: "rack-a-01"}, 10*time.Millisecond))
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"k8s.io/klog"
+)
+
+const (
+	// standbyAnnotationKey marks the nodes of machines that are kept powered on, but
+	// cordoned, while they are not needed.
+	standbyAnnotationKey = "baremetal.cluster-autoscaler.kubernetes.io/standby"
+	// addressAnnotationKey is the node annotation holding the address of a machine
+	// discovered with a node selector.
+	addressAnnotationKey = "baremetal.cluster-autoscaler.kubernetes.io/address"
+	// inventoryConfigMapName is the name of the ConfigMap remembering powered off and starting
+	// machines discovered with node selectors, which have no nodes, and node templates of pools.
+	inventoryConfigMapName = "cluster-autoscaler-baremetal-inventory"
+
+	instanceIDPrefix = "baremetal://"
+)
+
+type machineState int
+
+const (
+	// machineOff is a powered off machine without node.
+	machineOff machineState = iota
+	// machineStarting is a machine powered on by Cluster Autoscaler whose node hasn't registered yet.
+	machineStarting
+	// machineOn is a machine with a node in use.
+	machineOn
+	// machineStandby is a machine with a cordoned node, which isn't in use.
+	machineStandby
+)
+
+// machine is a machine of a pool and its state at the last refresh.
+type machine struct {
+	MachineConfig
+	// configured is true for machines listed in the config, false for the ones
+	// discovered with node selectors.
+	configured bool
+	node       *apiv1.Node
+	state      machineState
+}
+
+// instanceID returns the id of the cloudprovider.Instance of the machine: the provider id
+// of its node, or a placeholder before the node registers.
+func (m *machine) instanceID() string {
+	if m.node != nil && m.node.Spec.ProviderID != "" {
+		return m.node.Spec.ProviderID
+	}
+	return instanceIDPrefix + m.Name
+}
+
+// inventoryMachine is a machine discovered with a node selector stored in the inventory.
+type inventoryMachine struct {
+	MachineConfig
+	// Starting is true if the machine was powered on and its node hasn't registered yet.
+	Starting bool `json:"starting,omitempty"`
+}
+
+// inUse returns true if the machine counts toward the target size of its pool.
+func (m *machine) inUse() bool {
+	return m.state == machineOn || m.state == machineStarting
+}
+
+// baremetalManager keeps track of the machines of all pools and changes their power
+// state. The state of machines is read from nodes at every refresh; powered off
+// and starting machines discovered with node selectors and pool templates are stored
+// in the inventory ConfigMap, as they have no nodes.
+type baremetalManager struct {
+	client    kube_client.Interface
+	namespace string
+	pools     []*PoolConfig
+
+	lock      sync.Mutex
+	machines  map[string][]*machine
+	starting  map[string]bool
+	templates map[string]*apiv1.Node
+}
+
+func newBaremetalManager(config *Config, client kube_client.Interface, namespace string) *baremetalManager {
+	manager := &baremetalManager{
+		client:    client,
+		namespace: namespace,
+		machines:  make(map[string][]*machine),
+		starting:  make(map[string]bool),
+		templates: make(map[string]*apiv1.Node),
+	}
+	for i := range config.Pools {
+		manager.pools = append(manager.pools, &config.Pools[i])
+	}
+	return manager
+}
+
+// refresh rebuilds the machines of all pools from the current nodes and the inventory.
+func (m *baremetalManager) refresh() error {
+	nodeList, err := m.client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	inventory, err := m.getInventory()
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	nodes := make(map[string]*apiv1.Node)
+	for i := range nodeList.Items {
+		nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+	assigned := make(map[string]bool)
+	for _, pool := range m.pools {
+		for _, config := range pool.Machines {
+			assigned[config.Name] = true
+		}
+	}
+
+	machines := make(map[string][]*machine)
+	var staleInventories []*PoolConfig
+	for _, pool := range m.pools {
+		var poolMachines []*machine
+		for _, config := range pool.Machines {
+			poolMachines = append(poolMachines, &machine{MachineConfig: config, configured: true})
+		}
+		if pool.nodeSelector != nil {
+			for _, node := range nodeList.Items {
+				if assigned[node.Name] || !pool.nodeSelector.Matches(labels.Set(node.Labels)) {
+					continue
+				}
+				assigned[node.Name] = true
+				poolMachines = append(poolMachines, &machine{MachineConfig: MachineConfig{
+					Name:    node.Name,
+					Address: node.Annotations[addressAnnotationKey],
+				}})
+			}
+			var withoutNodes []inventoryMachine
+			if data, found := inventory.Data[pool.Name+".machines"]; found {
+				if err := json.Unmarshal([]byte(data), &withoutNodes); err != nil {
+					klog.Errorf("Ignoring invalid inventory of pool %s: %v", pool.Name, err)
+				}
+			}
+			stale := false
+			for _, stored := range withoutNodes {
+				if assigned[stored.Name] {
+					// The node of the machine registered, the machine is removed from the inventory.
+					stale = true
+					continue
+				}
+				assigned[stored.Name] = true
+				if stored.Starting {
+					m.starting[stored.Name] = true
+				}
+				poolMachines = append(poolMachines, &machine{MachineConfig: stored.MachineConfig})
+			}
+			if stale {
+				staleInventories = append(staleInventories, pool)
+			}
+		}
+
+		for _, pm := range poolMachines {
+			pm.node = nodes[pm.Name]
+			switch {
+			case pm.node != nil && pm.node.Annotations[standbyAnnotationKey] != "":
+				pm.state = machineStandby
+			case pm.node != nil:
+				pm.state = machineOn
+				m.templates[pool.Name] = pm.node
+			case m.starting[pm.Name]:
+				pm.state = machineStarting
+			default:
+				pm.state = machineOff
+			}
+			if pm.node != nil {
+				delete(m.starting, pm.Name)
+			}
+		}
+		if _, found := m.templates[pool.Name]; !found {
+			if data, found := inventory.Data[pool.Name+".template"]; found {
+				template := &apiv1.Node{}
+				if err := json.Unmarshal([]byte(data), template); err != nil {
+					klog.Errorf("Ignoring invalid template of pool %s: %v", pool.Name, err)
+				} else {
+					m.templates[pool.Name] = template
+				}
+			}
+		}
+		machines[pool.Name] = poolMachines
+	}
+	m.machines = machines
+	for _, pool := range staleInventories {
+		if err := m.updateInventory(pool); err != nil {
+			klog.Warningf("Failed to update the inventory of pool %s: %v", pool.Name, err)
+		}
+	}
+	return nil
+}
+
+// poolForNode returns the pool of the machine of the node if the machine is in use, nil otherwise.
+func (m *baremetalManager) poolForNode(node *apiv1.Node) *PoolConfig {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, pool := range m.pools {
+		for _, pm := range m.machines[pool.Name] {
+			if pm.Name == node.Name {
+				if pm.inUse() {
+					return pool
+				}
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// targetSize returns the number of machines of the pool that are in use.
+func (m *baremetalManager) targetSize(pool *PoolConfig) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	size := 0
+	for _, pm := range m.machines[pool.Name] {
+		if pm.inUse() {
+			size++
+		}
+	}
+	return size
+}
+
+// instances returns the instances of the machines of the pool that are in use.
+func (m *baremetalManager) instances(pool *PoolConfig) []cloudprovider.Instance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var instances []cloudprovider.Instance
+	for _, pm := range m.machines[pool.Name] {
+		switch pm.state {
+		case machineOn:
+			instances = append(instances, cloudprovider.Instance{
+				Id:     pm.instanceID(),
+				Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning},
+			})
+		case machineStarting:
+			instances = append(instances, cloudprovider.Instance{
+				Id:     pm.instanceID(),
+				Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceCreating},
+			})
+		}
+	}
+	return instances
+}
+
+// template returns a copy of the last node seen in the pool, if any.
+func (m *baremetalManager) template(pool *PoolConfig) *apiv1.Node {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if template, found := m.templates[pool.Name]; found {
+		return template.DeepCopy()
+	}
+	return nil
+}
+
+// increaseSize starts using delta more machines of the pool. Machines in standby are
+// uncordoned first, then powered off machines are powered on.
+func (m *baremetalManager) increaseSize(pool *PoolConfig, delta int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	size := 0
+	var standby, off []*machine
+	for _, pm := range m.machines[pool.Name] {
+		switch {
+		case pm.inUse():
+			size++
+		case pm.state == machineStandby:
+			standby = append(standby, pm)
+		case pm.state == machineOff && pool.PowerOn != nil:
+			off = append(off, pm)
+		}
+	}
+	if size+delta > pool.MaxSize {
+		return fmt.Errorf("size increase too large - desired:%d max:%d", size+delta, pool.MaxSize)
+	}
+	candidates := append(standby, off...)
+	if len(candidates) < delta {
+		return fmt.Errorf("pool %s has only %d available machines, %d requested", pool.Name, len(candidates), delta)
+	}
+	for _, pm := range candidates[:delta] {
+		if pm.state == machineStandby {
+			if err := m.wakeUp(pm); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.powerOn(pool, pm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteNodes stops using the machines of the given nodes. They are powered off if the
+// pool has a powerOff hook, put in standby otherwise.
+func (m *baremetalManager) deleteNodes(pool *PoolConfig, nodes []*apiv1.Node) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	size := 0
+	for _, pm := range m.machines[pool.Name] {
+		if pm.inUse() {
+			size++
+		}
+	}
+	if size-len(nodes) < pool.MinSize {
+		return fmt.Errorf("size decrease too large - desired:%d min:%d", size-len(nodes), pool.MinSize)
+	}
+	machines := make([]*machine, 0, len(nodes))
+	for _, node := range nodes {
+		var found *machine
+		for _, pm := range m.machines[pool.Name] {
+			if pm.inUse() && (pm.Name == node.Name || pm.instanceID() == node.Spec.ProviderID) {
+				found = pm
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("node %s doesn't belong to pool %s", node.Name, pool.Name)
+		}
+		if found.node == nil && pool.PowerOff == nil {
+			return fmt.Errorf("machine %s can't be stopped before its node registers, pool %s has no powerOff hook", found.Name, pool.Name)
+		}
+		machines = append(machines, found)
+	}
+	for _, pm := range machines {
+		if pool.PowerOff == nil {
+			if err := m.putInStandby(pm); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.powerOff(pool, pm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decreaseTargetSize stops powering on -delta machines of the pool whose nodes haven't
+// registered yet.
+func (m *baremetalManager) decreaseTargetSize(pool *PoolConfig, delta int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var starting []*machine
+	for _, pm := range m.machines[pool.Name] {
+		if pm.state == machineStarting {
+			starting = append(starting, pm)
+		}
+	}
+	if -delta > len(starting) {
+		return fmt.Errorf("attempt to delete existing nodes - delta:%d machines starting:%d", delta, len(starting))
+	}
+	forgotten := false
+	for _, pm := range starting[:-delta] {
+		if pool.PowerOff == nil {
+			klog.Warningf("Pool %s has no powerOff hook, machine %s will register once it starts", pool.Name, pm.Name)
+			delete(m.starting, pm.Name)
+			pm.state = machineOff
+			forgotten = forgotten || !pm.configured
+			continue
+		}
+		if err := m.powerOff(pool, pm); err != nil {
+			return err
+		}
+	}
+	if forgotten {
+		return m.updateInventory(pool)
+	}
+	return nil
+}
+
+func (m *baremetalManager) powerOn(pool *PoolConfig, pm *machine) error {
+	request := hookRequest{Action: powerOnAction, Pool: pool.Name, Machine: pm.Name, Address: pm.Address}
+	if err := pool.PowerOn.run(request, pool.hookTimeout); err != nil {
+		return err
+	}
+	m.starting[pm.Name] = true
+	pm.state = machineStarting
+	if !pm.configured {
+		return m.updateInventory(pool)
+	}
+	return nil
+}
+
+// powerOff powers off the machine and deletes its node. The node is stored as the
+// template of the pool, so that the pool can be scaled up from 0.
+func (m *baremetalManager) powerOff(pool *PoolConfig, pm *machine) error {
+	request := hookRequest{Action: powerOffAction, Pool: pool.Name, Machine: pm.Name, Address: pm.Address}
+	if err := pool.PowerOff.run(request, pool.hookTimeout); err != nil {
+		return err
+	}
+	delete(m.starting, pm.Name)
+	pm.state = machineOff
+	if pm.node != nil {
+		m.templates[pool.Name] = pm.node
+		if err := m.client.CoreV1().Nodes().Delete(pm.Name, &metav1.DeleteOptions{}); err != nil && !kube_errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete node of powered off machine %s: %v", pm.Name, err)
+		}
+		pm.node = nil
+	}
+	return m.updateInventory(pool)
+}
+
+func (m *baremetalManager) putInStandby(pm *machine) error {
+	klog.V(2).Infof("Putting machine %s in standby", pm.Name)
+	node, err := m.updateNode(pm.Name, func(node *apiv1.Node) {
+		node.Spec.Unschedulable = true
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[standbyAnnotationKey] = "true"
+		node.Spec.Taints = withoutToBeDeletedTaint(node.Spec.Taints)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put machine %s in standby: %v", pm.Name, err)
+	}
+	pm.node = node
+	pm.state = machineStandby
+	return nil
+}
+
+func (m *baremetalManager) wakeUp(pm *machine) error {
+	klog.V(2).Infof("Waking up machine %s from standby", pm.Name)
+	node, err := m.updateNode(pm.Name, func(node *apiv1.Node) {
+		node.Spec.Unschedulable = false
+		delete(node.Annotations, standbyAnnotationKey)
+		node.Spec.Taints = withoutToBeDeletedTaint(node.Spec.Taints)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wake up machine %s from standby: %v", pm.Name, err)
+	}
+	pm.node = node
+	pm.state = machineOn
+	return nil
+}
+
+func (m *baremetalManager) updateNode(name string, update func(node *apiv1.Node)) (*apiv1.Node, error) {
+	var result *apiv1.Node
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := m.client.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(node)
+		result, err = m.client.CoreV1().Nodes().Update(node)
+		return err
+	})
+	return result, err
+}
+
+func withoutToBeDeletedTaint(taints []apiv1.Taint) []apiv1.Taint {
+	result := make([]apiv1.Taint, 0, len(taints))
+	for _, taint := range taints {
+		if taint.Key != deletetaint.ToBeDeletedTaint {
+			result = append(result, taint)
+		}
+	}
+	return result
+}
+
+func (m *baremetalManager) getInventory() (*apiv1.ConfigMap, error) {
+	configMap, err := m.client.CoreV1().ConfigMaps(m.namespace).Get(inventoryConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		return &apiv1.ConfigMap{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bare-metal inventory: %v", err)
+	}
+	return configMap, nil
+}
+
+// updateInventory stores the powered off and starting machines of the pool discovered with
+// its node selector and the template of the pool in the inventory ConfigMap.
+func (m *baremetalManager) updateInventory(pool *PoolConfig) error {
+	var withoutNodes []inventoryMachine
+	for _, pm := range m.machines[pool.Name] {
+		if !pm.configured && (pm.state == machineOff || pm.state == machineStarting) {
+			withoutNodes = append(withoutNodes, inventoryMachine{MachineConfig: pm.MachineConfig, Starting: pm.state == machineStarting})
+		}
+	}
+	machinesData, err := json.Marshal(withoutNodes)
+	if err != nil {
+		return err
+	}
+	data := map[string]string{pool.Name + ".machines": string(machinesData)}
+	if template, found := m.templates[pool.Name]; found {
+		templateData, err := json.Marshal(template)
+		if err != nil {
+			return err
+		}
+		data[pool.Name+".template"] = string(templateData)
+	}
+
+	configMaps := m.client.CoreV1().ConfigMaps(m.namespace)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := configMaps.Get(inventoryConfigMapName, metav1.GetOptions{})
+		if kube_errors.IsNotFound(err) {
+			_, err = configMaps.Create(&apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: m.namespace, Name: inventoryConfigMapName},
+				Data:       data,
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		for key, value := range data {
+			configMap.Data[key] = value
+		}
+		_, err = configMaps.Update(configMap)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update bare-metal inventory: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func labelsOf(key, value string) labels.Set {
+	return labels.Set{key: value}
+}
+
+func buildNode(name string, nodeLabels map[string]string) *apiv1.Node {
+	return &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels, Annotations: map[string]string{}},
+		Spec:       apiv1.NodeSpec{ProviderID: instanceIDPrefix + name},
+	}
+}
+
+// hookServer records the hook requests it receives.
+type hookServer struct {
+	*httptest.Server
+	requests []hookRequest
+}
+
+func newHookServer(t *testing.T) *hookServer {
+	server := &hookServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := hookRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		server.requests = append(server.requests, request)
+	}))
+	return server
+}
+
+func newTestManager(t *testing.T, client *fake.Clientset, hooks *hookServer) *baremetalManager {
+	config, err := ParseConfig([]byte(`
+pools:
+- name: rack-a
+  minSize: 1
+  maxSize: 3
+  machines:
+  - {name: a1, address: 10.0.0.1}
+  - {name: a2, address: 10.0.0.2}
+  - {name: a3, address: 10.0.0.3}
+  powerOn: {url: "` + hooks.URL + `"}
+  powerOff: {url: "` + hooks.URL + `"}
+- name: rack-b
+  maxSize: 5
+  nodeSelector: rack=b
+  powerOn: {url: "` + hooks.URL + `"}
+  powerOff: {url: "` + hooks.URL + `"}
+- name: standby
+  maxSize: 2
+  machines:
+  - {name: s1}
+  - {name: s2}
+`))
+	assert.NoError(t, err)
+	manager := newBaremetalManager(config, client, "kube-system")
+	assert.NoError(t, manager.refresh())
+	return manager
+}
+
+func states(manager *baremetalManager, pool string) map[string]machineState {
+	result := make(map[string]machineState)
+	for _, pm := range manager.machines[pool] {
+		result[pm.Name] = pm.state
+	}
+	return result
+}
+
+func TestRefresh(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	s2 := buildNode("s2", nil)
+	s2.Annotations[standbyAnnotationKey] = "true"
+	client := fake.NewSimpleClientset(
+		buildNode("a1", nil),
+		buildNode("b1", map[string]string{"rack": "b"}),
+		buildNode("s1", nil), s2,
+		buildNode("other", nil),
+	)
+	manager := newTestManager(t, client, hooks)
+
+	assert.Equal(t, map[string]machineState{"a1": machineOn, "a2": machineOff, "a3": machineOff}, states(manager, "rack-a"))
+	assert.Equal(t, map[string]machineState{"b1": machineOn}, states(manager, "rack-b"))
+	assert.Equal(t, map[string]machineState{"s1": machineOn, "s2": machineStandby}, states(manager, "standby"))
+
+	rackA := manager.pools[0]
+	assert.Equal(t, rackA, manager.poolForNode(buildNode("a1", nil)))
+	assert.Nil(t, manager.poolForNode(buildNode("s2", nil)))
+	assert.Nil(t, manager.poolForNode(buildNode("other", nil)))
+	assert.Equal(t, 1, manager.targetSize(rackA))
+	assert.Equal(t, []cloudprovider.Instance{{
+		Id:     "baremetal://a1",
+		Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning},
+	}}, manager.instances(rackA))
+}
+
+func TestPowerOnAndOff(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	client := fake.NewSimpleClientset(buildNode("a1", nil))
+	manager := newTestManager(t, client, hooks)
+	rackA := manager.pools[0]
+
+	assert.Error(t, manager.increaseSize(rackA, 3))
+	assert.NoError(t, manager.increaseSize(rackA, 1))
+	assert.Equal(t, []hookRequest{{Action: powerOnAction, Pool: "rack-a", Machine: "a2", Address: "10.0.0.2"}}, hooks.requests)
+	assert.Equal(t, 2, manager.targetSize(rackA))
+	assert.Equal(t, cloudprovider.InstanceCreating, manager.instances(rackA)[1].Status.State)
+
+	// The machine is still starting after a refresh, until its node registers.
+	assert.NoError(t, manager.refresh())
+	assert.Equal(t, machineStarting, states(manager, "rack-a")["a2"])
+	_, err := client.CoreV1().Nodes().Create(buildNode("a2", nil))
+	assert.NoError(t, err)
+	assert.NoError(t, manager.refresh())
+	assert.Equal(t, machineOn, states(manager, "rack-a")["a2"])
+
+	// Scale down below min size.
+	assert.Error(t, manager.deleteNodes(rackA, []*apiv1.Node{buildNode("a1", nil), buildNode("a2", nil)}))
+	// Machine not in use.
+	assert.Error(t, manager.deleteNodes(rackA, []*apiv1.Node{buildNode("a3", nil)}))
+
+	hooks.requests = nil
+	assert.NoError(t, manager.deleteNodes(rackA, []*apiv1.Node{buildNode("a2", nil)}))
+	assert.Equal(t, []hookRequest{{Action: powerOffAction, Pool: "rack-a", Machine: "a2", Address: "10.0.0.2"}}, hooks.requests)
+	_, err = client.CoreV1().Nodes().Get("a2", metav1.GetOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, manager.targetSize(rackA))
+}
+
+func TestDecreaseTargetSize(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	client := fake.NewSimpleClientset(buildNode("a1", nil))
+	manager := newTestManager(t, client, hooks)
+	rackA := manager.pools[0]
+
+	assert.NoError(t, manager.increaseSize(rackA, 1))
+	assert.Error(t, manager.decreaseTargetSize(rackA, -2))
+	hooks.requests = nil
+	assert.NoError(t, manager.decreaseTargetSize(rackA, -1))
+	assert.Equal(t, []hookRequest{{Action: powerOffAction, Pool: "rack-a", Machine: "a2", Address: "10.0.0.2"}}, hooks.requests)
+	assert.Equal(t, 1, manager.targetSize(rackA))
+}
+
+func TestSelectorMachinesAreRemembered(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	b1 := buildNode("b1", map[string]string{"rack": "b"})
+	b1.Annotations[addressAnnotationKey] = "10.0.1.1"
+	b1.Spec.Taints = []apiv1.Taint{{Key: deletetaint.ToBeDeletedTaint, Effect: apiv1.TaintEffectNoSchedule}}
+	client := fake.NewSimpleClientset(b1, buildNode("b2", map[string]string{"rack": "b"}))
+	manager := newTestManager(t, client, hooks)
+	rackB := manager.pools[1]
+
+	assert.NoError(t, manager.deleteNodes(rackB, []*apiv1.Node{b1}))
+	assert.Equal(t, []hookRequest{{Action: powerOffAction, Pool: "rack-b", Machine: "b1", Address: "10.0.1.1"}}, hooks.requests)
+	inventory, err := client.CoreV1().ConfigMaps("kube-system").Get(inventoryConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"name":"b1","address":"10.0.1.1"}]`, inventory.Data["rack-b.machines"])
+	template := &apiv1.Node{}
+	assert.NoError(t, json.Unmarshal([]byte(inventory.Data["rack-b.template"]), template))
+	assert.Equal(t, "b1", template.Name)
+
+	// The node of b1 is gone, a new manager learns about the machine from the inventory.
+	manager = newTestManager(t, client, hooks)
+	rackB = manager.pools[1]
+	assert.Equal(t, map[string]machineState{"b1": machineOff, "b2": machineOn}, states(manager, "rack-b"))
+
+	hooks.requests = nil
+	assert.NoError(t, manager.increaseSize(rackB, 1))
+	assert.Equal(t, []hookRequest{{Action: powerOnAction, Pool: "rack-b", Machine: "b1", Address: "10.0.1.1"}}, hooks.requests)
+	inventory, err = client.CoreV1().ConfigMaps("kube-system").Get(inventoryConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"name":"b1","address":"10.0.1.1","starting":true}]`, inventory.Data["rack-b.machines"])
+
+	// The machine is starting until its node registers, even for a new manager.
+	assert.NoError(t, manager.refresh())
+	assert.Equal(t, map[string]machineState{"b1": machineStarting, "b2": machineOn}, states(manager, "rack-b"))
+	manager = newTestManager(t, client, hooks)
+	rackB = manager.pools[1]
+	assert.Equal(t, map[string]machineState{"b1": machineStarting, "b2": machineOn}, states(manager, "rack-b"))
+	assert.Equal(t, 2, manager.targetSize(rackB))
+
+	// No more machines.
+	assert.Error(t, manager.increaseSize(rackB, 1))
+
+	b1 = buildNode("b1", map[string]string{"rack": "b"})
+	b1.Annotations[addressAnnotationKey] = "10.0.1.1"
+	_, err = client.CoreV1().Nodes().Create(b1)
+	assert.NoError(t, err)
+	assert.NoError(t, manager.refresh())
+	assert.Equal(t, map[string]machineState{"b1": machineOn, "b2": machineOn}, states(manager, "rack-b"))
+	inventory, err = client.CoreV1().ConfigMaps("kube-system").Get(inventoryConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "null", inventory.Data["rack-b.machines"])
+}
+
+func TestStandby(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	s1 := buildNode("s1", nil)
+	s1.Spec.Taints = []apiv1.Taint{{Key: deletetaint.ToBeDeletedTaint, Effect: apiv1.TaintEffectNoSchedule}}
+	client := fake.NewSimpleClientset(s1, buildNode("s2", nil))
+	manager := newTestManager(t, client, hooks)
+	standby := manager.pools[2]
+
+	assert.NoError(t, manager.deleteNodes(standby, []*apiv1.Node{s1}))
+	node, err := client.CoreV1().Nodes().Get("s1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+	assert.Equal(t, "true", node.Annotations[standbyAnnotationKey])
+	assert.Empty(t, node.Spec.Taints)
+	assert.Equal(t, 1, manager.targetSize(standby))
+
+	assert.NoError(t, manager.refresh())
+	assert.Equal(t, map[string]machineState{"s1": machineStandby, "s2": machineOn}, states(manager, "standby"))
+
+	assert.NoError(t, manager.increaseSize(standby, 1))
+	node, err = client.CoreV1().Nodes().Get("s1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, node.Spec.Unschedulable)
+	assert.NotContains(t, node.Annotations, standbyAnnotationKey)
+	assert.Equal(t, 2, manager.targetSize(standby))
+	assert.Empty(t, hooks.requests)
+}
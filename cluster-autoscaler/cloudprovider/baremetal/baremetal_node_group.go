@@ -0,0 +1,142 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"fmt"
+	"math/rand"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// poolNodeGroup implements cloudprovider.NodeGroup for a pool of bare-metal machines.
+type poolNodeGroup struct {
+	pool    *PoolConfig
+	manager *baremetalManager
+}
+
+// MaxSize returns maximum size of the node group.
+func (ng *poolNodeGroup) MaxSize() int {
+	return ng.pool.MaxSize
+}
+
+// MinSize returns minimum size of the node group.
+func (ng *poolNodeGroup) MinSize() int {
+	return ng.pool.MinSize
+}
+
+// TargetSize returns the current target size of the node group. It is possible that the
+// number of nodes in Kubernetes is different at the moment but should be equal
+// to Size() once everything stabilizes (new nodes finish startup and registration or
+// removed nodes are deleted completely).
+func (ng *poolNodeGroup) TargetSize() (int, error) {
+	return ng.manager.targetSize(ng.pool), nil
+}
+
+// IncreaseSize increases the size of the node group. To delete a node you need
+// to explicitly name it and use DeleteNode. This function should wait until
+// node group size is updated.
+func (ng *poolNodeGroup) IncreaseSize(delta int) error {
+	if delta <= 0 {
+		return fmt.Errorf("size increase must be positive")
+	}
+	return ng.manager.increaseSize(ng.pool, delta)
+}
+
+// DeleteNodes deletes nodes from this node group. Error is returned either on
+// failure or if the given node doesn't belong to this node group. This function
+// should wait until node group size is updated.
+func (ng *poolNodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
+	return ng.manager.deleteNodes(ng.pool, nodes)
+}
+
+// DecreaseTargetSize decreases the target size of the node group. This function
+// doesn't permit to delete any existing node and can be used only to reduce the
+// request for new nodes that have not been yet fulfilled. Delta should be negative.
+func (ng *poolNodeGroup) DecreaseTargetSize(delta int) error {
+	if delta >= 0 {
+		return fmt.Errorf("size decrease must be negative")
+	}
+	return ng.manager.decreaseTargetSize(ng.pool, delta)
+}
+
+// Id returns an unique identifier of the node group.
+func (ng *poolNodeGroup) Id() string {
+	return ng.pool.Name
+}
+
+// Debug returns a string containing all information regarding this node group.
+func (ng *poolNodeGroup) Debug() string {
+	return fmt.Sprintf("%s (min: %d, max: %d)", ng.pool.Name, ng.pool.MinSize, ng.pool.MaxSize)
+}
+
+// Nodes returns a list of all nodes that belong to this node group.
+func (ng *poolNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	return ng.manager.instances(ng.pool), nil
+}
+
+// TemplateNodeInfo returns a node template for this node group. The template is the
+// last node seen in the pool, so a pool can be scaled up from 0 only after one of its
+// machines has been in use.
+func (ng *poolNodeGroup) TemplateNodeInfo() (*schedulernodeinfo.NodeInfo, error) {
+	node := ng.manager.template(ng.pool)
+	if node == nil {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	node.ObjectMeta = metav1.ObjectMeta{
+		Name:   fmt.Sprintf("%s-template-%d", ng.pool.Name, rand.Int63()),
+		Labels: node.Labels,
+	}
+	node.Spec.ProviderID = ""
+	node.Spec.Unschedulable = false
+	taints := make([]apiv1.Taint, 0, len(node.Spec.Taints))
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != deletetaint.ToBeDeletedTaint && taint.Key != deletetaint.DeletionCandidateTaint {
+			taints = append(taints, taint)
+		}
+	}
+	node.Spec.Taints = taints
+	node.Status.Conditions = cloudprovider.BuildReadyConditions()
+
+	nodeInfo := schedulernodeinfo.NewNodeInfo(cloudprovider.BuildKubeProxy(ng.pool.Name))
+	nodeInfo.SetNode(node)
+	return nodeInfo, nil
+}
+
+// Exist checks if the node group really exists on the cloud provider side.
+func (ng *poolNodeGroup) Exist() bool {
+	return true
+}
+
+// Create creates the node group on the cloud provider side. Implementation optional.
+func (ng *poolNodeGroup) Create() (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// Delete deletes the node group on the cloud provider side.
+func (ng *poolNodeGroup) Delete() error {
+	return cloudprovider.ErrNotImplemented
+}
+
+// Autoprovisioned returns true if the node group is autoprovisioned.
+func (ng *poolNodeGroup) Autoprovisioned() bool {
+	return false
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateNodeInfo(t *testing.T) {
+	hooks := newHookServer(t)
+	defer hooks.Close()
+	a1 := buildNode("a1", map[string]string{"rack": "a"})
+	a1.Spec.Unschedulable = true
+	a1.Spec.Taints = []apiv1.Taint{
+		{Key: deletetaint.ToBeDeletedTaint, Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule},
+	}
+	manager := newTestManager(t, fake.NewSimpleClientset(a1), hooks)
+	provider := newBaremetalCloudProvider(manager, nil)
+
+	nodeGroups := provider.NodeGroups()
+	assert.Equal(t, 3, len(nodeGroups))
+	rackA := nodeGroups[0]
+	assert.Equal(t, "rack-a", rackA.Id())
+
+	nodeGroup, err := provider.NodeGroupForNode(a1)
+	assert.NoError(t, err)
+	assert.Equal(t, "rack-a", nodeGroup.Id())
+
+	nodeInfo, err := rackA.TemplateNodeInfo()
+	assert.NoError(t, err)
+	node := nodeInfo.Node()
+	assert.NotEqual(t, "a1", node.Name)
+	assert.Equal(t, "a", node.Labels["rack"])
+	assert.Empty(t, node.Spec.ProviderID)
+	assert.False(t, node.Spec.Unschedulable)
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}}, node.Spec.Taints)
+	assert.Equal(t, cloudprovider.BuildReadyConditions()[0].Type, node.Status.Conditions[0].Type)
+	assert.Equal(t, 1, len(nodeInfo.Pods()))
+
+	// No machine of the pool has been seen yet.
+	_, err = nodeGroups[1].TemplateNodeInfo()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"k8s.io/klog"
+)
+
+const (
+	// ProviderName is the cloud provider name for Cluster API.
+	ProviderName = "clusterapi"
+)
+
+// clusterAPICloudProvider implements CloudProvider for clusters managed by Cluster API.
+// Node groups are MachineSets and MachineDeployments annotated with size limits.
+// They are listed at every Refresh.
+type clusterAPICloudProvider struct {
+	controller      *machineController
+	resourceLimiter *cloudprovider.ResourceLimiter
+
+	lock     sync.Mutex
+	snapshot *clusterSnapshot
+}
+
+func newClusterAPICloudProvider(client dynamic.Interface, rl *cloudprovider.ResourceLimiter) *clusterAPICloudProvider {
+	return &clusterAPICloudProvider{
+		controller:      &machineController{client: client},
+		resourceLimiter: rl,
+	}
+}
+
+// Name returns name of the cloud provider.
+func (p *clusterAPICloudProvider) Name() string {
+	return ProviderName
+}
+
+// NodeGroups returns all node groups configured for this cloud provider.
+func (p *clusterAPICloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	snapshot, err := p.getSnapshot()
+	if err != nil {
+		klog.Errorf("Failed to list Cluster API node groups: %v", err)
+		return []cloudprovider.NodeGroup{}
+	}
+	nodeGroups := make([]cloudprovider.NodeGroup, 0, len(snapshot.nodeGroups))
+	for _, ng := range snapshot.nodeGroups {
+		nodeGroups = append(nodeGroups, ng)
+	}
+	return nodeGroups
+}
+
+// NodeGroupForNode returns the node group for the given node, nil if the node
+// should not be processed by cluster autoscaler, or non-nil error if such
+// occurred.
+func (p *clusterAPICloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	snapshot, err := p.getSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	if m := snapshot.machineForNode(node); m != nil {
+		return m.nodeGroup, nil
+	}
+	return nil, nil
+}
+
+// Pricing returns pricing model for this cloud provider or error if not available.
+func (p *clusterAPICloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
+// Implementation optional.
+func (p *clusterAPICloudProvider) GetAvailableMachineTypes() ([]string, error) {
+	return []string{}, nil
+}
+
+// NewNodeGroup builds a theoretical node group based on the node definition provided. The node group is not automatically
+// created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
+// Implementation optional.
+func (p *clusterAPICloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
+	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
+func (p *clusterAPICloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimiter, error) {
+	return p.resourceLimiter, nil
+}
+
+// GPULabel returns the label added to nodes with GPU resource. Cluster API doesn't
+// define one.
+func (p *clusterAPICloudProvider) GPULabel() string {
+	return ""
+}
+
+// GetAvailableGPUTypes return all available GPU types cloud provider supports.
+func (p *clusterAPICloudProvider) GetAvailableGPUTypes() map[string]struct{} {
+	return map[string]struct{}{}
+}
+
+// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
+func (p *clusterAPICloudProvider) Cleanup() error {
+	return nil
+}
+
+// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
+// In particular the list of node groups returned by NodeGroups can change as a result of CloudProvider.Refresh().
+func (p *clusterAPICloudProvider) Refresh() error {
+	snapshot, err := p.controller.snapshot()
+	if err != nil {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.snapshot = snapshot
+	return nil
+}
+
+func (p *clusterAPICloudProvider) getSnapshot() (*clusterSnapshot, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.snapshot == nil {
+		snapshot, err := p.controller.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		p.snapshot = snapshot
+	}
+	return p.snapshot, nil
+}
+
+// BuildClusterAPI builds the Cluster API cloud provider. Cluster API objects are read
+// from the cluster of the kubeconfig given with --cloud-config, or from the cluster
+// Cluster Autoscaler runs in if it's empty.
+func BuildClusterAPI(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+	if do.StaticDiscoverySpecified() || do.AutoDiscoverySpecified() {
+		klog.Warningf("Node group discovery flags are ignored by the %s cloud provider, node groups are discovered from annotations", ProviderName)
+	}
+	var restConfig *rest.Config
+	var err error
+	if opts.CloudConfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", opts.CloudConfig)
+	} else {
+		restConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		klog.Fatalf("Failed to build Cluster API management cluster configuration: %v", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		klog.Fatalf("Failed to create Cluster API client: %v", err)
+	}
+	return newClusterAPICloudProvider(client, rl)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeGroupsAndRefresh(t *testing.T) {
+	cluster := newFakeCluster(
+		newScalableObject(machineSetKind, "default", "workers", 1, "1", "5"),
+		newMachineObject("default", "workers-1", "workers", "vsphere://1", "node-1"),
+		newScalableObject(machineSetKind, "default", "gpu", 0, "", ""),
+	)
+	provider := newClusterAPICloudProvider(cluster, nil)
+	assert.Equal(t, ProviderName, provider.Name())
+
+	nodeGroups := provider.NodeGroups()
+	assert.Equal(t, 1, len(nodeGroups))
+	assert.Equal(t, "machineset/default/workers", nodeGroups[0].Id())
+
+	node := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://1"}}
+	node.Name = "node-1"
+	nodeGroup, err := provider.NodeGroupForNode(node)
+	assert.NoError(t, err)
+	assert.Equal(t, nodeGroups[0], nodeGroup)
+
+	other := &apiv1.Node{}
+	other.Name = "control-plane"
+	nodeGroup, err = provider.NodeGroupForNode(other)
+	assert.NoError(t, err)
+	assert.Nil(t, nodeGroup)
+
+	// Annotating a MachineSet makes it a node group after the next Refresh.
+	gpu, err := cluster.Resource(machineSetResource).Namespace("default").Get("gpu", metav1.GetOptions{})
+	assert.NoError(t, err)
+	gpu.SetAnnotations(map[string]string{nodeGroupMinSizeAnnotationKey: "0", nodeGroupMaxSizeAnnotationKey: "2"})
+	_, err = cluster.Resource(machineSetResource).Namespace("default").Update(gpu, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(provider.NodeGroups()))
+
+	assert.NoError(t, provider.Refresh())
+	nodeGroups = provider.NodeGroups()
+	assert.Equal(t, 2, len(nodeGroups))
+	assert.Equal(t, "machineset/default/gpu", nodeGroups[1].Id())
+	assert.Equal(t, 2, nodeGroups[1].MaxSize())
+}
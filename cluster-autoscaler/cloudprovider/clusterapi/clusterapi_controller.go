@@ -0,0 +1,187 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"k8s.io/klog"
+)
+
+// machine is the part of a Cluster API Machine needed to map it to nodes and instances.
+type machine struct {
+	namespace    string
+	name         string
+	providerID   string
+	nodeName     string
+	deleting     bool
+	errorReason  string
+	errorMessage string
+	nodeGroup    *nodeGroup
+}
+
+// instanceID returns the id of the cloudprovider.Instance of the machine. Machines
+// whose infrastructure hasn't been provisioned yet don't have a provider id, they get
+// a placeholder instead.
+func (m *machine) instanceID() string {
+	if m.providerID != "" {
+		return m.providerID
+	}
+	return fmt.Sprintf("clusterapi://%s", namespacedName(m.namespace, m.name))
+}
+
+// clusterSnapshot holds the node groups formed by Cluster API objects and an index
+// of their machines at the time it was taken.
+type clusterSnapshot struct {
+	nodeGroups   []*nodeGroup
+	byMachine    map[string]*machine
+	byInstanceID map[string]*machine
+	byNodeName   map[string]*machine
+}
+
+// machineForNode returns the machine of the given node, or nil if it doesn't belong
+// to any node group.
+func (s *clusterSnapshot) machineForNode(node *apiv1.Node) *machine {
+	if key, found := node.Annotations[machineAnnotationKey]; found {
+		if m, found := s.byMachine[key]; found {
+			return m
+		}
+	}
+	if m, found := s.byInstanceID[node.Spec.ProviderID]; found && node.Spec.ProviderID != "" {
+		return m
+	}
+	return s.byNodeName[node.Name]
+}
+
+// machineController reads Cluster API objects through the dynamic client.
+type machineController struct {
+	client dynamic.Interface
+}
+
+// snapshot lists MachineDeployments, MachineSets and Machines of all namespaces and
+// builds node groups from the ones annotated with size limits. MachineSets owned by a
+// MachineDeployment are never node groups on their own, their machines belong to the
+// node group of the MachineDeployment, if any.
+func (c *machineController) snapshot() (*clusterSnapshot, error) {
+	machineDeployments, err := c.list(machineDeploymentResource)
+	if err != nil {
+		return nil, err
+	}
+	machineSets, err := c.list(machineSetResource)
+	if err != nil {
+		return nil, err
+	}
+	machines, err := c.list(machineResource)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &clusterSnapshot{
+		byMachine:    make(map[string]*machine),
+		byInstanceID: make(map[string]*machine),
+		byNodeName:   make(map[string]*machine),
+	}
+	deploymentNodeGroups := make(map[string]*nodeGroup)
+	for i := range machineDeployments {
+		if ng := c.newNodeGroup(&machineDeployments[i], machineDeploymentResource, machineDeploymentKind); ng != nil {
+			snapshot.nodeGroups = append(snapshot.nodeGroups, ng)
+			deploymentNodeGroups[namespacedName(ng.namespace, ng.name)] = ng
+		}
+	}
+	setNodeGroups := make(map[string]*nodeGroup)
+	for i := range machineSets {
+		machineSet := &machineSets[i]
+		key := namespacedName(machineSet.GetNamespace(), machineSet.GetName())
+		if owner := ownerName(machineSet, machineDeploymentKind); owner != "" {
+			if ng, found := deploymentNodeGroups[namespacedName(machineSet.GetNamespace(), owner)]; found {
+				setNodeGroups[key] = ng
+			}
+			continue
+		}
+		if ng := c.newNodeGroup(machineSet, machineSetResource, machineSetKind); ng != nil {
+			snapshot.nodeGroups = append(snapshot.nodeGroups, ng)
+			setNodeGroups[key] = ng
+		}
+	}
+	for i := range machines {
+		owner := ownerName(&machines[i], machineSetKind)
+		ng, found := setNodeGroups[namespacedName(machines[i].GetNamespace(), owner)]
+		if owner == "" || !found {
+			continue
+		}
+		m := newMachine(&machines[i], ng)
+		ng.machines = append(ng.machines, m)
+		snapshot.byMachine[namespacedName(m.namespace, m.name)] = m
+		snapshot.byInstanceID[m.instanceID()] = m
+		if m.nodeName != "" {
+			snapshot.byNodeName[m.nodeName] = m
+		}
+	}
+	return snapshot, nil
+}
+
+func (c *machineController) list(resource schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := c.client.Resource(resource).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", resource.Resource, err)
+	}
+	return list.Items, nil
+}
+
+// newNodeGroup returns the node group formed by a MachineSet or MachineDeployment, or
+// nil if it isn't annotated with size limits.
+func (c *machineController) newNodeGroup(obj *unstructured.Unstructured, resource schema.GroupVersionResource, kind string) *nodeGroup {
+	minSize, maxSize, enabled, err := parseScalingBounds(obj.GetAnnotations())
+	if err != nil {
+		klog.Warningf("Ignoring %s %s/%s: %v", kind, obj.GetNamespace(), obj.GetName(), err)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+	return &nodeGroup{
+		client:    c.client,
+		resource:  resource,
+		id:        fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), obj.GetNamespace(), obj.GetName()),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+		minSize:   minSize,
+		maxSize:   maxSize,
+		replicas:  replicas(obj),
+	}
+}
+
+func newMachine(obj *unstructured.Unstructured, ng *nodeGroup) *machine {
+	m := &machine{
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+		deleting:  obj.GetDeletionTimestamp() != nil,
+		nodeGroup: ng,
+	}
+	m.providerID, _, _ = unstructured.NestedString(obj.Object, "spec", "providerID")
+	m.nodeName, _, _ = unstructured.NestedString(obj.Object, "status", "nodeRef", "name")
+	m.errorReason, _, _ = unstructured.NestedString(obj.Object, "status", "errorReason")
+	m.errorMessage, _, _ = unstructured.NestedString(obj.Object, "status", "errorMessage")
+	return m
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"fmt"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newScalableObject(kind, namespace, name string, replicas int64, minSize, maxSize string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.k8s.io/v1alpha1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+		},
+	}}
+	annotations := map[string]string{}
+	if minSize != "" {
+		annotations[nodeGroupMinSizeAnnotationKey] = minSize
+	}
+	if maxSize != "" {
+		annotations[nodeGroupMaxSizeAnnotationKey] = maxSize
+	}
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func setOwner(obj *unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "cluster.k8s.io/v1alpha1", Kind: kind, Name: name}})
+	return obj
+}
+
+func newMachineObject(namespace, name, machineSet, providerID, nodeName string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.k8s.io/v1alpha1",
+		"kind":       "Machine",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec":   map[string]interface{}{},
+		"status": map[string]interface{}{},
+	}}
+	if providerID != "" {
+		unstructured.SetNestedField(obj.Object, providerID, "spec", "providerID")
+	}
+	if nodeName != "" {
+		unstructured.SetNestedField(obj.Object, nodeName, "status", "nodeRef", "name")
+	}
+	return setOwner(obj, machineSetKind, machineSet)
+}
+
+// fakeCluster is a fake dynamic client serving the scale subresource of MachineSets
+// and MachineDeployments from the scales map.
+type fakeCluster struct {
+	*fake.FakeDynamicClient
+	scales map[string]int64
+}
+
+func scaleKey(resource, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", resource, namespace, name)
+}
+
+func newFakeCluster(objects ...runtime.Object) *fakeCluster {
+	cluster := &fakeCluster{
+		FakeDynamicClient: fake.NewSimpleDynamicClient(runtime.NewScheme(), objects...),
+		scales:            make(map[string]int64),
+	}
+	for _, obj := range objects {
+		u := obj.(*unstructured.Unstructured)
+		switch u.GetKind() {
+		case machineSetKind:
+			cluster.scales[scaleKey(machineSetResource.Resource, u.GetNamespace(), u.GetName())] = int64(replicas(u))
+		case machineDeploymentKind:
+			cluster.scales[scaleKey(machineDeploymentResource.Resource, u.GetNamespace(), u.GetName())] = int64(replicas(u))
+		}
+	}
+	cluster.PrependReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != scaleSubresource {
+			return false, nil, nil
+		}
+		get := action.(clienttesting.GetAction)
+		key := scaleKey(get.GetResource().Resource, get.GetNamespace(), get.GetName())
+		replicas, found := cluster.scales[key]
+		if !found {
+			return true, nil, fmt.Errorf("%s not found", key)
+		}
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata": map[string]interface{}{
+				"namespace": get.GetNamespace(),
+				"name":      get.GetName(),
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+			},
+		}}, nil
+	})
+	cluster.PrependReactor("update", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != scaleSubresource {
+			return false, nil, nil
+		}
+		scale := action.(clienttesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		cluster.scales[scaleKey(action.GetResource().Resource, scale.GetNamespace(), scale.GetName())] = replicas
+		return true, scale, nil
+	})
+	return cluster
+}
+
+func TestSnapshot(t *testing.T) {
+	cluster := newFakeCluster(
+		newScalableObject(machineDeploymentKind, "default", "workers", 2, "1", "5"),
+		setOwner(newScalableObject(machineSetKind, "default", "workers-abc", 2, "", ""), machineDeploymentKind, "workers"),
+		newMachineObject("default", "workers-abc-1", "workers-abc", "vsphere://1", "node-1"),
+		newMachineObject("default", "workers-abc-2", "workers-abc", "", ""),
+		newScalableObject(machineSetKind, "metal", "bare", 1, "0", "3"),
+		newMachineObject("metal", "bare-1", "bare", "metal://1", "node-2"),
+		newScalableObject(machineSetKind, "default", "not-autoscaled", 1, "", ""),
+		newMachineObject("default", "not-autoscaled-1", "not-autoscaled", "vsphere://3", "node-3"),
+		newScalableObject(machineSetKind, "default", "invalid", 1, "3", "1"),
+	)
+	controller := &machineController{client: cluster}
+
+	snapshot, err := controller.snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(snapshot.nodeGroups))
+
+	workers := snapshot.nodeGroups[0]
+	assert.Equal(t, "machinedeployment/default/workers", workers.Id())
+	assert.Equal(t, machineDeploymentResource, workers.resource)
+	assert.Equal(t, 1, workers.MinSize())
+	assert.Equal(t, 5, workers.MaxSize())
+	size, err := workers.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, size)
+	assert.Equal(t, 2, len(workers.machines))
+
+	bare := snapshot.nodeGroups[1]
+	assert.Equal(t, "machineset/metal/bare", bare.Id())
+	assert.Equal(t, machineSetResource, bare.resource)
+	assert.Equal(t, 1, len(bare.machines))
+
+	byProviderID := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://1"}}
+	byProviderID.Name = "other-name"
+	assert.Equal(t, workers, snapshot.machineForNode(byProviderID).nodeGroup)
+
+	byNodeName := &apiv1.Node{}
+	byNodeName.Name = "node-2"
+	assert.Equal(t, bare, snapshot.machineForNode(byNodeName).nodeGroup)
+
+	byAnnotation := &apiv1.Node{}
+	byAnnotation.Name = "node-4"
+	byAnnotation.Annotations = map[string]string{machineAnnotationKey: "default/workers-abc-2"}
+	assert.Equal(t, workers, snapshot.machineForNode(byAnnotation).nodeGroup)
+
+	unregistered := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "clusterapi://default/workers-abc-2"}}
+	assert.Equal(t, workers, snapshot.machineForNode(unregistered).nodeGroup)
+
+	notAutoscaled := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://3"}}
+	notAutoscaled.Name = "node-3"
+	assert.Nil(t, snapshot.machineForNode(notAutoscaled))
+}
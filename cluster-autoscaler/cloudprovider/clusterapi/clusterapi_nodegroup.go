@@ -0,0 +1,284 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+// nodeGroup implements cloudprovider.NodeGroup for a MachineSet or MachineDeployment.
+// It's resized through the scale subresource of the object.
+type nodeGroup struct {
+	client    dynamic.Interface
+	resource  schema.GroupVersionResource
+	id        string
+	namespace string
+	name      string
+	minSize   int
+	maxSize   int
+	machines  []*machine
+
+	lock     sync.Mutex
+	replicas int
+}
+
+// MaxSize returns maximum size of the node group.
+func (ng *nodeGroup) MaxSize() int {
+	return ng.maxSize
+}
+
+// MinSize returns minimum size of the node group.
+func (ng *nodeGroup) MinSize() int {
+	return ng.minSize
+}
+
+// TargetSize returns the current target size of the node group. It is possible that the
+// number of nodes in Kubernetes is different at the moment but should be equal
+// to Size() once everything stabilizes (new nodes finish startup and registration or
+// removed nodes are deleted completely).
+func (ng *nodeGroup) TargetSize() (int, error) {
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+	return ng.replicas, nil
+}
+
+// IncreaseSize increases the size of the node group. To delete a node you need
+// to explicitly name it and use DeleteNode. This function should wait until
+// node group size is updated.
+func (ng *nodeGroup) IncreaseSize(delta int) error {
+	if delta <= 0 {
+		return fmt.Errorf("size increase must be positive")
+	}
+	return ng.scale(func(replicas int) (int, error) {
+		if replicas+delta > ng.maxSize {
+			return 0, fmt.Errorf("size increase too large - desired:%d max:%d", replicas+delta, ng.maxSize)
+		}
+		return replicas + delta, nil
+	})
+}
+
+// DeleteNodes deletes nodes from this node group. Error is returned either on
+// failure or if the given node doesn't belong to this node group. This function
+// should wait until node group size is updated. The machines of the nodes are
+// annotated for deletion before the number of replicas is decreased, so that
+// Cluster API removes these machines and not arbitrary ones.
+func (ng *nodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
+	machines := make([]*machine, 0, len(nodes))
+	for _, node := range nodes {
+		m := ng.machineForNode(node)
+		if m == nil {
+			return fmt.Errorf("node %s doesn't belong to node group %s", node.Name, ng.id)
+		}
+		machines = append(machines, m)
+	}
+	marked := false
+	err := ng.scale(func(replicas int) (int, error) {
+		if replicas-len(machines) < ng.minSize {
+			return 0, fmt.Errorf("size decrease too large - desired:%d min:%d", replicas-len(machines), ng.minSize)
+		}
+		if !marked {
+			marked = true
+			for _, m := range machines {
+				if err := ng.setDeleteAnnotation(m, time.Now().UTC().Format(time.RFC3339)); err != nil {
+					return 0, err
+				}
+			}
+		}
+		return replicas - len(machines), nil
+	})
+	if err != nil && marked {
+		// Otherwise the machines would be removed by the next scale-down of the node group,
+		// even if their nodes are still needed.
+		for _, m := range machines {
+			if unmarkErr := ng.setDeleteAnnotation(m, nil); unmarkErr != nil {
+				klog.Warningf("Failed to remove deletion mark of machine %s: %v", namespacedName(m.namespace, m.name), unmarkErr)
+			}
+		}
+	}
+	return err
+}
+
+// DecreaseTargetSize decreases the target size of the node group. This function
+// doesn't permit to delete any existing node and can be used only to reduce the
+// request for new nodes that have not been yet fulfilled. Delta should be negative.
+func (ng *nodeGroup) DecreaseTargetSize(delta int) error {
+	if delta >= 0 {
+		return fmt.Errorf("size decrease must be negative")
+	}
+	registered := 0
+	for _, m := range ng.machines {
+		if m.nodeName != "" {
+			registered++
+		}
+	}
+	return ng.scale(func(replicas int) (int, error) {
+		if replicas+delta < registered {
+			return 0, fmt.Errorf("attempt to delete existing nodes - target size:%d delta:%d registered nodes:%d", replicas, delta, registered)
+		}
+		return replicas + delta, nil
+	})
+}
+
+// Id returns an unique identifier of the node group.
+func (ng *nodeGroup) Id() string {
+	return ng.id
+}
+
+// Debug returns a string containing all information regarding this node group.
+func (ng *nodeGroup) Debug() string {
+	replicas, _ := ng.TargetSize()
+	return fmt.Sprintf("%s (min: %d, max: %d, replicas: %d)", ng.id, ng.minSize, ng.maxSize, replicas)
+}
+
+// Nodes returns a list of all nodes that belong to this node group.
+func (ng *nodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	instances := make([]cloudprovider.Instance, 0, len(ng.machines))
+	for _, m := range ng.machines {
+		status := &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}
+		switch {
+		case m.deleting:
+			status.State = cloudprovider.InstanceDeleting
+		case m.providerID == "" || m.nodeName == "":
+			status.State = cloudprovider.InstanceCreating
+		}
+		if m.errorReason != "" || m.errorMessage != "" {
+			status.ErrorInfo = &cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.OtherErrorClass,
+				ErrorCode:    m.errorReason,
+				ErrorMessage: m.errorMessage,
+			}
+		}
+		instances = append(instances, cloudprovider.Instance{Id: m.instanceID(), Status: status})
+	}
+	return instances, nil
+}
+
+// TemplateNodeInfo returns a node template for this node group. Cluster API objects
+// don't describe the nodes they create, so templates are built from existing nodes only.
+func (ng *nodeGroup) TemplateNodeInfo() (*schedulernodeinfo.NodeInfo, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// Exist checks if the node group really exists on the cloud provider side.
+func (ng *nodeGroup) Exist() bool {
+	return true
+}
+
+// Create creates the node group on the cloud provider side. Implementation optional.
+func (ng *nodeGroup) Create() (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// Delete deletes the node group on the cloud provider side.
+func (ng *nodeGroup) Delete() error {
+	return cloudprovider.ErrNotImplemented
+}
+
+// Autoprovisioned returns true if the node group is autoprovisioned.
+func (ng *nodeGroup) Autoprovisioned() bool {
+	return false
+}
+
+func (ng *nodeGroup) machineForNode(node *apiv1.Node) *machine {
+	for _, m := range ng.machines {
+		if node.Annotations[machineAnnotationKey] == namespacedName(m.namespace, m.name) ||
+			(node.Spec.ProviderID != "" && node.Spec.ProviderID == m.instanceID()) ||
+			(m.nodeName != "" && node.Name == m.nodeName) {
+			return m
+		}
+	}
+	return nil
+}
+
+// scale reads the scale subresource of the node group, computes the new number of
+// replicas from the current one with update and writes it back. The scale is read
+// again and update is called again if it was modified in the meantime, e.g. by the
+// MachineDeployment controller.
+func (ng *nodeGroup) scale(update func(replicas int) (int, error)) error {
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+
+	client := ng.client.Resource(ng.resource).Namespace(ng.namespace)
+	var current int64
+	var replicas int
+	var updateErr error
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updateErr = nil
+		scale, err := client.Get(ng.name, metav1.GetOptions{}, scaleSubresource)
+		if err != nil {
+			return fmt.Errorf("failed to get scale of node group %s: %v", ng.id, err)
+		}
+		current, _, err = unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		if err != nil {
+			return fmt.Errorf("invalid scale of node group %s: %v", ng.id, err)
+		}
+		replicas, err = update(int(current))
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas"); err != nil {
+			return fmt.Errorf("invalid scale of node group %s: %v", ng.id, err)
+		}
+		// Conflicts are returned as they are to be retried.
+		_, updateErr = client.Update(scale, metav1.UpdateOptions{}, scaleSubresource)
+		return updateErr
+	})
+	if updateErr != nil {
+		return fmt.Errorf("failed to scale node group %s to %d: %v", ng.id, replicas, updateErr)
+	}
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("Scaled node group %s from %d to %d replicas", ng.id, current, replicas)
+	ng.replicas = replicas
+	return nil
+}
+
+// setDeleteAnnotation sets the annotation of the machine which makes it deleted first
+// when the number of replicas of its MachineSet is decreased, or removes it if value is nil.
+func (ng *nodeGroup) setDeleteAnnotation(m *machine, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				machineDeleteAnnotationKey: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ng.client.Resource(machineResource).Namespace(m.namespace).Patch(m.name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update deletion mark of machine %s: %v", namespacedName(m.namespace, m.name), err)
+	}
+	return nil
+}
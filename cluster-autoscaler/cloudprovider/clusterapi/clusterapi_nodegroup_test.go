@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"fmt"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNodeGroup(t *testing.T) (*fakeCluster, *nodeGroup) {
+	cluster := newFakeCluster(
+		newScalableObject(machineSetKind, "default", "workers", 3, "1", "5"),
+		newMachineObject("default", "workers-1", "workers", "vsphere://1", "node-1"),
+		newMachineObject("default", "workers-2", "workers", "vsphere://2", "node-2"),
+		newMachineObject("default", "workers-3", "workers", "", ""),
+	)
+	snapshot, err := (&machineController{client: cluster}).snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(snapshot.nodeGroups))
+	return cluster, snapshot.nodeGroups[0]
+}
+
+func TestIncreaseSize(t *testing.T) {
+	cluster, ng := newTestNodeGroup(t)
+
+	assert.Error(t, ng.IncreaseSize(0))
+	assert.Error(t, ng.IncreaseSize(3))
+
+	assert.NoError(t, ng.IncreaseSize(2))
+	assert.Equal(t, int64(5), cluster.scales[scaleKey("machinesets", "default", "workers")])
+	size, err := ng.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, size)
+}
+
+func TestDeleteNodes(t *testing.T) {
+	cluster, ng := newTestNodeGroup(t)
+
+	node := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://2"}}
+	node.Name = "node-2"
+	assert.NoError(t, ng.DeleteNodes([]*apiv1.Node{node}))
+	assert.Equal(t, int64(2), cluster.scales[scaleKey("machinesets", "default", "workers")])
+	size, err := ng.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, size)
+
+	machine, err := cluster.Resource(machineResource).Namespace("default").Get("workers-2", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, machine.GetAnnotations(), machineDeleteAnnotationKey)
+	machine, err = cluster.Resource(machineResource).Namespace("default").Get("workers-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, machine.GetAnnotations(), machineDeleteAnnotationKey)
+
+	// Below min size.
+	node1 := &apiv1.Node{}
+	node1.Name = "node-1"
+	unregistered := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "clusterapi://default/workers-3"}}
+	assert.Error(t, ng.DeleteNodes([]*apiv1.Node{node1, unregistered}))
+	assert.Equal(t, int64(2), cluster.scales[scaleKey("machinesets", "default", "workers")])
+
+	// Not in the node group.
+	other := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://9"}}
+	other.Name = "node-9"
+	assert.Error(t, ng.DeleteNodes([]*apiv1.Node{other}))
+}
+
+func TestDeleteNodesConflict(t *testing.T) {
+	cluster, ng := newTestNodeGroup(t)
+	node := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: "vsphere://2"}}
+	node.Name = "node-2"
+
+	// The MachineSet controller scales the node group up between the read and the update of the scale.
+	conflicts := 1
+	cluster.PrependReactor("update", "machinesets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != scaleSubresource || conflicts == 0 {
+			return false, nil, nil
+		}
+		conflicts--
+		cluster.scales[scaleKey("machinesets", "default", "workers")] = 4
+		return true, nil, errors.NewConflict(machineSetResource.GroupResource(), "workers", fmt.Errorf("scale modified"))
+	})
+	assert.NoError(t, ng.DeleteNodes([]*apiv1.Node{node}))
+	assert.Equal(t, int64(3), cluster.scales[scaleKey("machinesets", "default", "workers")])
+	machine, err := cluster.Resource(machineResource).Namespace("default").Get("workers-2", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, machine.GetAnnotations(), machineDeleteAnnotationKey)
+
+	// The deletion mark is removed if the scale can't be updated.
+	node1 := &apiv1.Node{}
+	node1.Name = "node-1"
+	cluster.PrependReactor("update", "machinesets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != scaleSubresource {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	assert.Error(t, ng.DeleteNodes([]*apiv1.Node{node1}))
+	assert.Equal(t, int64(3), cluster.scales[scaleKey("machinesets", "default", "workers")])
+	machine, err = cluster.Resource(machineResource).Namespace("default").Get("workers-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotContains(t, machine.GetAnnotations(), machineDeleteAnnotationKey)
+}
+
+func TestDecreaseTargetSize(t *testing.T) {
+	cluster, ng := newTestNodeGroup(t)
+
+	assert.Error(t, ng.DecreaseTargetSize(0))
+	// Only one of three machines has no node.
+	assert.Error(t, ng.DecreaseTargetSize(-2))
+	assert.NoError(t, ng.DecreaseTargetSize(-1))
+	assert.Equal(t, int64(2), cluster.scales[scaleKey("machinesets", "default", "workers")])
+}
+
+func TestNodes(t *testing.T) {
+	_, ng := newTestNodeGroup(t)
+	ng.machines[1].deleting = true
+	ng.machines[2].errorReason = "CreateError"
+	ng.machines[2].errorMessage = "out of IP addresses"
+
+	instances, err := ng.Nodes()
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.Instance{
+		{Id: "vsphere://1", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+		{Id: "vsphere://2", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting}},
+		{Id: "clusterapi://default/workers-3", Status: &cloudprovider.InstanceStatus{
+			State: cloudprovider.InstanceCreating,
+			ErrorInfo: &cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.OtherErrorClass,
+				ErrorCode:    "CreateError",
+				ErrorMessage: "out of IP addresses",
+			},
+		}},
+	}, instances)
+
+	_, err = ng.TemplateNodeInfo()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// nodeGroupMinSizeAnnotationKey is the annotation of MachineSets and MachineDeployments
+	// holding the minimum size of the node group they form.
+	nodeGroupMinSizeAnnotationKey = "cluster.k8s.io/cluster-api-autoscaler-node-group-min-size"
+	// nodeGroupMaxSizeAnnotationKey is the annotation of MachineSets and MachineDeployments
+	// holding the maximum size of the node group they form.
+	nodeGroupMaxSizeAnnotationKey = "cluster.k8s.io/cluster-api-autoscaler-node-group-max-size"
+	// machineDeleteAnnotationKey marks the Machines a MachineSet should delete first
+	// when its number of replicas is decreased.
+	machineDeleteAnnotationKey = "cluster.k8s.io/delete-machine"
+	// machineAnnotationKey is set on nodes by Cluster API to the namespace/name of their Machine.
+	machineAnnotationKey = "cluster.k8s.io/machine"
+
+	machineSetKind        = "MachineSet"
+	machineDeploymentKind = "MachineDeployment"
+
+	// scaleSubresource is the subresource through which MachineSets and MachineDeployments are resized.
+	scaleSubresource = "scale"
+)
+
+var (
+	machineResource           = schema.GroupVersionResource{Group: "cluster.k8s.io", Version: "v1alpha1", Resource: "machines"}
+	machineSetResource        = schema.GroupVersionResource{Group: "cluster.k8s.io", Version: "v1alpha1", Resource: "machinesets"}
+	machineDeploymentResource = schema.GroupVersionResource{Group: "cluster.k8s.io", Version: "v1alpha1", Resource: "machinedeployments"}
+)
+
+// parseScalingBounds returns the node group size limits from the annotations of a
+// MachineSet or MachineDeployment. The returned bool is false if the object doesn't
+// have any of the annotations, i.e. it shouldn't be autoscaled.
+func parseScalingBounds(annotations map[string]string) (int, int, bool, error) {
+	minValue, hasMin := annotations[nodeGroupMinSizeAnnotationKey]
+	maxValue, hasMax := annotations[nodeGroupMaxSizeAnnotationKey]
+	if !hasMin && !hasMax {
+		return 0, 0, false, nil
+	}
+	if !hasMin || !hasMax {
+		return 0, 0, false, fmt.Errorf("both %s and %s annotations are required", nodeGroupMinSizeAnnotationKey, nodeGroupMaxSizeAnnotationKey)
+	}
+	minSize, err := strconv.Atoi(minValue)
+	if err != nil || minSize < 0 {
+		return 0, 0, false, fmt.Errorf("invalid min size %q", minValue)
+	}
+	maxSize, err := strconv.Atoi(maxValue)
+	if err != nil || maxSize < minSize {
+		return 0, 0, false, fmt.Errorf("invalid max size %q", maxValue)
+	}
+	return minSize, maxSize, true, nil
+}
+
+// ownerName returns the name of the owner of the object with the given kind, or an
+// empty string if there is none.
+func ownerName(obj *unstructured.Unstructured, kind string) string {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Kind == kind {
+			return owner.Name
+		}
+	}
+	return ""
+}
+
+// replicas returns the desired number of replicas of a MachineSet or MachineDeployment.
+// Cluster API defaults it to 1 if unset.
+func replicas(obj *unstructured.Unstructured) int {
+	value, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if err != nil || !found {
+		return 1
+	}
+	return int(value)
+}
+
+func namespacedName(namespace, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScalingBounds(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		minSize     int
+		maxSize     int
+		enabled     bool
+		expectErr   bool
+	}{
+		{name: "no annotations"},
+		{
+			name:        "valid",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "0", nodeGroupMaxSizeAnnotationKey: "10"},
+			maxSize:     10,
+			enabled:     true,
+		},
+		{
+			name:        "min equal to max",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "3", nodeGroupMaxSizeAnnotationKey: "3"},
+			minSize:     3,
+			maxSize:     3,
+			enabled:     true,
+		},
+		{
+			name:        "only min",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "1"},
+			expectErr:   true,
+		},
+		{
+			name:        "negative min",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "-1", nodeGroupMaxSizeAnnotationKey: "3"},
+			expectErr:   true,
+		},
+		{
+			name:        "max below min",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "4", nodeGroupMaxSizeAnnotationKey: "3"},
+			expectErr:   true,
+		},
+		{
+			name:        "not a number",
+			annotations: map[string]string{nodeGroupMinSizeAnnotationKey: "1", nodeGroupMaxSizeAnnotationKey: "ten"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			minSize, maxSize, enabled, err := parseScalingBounds(tc.annotations)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.minSize, minSize)
+			assert.Equal(t, tc.maxSize, maxSize)
+			assert.Equal(t, tc.enabled, enabled)
+		})
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	obj := newScalableObject(machineSetKind, "default", "workers", 4, "", "")
+	assert.Equal(t, 4, replicas(obj))
+	delete(obj.Object, "spec")
+	assert.Equal(t, 1, replicas(obj))
+}
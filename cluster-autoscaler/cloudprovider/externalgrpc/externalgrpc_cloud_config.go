@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalgrpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultGrpcTimeout = 5 * time.Second
+
+// CloudConfig is the configuration of the connection to the external cloud provider server.
+type CloudConfig struct {
+	// Address of the server, e.g. "cloud-provider.kube-system.svc:8086".
+	Address string `json:"address"`
+	// Key is the path to the client private key used for mutual TLS.
+	Key string `json:"key,omitempty"`
+	// Cert is the path to the client certificate used for mutual TLS.
+	Cert string `json:"cert,omitempty"`
+	// CACert is the path to the CA certificate the server certificate is verified with.
+	// The connection is not encrypted if empty.
+	CACert string `json:"cacert,omitempty"`
+	// GrpcTimeout is the timeout of a single call to the server. Defaults to 5s.
+	GrpcTimeout string `json:"grpc_timeout,omitempty"`
+}
+
+// parseCloudConfig parses and validates a YAML or JSON cloud config.
+func parseCloudConfig(data []byte) (*CloudConfig, time.Duration, error) {
+	config := &CloudConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse cloud config: %v", err)
+	}
+	if config.Address == "" {
+		return nil, 0, fmt.Errorf("cloud config has no server address")
+	}
+	if (config.Key == "") != (config.Cert == "") {
+		return nil, 0, fmt.Errorf("cloud config must set both key and cert or neither")
+	}
+	if config.Key != "" && config.CACert == "" {
+		return nil, 0, fmt.Errorf("cloud config sets client certificate without cacert")
+	}
+	timeout := defaultGrpcTimeout
+	if config.GrpcTimeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(config.GrpcTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, 0, fmt.Errorf("cloud config has invalid grpc_timeout %q", config.GrpcTimeout)
+		}
+	}
+	return config, timeout, nil
+}
+
+// dialOptions returns the options of the connection to the server.
+func (c *CloudConfig) dialOptions() ([]grpc.DialOption, error) {
+	if c.CACert == "" {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+	caCert, err := ioutil.ReadFile(c.CACert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate %s: %v", c.CACert, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA certificate %s", c.CACert)
+	}
+	tlsConfig := &tls.Config{RootCAs: pool}
+	if c.Cert != "" {
+		certificate, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}, nil
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+
+	"k8s.io/klog"
+)
+
+const (
+	// ProviderName is the cloud provider name for the external gRPC cloud provider.
+	ProviderName = "externalgrpc"
+)
+
+// externalGrpcCloudProvider implements CloudProvider by forwarding calls to an external gRPC server.
+// Node groups and node group lookups are cached until the next Refresh.
+type externalGrpcCloudProvider struct {
+	client          protos.CloudProviderClient
+	conn            *grpc.ClientConn
+	timeout         time.Duration
+	resourceLimiter *cloudprovider.ResourceLimiter
+	pricing         *externalGrpcPricingModel
+
+	lock             sync.Mutex
+	nodeGroups       []cloudprovider.NodeGroup
+	nodeGroupForNode map[string]cloudprovider.NodeGroup
+}
+
+func newExternalGrpcCloudProvider(client protos.CloudProviderClient, timeout time.Duration, rl *cloudprovider.ResourceLimiter) *externalGrpcCloudProvider {
+	return &externalGrpcCloudProvider{
+		client:           client,
+		timeout:          timeout,
+		resourceLimiter:  rl,
+		pricing:          &externalGrpcPricingModel{client: client, timeout: timeout},
+		nodeGroupForNode: make(map[string]cloudprovider.NodeGroup),
+	}
+}
+
+// Name returns name of the cloud provider.
+func (e *externalGrpcCloudProvider) Name() string {
+	return ProviderName
+}
+
+// NodeGroups returns all node groups configured for this cloud provider.
+func (e *externalGrpcCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.nodeGroups != nil {
+		return e.nodeGroups
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.NodeGroups(ctx, &protos.NodeGroupsRequest{})
+	if err != nil {
+		klog.Errorf("Failed to list node groups of external cloud provider: %v", err)
+		return []cloudprovider.NodeGroup{}
+	}
+	nodeGroups := make([]cloudprovider.NodeGroup, 0, len(response.GetNodeGroups()))
+	for _, nodeGroup := range response.GetNodeGroups() {
+		nodeGroups = append(nodeGroups, e.newNodeGroup(nodeGroup))
+	}
+	e.nodeGroups = nodeGroups
+	return nodeGroups
+}
+
+// NodeGroupForNode returns the node group for the given node, nil if the node
+// should not be processed by cluster autoscaler, or non-nil error if such
+// occurred.
+func (e *externalGrpcCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	key := node.Spec.ProviderID
+	if key == "" {
+		key = node.Name
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if nodeGroup, found := e.nodeGroupForNode[key]; found {
+		return nodeGroup, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.NodeGroupForNode(ctx, &protos.NodeGroupForNodeRequest{Node: externalGrpcNode(node)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node group of node %s: %v", node.Name, err)
+	}
+	var nodeGroup cloudprovider.NodeGroup
+	if response.GetNodeGroup().GetId() != "" {
+		nodeGroup = e.newNodeGroup(response.GetNodeGroup())
+	}
+	e.nodeGroupForNode[key] = nodeGroup
+	return nodeGroup, nil
+}
+
+// Pricing returns pricing model for this cloud provider or error if not available.
+// Pricing is not available once the external gRPC server doesn't implement PricingNodePrice.
+func (e *externalGrpcCloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
+	if e.pricing.isNotImplemented() {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	return e.pricing, nil
+}
+
+// GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
+// Implementation optional.
+func (e *externalGrpcCloudProvider) GetAvailableMachineTypes() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.GetAvailableMachineTypes(ctx, &protos.GetAvailableMachineTypesRequest{})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return response.GetMachineTypes(), nil
+}
+
+// NewNodeGroup builds a theoretical node group based on the node definition provided. The node group is not automatically
+// created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
+// Implementation optional.
+func (e *externalGrpcCloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
+	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
+	request := &protos.NewNodeGroupRequest{
+		MachineType:    machineType,
+		Labels:         labels,
+		SystemLabels:   systemLabels,
+		ExtraResources: make(map[string]string, len(extraResources)),
+	}
+	for _, taint := range taints {
+		request.Taints = append(request.Taints, &protos.Taint{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+	for name, quantity := range extraResources {
+		request.ExtraResources[name] = quantity.String()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.NewNodeGroup(ctx, request)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return e.newNodeGroup(response.GetNodeGroup()), nil
+}
+
+// GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
+// The limits configured in Cluster Autoscaler are returned if the external gRPC server doesn't provide them.
+func (e *externalGrpcCloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimiter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.GetResourceLimiter(ctx, &protos.GetResourceLimiterRequest{})
+	if err != nil {
+		if convertError(err) == cloudprovider.ErrNotImplemented {
+			return e.resourceLimiter, nil
+		}
+		return nil, err
+	}
+	return cloudprovider.NewResourceLimiter(response.GetMinLimits(), response.GetMaxLimits()), nil
+}
+
+// GPULabel returns the label added to nodes with GPU resource.
+func (e *externalGrpcCloudProvider) GPULabel() string {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	response, err := e.client.GPULabel(ctx, &protos.GPULabelRequest{})
+	if err != nil {
+		klog.Errorf("Failed to get GPU label of external cloud provider: %v", err)
+		return ""
+	}
+	return response.GetLabel()
+}
+
+// GetAvailableGPUTypes return all available GPU types cloud provider supports.
+func (e *externalGrpcCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	result := make(map[string]struct{})
+	response, err := e.client.GetAvailableGPUTypes(ctx, &protos.GetAvailableGPUTypesRequest{})
+	if err != nil {
+		klog.Errorf("Failed to get GPU types of external cloud provider: %v", err)
+		return result
+	}
+	for _, gpuType := range response.GetGpuTypes() {
+		result[gpuType] = struct{}{}
+	}
+	return result
+}
+
+// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
+func (e *externalGrpcCloudProvider) Cleanup() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	_, err := e.client.Cleanup(ctx, &protos.CleanupRequest{})
+	if e.conn != nil {
+		if closeErr := e.conn.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
+// In particular the list of node groups returned by NodeGroups can change as a result of CloudProvider.Refresh().
+func (e *externalGrpcCloudProvider) Refresh() error {
+	e.lock.Lock()
+	e.nodeGroups = nil
+	e.nodeGroupForNode = make(map[string]cloudprovider.NodeGroup)
+	e.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	_, err := e.client.Refresh(ctx, &protos.RefreshRequest{})
+	return err
+}
+
+func (e *externalGrpcCloudProvider) newNodeGroup(nodeGroup *protos.NodeGroup) *NodeGroup {
+	return &NodeGroup{
+		id:      nodeGroup.GetId(),
+		minSize: int(nodeGroup.GetMinSize()),
+		maxSize: int(nodeGroup.GetMaxSize()),
+		debug:   nodeGroup.GetDebug(),
+		client:  e.client,
+		timeout: e.timeout,
+	}
+}
+
+// externalGrpcPricingModel forwards pricing calls to the external gRPC server.
+type externalGrpcPricingModel struct {
+	client  protos.CloudProviderClient
+	timeout time.Duration
+
+	lock           sync.Mutex
+	notImplemented bool
+}
+
+func (p *externalGrpcPricingModel) isNotImplemented() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.notImplemented
+}
+
+// NodePrice returns a price of running the given node for a given period of time.
+func (p *externalGrpcPricingModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	start, end, err := timestamps(startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	response, err := p.client.PricingNodePrice(ctx, &protos.PricingNodePriceRequest{
+		Node:      externalGrpcNode(node),
+		StartTime: start,
+		EndTime:   end,
+	})
+	if err != nil {
+		err = convertError(err)
+		if err == cloudprovider.ErrNotImplemented {
+			p.lock.Lock()
+			p.notImplemented = true
+			p.lock.Unlock()
+		}
+		return 0, err
+	}
+	return response.GetPrice(), nil
+}
+
+// PodPrice returns a theoretical minimum price of running a pod for a given
+// period of time on a perfectly matching machine.
+func (p *externalGrpcPricingModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	start, end, err := timestamps(startTime, endTime)
+	if err != nil {
+		return 0, err
+	}
+	podJSON, err := json.Marshal(pod)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	response, err := p.client.PricingPodPrice(ctx, &protos.PricingPodPriceRequest{
+		Pod:       podJSON,
+		StartTime: start,
+		EndTime:   end,
+	})
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return response.GetPrice(), nil
+}
+
+func timestamps(startTime, endTime time.Time) (*timestamp.Timestamp, *timestamp.Timestamp, error) {
+	start, err := ptypes.TimestampProto(startTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := ptypes.TimestampProto(endTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	return start, end, nil
+}
+
+// externalGrpcNode returns the part of the node sent to the external gRPC server.
+func externalGrpcNode(node *apiv1.Node) *protos.ExternalGrpcNode {
+	return &protos.ExternalGrpcNode{
+		ProviderID:  node.Spec.ProviderID,
+		Name:        node.Name,
+		Labels:      node.Labels,
+		Annotations: node.Annotations,
+	}
+}
+
+// convertError returns cloudprovider.ErrNotImplemented for methods the external gRPC server doesn't implement.
+func convertError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return cloudprovider.ErrNotImplemented
+	}
+	return err
+}
+
+// BuildExternalGrpc builds the external gRPC cloud provider from the cloud config file.
+func BuildExternalGrpc(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+	if opts.CloudConfig == "" {
+		klog.Fatalf("No config file provided, please specify it via the --cloud-config flag")
+	}
+	data, err := ioutil.ReadFile(opts.CloudConfig)
+	if err != nil {
+		klog.Fatalf("Couldn't read cloud provider configuration %s: %v", opts.CloudConfig, err)
+	}
+	cloudConfig, timeout, err := parseCloudConfig(data)
+	if err != nil {
+		klog.Fatalf("Invalid cloud provider configuration %s: %v", opts.CloudConfig, err)
+	}
+	dialOptions, err := cloudConfig.dialOptions()
+	if err != nil {
+		klog.Fatalf("Failed to configure connection to external cloud provider: %v", err)
+	}
+	conn, err := grpc.Dial(cloudConfig.Address, dialOptions...)
+	if err != nil {
+		klog.Fatalf("Failed to connect to external cloud provider at %s: %v", cloudConfig.Address, err)
+	}
+	provider := newExternalGrpcCloudProvider(protos.NewCloudProviderClient(conn), timeout, rl)
+	provider.conn = conn
+	return provider
+}
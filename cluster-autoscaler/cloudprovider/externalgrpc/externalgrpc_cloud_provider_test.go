@@ -0,0 +1,466 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeServer is an external cloud provider with node groups of fixed instances.
+type fakeServer struct {
+	nodeGroups     []*protos.NodeGroup
+	instances      map[string][]*protos.Instance
+	targetSizes    map[string]int32
+	deletedNodes   []string
+	calls          map[string]int
+	template       *apiv1.Node
+	pricingEnabled bool
+	// autoprovisioning enables the optional node group creation methods.
+	autoprovisioning bool
+	newNodeGroups    []*protos.NewNodeGroupRequest
+	deletedGroups    []string
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{
+		nodeGroups: []*protos.NodeGroup{
+			{Id: "ng1", MinSize: 1, MaxSize: 10},
+			{Id: "ng2", MinSize: 0, MaxSize: 5},
+		},
+		instances: map[string][]*protos.Instance{
+			"ng1": {
+				{Id: "provider://n1", Status: &protos.InstanceStatus{InstanceState: protos.InstanceStatus_instanceRunning}},
+				{Id: "provider://n2", Status: &protos.InstanceStatus{
+					InstanceState: protos.InstanceStatus_instanceCreating,
+					ErrorInfo: &protos.InstanceErrorInfo{
+						ErrorClass:   protos.InstanceErrorInfo_outOfResourcesErrorClass,
+						ErrorCode:    "STOCKOUT",
+						ErrorMessage: "no capacity",
+					},
+				}},
+			},
+			"ng2": {{Id: "provider://n3"}},
+		},
+		targetSizes: map[string]int32{"ng1": 2, "ng2": 1},
+		calls:       make(map[string]int),
+	}
+}
+
+func (s *fakeServer) NodeGroups(ctx context.Context, req *protos.NodeGroupsRequest) (*protos.NodeGroupsResponse, error) {
+	s.calls["NodeGroups"]++
+	return &protos.NodeGroupsResponse{NodeGroups: s.nodeGroups}, nil
+}
+
+func (s *fakeServer) NodeGroupForNode(ctx context.Context, req *protos.NodeGroupForNodeRequest) (*protos.NodeGroupForNodeResponse, error) {
+	s.calls["NodeGroupForNode"]++
+	for id, instances := range s.instances {
+		for _, instance := range instances {
+			if instance.Id == req.GetNode().GetProviderID() {
+				for _, nodeGroup := range s.nodeGroups {
+					if nodeGroup.Id == id {
+						return &protos.NodeGroupForNodeResponse{NodeGroup: nodeGroup}, nil
+					}
+				}
+			}
+		}
+	}
+	return &protos.NodeGroupForNodeResponse{NodeGroup: &protos.NodeGroup{}}, nil
+}
+
+func (s *fakeServer) PricingNodePrice(ctx context.Context, req *protos.PricingNodePriceRequest) (*protos.PricingNodePriceResponse, error) {
+	if !s.pricingEnabled {
+		return nil, status.Error(codes.Unimplemented, "pricing not supported")
+	}
+	hours := float64(req.GetEndTime().GetSeconds()-req.GetStartTime().GetSeconds()) / 3600
+	return &protos.PricingNodePriceResponse{Price: hours * 2}, nil
+}
+
+func (s *fakeServer) PricingPodPrice(ctx context.Context, req *protos.PricingPodPriceRequest) (*protos.PricingPodPriceResponse, error) {
+	if !s.pricingEnabled {
+		return nil, status.Error(codes.Unimplemented, "pricing not supported")
+	}
+	pod := &apiv1.Pod{}
+	if err := json.Unmarshal(req.GetPod(), pod); err != nil {
+		return nil, err
+	}
+	return &protos.PricingPodPriceResponse{Price: float64(len(pod.Spec.Containers))}, nil
+}
+
+func (s *fakeServer) GPULabel(ctx context.Context, req *protos.GPULabelRequest) (*protos.GPULabelResponse, error) {
+	return &protos.GPULabelResponse{Label: "example.com/gpu"}, nil
+}
+
+func (s *fakeServer) GetAvailableGPUTypes(ctx context.Context, req *protos.GetAvailableGPUTypesRequest) (*protos.GetAvailableGPUTypesResponse, error) {
+	return &protos.GetAvailableGPUTypesResponse{GpuTypes: []string{"t4", "v100"}}, nil
+}
+
+func (s *fakeServer) Cleanup(ctx context.Context, req *protos.CleanupRequest) (*protos.CleanupResponse, error) {
+	return &protos.CleanupResponse{}, nil
+}
+
+func (s *fakeServer) Refresh(ctx context.Context, req *protos.RefreshRequest) (*protos.RefreshResponse, error) {
+	s.calls["Refresh"]++
+	return &protos.RefreshResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupTargetSize(ctx context.Context, req *protos.NodeGroupTargetSizeRequest) (*protos.NodeGroupTargetSizeResponse, error) {
+	size, found := s.targetSizes[req.GetId()]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "node group %s not found", req.GetId())
+	}
+	return &protos.NodeGroupTargetSizeResponse{TargetSize: size}, nil
+}
+
+func (s *fakeServer) NodeGroupIncreaseSize(ctx context.Context, req *protos.NodeGroupIncreaseSizeRequest) (*protos.NodeGroupIncreaseSizeResponse, error) {
+	s.targetSizes[req.GetId()] += req.GetDelta()
+	return &protos.NodeGroupIncreaseSizeResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupDeleteNodes(ctx context.Context, req *protos.NodeGroupDeleteNodesRequest) (*protos.NodeGroupDeleteNodesResponse, error) {
+	for _, node := range req.GetNodes() {
+		s.deletedNodes = append(s.deletedNodes, node.GetProviderID())
+	}
+	s.targetSizes[req.GetId()] -= int32(len(req.GetNodes()))
+	return &protos.NodeGroupDeleteNodesResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupDecreaseTargetSize(ctx context.Context, req *protos.NodeGroupDecreaseTargetSizeRequest) (*protos.NodeGroupDecreaseTargetSizeResponse, error) {
+	s.targetSizes[req.GetId()] += req.GetDelta()
+	return &protos.NodeGroupDecreaseTargetSizeResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupNodes(ctx context.Context, req *protos.NodeGroupNodesRequest) (*protos.NodeGroupNodesResponse, error) {
+	return &protos.NodeGroupNodesResponse{Instances: s.instances[req.GetId()]}, nil
+}
+
+func (s *fakeServer) NodeGroupTemplateNodeInfo(ctx context.Context, req *protos.NodeGroupTemplateNodeInfoRequest) (*protos.NodeGroupTemplateNodeInfoResponse, error) {
+	if s.template == nil {
+		return nil, status.Error(codes.Unimplemented, "templates not supported")
+	}
+	nodeJSON, err := json.Marshal(s.template)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.NodeGroupTemplateNodeInfoResponse{NodeInfo: nodeJSON}, nil
+}
+
+func (s *fakeServer) GetAvailableMachineTypes(ctx context.Context, req *protos.GetAvailableMachineTypesRequest) (*protos.GetAvailableMachineTypesResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	return &protos.GetAvailableMachineTypesResponse{MachineTypes: []string{"small", "large"}}, nil
+}
+
+func (s *fakeServer) NewNodeGroup(ctx context.Context, req *protos.NewNodeGroupRequest) (*protos.NewNodeGroupResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	s.newNodeGroups = append(s.newNodeGroups, req)
+	return &protos.NewNodeGroupResponse{NodeGroup: &protos.NodeGroup{Id: "nap-" + req.GetMachineType(), MaxSize: 100}}, nil
+}
+
+func (s *fakeServer) GetResourceLimiter(ctx context.Context, req *protos.GetResourceLimiterRequest) (*protos.GetResourceLimiterResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	return &protos.GetResourceLimiterResponse{
+		MinLimits: map[string]int64{cloudprovider.ResourceNameCores: 1},
+		MaxLimits: map[string]int64{cloudprovider.ResourceNameCores: 64},
+	}, nil
+}
+
+func (s *fakeServer) NodeGroupExist(ctx context.Context, req *protos.NodeGroupExistRequest) (*protos.NodeGroupExistResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	for _, nodeGroup := range s.nodeGroups {
+		if nodeGroup.Id == req.GetId() {
+			return &protos.NodeGroupExistResponse{Exist: true}, nil
+		}
+	}
+	return &protos.NodeGroupExistResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupCreate(ctx context.Context, req *protos.NodeGroupCreateRequest) (*protos.NodeGroupCreateResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	nodeGroup := &protos.NodeGroup{Id: req.GetId() + "-1", MaxSize: 100}
+	s.nodeGroups = append(s.nodeGroups, nodeGroup)
+	return &protos.NodeGroupCreateResponse{NodeGroup: nodeGroup}, nil
+}
+
+func (s *fakeServer) NodeGroupDelete(ctx context.Context, req *protos.NodeGroupDeleteRequest) (*protos.NodeGroupDeleteResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	s.deletedGroups = append(s.deletedGroups, req.GetId())
+	return &protos.NodeGroupDeleteResponse{}, nil
+}
+
+func (s *fakeServer) NodeGroupAutoprovisioned(ctx context.Context, req *protos.NodeGroupAutoprovisionedRequest) (*protos.NodeGroupAutoprovisionedResponse, error) {
+	if !s.autoprovisioning {
+		return nil, status.Error(codes.Unimplemented, "autoprovisioning not supported")
+	}
+	return &protos.NodeGroupAutoprovisionedResponse{Autoprovisioned: strings.HasPrefix(req.GetId(), "nap-")}, nil
+}
+
+func startFakeServer(t *testing.T, server *fakeServer) (*externalGrpcCloudProvider, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	protos.RegisterCloudProviderServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	provider := newExternalGrpcCloudProvider(protos.NewCloudProviderClient(conn), 5*time.Second, nil)
+	provider.conn = conn
+	return provider, func() {
+		provider.Cleanup()
+		grpcServer.Stop()
+	}
+}
+
+func TestNodeGroups(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+
+	nodeGroups := provider.NodeGroups()
+	assert.Equal(t, 2, len(nodeGroups))
+	assert.Equal(t, "ng1", nodeGroups[0].Id())
+	assert.Equal(t, 1, nodeGroups[0].MinSize())
+	assert.Equal(t, 10, nodeGroups[0].MaxSize())
+	assert.Equal(t, 1, server.calls["NodeGroups"])
+
+	// Node groups are cached until refresh.
+	provider.NodeGroups()
+	assert.Equal(t, 1, server.calls["NodeGroups"])
+	server.nodeGroups = server.nodeGroups[:1]
+	assert.NoError(t, provider.Refresh())
+	assert.Equal(t, 1, server.calls["Refresh"])
+	assert.Equal(t, 1, len(provider.NodeGroups()))
+	assert.Equal(t, 2, server.calls["NodeGroups"])
+}
+
+func TestNodeGroupForNode(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+
+	node := BuildTestNode("n3", 1000, 1000)
+	node.Spec.ProviderID = "provider://n3"
+	nodeGroup, err := provider.NodeGroupForNode(node)
+	assert.NoError(t, err)
+	assert.Equal(t, "ng2", nodeGroup.Id())
+
+	notAutoscaled := BuildTestNode("master", 1000, 1000)
+	nodeGroup, err = provider.NodeGroupForNode(notAutoscaled)
+	assert.NoError(t, err)
+	assert.Nil(t, nodeGroup)
+
+	// Lookups are cached until refresh.
+	provider.NodeGroupForNode(node)
+	provider.NodeGroupForNode(notAutoscaled)
+	assert.Equal(t, 2, server.calls["NodeGroupForNode"])
+}
+
+func TestNodeGroupOperations(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+	nodeGroup := provider.NodeGroups()[0]
+
+	size, err := nodeGroup.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, size)
+
+	assert.NoError(t, nodeGroup.IncreaseSize(3))
+	assert.Equal(t, int32(5), server.targetSizes["ng1"])
+	assert.Error(t, nodeGroup.IncreaseSize(0))
+
+	assert.NoError(t, nodeGroup.DecreaseTargetSize(-2))
+	assert.Equal(t, int32(3), server.targetSizes["ng1"])
+	assert.Error(t, nodeGroup.DecreaseTargetSize(1))
+
+	node := BuildTestNode("n1", 1000, 1000)
+	node.Spec.ProviderID = "provider://n1"
+	assert.NoError(t, nodeGroup.DeleteNodes([]*apiv1.Node{node}))
+	assert.Equal(t, []string{"provider://n1"}, server.deletedNodes)
+	assert.Equal(t, int32(2), server.targetSizes["ng1"])
+
+	instances, err := nodeGroup.Nodes()
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.Instance{
+		{Id: "provider://n1", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+		{Id: "provider://n2", Status: &cloudprovider.InstanceStatus{
+			State: cloudprovider.InstanceCreating,
+			ErrorInfo: &cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+				ErrorCode:    "STOCKOUT",
+				ErrorMessage: "no capacity",
+			},
+		}},
+	}, instances)
+
+	instances, err = provider.NodeGroups()[1].Nodes()
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.Instance{{Id: "provider://n3"}}, instances)
+
+	server.targetSizes = map[string]int32{}
+	_, err = nodeGroup.TargetSize()
+	assert.Error(t, err)
+}
+
+func TestTemplateNodeInfo(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+	nodeGroup := provider.NodeGroups()[1]
+
+	_, err := nodeGroup.TemplateNodeInfo()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+
+	server.template = BuildTestNode("template", 2000, 4000)
+	server.template.Labels = map[string]string{"pool": "ng2"}
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "ng2", nodeInfo.Node().Labels["pool"])
+	assert.Equal(t, int64(2000), nodeInfo.Node().Status.Allocatable.Cpu().MilliValue())
+	assert.Equal(t, 1, len(nodeInfo.Pods()))
+}
+
+func TestPricingAndGPUs(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+
+	server.pricingEnabled = true
+	pricing, err := provider.Pricing()
+	assert.NoError(t, err)
+	now := time.Now()
+	node := BuildTestNode("n1", 1000, 1000)
+	pod := BuildTestPod("p1", 100, 100)
+	price, priceErr := pricing.NodePrice(node, now, now.Add(3*time.Hour))
+	assert.NoError(t, priceErr)
+	assert.Equal(t, 6.0, price)
+	price, priceErr = pricing.PodPrice(pod, now, now.Add(time.Hour))
+	assert.NoError(t, priceErr)
+	assert.Equal(t, 1.0, price)
+
+	// Pricing is not available once the server turns out not to implement it.
+	server.pricingEnabled = false
+	_, priceErr = pricing.NodePrice(node, now, now.Add(time.Hour))
+	assert.Equal(t, cloudprovider.ErrNotImplemented, priceErr)
+	_, err = provider.Pricing()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+
+	assert.Equal(t, "example.com/gpu", provider.GPULabel())
+	assert.Equal(t, map[string]struct{}{"t4": {}, "v100": {}}, provider.GetAvailableGPUTypes())
+}
+
+func TestAutoprovisioning(t *testing.T) {
+	server := newFakeServer()
+	provider, stop := startFakeServer(t, server)
+	defer stop()
+	limiter := cloudprovider.NewResourceLimiter(map[string]int64{}, map[string]int64{cloudprovider.ResourceNameCores: 10})
+	provider.resourceLimiter = limiter
+	nodeGroup := provider.NodeGroups()[0]
+
+	// Without support on the server side.
+	_, err := provider.GetAvailableMachineTypes()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+	_, err = provider.NewNodeGroup("small", nil, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+	resourceLimiter, err := provider.GetResourceLimiter()
+	assert.NoError(t, err)
+	assert.Equal(t, limiter, resourceLimiter)
+	assert.True(t, nodeGroup.Exist())
+	assert.False(t, nodeGroup.Autoprovisioned())
+	_, err = nodeGroup.Create()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+	assert.Equal(t, cloudprovider.ErrNotImplemented, nodeGroup.Delete())
+
+	server.autoprovisioning = true
+	machineTypes, err := provider.GetAvailableMachineTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"small", "large"}, machineTypes)
+	resourceLimiter, err = provider.GetResourceLimiter()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resourceLimiter.GetMin(cloudprovider.ResourceNameCores))
+	assert.Equal(t, int64(64), resourceLimiter.GetMax(cloudprovider.ResourceNameCores))
+
+	taints := []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}
+	extraResources := map[string]resource.Quantity{"nvidia.com/gpu": resource.MustParse("2")}
+	newNodeGroup, err := provider.NewNodeGroup("large", map[string]string{"pool": "gpu"}, nil, taints, extraResources)
+	assert.NoError(t, err)
+	assert.Equal(t, "nap-large", newNodeGroup.Id())
+	assert.Equal(t, 1, len(server.newNodeGroups))
+	assert.Equal(t, "large", server.newNodeGroups[0].GetMachineType())
+	assert.Equal(t, map[string]string{"pool": "gpu"}, server.newNodeGroups[0].GetLabels())
+	assert.Equal(t, []*protos.Taint{{Key: "dedicated", Value: "gpu", Effect: "NoSchedule"}}, server.newNodeGroups[0].GetTaints())
+	assert.Equal(t, map[string]string{"nvidia.com/gpu": "2"}, server.newNodeGroups[0].GetExtraResources())
+	assert.False(t, newNodeGroup.Exist())
+	assert.True(t, newNodeGroup.Autoprovisioned())
+
+	createdNodeGroup, err := newNodeGroup.Create()
+	assert.NoError(t, err)
+	assert.Equal(t, "nap-large-1", createdNodeGroup.Id())
+	assert.Equal(t, 100, createdNodeGroup.MaxSize())
+	assert.True(t, createdNodeGroup.Exist())
+	assert.NoError(t, createdNodeGroup.Delete())
+	assert.Equal(t, []string{"nap-large-1"}, server.deletedGroups)
+}
+
+func TestParseCloudConfig(t *testing.T) {
+	config, timeout, err := parseCloudConfig([]byte("address: provider.kube-system.svc:8086\ngrpc_timeout: 10s\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "provider.kube-system.svc:8086", config.Address)
+	assert.Equal(t, 10*time.Second, timeout)
+
+	_, timeout, err = parseCloudConfig([]byte("address: a:1\ncacert: /ca.pem\nkey: /key.pem\ncert: /cert.pem\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultGrpcTimeout, timeout)
+
+	for name, data := range map[string]string{
+		"no address":    "grpc_timeout: 10s\n",
+		"key only":      "address: a:1\ncacert: /ca.pem\nkey: /key.pem\n",
+		"no cacert":     "address: a:1\nkey: /key.pem\ncert: /cert.pem\n",
+		"bad timeout":   "address: a:1\ngrpc_timeout: soon\n",
+		"unknown field": "address: a:1\nport: 1\n",
+	} {
+		_, _, err := parseCloudConfig([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/externalgrpc/protos"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+// NodeGroup implements cloudprovider.NodeGroup by forwarding calls to an external gRPC server.
+type NodeGroup struct {
+	id      string
+	minSize int
+	maxSize int
+	debug   string
+	client  protos.CloudProviderClient
+	timeout time.Duration
+}
+
+// MaxSize returns maximum size of the node group.
+func (ng *NodeGroup) MaxSize() int {
+	return ng.maxSize
+}
+
+// MinSize returns minimum size of the node group.
+func (ng *NodeGroup) MinSize() int {
+	return ng.minSize
+}
+
+// TargetSize returns the current target size of the node group. It is possible that the
+// number of nodes in Kubernetes is different at the moment but should be equal
+// to Size() once everything stabilizes (new nodes finish startup and registration or
+// removed nodes are deleted completely).
+func (ng *NodeGroup) TargetSize() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupTargetSize(ctx, &protos.NodeGroupTargetSizeRequest{Id: ng.id})
+	if err != nil {
+		return 0, err
+	}
+	return int(response.GetTargetSize()), nil
+}
+
+// IncreaseSize increases the size of the node group. To delete a node you need
+// to explicitly name it and use DeleteNode. This function should wait until
+// node group size is updated.
+func (ng *NodeGroup) IncreaseSize(delta int) error {
+	if delta <= 0 {
+		return fmt.Errorf("size increase must be positive")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	_, err := ng.client.NodeGroupIncreaseSize(ctx, &protos.NodeGroupIncreaseSizeRequest{Id: ng.id, Delta: int32(delta)})
+	return err
+}
+
+// DeleteNodes deletes nodes from this node group. Error is returned either on
+// failure or if the given node doesn't belong to this node group. This function
+// should wait until node group size is updated.
+func (ng *NodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
+	request := &protos.NodeGroupDeleteNodesRequest{Id: ng.id}
+	for _, node := range nodes {
+		request.Nodes = append(request.Nodes, externalGrpcNode(node))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	_, err := ng.client.NodeGroupDeleteNodes(ctx, request)
+	return err
+}
+
+// DecreaseTargetSize decreases the target size of the node group. This function
+// doesn't permit to delete any existing node and can be used only to reduce the
+// request for new nodes that have not been yet fulfilled. Delta should be negative.
+func (ng *NodeGroup) DecreaseTargetSize(delta int) error {
+	if delta >= 0 {
+		return fmt.Errorf("size decrease must be negative")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	_, err := ng.client.NodeGroupDecreaseTargetSize(ctx, &protos.NodeGroupDecreaseTargetSizeRequest{Id: ng.id, Delta: int32(delta)})
+	return err
+}
+
+// Id returns an unique identifier of the node group.
+func (ng *NodeGroup) Id() string {
+	return ng.id
+}
+
+// Debug returns a string containing all information regarding this node group.
+func (ng *NodeGroup) Debug() string {
+	return fmt.Sprintf("%s (min: %d, max: %d): %s", ng.id, ng.minSize, ng.maxSize, ng.debug)
+}
+
+// Nodes returns a list of all nodes that belong to this node group.
+func (ng *NodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupNodes(ctx, &protos.NodeGroupNodesRequest{Id: ng.id})
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]cloudprovider.Instance, 0, len(response.GetInstances()))
+	for _, instance := range response.GetInstances() {
+		instances = append(instances, cloudprovider.Instance{
+			Id:     instance.GetId(),
+			Status: instanceStatus(instance.GetStatus()),
+		})
+	}
+	return instances, nil
+}
+
+func instanceStatus(status *protos.InstanceStatus) *cloudprovider.InstanceStatus {
+	if status == nil {
+		return nil
+	}
+	result := &cloudprovider.InstanceStatus{}
+	switch status.GetInstanceState() {
+	case protos.InstanceStatus_instanceRunning:
+		result.State = cloudprovider.InstanceRunning
+	case protos.InstanceStatus_instanceCreating:
+		result.State = cloudprovider.InstanceCreating
+	case protos.InstanceStatus_instanceDeleting:
+		result.State = cloudprovider.InstanceDeleting
+	}
+	if errorInfo := status.GetErrorInfo(); errorInfo != nil {
+		result.ErrorInfo = &cloudprovider.InstanceErrorInfo{
+			ErrorCode:    errorInfo.GetErrorCode(),
+			ErrorMessage: errorInfo.GetErrorMessage(),
+			ErrorClass:   cloudprovider.OtherErrorClass,
+		}
+		if errorInfo.GetErrorClass() == protos.InstanceErrorInfo_outOfResourcesErrorClass {
+			result.ErrorInfo.ErrorClass = cloudprovider.OutOfResourcesErrorClass
+		}
+	}
+	return result
+}
+
+// TemplateNodeInfo returns a node template for this node group. Returns
+// cloudprovider.ErrNotImplemented if the external gRPC server doesn't provide templates.
+func (ng *NodeGroup) TemplateNodeInfo() (*schedulernodeinfo.NodeInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupTemplateNodeInfo(ctx, &protos.NodeGroupTemplateNodeInfoRequest{Id: ng.id})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	if len(response.GetNodeInfo()) == 0 {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	node := &apiv1.Node{}
+	if err := json.Unmarshal(response.GetNodeInfo(), node); err != nil {
+		return nil, fmt.Errorf("failed to parse template node of node group %s: %v", ng.id, err)
+	}
+	nodeInfo := schedulernodeinfo.NewNodeInfo(cloudprovider.BuildKubeProxy(ng.id))
+	nodeInfo.SetNode(node)
+	return nodeInfo, nil
+}
+
+// Exist checks if the node group really exists on the cloud provider side. Node groups are
+// assumed to exist if the external gRPC server can't tell.
+func (ng *NodeGroup) Exist() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupExist(ctx, &protos.NodeGroupExistRequest{Id: ng.id})
+	if err != nil {
+		if convertError(err) != cloudprovider.ErrNotImplemented {
+			klog.Errorf("Failed to check if node group %s exists: %v", ng.id, err)
+		}
+		return true
+	}
+	return response.GetExist()
+}
+
+// Create creates the node group on the cloud provider side. Implementation optional.
+func (ng *NodeGroup) Create() (cloudprovider.NodeGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupCreate(ctx, &protos.NodeGroupCreateRequest{Id: ng.id})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &NodeGroup{
+		id:      response.GetNodeGroup().GetId(),
+		minSize: int(response.GetNodeGroup().GetMinSize()),
+		maxSize: int(response.GetNodeGroup().GetMaxSize()),
+		debug:   response.GetNodeGroup().GetDebug(),
+		client:  ng.client,
+		timeout: ng.timeout,
+	}, nil
+}
+
+// Delete deletes the node group on the cloud provider side.
+// This will be executed only for autoprovisioned node groups, once their size drops to 0.
+// Implementation optional.
+func (ng *NodeGroup) Delete() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	_, err := ng.client.NodeGroupDelete(ctx, &protos.NodeGroupDeleteRequest{Id: ng.id})
+	return convertError(err)
+}
+
+// Autoprovisioned returns true if the node group is autoprovisioned. Node groups are assumed
+// not to be autoprovisioned if the external gRPC server can't tell.
+func (ng *NodeGroup) Autoprovisioned() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), ng.timeout)
+	defer cancel()
+	response, err := ng.client.NodeGroupAutoprovisioned(ctx, &protos.NodeGroupAutoprovisionedRequest{Id: ng.id})
+	if err != nil {
+		if convertError(err) != cloudprovider.ErrNotImplemented {
+			klog.Errorf("Failed to check if node group %s is autoprovisioned: %v", ng.id, err)
+		}
+		return false
+	}
+	return response.GetAutoprovisioned()
+}
@@ -23,15 +23,18 @@ package kubemark
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/config/dynamic"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/client-go/informers"
 	kubeclient "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubernetes/pkg/kubemark"
@@ -46,6 +49,10 @@ const (
 
 	// GPULabel is the label added to nodes with GPU resource.
 	GPULabel = "cloud.google.com/gke-accelerator"
+
+	// nodeGroupLabel is the label the kubemark controller puts on replication controllers of
+	// hollow nodes to record their node group.
+	nodeGroupLabel = "autoscaling.k8s.io/nodegroup"
 )
 
 var (
@@ -59,28 +66,39 @@ var (
 // KubemarkCloudProvider implements CloudProvider interface for kubemark
 type KubemarkCloudProvider struct {
 	kubemarkController *kubemark.KubemarkController
-	nodeGroups         []*NodeGroup
-	resourceLimiter    *cloudprovider.ResourceLimiter
+	// rcLister lists the replication controllers of hollow nodes, used to find autoprovisioned
+	// node groups created before a restart.
+	rcLister        listersv1.ReplicationControllerLister
+	nodeGroups      []*NodeGroup
+	resourceLimiter *cloudprovider.ResourceLimiter
+	config          *Config
 }
 
 // BuildKubemarkCloudProvider builds a CloudProvider for kubemark. Builds
-// node groups from passed in specs.
-func BuildKubemarkCloudProvider(kubemarkController *kubemark.KubemarkController, specs []string, resourceLimiter *cloudprovider.ResourceLimiter) (*KubemarkCloudProvider, error) {
+// node groups from passed in specs and from the node group labels of hollow
+// node replication controllers listed by rcLister, if set.
+func BuildKubemarkCloudProvider(kubemarkController *kubemark.KubemarkController, rcLister listersv1.ReplicationControllerLister,
+	specs []string, resourceLimiter *cloudprovider.ResourceLimiter, config *Config) (*KubemarkCloudProvider, error) {
 	kubemark := &KubemarkCloudProvider{
 		kubemarkController: kubemarkController,
+		rcLister:           rcLister,
 		nodeGroups:         make([]*NodeGroup, 0),
 		resourceLimiter:    resourceLimiter,
+		config:             config,
 	}
 	for _, spec := range specs {
 		if err := kubemark.addNodeGroup(spec); err != nil {
 			return nil, err
 		}
 	}
+	if err := kubemark.discoverAutoprovisionedNodeGroups(); err != nil {
+		return nil, err
+	}
 	return kubemark, nil
 }
 
 func (kubemark *KubemarkCloudProvider) addNodeGroup(spec string) error {
-	nodeGroup, err := buildNodeGroup(spec, kubemark.kubemarkController)
+	nodeGroup, err := buildNodeGroup(spec, kubemark)
 	if err != nil {
 		return err
 	}
@@ -89,6 +107,47 @@ func (kubemark *KubemarkCloudProvider) addNodeGroup(spec string) error {
 	return nil
 }
 
+// discoverAutoprovisionedNodeGroups starts tracking autoprovisioned node groups that have
+// hollow nodes but are not tracked, e.g. because they were created before a restart.
+func (kubemark *KubemarkCloudProvider) discoverAutoprovisionedNodeGroups() error {
+	if kubemark.rcLister == nil {
+		return nil
+	}
+	rcs, err := kubemark.rcLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list hollow nodes: %v", err)
+	}
+	for _, rc := range rcs {
+		name := rc.Labels[nodeGroupLabel]
+		if !isAutoprovisionedNodeGroupName(name) || kubemark.getNodeGroup(name) != nil {
+			continue
+		}
+		klog.V(2).Infof("adding autoprovisioned node group: %s", name)
+		kubemark.nodeGroups = append(kubemark.nodeGroups, kubemark.autoprovisionedNodeGroup(name))
+	}
+	return nil
+}
+
+func (kubemark *KubemarkCloudProvider) autoprovisionedNodeGroup(name string) *NodeGroup {
+	return &NodeGroup{
+		Name:               name,
+		kubemarkController: kubemark.kubemarkController,
+		kubemark:           kubemark,
+		minSize:            0,
+		maxSize:            kubemark.config.AutoprovisionedMaxSize,
+		autoprovisioned:    true,
+	}
+}
+
+func (kubemark *KubemarkCloudProvider) getNodeGroup(name string) *NodeGroup {
+	for _, nodeGroup := range kubemark.nodeGroups {
+		if nodeGroup.Name == name {
+			return nodeGroup
+		}
+	}
+	return nil
+}
+
 // Name returns name of the cloud provider.
 func (kubemark *KubemarkCloudProvider) Name() string {
 	return ProviderName
@@ -124,10 +183,8 @@ func (kubemark *KubemarkCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloud
 	if err != nil {
 		return nil, err
 	}
-	for _, nodeGroup := range kubemark.nodeGroups {
-		if nodeGroup.Name == nodeGroupName {
-			return nodeGroup, nil
-		}
+	if nodeGroup := kubemark.getNodeGroup(nodeGroupName); nodeGroup != nil {
+		return nodeGroup, nil
 	}
 	return nil, nil
 }
@@ -135,14 +192,35 @@ func (kubemark *KubemarkCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloud
 // GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
 // Implementation optional.
 func (kubemark *KubemarkCloudProvider) GetAvailableMachineTypes() ([]string, error) {
-	return []string{}, cloudprovider.ErrNotImplemented
+	return []string{HollowNodeMachineType}, nil
 }
 
 // NewNodeGroup builds a theoretical node group based on the node definition provided.
+// All hollow nodes are created from the same template, so the labels, taints and
+// resources requested have to be ones of the configured hollow node spec.
 func (kubemark *KubemarkCloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
 	taints []apiv1.Taint,
 	extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	spec := kubemark.config.HollowNode
+	if machineType != HollowNodeMachineType {
+		return nil, cloudprovider.ErrIllegalConfiguration
+	}
+	for key, value := range cloudprovider.JoinStringMaps(labels, systemLabels) {
+		if specValue, found := spec.Labels[key]; !found || specValue != value {
+			return nil, cloudprovider.ErrIllegalConfiguration
+		}
+	}
+	for _, taint := range taints {
+		if !hasTaint(spec.Taints, taint) {
+			return nil, cloudprovider.ErrIllegalConfiguration
+		}
+	}
+	for name := range extraResources {
+		if _, found := spec.Capacity[apiv1.ResourceName(name)]; !found {
+			return nil, cloudprovider.ErrIllegalConfiguration
+		}
+	}
+	return kubemark.autoprovisionedNodeGroup(autoprovisionedNodeGroupName()), nil
 }
 
 // GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
@@ -153,7 +231,7 @@ func (kubemark *KubemarkCloudProvider) GetResourceLimiter() (*cloudprovider.Reso
 // Refresh is called before every main loop and can be used to dynamically update cloud provider state.
 // In particular the list of node groups returned by NodeGroups can change as a result of CloudProvider.Refresh().
 func (kubemark *KubemarkCloudProvider) Refresh() error {
-	return nil
+	return kubemark.discoverAutoprovisionedNodeGroups()
 }
 
 // Cleanup cleans up all resources before the cloud provider is removed
@@ -165,8 +243,10 @@ func (kubemark *KubemarkCloudProvider) Cleanup() error {
 type NodeGroup struct {
 	Name               string
 	kubemarkController *kubemark.KubemarkController
+	kubemark           *KubemarkCloudProvider
 	minSize            int
 	maxSize            int
+	autoprovisioned    bool
 }
 
 // Id returns nodegroup name.
@@ -267,30 +347,64 @@ func (nodeGroup *NodeGroup) DecreaseTargetSize(delta int) error {
 
 // TemplateNodeInfo returns a node template for this node group.
 func (nodeGroup *NodeGroup) TemplateNodeInfo() (*schedulernodeinfo.NodeInfo, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	return buildTemplateNodeInfo(nodeGroup.Name, nodeGroup.kubemark.config.HollowNode), nil
 }
 
 // Exist checks if the node group really exists on the cloud provider side.
 func (nodeGroup *NodeGroup) Exist() bool {
-	return true
+	return nodeGroup.kubemark.getNodeGroup(nodeGroup.Name) != nil
 }
 
-// Create creates the node group on the cloud provider side.
+// Create creates the node group on the cloud provider side. Node groups of kubemark
+// are only labels of hollow nodes, so the group just starts being tracked.
 func (nodeGroup *NodeGroup) Create() (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if nodeGroup.Exist() {
+		return nil, cloudprovider.ErrAlreadyExist
+	}
+	klog.V(2).Infof("adding node group: %s", nodeGroup.Name)
+	nodeGroup.kubemark.nodeGroups = append(nodeGroup.kubemark.nodeGroups, nodeGroup)
+	return nodeGroup, nil
 }
 
-// Delete deletes the node group on the cloud provider side.
+// Delete deletes the node group on the cloud provider side. Only empty
+// autoprovisioned node groups can be deleted.
 func (nodeGroup *NodeGroup) Delete() error {
-	return cloudprovider.ErrNotImplemented
+	if !nodeGroup.autoprovisioned {
+		return fmt.Errorf("node group %s is not autoprovisioned and can't be deleted", nodeGroup.Name)
+	}
+	size, err := nodeGroup.kubemarkController.GetNodeGroupTargetSize(nodeGroup.Name)
+	if err != nil {
+		return err
+	}
+	if size > 0 {
+		return fmt.Errorf("node group %s has %d nodes and can't be deleted", nodeGroup.Name, size)
+	}
+	nodeGroups := make([]*NodeGroup, 0, len(nodeGroup.kubemark.nodeGroups))
+	for _, existing := range nodeGroup.kubemark.nodeGroups {
+		if existing.Name != nodeGroup.Name {
+			nodeGroups = append(nodeGroups, existing)
+		}
+	}
+	klog.V(2).Infof("removing node group: %s", nodeGroup.Name)
+	nodeGroup.kubemark.nodeGroups = nodeGroups
+	return nil
 }
 
 // Autoprovisioned returns true if the node group is autoprovisioned.
 func (nodeGroup *NodeGroup) Autoprovisioned() bool {
+	return nodeGroup.autoprovisioned
+}
+
+func hasTaint(taints []apiv1.Taint, taint apiv1.Taint) bool {
+	for _, t := range taints {
+		if t.MatchTaint(&taint) && t.Value == taint.Value {
+			return true
+		}
+	}
 	return false
 }
 
-func buildNodeGroup(value string, kubemarkController *kubemark.KubemarkController) (*NodeGroup, error) {
+func buildNodeGroup(value string, kubemark *KubemarkCloudProvider) (*NodeGroup, error) {
 	spec, err := dynamic.SpecFromString(value, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse node group spec: %v", err)
@@ -298,7 +412,8 @@ func buildNodeGroup(value string, kubemarkController *kubemark.KubemarkControlle
 
 	nodeGroup := &NodeGroup{
 		Name:               spec.Name,
-		kubemarkController: kubemarkController,
+		kubemarkController: kubemark.kubemarkController,
+		kubemark:           kubemark,
 		minSize:            spec.MinSize,
 		maxSize:            spec.MaxSize,
 	}
@@ -308,6 +423,18 @@ func buildNodeGroup(value string, kubemarkController *kubemark.KubemarkControlle
 
 // BuildKubemark builds Kubemark cloud provider.
 func BuildKubemark(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+	kubemarkProviderConfig := DefaultConfig()
+	if opts.CloudConfig != "" {
+		data, err := ioutil.ReadFile(opts.CloudConfig)
+		if err != nil {
+			klog.Fatalf("Couldn't read cloud provider configuration %s: %v", opts.CloudConfig, err)
+		}
+		kubemarkProviderConfig, err = ParseConfig(data)
+		if err != nil {
+			klog.Fatalf("Failed to parse cloud provider configuration: %v", err)
+		}
+	}
+
 	externalConfig, err := rest.InClusterConfig()
 	if err != nil {
 		klog.Fatalf("Failed to get kubeclient config for external cluster: %v", err)
@@ -340,7 +467,10 @@ func BuildKubemark(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDis
 	}
 	go kubemarkController.Run(stop)
 
-	provider, err := BuildKubemarkCloudProvider(kubemarkController, do.NodeGroupSpecs, rl)
+	// The kubemark controller already watches the replication controllers of hollow nodes, so this
+	// lister shares its informer.
+	rcLister := externalInformerFactory.Core().V1().ReplicationControllers().Lister()
+	provider, err := BuildKubemarkCloudProvider(kubemarkController, rcLister, do.NodeGroupSpecs, rl, kubemarkProviderConfig)
 	if err != nil {
 		klog.Fatalf("Failed to create Kubemark cloud provider: %v", err)
 	}
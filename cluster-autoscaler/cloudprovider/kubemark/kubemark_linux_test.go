@@ -0,0 +1,112 @@
+// +build linux
+
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNodeGroup(t *testing.T) {
+	config := DefaultConfig()
+	config.HollowNode.Labels = map[string]string{"pool": "default"}
+	config.HollowNode.Taints = []apiv1.Taint{{Key: "dedicated", Value: "kubemark", Effect: apiv1.TaintEffectNoSchedule}}
+	provider, err := BuildKubemarkCloudProvider(nil, nil, []string{"1:10:ng1"}, nil, config)
+	assert.NoError(t, err)
+
+	machineTypes, err := provider.GetAvailableMachineTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{HollowNodeMachineType}, machineTypes)
+
+	_, err = provider.NewNodeGroup("n1-standard-1", nil, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+	_, err = provider.NewNodeGroup(HollowNodeMachineType, map[string]string{"pool": "other"}, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+	_, err = provider.NewNodeGroup(HollowNodeMachineType, nil, nil,
+		[]apiv1.Taint{{Key: "dedicated", Value: "other", Effect: apiv1.TaintEffectNoSchedule}}, nil)
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+	_, err = provider.NewNodeGroup(HollowNodeMachineType, nil, nil, nil, map[string]resource.Quantity{"nvidia.com/gpu": resource.MustParse("1")})
+	assert.Equal(t, cloudprovider.ErrIllegalConfiguration, err)
+
+	nodeGroup, err := provider.NewNodeGroup(HollowNodeMachineType, map[string]string{"pool": "default"}, nil, config.HollowNode.Taints,
+		map[string]resource.Quantity{"cpu": resource.MustParse("1")})
+	assert.NoError(t, err)
+	assert.True(t, nodeGroup.Autoprovisioned())
+	assert.False(t, nodeGroup.Exist())
+	assert.Equal(t, 0, nodeGroup.MinSize())
+	assert.Equal(t, defaultAutoprovisionedMaxSize, nodeGroup.MaxSize())
+	assert.Equal(t, 1, len(provider.NodeGroups()))
+
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "default", nodeInfo.Node().Labels["pool"])
+
+	created, err := nodeGroup.Create()
+	assert.NoError(t, err)
+	assert.True(t, created.Exist())
+	assert.Equal(t, 2, len(provider.NodeGroups()))
+	_, err = nodeGroup.Create()
+	assert.Equal(t, cloudprovider.ErrAlreadyExist, err)
+
+	assert.Error(t, provider.NodeGroups()[0].Delete())
+}
+
+func TestDiscoverAutoprovisionedNodeGroups(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	addHollowNode := func(name, nodeGroup string) {
+		assert.NoError(t, indexer.Add(&apiv1.ReplicationController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kubemark",
+				Labels:    map[string]string{nodeGroupLabel: nodeGroup},
+			},
+		}))
+	}
+	addHollowNode("ng1-1", "ng1")
+	addHollowNode("nap-hollow-node-1-1", "nap-hollow-node-1")
+	addHollowNode("nap-hollow-node-1-2", "nap-hollow-node-1")
+	addHollowNode("nap-hollow-node-other-1", "nap-hollow-node-other")
+	provider, err := BuildKubemarkCloudProvider(nil, listersv1.NewReplicationControllerLister(indexer), []string{"1:10:ng1"}, nil, DefaultConfig())
+	assert.NoError(t, err)
+
+	nodeGroups := provider.NodeGroups()
+	assert.Equal(t, 2, len(nodeGroups))
+	assert.Equal(t, "ng1", nodeGroups[0].Id())
+	assert.False(t, nodeGroups[0].Autoprovisioned())
+	assert.Equal(t, "nap-hollow-node-1", nodeGroups[1].Id())
+	assert.True(t, nodeGroups[1].Autoprovisioned())
+	assert.True(t, nodeGroups[1].Exist())
+	assert.Equal(t, 0, nodeGroups[1].MinSize())
+	assert.Equal(t, defaultAutoprovisionedMaxSize, nodeGroups[1].MaxSize())
+
+	// Node groups created in the meantime are found on refresh.
+	addHollowNode("nap-hollow-node-2-1", "nap-hollow-node-2")
+	assert.NoError(t, provider.Refresh())
+	nodeGroups = provider.NodeGroups()
+	assert.Equal(t, 3, len(nodeGroups))
+	assert.Equal(t, "nap-hollow-node-2", nodeGroups[2].Id())
+}
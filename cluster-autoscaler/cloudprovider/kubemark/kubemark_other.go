@@ -50,7 +50,8 @@ type KubemarkCloudProvider struct{}
 
 // BuildKubemarkCloudProvider builds a CloudProvider for kubemark. Builds
 // node groups from passed in specs.
-func BuildKubemarkCloudProvider(kubemarkController interface{}, specs []string, resourceLimiter *cloudprovider.ResourceLimiter) (*KubemarkCloudProvider, error) {
+func BuildKubemarkCloudProvider(kubemarkController interface{}, rcLister interface{},
+	specs []string, resourceLimiter *cloudprovider.ResourceLimiter, config *Config) (*KubemarkCloudProvider, error) {
 	return nil, cloudprovider.ErrNotImplemented
 }
 
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	kubeletapis "k8s.io/kubernetes/pkg/kubelet/apis"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// HollowNodeMachineType is the only machine type of kubemark, all hollow nodes
+	// are created from the same replication controller template.
+	HollowNodeMachineType = "hollow-node"
+
+	// autoprovisionedNodeGroupPrefix is the name prefix of autoprovisioned node groups.
+	autoprovisionedNodeGroupPrefix = "nap"
+
+	defaultAutoprovisionedMaxSize = 100
+)
+
+// Config is the configuration of the kubemark cloud provider, read from --cloud-config.
+type Config struct {
+	// HollowNode describes the nodes registered by hollow nodes.
+	HollowNode HollowNodeSpec `json:"hollowNode"`
+	// AutoprovisionedMaxSize is the max size of autoprovisioned node groups.
+	AutoprovisionedMaxSize int `json:"autoprovisionedMaxSize"`
+}
+
+// HollowNodeSpec describes a hollow node. It has to match the flags of the hollow
+// node replication controllers, as it's only used to build node templates.
+type HollowNodeSpec struct {
+	// Capacity of the node. Resources that are not set default to the capacity
+	// reported by the fake cAdvisor of hollow nodes.
+	Capacity apiv1.ResourceList `json:"capacity"`
+	// Labels of the node, in addition to the well-known labels set by kubelet.
+	Labels map[string]string `json:"labels"`
+	// Taints of the node.
+	Taints []apiv1.Taint `json:"taints"`
+}
+
+// DefaultConfig returns the configuration used when --cloud-config is not set.
+func DefaultConfig() *Config {
+	config := &Config{}
+	config.setDefaults()
+	return config
+}
+
+// ParseConfig parses and validates the kubemark cloud provider configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse kubemark config: %v", err)
+	}
+	if config.AutoprovisionedMaxSize < 0 {
+		return nil, fmt.Errorf("autoprovisionedMaxSize must be non-negative, got %d", config.AutoprovisionedMaxSize)
+	}
+	config.setDefaults()
+	return config, nil
+}
+
+func (config *Config) setDefaults() {
+	defaults := apiv1.ResourceList{
+		apiv1.ResourceCPU:    resource.MustParse("1"),
+		apiv1.ResourceMemory: resource.MustParse("3840Mi"),
+		apiv1.ResourcePods:   resource.MustParse("110"),
+	}
+	if config.HollowNode.Capacity == nil {
+		config.HollowNode.Capacity = apiv1.ResourceList{}
+	}
+	for name, quantity := range defaults {
+		if _, found := config.HollowNode.Capacity[name]; !found {
+			config.HollowNode.Capacity[name] = quantity
+		}
+	}
+	if config.AutoprovisionedMaxSize == 0 {
+		config.AutoprovisionedMaxSize = defaultAutoprovisionedMaxSize
+	}
+}
+
+// buildTemplateNodeInfo builds the template of a hollow node of the given node group.
+func buildTemplateNodeInfo(nodeGroupName string, spec HollowNodeSpec) *schedulernodeinfo.NodeInfo {
+	nodeName := fmt.Sprintf("%s-template-%d", nodeGroupName, rand.Int63())
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:     nodeName,
+			SelfLink: fmt.Sprintf("/api/v1/nodes/%s", nodeName),
+			Labels: cloudprovider.JoinStringMaps(spec.Labels, map[string]string{
+				kubeletapis.LabelArch:   cloudprovider.DefaultArch,
+				kubeletapis.LabelOS:     cloudprovider.DefaultOS,
+				apiv1.LabelInstanceType: HollowNodeMachineType,
+				apiv1.LabelHostname:     nodeName,
+			}),
+		},
+		Spec: apiv1.NodeSpec{
+			Taints: append([]apiv1.Taint{}, spec.Taints...),
+		},
+		Status: apiv1.NodeStatus{
+			Capacity:    spec.Capacity.DeepCopy(),
+			Allocatable: spec.Capacity.DeepCopy(),
+			Conditions:  cloudprovider.BuildReadyConditions(),
+		},
+	}
+	nodeInfo := schedulernodeinfo.NewNodeInfo(cloudprovider.BuildKubeProxy(nodeGroupName))
+	nodeInfo.SetNode(node)
+	return nodeInfo
+}
+
+// autoprovisionedNodeGroupName returns a new, random name of an autoprovisioned node group.
+func autoprovisionedNodeGroupName() string {
+	return fmt.Sprintf("%s-%s-%d", autoprovisionedNodeGroupPrefix, HollowNodeMachineType, rand.Int63())
+}
+
+// isAutoprovisionedNodeGroupName returns true if the node group name was returned by
+// autoprovisionedNodeGroupName.
+func isAutoprovisionedNodeGroupName(name string) bool {
+	prefix := fmt.Sprintf("%s-%s-", autoprovisionedNodeGroupPrefix, HollowNodeMachineType)
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	_, err := strconv.ParseInt(strings.TrimPrefix(name, prefix), 10, 64)
+	return err == nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubemark
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+hollowNode:
+  capacity:
+    cpu: "4"
+    nvidia.com/gpu: "1"
+  labels:
+    pool: gpu
+  taints:
+  - {key: dedicated, value: gpu, effect: NoSchedule}
+autoprovisionedMaxSize: 10
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, resource.MustParse("4"), config.HollowNode.Capacity[apiv1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("1"), config.HollowNode.Capacity["nvidia.com/gpu"])
+	assert.Equal(t, resource.MustParse("3840Mi"), config.HollowNode.Capacity[apiv1.ResourceMemory])
+	assert.Equal(t, resource.MustParse("110"), config.HollowNode.Capacity[apiv1.ResourcePods])
+	assert.Equal(t, map[string]string{"pool": "gpu"}, config.HollowNode.Labels)
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}, config.HollowNode.Taints)
+	assert.Equal(t, 10, config.AutoprovisionedMaxSize)
+
+	assert.Equal(t, defaultAutoprovisionedMaxSize, DefaultConfig().AutoprovisionedMaxSize)
+
+	_, err = ParseConfig([]byte(`{hollowNode: {cpu: "1"}}`))
+	assert.Error(t, err)
+	_, err = ParseConfig([]byte(`{autoprovisionedMaxSize: -1}`))
+	assert.Error(t, err)
+}
+
+func TestBuildTemplateNodeInfo(t *testing.T) {
+	spec := DefaultConfig().HollowNode
+	spec.Labels = map[string]string{"pool": "default"}
+	spec.Taints = []apiv1.Taint{{Key: "dedicated", Value: "kubemark", Effect: apiv1.TaintEffectNoSchedule}}
+
+	nodeInfo := buildTemplateNodeInfo("ng1", spec)
+	node := nodeInfo.Node()
+	assert.Contains(t, node.Name, "ng1-template-")
+	assert.Equal(t, "default", node.Labels["pool"])
+	assert.Equal(t, HollowNodeMachineType, node.Labels[apiv1.LabelInstanceType])
+	assert.Equal(t, node.Name, node.Labels[apiv1.LabelHostname])
+	assert.Equal(t, spec.Taints, node.Spec.Taints)
+	assert.Equal(t, spec.Capacity, node.Status.Allocatable)
+	assert.Equal(t, cloudprovider.BuildReadyConditions()[0].Type, node.Status.Conditions[0].Type)
+	assert.Equal(t, 1, len(nodeInfo.Pods()))
+
+	// The spec is not modified by templates.
+	node.Status.Capacity[apiv1.ResourceCPU] = resource.MustParse("2")
+	assert.Equal(t, resource.MustParse("1"), spec.Capacity[apiv1.ResourceCPU])
+	assert.NotEqual(t, node.Name, buildTemplateNodeInfo("ng1", spec).Node().Name)
+}
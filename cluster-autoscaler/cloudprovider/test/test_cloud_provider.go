@@ -18,6 +18,7 @@ package test
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -162,9 +163,22 @@ func (tcp *TestCloudProvider) GetAvailableMachineTypes() ([]string, error) {
 // created on the cloud provider side. The node group is not returned by NodeGroups() until it is created.
 func (tcp *TestCloudProvider) NewNodeGroup(machineType string, labels map[string]string, systemLabels map[string]string,
 	taints []apiv1.Taint, extraResources map[string]resource.Quantity) (cloudprovider.NodeGroup, error) {
+	// Node groups of the same machine type but with different taints or extra resources get different ids.
+	id := "autoprovisioned-" + machineType
+	for _, taint := range taints {
+		id += "-" + taint.Key
+	}
+	resourceNames := make([]string, 0, len(extraResources))
+	for name := range extraResources {
+		resourceNames = append(resourceNames, name)
+	}
+	sort.Strings(resourceNames)
+	for _, name := range resourceNames {
+		id += "-" + name
+	}
 	return &TestNodeGroup{
 		cloudProvider:   tcp,
-		id:              "autoprovisioned-" + machineType,
+		id:              id,
 		minSize:         0,
 		maxSize:         1000,
 		targetSize:      0,
@@ -173,6 +187,7 @@ func (tcp *TestCloudProvider) NewNodeGroup(machineType string, labels map[string
 		machineType:     machineType,
 		labels:          labels,
 		taints:          taints,
+		extraResources:  extraResources,
 	}, nil
 }
 
@@ -227,6 +242,14 @@ func (tcp *TestCloudProvider) AddNode(nodeGroupId string, node *apiv1.Node) {
 	tcp.nodes[node.Name] = nodeGroupId
 }
 
+// RemoveNode removes the given node from its group.
+func (tcp *TestCloudProvider) RemoveNode(nodeName string) {
+	tcp.Lock()
+	defer tcp.Unlock()
+
+	delete(tcp.nodes, nodeName)
+}
+
 // GetResourceLimiter returns struct containing limits (max, min) for resources (cores, memory etc.).
 func (tcp *TestCloudProvider) GetResourceLimiter() (*cloudprovider.ResourceLimiter, error) {
 	return tcp.resourceLimiter, nil
@@ -261,6 +284,7 @@ type TestNodeGroup struct {
 	machineType     string
 	labels          map[string]string
 	taints          []apiv1.Taint
+	extraResources  map[string]resource.Quantity
 }
 
 // MaxSize returns maximum size of the node group.
@@ -426,3 +450,8 @@ func (tng *TestNodeGroup) Labels() map[string]string {
 func (tng *TestNodeGroup) Taints() []apiv1.Taint {
 	return tng.taints
 }
+
+// ExtraResources returns extra resources passed to the test node group when it was created.
+func (tng *TestNodeGroup) ExtraResources() map[string]resource.Quantity {
+	return tng.extraResources
+}
@@ -26,7 +26,9 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
@@ -55,6 +57,10 @@ const (
 
 	// NodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.
 	NodeGroupBackoffResetTimeout = 3 * time.Hour
+
+	// TimeoutErrorCode is the error code of scale-ups that failed because new nodes did not
+	// register within MaxNodeProvisionTime.
+	TimeoutErrorCode = "timeout"
 )
 
 // ScaleUpRequest contains information about the requested node group scale up.
@@ -90,6 +96,9 @@ type ClusterStateRegistryConfig struct {
 	OkTotalUnreadyCount int
 	//  Maximum time CA waits for node to be provisioned
 	MaxNodeProvisionTime time.Duration
+	// AdaptiveNodeProvisionTimeFactor, if positive, replaces MaxNodeProvisionTime of scale-ups of node groups
+	// with enough history by this multiple of the p99 time their new nodes took to become ready.
+	AdaptiveNodeProvisionTimeFactor float64
 }
 
 // IncorrectNodeGroupSize contains information about how much the current size of the node group
@@ -113,6 +122,18 @@ type UnregisteredNode struct {
 	UnregisteredSince time.Time
 }
 
+// ScaleUpFailure contains information about a failed scale-up of a node group.
+type ScaleUpFailure struct {
+	// NodeGroup is the node group that failed to scale up.
+	NodeGroup cloudprovider.NodeGroup
+	// ErrorClass is the class of the error that caused the failure.
+	ErrorClass cloudprovider.InstanceErrorClass
+	// ErrorCode is the cloud provider specific error code, or TimeoutErrorCode.
+	ErrorCode string
+	// Time is the time when the failure was detected.
+	Time time.Time
+}
+
 // ClusterStateRegistry is a structure to keep track the current state of the cluster.
 type ClusterStateRegistry struct {
 	sync.Mutex
@@ -134,31 +155,59 @@ type ClusterStateRegistry struct {
 	logRecorder                        *utils.LogEventRecorder
 	cloudProviderNodeInstances         map[string][]cloudprovider.Instance
 	previousCloudProviderNodeInstances map[string][]cloudprovider.Instance
+	nodeGroupConfigProcessor           nodegroupconfig.NodeGroupConfigProcessor
+	scaleUpFailures                    map[string]ScaleUpFailure
+	provisioningTracker                *provisioningTracker
 }
 
 // NewClusterStateRegistry creates new ClusterStateRegistry.
-func NewClusterStateRegistry(cloudProvider cloudprovider.CloudProvider, config ClusterStateRegistryConfig, logRecorder *utils.LogEventRecorder, backoff backoff.Backoff) *ClusterStateRegistry {
+func NewClusterStateRegistry(cloudProvider cloudprovider.CloudProvider, config ClusterStateRegistryConfig, logRecorder *utils.LogEventRecorder, backoff backoff.Backoff,
+	nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) *ClusterStateRegistry {
 	emptyStatus := &api.ClusterAutoscalerStatus{
 		ClusterwideConditions: make([]api.ClusterAutoscalerCondition, 0),
 		NodeGroupStatuses:     make([]api.NodeGroupStatus, 0),
 	}
 	return &ClusterStateRegistry{
-		scaleUpRequests:         make(map[string]*ScaleUpRequest),
-		scaleDownRequests:       make([]*ScaleDownRequest, 0),
-		nodes:                   make([]*apiv1.Node, 0),
-		cloudProvider:           cloudProvider,
-		config:                  config,
-		perNodeGroupReadiness:   make(map[string]Readiness),
-		acceptableRanges:        make(map[string]AcceptableRange),
-		incorrectNodeGroupSizes: make(map[string]IncorrectNodeGroupSize),
-		unregisteredNodes:       make(map[string]UnregisteredNode),
-		candidatesForScaleDown:  make(map[string][]string),
-		backoff:                 backoff,
-		lastStatus:              emptyStatus,
-		logRecorder:             logRecorder,
+		scaleUpRequests:          make(map[string]*ScaleUpRequest),
+		scaleDownRequests:        make([]*ScaleDownRequest, 0),
+		nodes:                    make([]*apiv1.Node, 0),
+		cloudProvider:            cloudProvider,
+		config:                   config,
+		perNodeGroupReadiness:    make(map[string]Readiness),
+		acceptableRanges:         make(map[string]AcceptableRange),
+		incorrectNodeGroupSizes:  make(map[string]IncorrectNodeGroupSize),
+		unregisteredNodes:        make(map[string]UnregisteredNode),
+		candidatesForScaleDown:   make(map[string][]string),
+		backoff:                  backoff,
+		lastStatus:               emptyStatus,
+		logRecorder:              logRecorder,
+		nodeGroupConfigProcessor: nodeGroupConfigProcessor,
+		scaleUpFailures:          make(map[string]ScaleUpFailure),
+		provisioningTracker:      newProvisioningTracker(),
 	}
 }
 
+// maxNodeProvisionTime returns the maximum time to wait for a node of the node group to be provisioned.
+func (csr *ClusterStateRegistry) maxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) time.Duration {
+	defaults := config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: csr.config.MaxNodeProvisionTime}
+	return csr.nodeGroupConfigProcessor.GetOptions(nodeGroup, defaults).MaxNodeProvisionTime
+}
+
+// NodeProvisionTimeout returns the time after which a scale-up of the node group is considered
+// failed and its unregistered nodes are removed. With AdaptiveNodeProvisionTimeFactor set, node
+// groups without a MaxNodeProvisionTime override that have enough provisioning history wait for
+// a multiple of their usual provisioning time.
+func (csr *ClusterStateRegistry) NodeProvisionTimeout(nodeGroup cloudprovider.NodeGroup) time.Duration {
+	configured := csr.maxNodeProvisionTime(nodeGroup)
+	if csr.config.AdaptiveNodeProvisionTimeFactor <= 0 || csr.nodeGroupConfigProcessor.HasMaxNodeProvisionTime(nodeGroup) {
+		return configured
+	}
+	if adaptive, found := csr.provisioningTracker.adaptiveProvisionTime(nodeGroup.Id(), csr.config.AdaptiveNodeProvisionTimeFactor); found {
+		return adaptive
+	}
+	return configured
+}
+
 // RegisterOrUpdateScaleUp registers scale-up for give node group or changes requested node increase
 // count.
 // If delta is positive then number of new nodes requested is increased; Time and expectedAddTime
@@ -173,12 +222,15 @@ func (csr *ClusterStateRegistry) RegisterOrUpdateScaleUp(nodeGroup cloudprovider
 
 func (csr *ClusterStateRegistry) registerOrUpdateScaleUpNoLock(nodeGroup cloudprovider.NodeGroup, delta int, currentTime time.Time) {
 	scaleUpRequest, found := csr.scaleUpRequests[nodeGroup.Id()]
+	if found || delta > 0 {
+		csr.provisioningTracker.registerScaleUp(nodeGroup.Id(), delta, currentTime)
+	}
 	if !found && delta > 0 {
 		scaleUpRequest = &ScaleUpRequest{
 			NodeGroup:       nodeGroup,
 			Increase:        delta,
 			Time:            currentTime,
-			ExpectedAddTime: currentTime.Add(csr.config.MaxNodeProvisionTime),
+			ExpectedAddTime: currentTime.Add(csr.NodeProvisionTimeout(nodeGroup)),
 		}
 		csr.scaleUpRequests[nodeGroup.Id()] = scaleUpRequest
 		return
@@ -200,7 +252,7 @@ func (csr *ClusterStateRegistry) registerOrUpdateScaleUpNoLock(nodeGroup cloudpr
 	if delta > 0 {
 		// if we are actually adding new nodes shift Time and ExpectedAddTime
 		scaleUpRequest.Time = currentTime
-		scaleUpRequest.ExpectedAddTime = currentTime.Add(csr.config.MaxNodeProvisionTime)
+		scaleUpRequest.ExpectedAddTime = currentTime.Add(csr.NodeProvisionTimeout(nodeGroup))
 	}
 }
 
@@ -221,6 +273,8 @@ func (csr *ClusterStateRegistry) updateScaleRequests(currentTime time.Time) {
 			// scale-out finished successfully
 			// remove it and reset node group backoff
 			delete(csr.scaleUpRequests, nodeGroupName)
+			delete(csr.scaleUpFailures, nodeGroupName)
+			csr.provisioningTracker.forgetScaleUps(nodeGroupName)
 			csr.backoff.RemoveBackoff(scaleUpRequest.NodeGroup, csr.nodeInfosForGroups[scaleUpRequest.NodeGroup.Id()])
 			klog.V(4).Infof("Scale up in group %v finished successfully in %v",
 				nodeGroupName, currentTime.Sub(scaleUpRequest.Time))
@@ -234,8 +288,9 @@ func (csr *ClusterStateRegistry) updateScaleRequests(currentTime time.Time) {
 				"Nodes added to group %s failed to register within %v",
 				scaleUpRequest.NodeGroup.Id(), currentTime.Sub(scaleUpRequest.Time))
 			metrics.RegisterFailedScaleUp(metrics.Timeout)
-			csr.backoffNodeGroup(scaleUpRequest.NodeGroup, cloudprovider.OtherErrorClass, "timeout", currentTime)
+			csr.backoffNodeGroup(scaleUpRequest.NodeGroup, cloudprovider.OtherErrorClass, TimeoutErrorCode, currentTime)
 			delete(csr.scaleUpRequests, nodeGroupName)
+			csr.provisioningTracker.expireScaleUps(nodeGroupName, currentTime.Add(csr.maxNodeProvisionTime(scaleUpRequest.NodeGroup)))
 		}
 	}
 
@@ -252,6 +307,12 @@ func (csr *ClusterStateRegistry) updateScaleRequests(currentTime time.Time) {
 func (csr *ClusterStateRegistry) backoffNodeGroup(nodeGroup cloudprovider.NodeGroup, errorClass cloudprovider.InstanceErrorClass, errorCode string, currentTime time.Time) {
 	nodeGroupInfo := csr.nodeInfosForGroups[nodeGroup.Id()]
 	backoffUntil := csr.backoff.Backoff(nodeGroup, nodeGroupInfo, errorClass, errorCode, currentTime)
+	csr.scaleUpFailures[nodeGroup.Id()] = ScaleUpFailure{
+		NodeGroup:  nodeGroup,
+		ErrorClass: errorClass,
+		ErrorCode:  errorCode,
+		Time:       currentTime,
+	}
 	klog.Warningf("Disabling scale-up for node group %v until %v; errorClass=%v; errorCode=%v", nodeGroup.Id(), backoffUntil, errorClass, errorCode)
 }
 
@@ -282,11 +343,13 @@ func (csr *ClusterStateRegistry) UpdateNodes(nodes []*apiv1.Node, nodeInfosForGr
 		return err
 	}
 	notRegistered := getNotRegisteredNodes(nodes, cloudProviderNodeInstances, currentTime)
+	nodeGroupIds := getNodeGroupIds(nodes, cloudProviderNodeInstances)
 
 	csr.Lock()
 	defer csr.Unlock()
 
 	csr.nodes = nodes
+	csr.provisioningTracker.update(nodes, nodeGroupIds, currentTime)
 	csr.nodeInfosForGroups = nodeInfosForGroups
 	csr.previousCloudProviderNodeInstances = csr.cloudProviderNodeInstances
 	csr.cloudProviderNodeInstances = cloudProviderNodeInstances
@@ -401,7 +464,7 @@ func (csr *ClusterStateRegistry) IsNodeGroupSafeToScaleUp(nodeGroup cloudprovide
 	if !csr.IsNodeGroupHealthy(nodeGroup.Id()) {
 		return false
 	}
-	return !csr.backoff.IsBackedOff(nodeGroup, csr.nodeInfosForGroups[nodeGroup.Id()], now)
+	return !csr.IsNodeGroupBackedOff(nodeGroup, now)
 }
 
 func (csr *ClusterStateRegistry) getProvisionedAndTargetSizesForNodeGroup(nodeGroupName string) (provisioned, target int, ok bool) {
@@ -559,7 +622,7 @@ func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
 			continue
 		}
 		perNgCopy := perNodeGroup[nodeGroup.Id()]
-		if unregistered.UnregisteredSince.Add(csr.config.MaxNodeProvisionTime).Before(currentTime) {
+		if unregistered.UnregisteredSince.Add(csr.NodeProvisionTimeout(nodeGroup)).Before(currentTime) {
 			perNgCopy.LongUnregistered++
 			total.LongUnregistered++
 		} else {
@@ -704,6 +767,36 @@ func (csr *ClusterStateRegistry) GetClusterReadiness() Readiness {
 	return csr.totalReadiness
 }
 
+// GetNodeGroupReadiness returns current readiness stats of the given node group.
+func (csr *ClusterStateRegistry) GetNodeGroupReadiness(nodeGroupName string) (Readiness, bool) {
+	readiness, found := csr.perNodeGroupReadiness[nodeGroupName]
+	return readiness, found
+}
+
+// GetAcceptableRange returns the acceptable size range of the given node group.
+func (csr *ClusterStateRegistry) GetAcceptableRange(nodeGroupName string) (AcceptableRange, bool) {
+	acceptable, found := csr.acceptableRanges[nodeGroupName]
+	return acceptable, found
+}
+
+// IsNodeGroupBackedOff returns true if scale-ups of the node group are backed off after a recent failure.
+func (csr *ClusterStateRegistry) IsNodeGroupBackedOff(nodeGroup cloudprovider.NodeGroup, now time.Time) bool {
+	return csr.backoff.IsBackedOff(nodeGroup, csr.nodeInfosForGroups[nodeGroup.Id()], now)
+}
+
+// GetScaleUpFailures returns the last scale-up failure of each node group that has not
+// completed a scale-up successfully since then.
+func (csr *ClusterStateRegistry) GetScaleUpFailures() map[string]ScaleUpFailure {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make(map[string]ScaleUpFailure, len(csr.scaleUpFailures))
+	for nodeGroupName, failure := range csr.scaleUpFailures {
+		result[nodeGroupName] = failure
+	}
+	return result
+}
+
 func buildHealthStatusNodeGroup(isReady bool, readiness Readiness, acceptable AcceptableRange, minSize, maxSize int) api.ClusterAutoscalerCondition {
 	condition := api.ClusterAutoscalerCondition{
 		Type: api.ClusterAutoscalerHealth,
@@ -931,6 +1024,23 @@ func getCloudProviderNodeInstances(cloudProvider cloudprovider.CloudProvider) (m
 	return allInstances, nil
 }
 
+// getNodeGroupIds maps names of registered nodes to ids of their node groups.
+func getNodeGroupIds(allNodes []*apiv1.Node, cloudProviderNodeInstances map[string][]cloudprovider.Instance) map[string]string {
+	nodeGroupByInstance := make(map[string]string)
+	for nodeGroupId, instances := range cloudProviderNodeInstances {
+		for _, instance := range instances {
+			nodeGroupByInstance[instance.Id] = nodeGroupId
+		}
+	}
+	result := make(map[string]string)
+	for _, node := range allNodes {
+		if nodeGroupId, found := nodeGroupByInstance[node.Spec.ProviderID]; found {
+			result[node.Name] = nodeGroupId
+		}
+	}
+	return result
+}
+
 // Calculates which of the existing cloud provider nodes are not registered in Kubernetes.
 func getNotRegisteredNodes(allNodes []*apiv1.Node, cloudProviderNodeInstances map[string][]cloudprovider.Instance, time time.Time) []UnregisteredNode {
 	registered := sets.NewString()
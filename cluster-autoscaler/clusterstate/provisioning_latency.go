@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstate
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+
+	"k8s.io/klog"
+)
+
+const (
+	// maxProvisioningSamples is the number of the most recent ready latencies kept per node group.
+	maxProvisioningSamples = 100
+	// minProvisioningSamples is the number of ready latencies needed to adapt the provision time of a node group.
+	minProvisioningSamples = 5
+	// provisioningLatencyPercentile is the percentile of ready latencies the adaptive provision time is based on.
+	provisioningLatencyPercentile = 0.99
+	// MinAdaptiveNodeProvisionTime is the lower bound of the adaptive provision time.
+	MinAdaptiveNodeProvisionTime = 3 * time.Minute
+)
+
+// pendingScaleUp is a scale-up of a node group whose nodes haven't registered yet.
+type pendingScaleUp struct {
+	time  time.Time
+	nodes int
+	// expires is the time until which nodes are still attributed to a timed out scale-up.
+	// Zero if the scale-up didn't time out.
+	expires time.Time
+}
+
+// provisioningTracker measures the time from a scale-up to the registration and readiness
+// of each node added by it. New nodes of a node group are attributed to its pending
+// scale-ups in the order the scale-ups were requested.
+type provisioningTracker struct {
+	initialized bool
+	pending     map[string][]*pendingScaleUp
+	seenNodes   map[string]bool
+	// nodeStarts is the start of the scale-up that added each registered node, until the node is ready.
+	nodeStarts map[string]time.Time
+	// samples are the most recent ready latencies of each node group.
+	samples map[string][]time.Duration
+}
+
+func newProvisioningTracker() *provisioningTracker {
+	return &provisioningTracker{
+		pending:    make(map[string][]*pendingScaleUp),
+		seenNodes:  make(map[string]bool),
+		nodeStarts: make(map[string]time.Time),
+		samples:    make(map[string][]time.Duration),
+	}
+}
+
+// registerScaleUp records a change of the number of nodes requested from the node group.
+// Decreases cancel the most recent scale-ups first.
+func (t *provisioningTracker) registerScaleUp(nodeGroupId string, delta int, currentTime time.Time) {
+	if delta > 0 {
+		t.pending[nodeGroupId] = append(t.pending[nodeGroupId], &pendingScaleUp{time: currentTime, nodes: delta})
+		return
+	}
+	pending := t.pending[nodeGroupId]
+	for delta < 0 && len(pending) > 0 {
+		last := pending[len(pending)-1]
+		if last.nodes > -delta {
+			last.nodes += delta
+			break
+		}
+		delta += last.nodes
+		pending = pending[:len(pending)-1]
+	}
+	t.pending[nodeGroupId] = pending
+}
+
+// forgetScaleUps drops the pending scale-ups of the node group, after they finished.
+func (t *provisioningTracker) forgetScaleUps(nodeGroupId string) {
+	delete(t.pending, nodeGroupId)
+}
+
+// expireScaleUps keeps the pending scale-ups of the node group, after they timed out, until
+// expireTime, so that nodes registering late are still measured. Otherwise only nodes faster
+// than the timeout would be sampled and an adaptive provision time could only shrink.
+func (t *provisioningTracker) expireScaleUps(nodeGroupId string, expireTime time.Time) {
+	for _, scaleUp := range t.pending[nodeGroupId] {
+		if scaleUp.expires.IsZero() {
+			scaleUp.expires = expireTime
+		}
+	}
+}
+
+// dropExpiredScaleUps drops timed out scale-ups whose grace period ended.
+func (t *provisioningTracker) dropExpiredScaleUps(currentTime time.Time) {
+	for nodeGroupId, pending := range t.pending {
+		kept := make([]*pendingScaleUp, 0, len(pending))
+		for _, scaleUp := range pending {
+			if scaleUp.expires.IsZero() || !scaleUp.expires.Before(currentTime) {
+				kept = append(kept, scaleUp)
+			}
+		}
+		if len(kept) == 0 {
+			delete(t.pending, nodeGroupId)
+		} else {
+			t.pending[nodeGroupId] = kept
+		}
+	}
+}
+
+// update matches newly registered nodes with pending scale-ups and records the latencies of
+// nodes that registered or became ready. nodeGroupIds maps node names to node group ids.
+func (t *provisioningTracker) update(nodes []*apiv1.Node, nodeGroupIds map[string]string, currentTime time.Time) {
+	t.dropExpiredScaleUps(currentTime)
+	seenNodes := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		seenNodes[node.Name] = true
+		nodeGroupId, found := nodeGroupIds[node.Name]
+		if !found {
+			continue
+		}
+		if t.initialized && !t.seenNodes[node.Name] {
+			if start, found := t.takeScaleUp(nodeGroupId, node.CreationTimestamp.Time); found {
+				metrics.RegisterNodeProvisioningDuration(nodeGroupId, metrics.NodeRegistered, node.CreationTimestamp.Time.Sub(start))
+				t.nodeStarts[node.Name] = start
+			}
+		}
+		start, found := t.nodeStarts[node.Name]
+		if !found {
+			continue
+		}
+		ready, readySince, err := kube_util.GetReadinessState(node)
+		if err != nil || !ready {
+			continue
+		}
+		latency := readySince.Sub(start)
+		klog.V(4).Infof("Node %s of node group %s became ready %v after scale-up", node.Name, nodeGroupId, latency)
+		metrics.RegisterNodeProvisioningDuration(nodeGroupId, metrics.NodeReady, latency)
+		t.addSample(nodeGroupId, latency)
+		delete(t.nodeStarts, node.Name)
+	}
+	for name := range t.nodeStarts {
+		if !seenNodes[name] {
+			delete(t.nodeStarts, name)
+		}
+	}
+	t.seenNodes = seenNodes
+	t.initialized = true
+}
+
+// takeScaleUp returns the start of the oldest pending scale-up of the node group requested
+// before the node was created, and counts the node as added by it.
+func (t *provisioningTracker) takeScaleUp(nodeGroupId string, created time.Time) (time.Time, bool) {
+	pending := t.pending[nodeGroupId]
+	if len(pending) == 0 || pending[0].time.After(created) {
+		return time.Time{}, false
+	}
+	start := pending[0].time
+	pending[0].nodes--
+	if pending[0].nodes <= 0 {
+		pending = pending[1:]
+	}
+	t.pending[nodeGroupId] = pending
+	return start, true
+}
+
+func (t *provisioningTracker) addSample(nodeGroupId string, latency time.Duration) {
+	samples := append(t.samples[nodeGroupId], latency)
+	if len(samples) > maxProvisioningSamples {
+		samples = samples[len(samples)-maxProvisioningSamples:]
+	}
+	t.samples[nodeGroupId] = samples
+}
+
+// adaptiveProvisionTime returns the given multiple of the p99 ready latency of the node group,
+// but not less than MinAdaptiveNodeProvisionTime. Returns false if there are not enough samples.
+func (t *provisioningTracker) adaptiveProvisionTime(nodeGroupId string, factor float64) (time.Duration, bool) {
+	samples := t.samples[nodeGroupId]
+	if len(samples) < minProvisioningSamples {
+		return 0, false
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(math.Ceil(provisioningLatencyPercentile*float64(len(sorted)))) - 1
+	result := time.Duration(float64(sorted[index]) * factor)
+	if result < MinAdaptiveNodeProvisionTime {
+		result = MinAdaptiveNodeProvisionTime
+	}
+	return result, true
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildProvisionedNode(name string, created time.Time, readySince *time.Time) *apiv1.Node {
+	node := BuildTestNode(name, 1000, 1000)
+	node.CreationTimestamp = metav1.NewTime(created)
+	if readySince != nil {
+		SetNodeReadyState(node, true, *readySince)
+	} else {
+		SetNodeReadyState(node, false, created)
+	}
+	return node
+}
+
+func TestProvisioningTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newProvisioningTracker()
+	existing := buildProvisionedNode("existing", now.Add(-time.Hour), &now)
+	tracker.update([]*apiv1.Node{existing}, map[string]string{"existing": "ng1"}, now)
+
+	tracker.registerScaleUp("ng1", 2, now)
+	tracker.registerScaleUp("ng1", 3, now.Add(time.Minute))
+	tracker.registerScaleUp("ng1", -2, now.Add(time.Minute))
+	assert.Equal(t, 2, len(tracker.pending["ng1"]))
+	assert.Equal(t, 1, tracker.pending["ng1"][1].nodes)
+
+	// The first node registers but is not ready yet, the second one is ready right away.
+	readyAt := now.Add(4 * time.Minute)
+	n1 := buildProvisionedNode("n1", now.Add(2*time.Minute), nil)
+	n2 := buildProvisionedNode("n2", now.Add(2*time.Minute), &readyAt)
+	nodeGroupIds := map[string]string{"existing": "ng1", "n1": "ng1", "n2": "ng1"}
+	tracker.update([]*apiv1.Node{existing, n1, n2}, nodeGroupIds, now.Add(4*time.Minute))
+	assert.Equal(t, []time.Duration{4 * time.Minute}, tracker.samples["ng1"])
+	assert.Equal(t, map[string]time.Time{"n1": now}, tracker.nodeStarts)
+	assert.Equal(t, 1, len(tracker.pending["ng1"]))
+
+	// The third node is attributed to the second scale-up.
+	readyAt = now.Add(7 * time.Minute)
+	n1 = buildProvisionedNode("n1", now.Add(2*time.Minute), &readyAt)
+	n3 := buildProvisionedNode("n3", now.Add(3*time.Minute), &readyAt)
+	nodeGroupIds["n3"] = "ng1"
+	tracker.update([]*apiv1.Node{existing, n1, n2, n3}, nodeGroupIds, now.Add(7*time.Minute))
+	assert.Equal(t, []time.Duration{4 * time.Minute, 7 * time.Minute, 6 * time.Minute}, tracker.samples["ng1"])
+	assert.Empty(t, tracker.nodeStarts)
+	assert.Empty(t, tracker.pending["ng1"])
+
+	_, found := tracker.adaptiveProvisionTime("ng1", 1.5)
+	assert.False(t, found)
+	tracker.addSample("ng1", 2*time.Minute)
+	tracker.addSample("ng1", 3*time.Minute)
+	adaptive, found := tracker.adaptiveProvisionTime("ng1", 1.5)
+	assert.True(t, found)
+	assert.Equal(t, 10*time.Minute+30*time.Second, adaptive)
+
+	for i := 0; i < maxProvisioningSamples; i++ {
+		tracker.addSample("ng2", time.Minute)
+	}
+	assert.Equal(t, maxProvisioningSamples, len(tracker.samples["ng2"]))
+	adaptive, found = tracker.adaptiveProvisionTime("ng2", 1.5)
+	assert.True(t, found)
+	assert.Equal(t, MinAdaptiveNodeProvisionTime, adaptive)
+}
+
+func TestProvisioningTrackerLateNodes(t *testing.T) {
+	now := time.Now()
+	tracker := newProvisioningTracker()
+	tracker.update([]*apiv1.Node{}, nil, now)
+	tracker.registerScaleUp("ng1", 2, now)
+	tracker.registerScaleUp("ng2", 1, now)
+
+	// Both scale-ups time out after 6 minutes, with a grace period of 15 minutes.
+	tracker.expireScaleUps("ng1", now.Add(21*time.Minute))
+	tracker.expireScaleUps("ng2", now.Add(21*time.Minute))
+	tracker.registerScaleUp("ng1", 1, now.Add(10*time.Minute))
+
+	// A node registering after the timeout is still measured.
+	readyAt := now.Add(12 * time.Minute)
+	n1 := buildProvisionedNode("n1", now.Add(10*time.Minute), &readyAt)
+	tracker.update([]*apiv1.Node{n1}, map[string]string{"n1": "ng1"}, readyAt)
+	assert.Equal(t, []time.Duration{12 * time.Minute}, tracker.samples["ng1"])
+	assert.Equal(t, 2, len(tracker.pending["ng1"]))
+
+	// Once the grace period ends only the scale-ups that didn't time out are kept.
+	tracker.update([]*apiv1.Node{n1}, map[string]string{"n1": "ng1"}, now.Add(22*time.Minute))
+	assert.Equal(t, 1, len(tracker.pending["ng1"]))
+	assert.Equal(t, now.Add(10*time.Minute), tracker.pending["ng1"][0].time)
+	assert.Empty(t, tracker.pending["ng2"])
+}
+
+func TestAdaptiveScaleUpTimeout(t *testing.T) {
+	now := time.Now()
+	provider := testprovider.NewTestCloudProvider(func(string, int) error {
+		return nil
+	}, nil)
+	provider.AddNodeGroup("ng1", 0, 100, 0)
+	provider.AddNodeGroup("ng2", 0, 100, 0)
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false)
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage:       10,
+		OkTotalUnreadyCount:             1,
+		MaxNodeProvisionTime:            15 * time.Minute,
+		AdaptiveNodeProvisionTimeFactor: 1.5,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, clusterstate.UpdateNodes([]*apiv1.Node{}, nil, now))
+
+	// Nodes of ng1 take 4 minutes to become ready.
+	nodes := make([]*apiv1.Node, 0)
+	for i := 0; i < minProvisioningSamples; i++ {
+		start := now.Add(time.Duration(i) * 10 * time.Minute)
+		provider.GetNodeGroup("ng1").IncreaseSize(1)
+		clusterstate.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng1"), 1, start)
+		readyAt := start.Add(4 * time.Minute)
+		node := buildProvisionedNode(fmt.Sprintf("ng1-%d", i), start.Add(time.Minute), &readyAt)
+		provider.AddNode("ng1", node)
+		nodes = append(nodes, node)
+		assert.NoError(t, clusterstate.UpdateNodes(nodes, nil, readyAt))
+	}
+	assert.Empty(t, clusterstate.scaleUpRequests)
+
+	later := now.Add(time.Hour)
+	clusterstate.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng1"), 1, later)
+	assert.Equal(t, later.Add(6*time.Minute), clusterstate.scaleUpRequests["ng1"].ExpectedAddTime)
+	// Node groups without history wait for MaxNodeProvisionTime.
+	clusterstate.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng2"), 1, later)
+	assert.Equal(t, later.Add(15*time.Minute), clusterstate.scaleUpRequests["ng2"].ExpectedAddTime)
+	assert.Equal(t, 6*time.Minute, clusterstate.NodeProvisionTimeout(provider.GetNodeGroup("ng1")))
+	assert.Equal(t, 15*time.Minute, clusterstate.NodeProvisionTimeout(provider.GetNodeGroup("ng2")))
+
+	// A MaxNodeProvisionTime set for the node group is kept even if it equals the global one.
+	clusterstate.nodeGroupConfigProcessor = &provisionTimeSetProcessor{nodeGroups: map[string]bool{"ng1": true}}
+	evenLater := later.Add(time.Minute)
+	clusterstate.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng1"), 1, evenLater)
+	assert.Equal(t, evenLater.Add(15*time.Minute), clusterstate.scaleUpRequests["ng1"].ExpectedAddTime)
+}
+
+type provisionTimeSetProcessor struct {
+	nodegroupconfig.NoOpNodeGroupConfigProcessor
+	nodeGroups map[string]bool
+}
+
+func (p *provisionTimeSetProcessor) HasMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) bool {
+	return p.nodeGroups[nodeGroup.Id()]
+}
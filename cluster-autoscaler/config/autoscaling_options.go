@@ -30,10 +30,50 @@ type GpuLimits struct {
 	Max int64
 }
 
+// NodeGroupAutoscalingOptions contain autoscaling options that can be overridden per node group.
+type NodeGroupAutoscalingOptions struct {
+	// ScaleDownEnabled is used to allow CA to scale down the node group.
+	ScaleDownEnabled bool
+	// ScaleDownUtilizationThreshold sets threshold for nodes to be considered for scale down.
+	ScaleDownUtilizationThreshold float64
+	// ScaleDownUnneededTime sets the duration CA expects a node to be unneeded/eligible for removal
+	// before scaling down the node.
+	ScaleDownUnneededTime time.Duration
+	// ScaleDownUnreadyTime represents how long an unready node should be unneeded before it is eligible for scale down
+	ScaleDownUnreadyTime time.Duration
+	// MaxNodeProvisionTime is the maximum time CA waits for node to be provisioned
+	MaxNodeProvisionTime time.Duration
+	// MaxDisruptedNodesPercentage is the maximum percentage of nodes of the node group removed
+	// at the same time. At least one node can always be removed. 0 means no limit.
+	MaxDisruptedNodesPercentage float64
+	// ExpanderPriority is the priority of the node group in the priority expander. Nil means
+	// the priority is taken from the priority expander configmap.
+	ExpanderPriority *int
+}
+
+// GRPCExpanderOptions configure the expander delegating the choice of node group to an external gRPC service.
+type GRPCExpanderOptions struct {
+	// URL is the address of the gRPC expander service.
+	URL string
+	// Cert is the path to the CA certificate used to verify the service. Empty for an insecure connection.
+	Cert string
+	// Timeout is how long CA waits for the service to choose a node group.
+	Timeout time.Duration
+	// Fallback is the list of expanders consulted in order when the service fails.
+	// Deprecated: list the expanders after grpc in ExpanderNames instead.
+	Fallback []string
+}
+
 // AutoscalingOptions contain various options to customize how autoscaling works
 type AutoscalingOptions struct {
 	// MaxEmptyBulkDelete is a number of empty nodes that can be removed at the same time.
 	MaxEmptyBulkDelete int
+	// MaxDrainParallelism is the maximum number of non empty nodes drained and removed at the same time.
+	MaxDrainParallelism int
+	// MaxDisruptedNodesPercentage is the maximum percentage of nodes of a node group removed at the same time. 0 means no limit.
+	MaxDisruptedNodesPercentage float64
+	// MaxDisruptedNodesPerZone is the maximum number of nodes of a zone removed at the same time. 0 means no limit.
+	MaxDisruptedNodesPerZone int
 	// ScaleDownUtilizationThreshold sets threshold for nodes to be considered for scale down.
 	// Well-utilized nodes are not touched.
 	ScaleDownUtilizationThreshold float64
@@ -58,8 +98,11 @@ type AutoscalingOptions struct {
 	NodeGroupAutoDiscovery []string
 	// EstimatorName is the estimator used to estimate the number of needed nodes in scale up.
 	EstimatorName string
-	// ExpanderName sets the type of node group expander to be used in scale up
-	ExpanderName string
+	// ExpanderNames is a comma separated list of node group expanders to be used in scale up.
+	// Each expander breaks the ties left by the previous one.
+	ExpanderNames string
+	// GRPCExpander configures the grpc expander.
+	GRPCExpander GRPCExpanderOptions
 	// IgnoreDaemonSetsUtilization is whether CA will ignore DaemonSet pods when calculating resource utilization for scaling down
 	IgnoreDaemonSetsUtilization bool
 	// IgnoreMirrorPodsUtilization is whether CA will ignore Mirror pods when calculating resource utilization for scaling down
@@ -69,12 +112,17 @@ type AutoscalingOptions struct {
 	MaxGracefulTerminationSec int
 	//  Maximum time CA waits for node to be provisioned
 	MaxNodeProvisionTime time.Duration
+	// AdaptiveNodeProvisionTimeFactor, if positive, makes CA wait for scale-ups of node groups with enough history
+	// for this multiple of the p99 time their new nodes took to become ready, instead of MaxNodeProvisionTime.
+	AdaptiveNodeProvisionTimeFactor float64
 	// MaxTotalUnreadyPercentage is the maximum percentage of unready nodes after which CA halts operations
 	MaxTotalUnreadyPercentage float64
 	// OkTotalUnreadyCount is the number of allowed unready nodes, irrespective of max-total-unready-percentage
 	OkTotalUnreadyCount int
 	// CloudConfig is the path to the cloud provider configuration file. Empty string for no configuration file.
 	CloudConfig string
+	// KubeConfigPath is the path to the kubeconfig file of the cluster. Empty string for in-cluster configuration.
+	KubeConfigPath string
 	// CloudProviderName sets the type of the cloud provider CA is about to run in. Allowed values: gce, aws
 	CloudProviderName string
 	// NodeGroups is the list of node groups a.k.a autoscaling targets
@@ -130,4 +178,29 @@ type AutoscalingOptions struct {
 	// Setting it to false employs a more lenient filtering approach that does not try to pack the pods on the nodes.
 	// Pods with nominatedNodeName set are always filtered out.
 	FilterOutSchedulablePodsUsesPacking bool
+	// DryRun makes CA only record the scaling actions it would take, without modifying nodes or node groups.
+	DryRun bool
+	// ConsolidationEnabled makes CA replace underutilized nodes of a node group with fewer nodes of another node group.
+	ConsolidationEnabled bool
+	// MaxConsolidationNodes is the maximum number of nodes replaced in a single consolidation.
+	MaxConsolidationNodes int
+	// SpotFallbackEnabled makes CA fall back to on-demand node groups when spot node groups fail to scale up,
+	// and replace the on-demand nodes with spot nodes once the fallback ends.
+	SpotFallbackEnabled bool
+	// AWSSpotPriceDiscount is the fraction of the on-demand price saved by AWS spot instances, used by the AWS pricing model.
+	AWSSpotPriceDiscount float64
+	// AWSAutoprovisioningConfig is the path to the configuration of ASGs created by node autoprovisioning on AWS.
+	AWSAutoprovisioningConfig string
+}
+
+// NodeGroupDefaults returns the options used for node groups without overrides.
+func (o AutoscalingOptions) NodeGroupDefaults() NodeGroupAutoscalingOptions {
+	return NodeGroupAutoscalingOptions{
+		ScaleDownEnabled:              o.ScaleDownEnabled,
+		ScaleDownUtilizationThreshold: o.ScaleDownUtilizationThreshold,
+		ScaleDownUnneededTime:         o.ScaleDownUnneededTime,
+		ScaleDownUnreadyTime:          o.ScaleDownUnreadyTime,
+		MaxNodeProvisionTime:          o.MaxNodeProvisionTime,
+		MaxDisruptedNodesPercentage:   o.MaxDisruptedNodesPercentage,
+	}
 }
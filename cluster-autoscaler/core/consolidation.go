@@ -0,0 +1,524 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// consolidationPlan replaces nodes of one node group with fewer nodes of another one.
+type consolidationPlan struct {
+	// nodes are the nodes to drain once the new nodes are ready.
+	nodes []*apiv1.Node
+	// target is the node group providing the new nodes.
+	target cloudprovider.NodeGroup
+	// newNodes is the number of nodes added to target.
+	newNodes int
+	// oldInstances are the ids of the instances of target before the scale-up.
+	oldInstances map[string]bool
+	// savings are the hourly savings in USD if pricing is available, the number of removed
+	// nodes otherwise.
+	savings float64
+	// rebalance is true if the plan moves nodes added during a spot fallback back to spot capacity.
+	rebalance bool
+	startTime time.Time
+}
+
+// Consolidation fixes fragmentation by replacing underutilized nodes, whose pods don't fit
+// on the existing capacity, with fewer nodes from a larger or cheaper node group. The target
+// node group is scaled up first and the old nodes are drained once the new nodes are ready.
+// Drains share the --max-drain-parallelism slots and the scale-down budgets with regular scale-down.
+// The same way, nodes added to on-demand node groups during a spot fallback are replaced with
+// nodes of spot node groups once the fallback ends.
+type Consolidation struct {
+	scaleDown *ScaleDown
+	// underutilizedSince tracks for how long the nodes have been underutilized.
+	underutilizedSince map[string]time.Time
+	plan               *consolidationPlan
+	// dryRunPlan describes the plan recorded in dry-run mode, so that it is not recorded again in every loop.
+	dryRunPlan string
+}
+
+// NewConsolidation builds new Consolidation object.
+func NewConsolidation(scaleDown *ScaleDown) *Consolidation {
+	return &Consolidation{
+		scaleDown:          scaleDown,
+		underutilizedSince: make(map[string]time.Time),
+	}
+}
+
+// TryToConsolidate drains the next node of the consolidation in progress if its new nodes
+// are ready, or looks for a new consolidation and starts its scale-up.
+func (c *Consolidation) TryToConsolidate(allNodes []*apiv1.Node, pods []*apiv1.Pod, pdbs []*policyv1.PodDisruptionBudget,
+	nodeInfos map[string]*schedulernodeinfo.NodeInfo, currentTime time.Time) errors.AutoscalerError {
+	nonExpendablePods := filterOutExpendablePods(pods, c.scaleDown.context.ExpendablePodsPriorityCutoff)
+	if c.plan != nil {
+		return c.continuePlan(allNodes, nonExpendablePods, pdbs, currentTime)
+	}
+
+	plan := c.findPlan(allNodes, nonExpendablePods, pdbs, nodeInfos, currentTime)
+	if plan == nil {
+		c.dryRunPlan = ""
+		return nil
+	}
+	return c.startPlan(plan, nodeInfos, currentTime)
+}
+
+// findPlan returns the consolidation saving the most, or nil if there is none. Nodes added to
+// on-demand node groups during a spot fallback that has ended are moved back to spot node
+// groups first, regardless of their utilization and the savings.
+func (c *Consolidation) findPlan(allNodes []*apiv1.Node, pods []*apiv1.Pod, pdbs []*policyv1.PodDisruptionBudget,
+	nodeInfos map[string]*schedulernodeinfo.NodeInfo, currentTime time.Time) *consolidationPlan {
+	sd := c.scaleDown
+	context := sd.context
+	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(pods, allNodes)
+
+	underutilizedSince := make(map[string]time.Time)
+	candidates := make(map[string][]*apiv1.Node)
+	rebalanceCandidates := make(map[string][]*apiv1.Node)
+	podsToMove := make(map[string][]*apiv1.Pod)
+	utilization := make(map[string]float64)
+	nodeGroups := make(map[string]cloudprovider.NodeGroup)
+	for _, node := range allNodes {
+		if _, found := sd.unneededNodes[node.Name]; found {
+			// Regular scale-down takes care of it.
+			continue
+		}
+		if isNodeBeingDeleted(node, currentTime) || hasNoScaleDownAnnotation(node) {
+			continue
+		}
+		nodeGroup := sd.nodeGroupForNode(node)
+		if nodeGroup == nil {
+			continue
+		}
+		nodeInfo, found := nodeNameToNodeInfo[node.Name]
+		if !found {
+			continue
+		}
+		options := sd.processors.NodeGroupConfigProcessor.GetOptions(nodeGroup, context.NodeGroupDefaults())
+		if !options.ScaleDownEnabled {
+			continue
+		}
+		utilInfo, err := simulator.CalculateUtilization(node, nodeInfo, context.IgnoreDaemonSetsUtilization, context.IgnoreMirrorPodsUtilization)
+		if err != nil {
+			continue
+		}
+		underutilized := false
+		if utilInfo.Utilization < options.ScaleDownUtilizationThreshold {
+			since, found := c.underutilizedSince[node.Name]
+			if !found {
+				since = currentTime
+			}
+			underutilizedSince[node.Name] = since
+			underutilized = !since.Add(options.ScaleDownUnneededTime).After(currentTime)
+		}
+		rebalance := sd.isRebalanceCandidate(node, currentTime)
+		if !rebalance && !(context.ConsolidationEnabled && underutilized) {
+			continue
+		}
+		nodePods, err := simulator.GetPodsToMove(nodeInfo, pdbs)
+		if err != nil {
+			klog.V(4).Infof("Node %s can't be consolidated: %v", node.Name, err)
+			continue
+		}
+		if rebalance {
+			rebalanceCandidates[nodeGroup.Id()] = append(rebalanceCandidates[nodeGroup.Id()], node)
+		} else {
+			candidates[nodeGroup.Id()] = append(candidates[nodeGroup.Id()], node)
+		}
+		podsToMove[node.Name] = nodePods
+		utilization[node.Name] = utilInfo.Utilization
+		nodeGroups[nodeGroup.Id()] = nodeGroup
+	}
+	c.underutilizedSince = underutilizedSince
+
+	pricingModel, err := context.CloudProvider.Pricing()
+	if err != nil {
+		pricingModel = nil
+	}
+
+	if best := c.bestPlan(rebalanceCandidates, nodeGroups, podsToMove, utilization, nodeInfos, len(allNodes), pricingModel, true, currentTime); best != nil {
+		return best
+	}
+	return c.bestPlan(candidates, nodeGroups, podsToMove, utilization, nodeInfos, len(allNodes), pricingModel, false, currentTime)
+}
+
+// bestPlan returns the plan saving the most among the plans replacing the candidate nodes of
+// each node group, or nil if there is none. Rebalancing plans replace the nodes with nodes of
+// spot node groups.
+func (c *Consolidation) bestPlan(candidates map[string][]*apiv1.Node, nodeGroups map[string]cloudprovider.NodeGroup,
+	podsToMove map[string][]*apiv1.Pod, utilization map[string]float64, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+	clusterSize int, pricingModel cloudprovider.PricingModel, rebalance bool, currentTime time.Time) *consolidationPlan {
+	sd := c.scaleDown
+	context := sd.context
+
+	var best *consolidationPlan
+	for id, nodes := range candidates {
+		source := nodeGroups[id]
+		size, err := source.TargetSize()
+		if err != nil {
+			klog.Errorf("Failed to get size of node group %s: %v", id, err)
+			continue
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return utilization[nodes[i].Name] < utilization[nodes[j].Name] })
+		if maxNodes := size - sd.processors.ScalingWindowProcessor.GetMinSize(source, currentTime); len(nodes) > maxNodes {
+			nodes = nodes[:maxNodes]
+		}
+		if context.MaxConsolidationNodes > 0 && len(nodes) > context.MaxConsolidationNodes {
+			nodes = nodes[:context.MaxConsolidationNodes]
+		}
+		// Replacing a single node with a node of another group only pays off when rebalancing.
+		if len(nodes) == 0 || (len(nodes) < 2 && !rebalance) || sd.processors.ScalingWindowProcessor.IsScaleDownBlocked(source, currentTime) {
+			continue
+		}
+		var sourcePods []*apiv1.Pod
+		for _, node := range nodes {
+			for _, pod := range podsToMove[node.Name] {
+				// Simulate the pods being rescheduled.
+				movedPod := *pod
+				movedPod.Spec.NodeName = ""
+				sourcePods = append(sourcePods, &movedPod)
+			}
+		}
+		for _, target := range context.CloudProvider.NodeGroups() {
+			if target.Id() == id {
+				continue
+			}
+			template := nodeInfos[target.Id()]
+			if rebalance && (template == nil || capacitytype.GetCapacityType(template.Node()) != capacitytype.Spot) {
+				continue
+			}
+			plan := c.planFor(nodes, sourcePods, target, template, clusterSize, pricingModel, rebalance, currentTime)
+			if plan != nil && (best == nil || plan.savings > best.savings) {
+				best = plan
+			}
+		}
+	}
+	return best
+}
+
+// planFor returns a plan replacing nodes with nodes of the target node group, or nil if it
+// doesn't save anything. Rebalancing plans are returned regardless of the savings.
+func (c *Consolidation) planFor(nodes []*apiv1.Node, pods []*apiv1.Pod, target cloudprovider.NodeGroup,
+	template *schedulernodeinfo.NodeInfo, clusterSize int, pricingModel cloudprovider.PricingModel, rebalance bool,
+	currentTime time.Time) *consolidationPlan {
+	context := c.scaleDown.context
+	if template == nil || !c.scaleDown.clusterStateRegistry.IsNodeGroupSafeToScaleUp(target, currentTime) {
+		return nil
+	}
+	for _, pod := range pods {
+		if err := context.PredicateChecker.CheckPredicates(pod, nil, template); err != nil {
+			return nil
+		}
+	}
+	newNodes := 0
+	if len(pods) > 0 {
+		newNodes, _ = context.EstimatorBuilder(context.PredicateChecker).Estimate(pods, template, nil, nil)
+	}
+	if newNodes >= len(nodes) && !rebalance {
+		return nil
+	}
+	size, err := target.TargetSize()
+	if err != nil || size+newNodes > target.MaxSize() {
+		return nil
+	}
+	if context.MaxNodesTotal > 0 && clusterSize+newNodes > context.MaxNodesTotal {
+		return nil
+	}
+
+	savings := float64(len(nodes) - newNodes)
+	if pricingModel != nil {
+		priced, err := priceSavings(pricingModel, nodes, template.Node(), newNodes, currentTime)
+		switch {
+		case err == cloudprovider.ErrNotImplemented:
+			// Nodes that can't be priced are compared by count, as without a pricing model.
+		case err != nil:
+			klog.Warningf("Failed to compare prices of consolidating into %s: %v", target.Id(), err)
+			return nil
+		case priced <= 0 && !rebalance:
+			return nil
+		default:
+			savings = priced
+		}
+	}
+	return &consolidationPlan{
+		nodes:     nodes,
+		target:    target,
+		newNodes:  newNodes,
+		savings:   savings,
+		rebalance: rebalance,
+	}
+}
+
+// priceSavings returns the hourly savings from replacing nodes with newNodes nodes built from template.
+func priceSavings(pricingModel cloudprovider.PricingModel, nodes []*apiv1.Node, template *apiv1.Node, newNodes int, currentTime time.Time) (float64, error) {
+	then := currentTime.Add(time.Hour)
+	savings := 0.0
+	for _, node := range nodes {
+		price, err := pricingModel.NodePrice(node, currentTime, then)
+		if err != nil {
+			return 0, err
+		}
+		savings += price
+	}
+	if newNodes > 0 {
+		price, err := pricingModel.NodePrice(template, currentTime, then)
+		if err != nil {
+			return 0, err
+		}
+		savings -= price * float64(newNodes)
+	}
+	return savings, nil
+}
+
+// startPlan scales up the target node group of the plan.
+func (c *Consolidation) startPlan(plan *consolidationPlan, nodeInfos map[string]*schedulernodeinfo.NodeInfo, currentTime time.Time) errors.AutoscalerError {
+	context := c.scaleDown.context
+	names := make([]string, 0, len(plan.nodes))
+	for _, node := range plan.nodes {
+		names = append(names, node.Name)
+	}
+	if context.DryRun {
+		c.recordDryRunPlan(plan, names)
+		return nil
+	}
+	if plan.rebalance {
+		klog.V(0).Infof("Consolidation: moving nodes %v back to spot capacity with %d nodes of group %s", names, plan.newNodes, plan.target.Id())
+		context.LogRecorder.Eventf(apiv1.EventTypeNormal, "Consolidation", "Consolidation: moving nodes %v back to spot capacity with %d nodes of group %s",
+			names, plan.newNodes, plan.target.Id())
+	} else {
+		klog.V(0).Infof("Consolidation: replacing nodes %v with %d nodes of group %s", names, plan.newNodes, plan.target.Id())
+		context.LogRecorder.Eventf(apiv1.EventTypeNormal, "Consolidation", "Consolidation: replacing nodes %v with %d nodes of group %s",
+			names, plan.newNodes, plan.target.Id())
+	}
+
+	instances, err := plan.target.Nodes()
+	if err != nil {
+		return errors.ToAutoscalerError(errors.CloudProviderError, err)
+	}
+	plan.oldInstances = make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		plan.oldInstances[instance.Id] = true
+	}
+	plan.startTime = currentTime
+	if plan.newNodes > 0 {
+		size, err := plan.target.TargetSize()
+		if err != nil {
+			return errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		info := nodegroupset.ScaleUpInfo{
+			Group:       plan.target,
+			CurrentSize: size,
+			NewSize:     size + plan.newNodes,
+			MaxSize:     plan.target.MaxSize(),
+		}
+		gpuType := gpu.GetGpuTypeForMetrics(context.CloudProvider.GPULabel(), context.CloudProvider.GetAvailableGPUTypes(),
+			nodeInfos[plan.target.Id()].Node(), plan.target)
+		if err := executeScaleUp(context, c.scaleDown.clusterStateRegistry, info, gpuType, currentTime); err != nil {
+			return err
+		}
+	}
+	c.plan = plan
+	return nil
+}
+
+// recordDryRunPlan records the plan that is not started because CA runs in dry-run mode. Nothing
+// changes in the cluster in dry-run mode, so the same plan is found in every loop; it is recorded
+// only if it differs from the plan recorded last.
+func (c *Consolidation) recordDryRunPlan(plan *consolidationPlan, names []string) {
+	key := fmt.Sprintf("%s:%d:%v", plan.target.Id(), plan.newNodes, names)
+	if key == c.dryRunPlan {
+		return
+	}
+	c.dryRunPlan = key
+	action, count := metrics.DryRunScaleUp, plan.newNodes
+	if plan.newNodes == 0 {
+		action, count = metrics.DryRunScaleDown, len(plan.nodes)
+	}
+	if plan.rebalance {
+		recordDryRunAction(c.scaleDown.context, action, count, "Consolidation: would move nodes %v back to spot capacity with %d nodes of group %s",
+			names, plan.newNodes, plan.target.Id())
+	} else {
+		recordDryRunAction(c.scaleDown.context, action, count, "Consolidation: would replace nodes %v with %d nodes of group %s",
+			names, plan.newNodes, plan.target.Id())
+	}
+}
+
+// continuePlan drains the nodes of the consolidation in progress once the new nodes are ready.
+// As many nodes are drained as there are free drain slots and the scale-down budgets allow.
+func (c *Consolidation) continuePlan(allNodes []*apiv1.Node, pods []*apiv1.Pod, pdbs []*policyv1.PodDisruptionBudget,
+	currentTime time.Time) errors.AutoscalerError {
+	sd := c.scaleDown
+	plan := c.plan
+
+	if ready := c.readyNewNodes(allNodes); ready < plan.newNodes {
+		provisionTime := sd.processors.NodeGroupConfigProcessor.GetOptions(plan.target, sd.context.NodeGroupDefaults()).MaxNodeProvisionTime
+		if plan.startTime.Add(provisionTime).Before(currentTime) {
+			c.abandonPlan(metrics.NewNodesNotReady, "%d of %d new nodes of group %s ready after %v", ready, plan.newNodes,
+				plan.target.Id(), provisionTime)
+		}
+		return nil
+	}
+
+	drainSlots := sd.drainSlotsLeft()
+	if drainSlots <= 0 {
+		return nil
+	}
+	existing := make(map[string]bool, len(allNodes))
+	for _, node := range allNodes {
+		existing[node.Name] = true
+	}
+	destinationNodes, beingDeleted := sd.splitNodesBeingDeleted(allNodes)
+	// Pods not evicted yet from the nodes being drained are going to take capacity of the other nodes.
+	pods = simulator.ReschedulePodsOfRemovedNodes(beingDeleted, destinationNodes, pods, sd.context.PredicateChecker, currentTime)
+	candidates := make([]*apiv1.Node, 0, len(plan.nodes))
+	for _, node := range plan.nodes {
+		if existing[node.Name] && !sd.nodeDeleteStatus.IsNodeBeingDeleted(node.Name) {
+			candidates = append(candidates, node)
+		}
+	}
+
+	// Nodes drained together have to fit their pods on the remaining nodes together. Pods are never
+	// moved to other nodes of the plan, they would be evicted twice.
+	nodesToRemove, unremovable, _, err := simulator.FindNodesToReplace(candidates, destinationNodes, pods, sd.context.ListerRegistry,
+		sd.context.PredicateChecker, drainSlots, sd.podLocationHints, sd.usageTracker, currentTime, pdbs)
+	if err != nil {
+		return err.AddPrefix("Find node to consolidate failed: ")
+	}
+	if len(unremovable) > 0 {
+		names := make([]string, 0, len(unremovable))
+		for _, node := range unremovable {
+			names = append(names, node.Name)
+		}
+		// Draining the other nodes would still leave the cluster with both the old and the new
+		// nodes, so the new nodes are left to regular scale-down instead.
+		c.abandonPlan(metrics.NodesUnremovable, "nodes %v can't be removed after scaling up group %s", names, plan.target.Id())
+		return nil
+	}
+	removed := make(map[string]bool, len(nodesToRemove))
+	nodeGroupSize := getNodeGroupSizeMap(sd.context.CloudProvider)
+	budget := sd.newScaleDownBudget(nodeGroupSize)
+	for _, toRemove := range nodesToRemove {
+		nodeGroup := sd.nodeGroupForNode(toRemove.Node)
+		if nodeGroup == nil {
+			removed[toRemove.Node.Name] = true
+			continue
+		}
+		if !budget.tryTake(toRemove.Node, nodeGroup, nodeGroupSize[nodeGroup.Id()]) {
+			klog.V(4).Infof("Consolidation: scale-down budget of node %s exhausted, waiting", toRemove.Node.Name)
+			continue
+		}
+		c.drainNode(toRemove, nodeGroup)
+		removed[toRemove.Node.Name] = true
+	}
+
+	remaining := make([]*apiv1.Node, 0, len(candidates))
+	for _, node := range candidates {
+		if !removed[node.Name] {
+			remaining = append(remaining, node)
+		}
+	}
+	plan.nodes = remaining
+	if len(plan.nodes) == 0 {
+		klog.V(1).Infof("Consolidation into group %s finished", plan.target.Id())
+		c.plan = nil
+	}
+	return nil
+}
+
+// abandonPlan gives up the consolidation in progress. Its remaining nodes and the nodes added
+// by its scale-up are left to regular scale-down.
+func (c *Consolidation) abandonPlan(reason metrics.FailedConsolidationReason, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	klog.Warningf("Consolidation failed: %s, giving up", message)
+	c.scaleDown.context.LogRecorder.Eventf(apiv1.EventTypeWarning, "ConsolidationFailed", "Consolidation failed: %s", message)
+	metrics.RegisterFailedConsolidation(reason)
+	c.plan = nil
+}
+
+// readyNewNodes returns the number of ready nodes added to the target node group of the plan.
+func (c *Consolidation) readyNewNodes(allNodes []*apiv1.Node) int {
+	if c.plan.newNodes == 0 {
+		return 0
+	}
+	instances, err := c.plan.target.Nodes()
+	if err != nil {
+		klog.Errorf("Failed to get nodes of group %s: %v", c.plan.target.Id(), err)
+		return 0
+	}
+	newInstances := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if !c.plan.oldInstances[instance.Id] {
+			newInstances[instance.Id] = true
+		}
+	}
+	ready := 0
+	for _, node := range allNodes {
+		if !newInstances[node.Spec.ProviderID] {
+			continue
+		}
+		if isReady, _, err := kube_util.GetReadinessState(node); err == nil && isReady {
+			ready++
+		}
+	}
+	return ready
+}
+
+// drainNode removes the node in the background, like ScaleDown.TryToScaleDown does.
+func (c *Consolidation) drainNode(toRemove simulator.NodeToBeRemoved, nodeGroup cloudprovider.NodeGroup) {
+	sd := c.scaleDown
+	node := toRemove.Node
+	// Headroom pods are virtual, they only reserve capacity and are never evicted.
+	toRemove.PodsToReschedule = headroom.FilterOutHeadroomPods(toRemove.PodsToReschedule)
+	klog.V(0).Infof("Consolidation: removing node %s, pods to reschedule: %d", node.Name, len(toRemove.PodsToReschedule))
+	simulator.RemoveNodeFromTracker(sd.usageTracker, node.Name, sd.unneededNodes)
+	sd.nodeDeleteStatus.StartDeletion(node, nodeGroup)
+
+	go func() {
+		var err error
+		defer func() { sd.nodeDeleteStatus.AddNodeDeleteResult(node.Name, err) }()
+		defer sd.nodeDeleteStatus.FinishDeletion(node.Name)
+		err = sd.deleteNode(node, toRemove.PodsToReschedule)
+		if err != nil {
+			klog.Errorf("Failed to delete %s: %v", node.Name, err)
+			return
+		}
+		if sd.context.DryRun {
+			return
+		}
+		gpuType := gpu.GetGpuTypeForMetrics(sd.context.CloudProvider.GPULabel(), sd.context.CloudProvider.GetAvailableGPUTypes(), node, nodeGroup)
+		metrics.RegisterScaleDown(1, gpuType, metrics.Consolidated)
+	}()
+}
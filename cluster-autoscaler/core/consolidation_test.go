@@ -0,0 +1,470 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type consolidationTest struct {
+	provider      *testprovider.TestCloudProvider
+	csr           *clusterstate.ClusterStateRegistry
+	consolidation *Consolidation
+	nodes         []*apiv1.Node
+	pods          []*apiv1.Pod
+	nodeInfos     map[string]*schedulernodeinfo.NodeInfo
+	scaledUp      chan string
+	deletedNodes  chan string
+}
+
+// newConsolidationTest builds a cluster with three nodes of ng1 that can't be removed, as
+// their pods don't fit on other nodes, but could be replaced with one node of ng2.
+func newConsolidationTest(t *testing.T) *consolidationTest {
+	test := &consolidationTest{
+		scaledUp:     make(chan string, 10),
+		deletedNodes: make(chan string, 10),
+	}
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job",
+			Namespace: "default",
+			SelfLink:  "/apivs/batch/v1/namespaces/default/jobs/job",
+		},
+	}
+
+	test.provider = testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+		test.scaledUp <- fmt.Sprintf("%s-%d", nodeGroup, increase)
+		return nil
+	}, func(nodeGroup string, node string) error {
+		test.deletedNodes <- node
+		return nil
+	})
+	test.provider.AddNodeGroup("ng1", 0, 10, 3)
+	test.provider.AddNodeGroup("ng2", 0, 10, 0)
+	for i := 1; i <= 3; i++ {
+		node := BuildTestNode(fmt.Sprintf("n%d", i), 1000, 1000)
+		SetNodeReadyState(node, true, time.Time{})
+		test.provider.AddNode("ng1", node)
+		test.nodes = append(test.nodes, node)
+
+		pod := BuildTestPod(fmt.Sprintf("p%d", i), 600, 0)
+		pod.OwnerReferences = GenerateOwnerReferences(job.Name, "Job", "batch/v1", "")
+		pod.Spec.NodeName = node.Name
+		test.pods = append(test.pods, pod)
+	}
+	template := schedulernodeinfo.NewNodeInfo()
+	templateNode := BuildTestNode("ng2-template", 2000, 1000)
+	SetNodeReadyState(templateNode, true, time.Time{})
+	template.SetNode(templateNode)
+	test.nodeInfos = map[string]*schedulernodeinfo.NodeInfo{"ng2": template}
+
+	fakeClient := &fake.Clientset{}
+	fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewNotFound(apiv1.Resource("pod"), "whatever")
+	})
+	fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		getAction := action.(core.GetAction)
+		for _, node := range test.nodes {
+			if node.Name == getAction.GetName() {
+				return true, node, nil
+			}
+		}
+		return true, nil, fmt.Errorf("wrong node: %v", getAction.GetName())
+	})
+	fakeClient.Fake.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		return true, action.(core.UpdateAction).GetObject(), nil
+	})
+	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
+
+	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
+		ScaleDownUtilizationThreshold: 0.7,
+		ScaleDownUnneededTime:         10 * time.Minute,
+		MaxGracefulTerminationSec:     60,
+		MaxNodeProvisionTime:          15 * time.Minute,
+		EstimatorName:                 estimator.BinpackingEstimatorName,
+		ConsolidationEnabled:          true,
+	}
+	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, test.provider)
+	// Draining several nodes records more events than the default fake recorder holds.
+	recorder := kube_record.NewFakeRecorder(100)
+	context.Recorder = recorder
+	context.LogRecorder, _ = utils.NewStatusMapRecorder(fakeClient, "kube-system", recorder, false)
+	test.csr = clusterstate.NewClusterStateRegistry(test.provider, clusterstate.ClusterStateRegistryConfig{OkTotalUnreadyCount: 1},
+		context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	test.consolidation = NewConsolidation(NewScaleDown(&context, ca_processors.TestProcessors(), test.csr))
+	return test
+}
+
+func (test *consolidationTest) run(t *testing.T, now time.Time) {
+	assert.NoError(t, test.csr.UpdateNodes(test.nodes, test.nodeInfos, now))
+	assert.Nil(t, test.consolidation.TryToConsolidate(test.nodes, test.pods, nil, test.nodeInfos, now))
+	waitForDeleteToFinish(t, test.consolidation.scaleDown)
+}
+
+// removeNode simulates the removal of the node and rescheduling of its pods to the node.
+func (test *consolidationTest) removeNode(name string, to *apiv1.Node) {
+	for i, node := range test.nodes {
+		if node.Name == name {
+			test.nodes = append(test.nodes[:i], test.nodes[i+1:]...)
+			break
+		}
+	}
+	for _, pod := range test.pods {
+		if pod.Spec.NodeName == name {
+			pod.Spec.NodeName = to.Name
+		}
+	}
+}
+
+func TestConsolidation(t *testing.T) {
+	test := newConsolidationTest(t)
+	now := time.Now()
+
+	// Nodes have to be underutilized long enough.
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.scaledUp))
+
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+	assert.NotNil(t, test.consolidation.plan)
+
+	// Old nodes are drained only after the new node is ready.
+	now = now.Add(time.Minute)
+	test.run(t, now)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.deletedNodes))
+
+	newNode := BuildTestNode("m1", 2000, 1000)
+	SetNodeReadyState(newNode, true, now)
+	test.provider.AddNode("ng2", newNode)
+	test.nodes = append(test.nodes, newNode)
+	for _, name := range []string{"n1", "n2", "n3"} {
+		now = now.Add(time.Minute)
+		test.run(t, now)
+		assert.Equal(t, name, getStringFromChanImmediately(test.deletedNodes))
+		test.removeNode(name, newNode)
+	}
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.scaledUp))
+}
+
+func TestConsolidationDryRun(t *testing.T) {
+	test := newConsolidationTest(t)
+	context := test.consolidation.scaleDown.context
+	context.DryRun = true
+	recorder := kube_record.NewFakeRecorder(10)
+	logRecorder, err := utils.NewStatusMapRecorder(fake.NewSimpleClientset(), "kube-system", recorder, true)
+	assert.NoError(t, err)
+	context.LogRecorder = logRecorder
+	events := func() []string {
+		var result []string
+		for {
+			select {
+			case event := <-recorder.Events:
+				result = append(result, event)
+			default:
+				return result
+			}
+		}
+	}
+	now := time.Now()
+
+	test.run(t, now)
+	assert.Empty(t, events())
+
+	// The plan is recorded once instead of being started.
+	for i := 0; i < 3; i++ {
+		now = now.Add(11 * time.Minute)
+		test.run(t, now)
+		assert.Nil(t, test.consolidation.plan)
+		assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.scaledUp))
+		recorded := events()
+		if i == 0 {
+			assert.Len(t, recorded, 1)
+			assert.Contains(t, recorded[0], "DryRun Dry-run: Consolidation: would replace nodes [n1 n2 n3] with 1 nodes of group ng2")
+		} else {
+			assert.Empty(t, recorded)
+		}
+	}
+}
+
+func TestConsolidationWaitsForRequestedNodes(t *testing.T) {
+	test := newConsolidationTest(t)
+	now := time.Now()
+
+	// An unready node of ng2 existing before the consolidation doesn't count as a new node.
+	oldNode := BuildTestNode("m0", 2000, 1000)
+	SetNodeReadyState(oldNode, false, now)
+	test.provider.AddNode("ng2", oldNode)
+	test.nodes = append(test.nodes, oldNode)
+
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+	assert.NotNil(t, test.consolidation.plan)
+
+	SetNodeReadyState(oldNode, true, now)
+	now = now.Add(time.Minute)
+	test.run(t, now)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.deletedNodes))
+
+	newNode := BuildTestNode("m1", 2000, 1000)
+	SetNodeReadyState(newNode, true, now)
+	test.provider.AddNode("ng2", newNode)
+	test.nodes = append(test.nodes, newNode)
+	now = now.Add(time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "n1", getStringFromChanImmediately(test.deletedNodes))
+}
+
+func TestConsolidationParallelDrains(t *testing.T) {
+	for _, tc := range []struct {
+		description     string
+		percentage      float64
+		expectedDeleted []string
+	}{
+		{
+			description:     "all nodes drained together",
+			expectedDeleted: []string{"n1", "n2", "n3"},
+		},
+		{
+			description:     "drains limited by the node group budget",
+			percentage:      50,
+			expectedDeleted: []string{"n1"},
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			test := newConsolidationTest(t)
+			test.consolidation.scaleDown.context.MaxDrainParallelism = 3
+			test.consolidation.scaleDown.context.MaxDisruptedNodesPercentage = tc.percentage
+			now := time.Now()
+
+			test.run(t, now)
+			now = now.Add(11 * time.Minute)
+			test.run(t, now)
+			assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+
+			newNode := BuildTestNode("m1", 2000, 1000)
+			SetNodeReadyState(newNode, true, now)
+			test.provider.AddNode("ng2", newNode)
+			test.nodes = append(test.nodes, newNode)
+			now = now.Add(time.Minute)
+			test.run(t, now)
+			deleted := make([]string, 0)
+			for range tc.expectedDeleted {
+				deleted = append(deleted, getStringFromChan(test.deletedNodes))
+			}
+			assertEqualSet(t, tc.expectedDeleted, deleted)
+			assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.deletedNodes))
+		})
+	}
+}
+
+type provisionTimeNodeGroupConfigProcessor struct {
+	nodegroupconfig.NoOpNodeGroupConfigProcessor
+	provisionTimes map[string]time.Duration
+}
+
+func (p *provisionTimeNodeGroupConfigProcessor) GetOptions(nodeGroup cloudprovider.NodeGroup, defaults config.NodeGroupAutoscalingOptions) config.NodeGroupAutoscalingOptions {
+	if provisionTime, found := p.provisionTimes[nodeGroup.Id()]; found {
+		defaults.MaxNodeProvisionTime = provisionTime
+	}
+	return defaults
+}
+
+func (p *provisionTimeNodeGroupConfigProcessor) HasMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) bool {
+	_, found := p.provisionTimes[nodeGroup.Id()]
+	return found
+}
+
+func TestConsolidationUsesNodeGroupProvisionTime(t *testing.T) {
+	test := newConsolidationTest(t)
+	test.consolidation.scaleDown.processors.NodeGroupConfigProcessor = &provisionTimeNodeGroupConfigProcessor{
+		provisionTimes: map[string]time.Duration{"ng2": 30 * time.Minute},
+	}
+	now := time.Now()
+
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+
+	now = now.Add(16 * time.Minute)
+	test.run(t, now)
+	assert.NotNil(t, test.consolidation.plan)
+
+	now = now.Add(15 * time.Minute)
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+}
+
+func TestConsolidationGivesUpWithoutNewNodes(t *testing.T) {
+	test := newConsolidationTest(t)
+	now := time.Now()
+
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+
+	now = now.Add(16 * time.Minute)
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.deletedNodes))
+}
+
+func TestConsolidationGivesUpWithUnremovableNodes(t *testing.T) {
+	test := newConsolidationTest(t)
+	// The pod of n1 fits on n2, but n2 is replaced as well.
+	test.pods[0].Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU] = *resource.NewMilliQuantity(300, resource.DecimalSI)
+	now := time.Now()
+
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+
+	// The new node turns out too small for the pods of n1.
+	newNode := BuildTestNode("m1", 200, 1000)
+	SetNodeReadyState(newNode, true, now)
+	test.provider.AddNode("ng2", newNode)
+	test.nodes = append(test.nodes, newNode)
+	now = now.Add(time.Minute)
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.deletedNodes))
+}
+
+func TestConsolidationComparesPrices(t *testing.T) {
+	test := newConsolidationTest(t)
+	prices := &testNodePriceModel{prices: map[string]float64{"n1": 1, "n2": 1, "n3": 1, "ng2-template": 4}}
+	test.provider.SetPricingModel(prices)
+	now := time.Now()
+
+	// One node of ng2 costs more than three nodes of ng1.
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.scaledUp))
+
+	prices.prices["ng2-template"] = 2
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+	assert.InDelta(t, 1.0, test.consolidation.plan.savings, 1e-9)
+}
+
+// notImplementedNodePriceModel prices nothing, like providers without a price list.
+type notImplementedNodePriceModel struct{}
+
+func (m *notImplementedNodePriceModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
+func (m *notImplementedNodePriceModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0, cloudprovider.ErrNotImplemented
+}
+
+func TestConsolidationWithoutNodePrices(t *testing.T) {
+	test := newConsolidationTest(t)
+	test.provider.SetPricingModel(&notImplementedNodePriceModel{})
+	now := time.Now()
+
+	// Nodes are compared by count, as if the provider had no pricing model.
+	test.run(t, now)
+	now = now.Add(11 * time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+	assert.Equal(t, 2.0, test.consolidation.plan.savings)
+}
+
+// rebalancingCapacityTypeProcessor marks the nodes of the given node group as rebalance candidates.
+type rebalancingCapacityTypeProcessor struct {
+	capacitytype.NoOpCapacityTypeProcessor
+	nodeGroup string
+}
+
+func (p *rebalancingCapacityTypeProcessor) IsRebalanceCandidate(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) bool {
+	return nodeGroup.Id() == p.nodeGroup
+}
+
+func TestConsolidationRebalancesToSpot(t *testing.T) {
+	test := newConsolidationTest(t)
+	sd := test.consolidation.scaleDown
+	sd.context.ConsolidationEnabled = false
+	sd.processors.CapacityTypeProcessor = &rebalancingCapacityTypeProcessor{nodeGroup: "ng1"}
+	// A single node is moved back to spot capacity, even if it isn't underutilized.
+	test.pods[0].Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU] = *resource.NewMilliQuantity(900, resource.DecimalSI)
+	test.provider.GetNodeGroup("ng1").(*testprovider.TestNodeGroup).SetTargetSize(1)
+	test.nodes = test.nodes[:1]
+	test.pods = test.pods[:1]
+	now := time.Now()
+
+	// Only spot node groups replace the nodes.
+	test.run(t, now)
+	assert.Nil(t, test.consolidation.plan)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(test.scaledUp))
+
+	test.nodeInfos["ng2"].Node().Labels[capacitytype.CapacityTypeLabel] = "spot"
+	test.run(t, now)
+	assert.Equal(t, "ng2-1", getStringFromChanImmediately(test.scaledUp))
+	assert.True(t, test.consolidation.plan.rebalance)
+
+	newNode := BuildTestNode("m1", 2000, 1000)
+	SetNodeReadyState(newNode, true, now)
+	test.provider.AddNode("ng2", newNode)
+	test.nodes = append(test.nodes, newNode)
+	now = now.Add(time.Minute)
+	test.run(t, now)
+	assert.Equal(t, "n1", getStringFromChanImmediately(test.deletedNodes))
+	assert.Nil(t, test.consolidation.plan)
+}
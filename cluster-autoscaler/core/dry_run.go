@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// recordDryRunAction logs an action that was not executed because CA runs in dry-run mode,
+// emits an event on the status config map and counts the action in metrics.
+func recordDryRunAction(context *context.AutoscalingContext, action metrics.DryRunAction, count int, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	klog.V(0).Infof("Dry-run: %s", message)
+	context.LogRecorder.Eventf(apiv1.EventTypeNormal, "DryRun", "Dry-run: %s", message)
+	metrics.RegisterDryRunAction(action, count)
+}
+
+// recordDryRunScaleUps records the scale-ups of a successful scale-up status that were not
+// executed because CA runs in dry-run mode. Nothing changes in the cluster in dry-run mode, so
+// the same scale-up is computed in every loop for the pods waiting for it; it is recorded only
+// if the node group wasn't already scaled up to the same size for the same pods in the previous
+// call. Scale-ups that aren't triggered by pods, like the ones of scaling windows, are recorded
+// once per size. Returns the scale-ups to pass to the next call.
+func recordDryRunScaleUps(context *context.AutoscalingContext, scaleUpStatus *status.ScaleUpStatus, recorded map[string]string) map[string]string {
+	result := make(map[string]string)
+	if scaleUpStatus == nil || scaleUpStatus.Result != status.ScaleUpSuccessful {
+		return result
+	}
+	podNames := make([]string, 0, len(scaleUpStatus.PodsTriggeredScaleUp))
+	for _, pod := range scaleUpStatus.PodsTriggeredScaleUp {
+		podNames = append(podNames, pod.Namespace+"/"+pod.Name)
+	}
+	sort.Strings(podNames)
+	pods := strings.Join(podNames, ",")
+
+	for _, info := range scaleUpStatus.ScaleUpInfos {
+		id := info.Group.Id()
+		result[id] = fmt.Sprintf("%d:%s", info.NewSize, pods)
+		if recorded[id] == result[id] {
+			continue
+		}
+		if !info.Group.Exist() {
+			recordDryRunAction(context, metrics.DryRunNodeGroupCreation, 1,
+				"Scale-up: would create node group %s", id)
+		}
+		recordDryRunAction(context, metrics.DryRunScaleUp, info.NewSize-info.CurrentSize,
+			"Scale-up: would set group %s size to %d", id, info.NewSize)
+	}
+	return result
+}
+
+// dryRunStatusString describes scaling decisions of the current iteration that were
+// not executed because of dry-run mode. It is appended to the status config map.
+func dryRunStatusString(scaleUpStatus *status.ScaleUpStatus, scaleDownStatus *status.ScaleDownStatus) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("\nDry-run mode, the following actions were not executed:\n")
+	actions := 0
+	if scaleUpStatus != nil && scaleUpStatus.Result == status.ScaleUpSuccessful {
+		for _, info := range scaleUpStatus.ScaleUpInfos {
+			buffer.WriteString(fmt.Sprintf("  ScaleUp: group=%s size=%d->%d\n", info.Group.Id(), info.CurrentSize, info.NewSize))
+			actions++
+		}
+	}
+	if scaleDownStatus != nil && (scaleDownStatus.Result == status.ScaleDownNodeDeleted ||
+		scaleDownStatus.Result == status.ScaleDownNodeDeleteStarted) {
+		for _, node := range scaleDownStatus.ScaledDownNodes {
+			buffer.WriteString(fmt.Sprintf("  ScaleDown: node=%s group=%s evictedPods=%d\n", node.Node.Name, node.NodeGroup.Id(), len(node.EvictedPods)))
+			actions++
+		}
+	}
+	if actions == 0 {
+		buffer.WriteString("  none\n")
+	}
+	return buffer.String()
+}
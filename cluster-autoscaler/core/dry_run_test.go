@@ -0,0 +1,318 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resultScaleDownStatusProcessor remembers the result of the last scale-down.
+type resultScaleDownStatusProcessor struct {
+	result status.ScaleDownResult
+}
+
+func (p *resultScaleDownStatusProcessor) Process(context *context.AutoscalingContext, status *status.ScaleDownStatus) {
+	p.result = status.Result
+}
+
+func (p *resultScaleDownStatusProcessor) CleanUp() {}
+
+func TestStaticAutoscalerRunOnceDryRun(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	p1 := BuildTestPod("p1", 600, 100)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 600, 100)
+
+	tn := BuildTestNode("tn", 1000, 1000)
+	tni := schedulernodeinfo.NewNodeInfo()
+	tni.SetNode(tn)
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(
+		func(id string, delta int) error {
+			return fmt.Errorf("unexpected scale-up of %s by %d", id, delta)
+		}, func(id string, name string) error {
+			return fmt.Errorf("unexpected scale-down of %s in %s", name, id)
+		},
+		nil, nil,
+		nil, map[string]*schedulernodeinfo.NodeInfo{"ng1": tni})
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:                       estimator.BinpackingEstimatorName,
+		ScaleDownEnabled:                    true,
+		ScaleDownUtilizationThreshold:       0.5,
+		MaxNodesTotal:                       10,
+		MaxCoresTotal:                       10,
+		MaxMemoryTotal:                      100000,
+		ScaleDownUnreadyTime:                time.Minute,
+		ScaleDownUnneededTime:               time.Minute,
+		MaxEmptyBulkDelete:                  10,
+		FilterOutSchedulablePodsUsesPacking: true,
+		DryRun:                              true,
+	}
+	fakeClient := &fake.Clientset{}
+	context := NewScaleTestAutoscalingContext(options, fakeClient, nil, provider)
+
+	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
+		OkTotalUnreadyCount:  1,
+		MaxNodeProvisionTime: 10 * time.Second,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterStateConfig, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	processors := ca_processors.TestProcessors()
+	scaleDownStatusProcessor := &resultScaleDownStatusProcessor{}
+	processors.ScaleDownStatusProcessor = scaleDownStatusProcessor
+	autoscaler := &StaticAutoscaler{
+		AutoscalingContext:    &context,
+		clusterStateRegistry:  clusterState,
+		lastScaleUpTime:       now,
+		lastScaleDownFailTime: now,
+		scaleDown:             NewScaleDown(&context, processors, clusterState),
+		processors:            processors,
+		initialized:           true,
+		nodeInfoCache:         make(map[string]*schedulernodeinfo.NodeInfo),
+	}
+	setListers := func(nodes []*apiv1.Node, scheduled, unschedulable []*apiv1.Pod) {
+		daemonSetLister, err := kube_util.NewTestDaemonSetLister(nil)
+		assert.NoError(t, err)
+		nodeLister := kube_util.NewTestNodeLister(nodes)
+		context.ListerRegistry = kube_util.NewListerRegistry(nodeLister, nodeLister,
+			kube_util.NewTestPodLister(scheduled), kube_util.NewTestPodLister(unschedulable),
+			kube_util.NewTestPodDisruptionBudgetLister(nil), daemonSetLister,
+			nil, nil, nil, nil, nil, nil)
+	}
+
+	// Scale-up is only recorded.
+	setListers([]*apiv1.Node{n1}, []*apiv1.Pod{p1}, []*apiv1.Pod{p2})
+	err := autoscaler.RunOnce(now.Add(time.Hour))
+	assert.NoError(t, err)
+	targetSize, _ := provider.GetNodeGroup("ng1").TargetSize()
+	assert.Equal(t, 1, targetSize)
+	assert.Empty(t, clusterState.GetUpcomingNodes())
+	// Scale-down is simulated as if there was no scale-up.
+	assert.Equal(t, now, autoscaler.lastScaleUpTime)
+	assert.Equal(t, status.ScaleDownNoUnneeded, scaleDownStatusProcessor.result)
+
+	// Empty node is only reported as removed.
+	provider.AddNode("ng1", n2)
+	provider.GetNodeGroup("ng1").(*testprovider.TestNodeGroup).SetTargetSize(2)
+	setListers([]*apiv1.Node{n1, n2}, []*apiv1.Pod{p1}, nil)
+	err = autoscaler.RunOnce(now.Add(2 * time.Hour))
+	assert.NoError(t, err)
+	err = autoscaler.RunOnce(now.Add(3 * time.Hour))
+	assert.NoError(t, err)
+	nodes, nodesErr := provider.GetNodeGroup("ng1").Nodes()
+	assert.NoError(t, nodesErr)
+	assert.Len(t, nodes, 2)
+	assert.Equal(t, now.Add(3*time.Hour), autoscaler.lastScaleDownDeleteTime)
+	assert.Empty(t, fakeClient.Actions())
+}
+
+func TestStaticAutoscalerRunOnceDryRunScalingWindows(t *testing.T) {
+	windows, err := scalingwindows.ParseConfig([]byte(testScalingWindows))
+	assert.NoError(t, err)
+	processors := ca_processors.TestProcessors()
+	processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+	scaleDownStatusProcessor := &resultScaleDownStatusProcessor{}
+	processors.ScaleDownStatusProcessor = scaleDownStatusProcessor
+
+	now := time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	tn := BuildTestNode("tn", 1000, 1000)
+	tni := schedulernodeinfo.NewNodeInfo()
+	tni.SetNode(tn)
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(
+		func(id string, delta int) error {
+			return fmt.Errorf("unexpected scale-up of %s by %d", id, delta)
+		}, nil,
+		nil, nil,
+		nil, map[string]*schedulernodeinfo.NodeInfo{"ng1": tni})
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:                       estimator.BinpackingEstimatorName,
+		ScaleDownEnabled:                    true,
+		ScaleDownUtilizationThreshold:       0.5,
+		MaxNodesTotal:                       10,
+		MaxCoresTotal:                       10,
+		MaxMemoryTotal:                      100000,
+		FilterOutSchedulablePodsUsesPacking: true,
+		DryRun:                              true,
+	}
+	fakeClient := fake.NewSimpleClientset()
+	context := NewScaleTestAutoscalingContext(options, fakeClient, nil, provider)
+	fakeRecorder := kube_record.NewFakeRecorder(10)
+	logRecorder, err := utils.NewStatusMapRecorder(fakeClient, "kube-system", fakeRecorder, true)
+	assert.NoError(t, err)
+	context.LogRecorder = logRecorder
+	daemonSetLister, err := kube_util.NewTestDaemonSetLister(nil)
+	assert.NoError(t, err)
+	nodeLister := kube_util.NewTestNodeLister([]*apiv1.Node{n1})
+	context.ListerRegistry = kube_util.NewListerRegistry(nodeLister, nodeLister,
+		kube_util.NewTestPodLister(nil), kube_util.NewTestPodLister(nil),
+		kube_util.NewTestPodDisruptionBudgetLister(nil), daemonSetLister,
+		nil, nil, nil, nil, nil, nil)
+
+	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
+		OkTotalUnreadyCount:  1,
+		MaxNodeProvisionTime: 10 * time.Second,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterStateConfig, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	autoscaler := &StaticAutoscaler{
+		AutoscalingContext:    &context,
+		clusterStateRegistry:  clusterState,
+		lastScaleUpTime:       now,
+		lastScaleDownFailTime: now,
+		scaleDown:             NewScaleDown(&context, processors, clusterState),
+		processors:            processors,
+		initialized:           true,
+		nodeInfoCache:         make(map[string]*schedulernodeinfo.NodeInfo),
+	}
+	events := func() []string {
+		var result []string
+		for {
+			select {
+			case event := <-fakeRecorder.Events:
+				result = append(result, event)
+			default:
+				return result
+			}
+		}
+	}
+
+	// The scale-up to the window min size is recorded once while the window is active,
+	// and the iterations go on without it.
+	assert.NoError(t, autoscaler.RunOnce(now))
+	recorded := events()
+	assert.Len(t, recorded, 1)
+	assert.Contains(t, recorded[0], "would set group ng1 size to 5")
+	assert.Equal(t, status.ScaleDownNoUnneeded, scaleDownStatusProcessor.result)
+	assert.NoError(t, autoscaler.RunOnce(now.Add(time.Minute)))
+	assert.Empty(t, events())
+	assert.Equal(t, status.ScaleDownNoUnneeded, scaleDownStatusProcessor.result)
+	assert.Equal(t, now, autoscaler.lastScaleUpTime)
+	targetSize, _ := provider.GetNodeGroup("ng1").TargetSize()
+	assert.Equal(t, 1, targetSize)
+	assert.Empty(t, clusterState.GetUpcomingNodes())
+}
+
+func TestRecordDryRunScaleUps(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	ng1 := provider.GetNodeGroup("ng1")
+	p1 := BuildTestPod("p1", 600, 100)
+	p2 := BuildTestPod("p2", 600, 100)
+
+	fakeClient := fake.NewSimpleClientset()
+	fakeRecorder := kube_record.NewFakeRecorder(10)
+	context := NewScaleTestAutoscalingContext(config.AutoscalingOptions{DryRun: true}, fakeClient, nil, provider)
+	logRecorder, err := utils.NewStatusMapRecorder(fakeClient, "kube-system", fakeRecorder, true)
+	assert.NoError(t, err)
+	context.LogRecorder = logRecorder
+	events := func() int {
+		count := 0
+		for {
+			select {
+			case event := <-fakeRecorder.Events:
+				assert.Contains(t, event, "would set group ng1 size to")
+				count++
+			default:
+				return count
+			}
+		}
+	}
+	scaleUpStatus := func(newSize int, pods ...*apiv1.Pod) *status.ScaleUpStatus {
+		return &status.ScaleUpStatus{
+			Result:               status.ScaleUpSuccessful,
+			ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 1, NewSize: newSize, MaxSize: 10}},
+			PodsTriggeredScaleUp: pods,
+		}
+	}
+
+	recorded := recordDryRunScaleUps(&context, scaleUpStatus(2, p1), nil)
+	assert.Equal(t, 1, events())
+
+	// The same scale-up for the same pods is recorded once.
+	recorded = recordDryRunScaleUps(&context, scaleUpStatus(2, p1), recorded)
+	assert.Equal(t, 0, events())
+
+	// More pods need a bigger scale-up.
+	recorded = recordDryRunScaleUps(&context, scaleUpStatus(3, p1, p2), recorded)
+	assert.Equal(t, 1, events())
+
+	// Once the scale-up isn't needed, it is recorded again the next time.
+	recorded = recordDryRunScaleUps(&context, &status.ScaleUpStatus{Result: status.ScaleUpNotNeeded}, recorded)
+	assert.Empty(t, recorded)
+	recordDryRunScaleUps(&context, scaleUpStatus(3, p1, p2), recorded)
+	assert.Equal(t, 1, events())
+}
+
+func TestDryRunStatusString(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	provider.AddNode("ng1", n1)
+	ng1 := provider.GetNodeGroup("ng1")
+
+	assert.True(t, strings.HasSuffix(dryRunStatusString(&status.ScaleUpStatus{Result: status.ScaleUpNotNeeded},
+		&status.ScaleDownStatus{Result: status.ScaleDownNoUnneeded}), "  none\n"))
+
+	result := dryRunStatusString(
+		&status.ScaleUpStatus{
+			Result:       status.ScaleUpSuccessful,
+			ScaleUpInfos: []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 1, NewSize: 3, MaxSize: 10}},
+		},
+		&status.ScaleDownStatus{
+			Result:          status.ScaleDownNodeDeleteStarted,
+			ScaledDownNodes: []*status.ScaleDownNode{{Node: n1, NodeGroup: ng1}},
+		})
+	assert.Contains(t, result, "ScaleUp: group=ng1 size=1->3")
+	assert.Contains(t, result, "ScaleDown: node=n1 group=ng1 evictedPods=0")
+}
@@ -20,14 +20,19 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
@@ -64,33 +69,83 @@ const (
 	// PodEvictionHeadroom is the extra time we wait to catch situations when the pod is ignoring SIGTERM and
 	// is killed with SIGKILL after MaxGracefulTerminationTime
 	PodEvictionHeadroom = 30 * time.Second
+	// NodeBillingPeriod is the period nodes are assumed to be billed for when comparing
+	// the savings from removing them.
+	NodeBillingPeriod = time.Hour
 )
 
-// NodeDeleteStatus tells whether a node is being deleted right now.
+// NodeDeleteStatus tells whether nodes are being deleted right now.
 type NodeDeleteStatus struct {
 	sync.Mutex
-	deleteInProgress bool
+	// Nodes being deleted by name.
+	deletionsInProgress map[string]nodeDeletion
 	// A map of node delete results by node name. It contains nil if the delete was successful and an error otherwise.
 	// It's being constantly drained into ScaleDownStatus objects in order to notify the ScaleDownStatusProcessor that
 	// the node drain has ended or that an error occurred during the deletion process.
 	nodeDeleteResults map[string]error
 }
 
+// nodeDeletion is a node being deleted together with its node group.
+type nodeDeletion struct {
+	node      *apiv1.Node
+	nodeGroup cloudprovider.NodeGroup
+}
+
 // Get current time. Proxy for unit tests.
 var now func() time.Time = time.Now
 
+func newNodeDeleteStatus() *NodeDeleteStatus {
+	return &NodeDeleteStatus{
+		deletionsInProgress: make(map[string]nodeDeletion),
+		nodeDeleteResults:   make(map[string]error),
+	}
+}
+
 // IsDeleteInProgress returns true if a node is being deleted.
 func (n *NodeDeleteStatus) IsDeleteInProgress() bool {
 	n.Lock()
 	defer n.Unlock()
-	return n.deleteInProgress
+	return len(n.deletionsInProgress) > 0
+}
+
+// DeletionsInProgress returns the number of nodes being deleted.
+func (n *NodeDeleteStatus) DeletionsInProgress() int {
+	n.Lock()
+	defer n.Unlock()
+	return len(n.deletionsInProgress)
 }
 
-// SetDeleteInProgress sets deletion process status
-func (n *NodeDeleteStatus) SetDeleteInProgress(status bool) {
+// IsNodeBeingDeleted returns true if the node with the given name is being deleted.
+func (n *NodeDeleteStatus) IsNodeBeingDeleted(nodeName string) bool {
 	n.Lock()
 	defer n.Unlock()
-	n.deleteInProgress = status
+	_, found := n.deletionsInProgress[nodeName]
+	return found
+}
+
+// StartDeletion registers the start of the deletion of a node from the given node group.
+func (n *NodeDeleteStatus) StartDeletion(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup) {
+	n.Lock()
+	defer n.Unlock()
+	n.deletionsInProgress[node.Name] = nodeDeletion{node: node, nodeGroup: nodeGroup}
+}
+
+// FinishDeletion registers the end of the deletion of the node with the given name.
+func (n *NodeDeleteStatus) FinishDeletion(nodeName string) {
+	n.Lock()
+	defer n.Unlock()
+	delete(n.deletionsInProgress, nodeName)
+}
+
+// nodeDeletions returns the nodes being deleted.
+func (n *NodeDeleteStatus) nodeDeletions() []nodeDeletion {
+	n.Lock()
+	defer n.Unlock()
+	result := make([]nodeDeletion, 0, len(n.deletionsInProgress))
+	for _, deletion := range n.deletionsInProgress {
+		result = append(result, deletion)
+	}
+	return result
 }
 
 // AddNodeDeleteResult adds a node delete result to the result map.
@@ -304,6 +359,7 @@ func (limits *scaleDownResourcesLimits) tryDecrementLimitsByDelta(delta scaleDow
 // ScaleDown is responsible for maintaining the state needed to perform unneeded node removals.
 type ScaleDown struct {
 	context              *context.AutoscalingContext
+	processors           *ca_processors.AutoscalingProcessors
 	clusterStateRegistry *clusterstate.ClusterStateRegistry
 	unneededNodes        map[string]time.Time
 	unneededNodesList    []*apiv1.Node
@@ -312,12 +368,16 @@ type ScaleDown struct {
 	nodeUtilizationMap   map[string]simulator.UtilizationInfo
 	usageTracker         *simulator.UsageTracker
 	nodeDeleteStatus     *NodeDeleteStatus
+	// dryRunSoftTaints holds the deletion candidate taints nodes would have if CA
+	// was not running in dry-run mode.
+	dryRunSoftTaints map[string]bool
 }
 
 // NewScaleDown builds new ScaleDown object.
-func NewScaleDown(context *context.AutoscalingContext, clusterStateRegistry *clusterstate.ClusterStateRegistry) *ScaleDown {
+func NewScaleDown(context *context.AutoscalingContext, processors *ca_processors.AutoscalingProcessors, clusterStateRegistry *clusterstate.ClusterStateRegistry) *ScaleDown {
 	return &ScaleDown{
 		context:              context,
+		processors:           processors,
 		clusterStateRegistry: clusterStateRegistry,
 		unneededNodes:        make(map[string]time.Time),
 		unremovableNodes:     make(map[string]time.Time),
@@ -325,7 +385,7 @@ func NewScaleDown(context *context.AutoscalingContext, clusterStateRegistry *clu
 		nodeUtilizationMap:   make(map[string]simulator.UtilizationInfo),
 		usageTracker:         simulator.NewUsageTracker(),
 		unneededNodesList:    make([]*apiv1.Node, 0),
-		nodeDeleteStatus:     &NodeDeleteStatus{nodeDeleteResults: make(map[string]error)},
+		nodeDeleteStatus:     newNodeDeleteStatus(),
 	}
 }
 
@@ -334,6 +394,35 @@ func (sd *ScaleDown) CleanUp(timestamp time.Time) {
 	sd.usageTracker.CleanUp(timestamp.Add(-sd.context.ScaleDownUnneededTime))
 }
 
+// nodeGroupForNode returns the node group of the given node, or nil if it is not autoscaled.
+func (sd *ScaleDown) nodeGroupForNode(node *apiv1.Node) cloudprovider.NodeGroup {
+	nodeGroup, err := sd.context.CloudProvider.NodeGroupForNode(node)
+	if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+		return nil
+	}
+	return nodeGroup
+}
+
+// nodeGroupOptions returns the autoscaling options that apply to the node group of the given node.
+func (sd *ScaleDown) nodeGroupOptions(node *apiv1.Node) config.NodeGroupAutoscalingOptions {
+	defaults := sd.context.NodeGroupDefaults()
+	nodeGroup := sd.nodeGroupForNode(node)
+	if nodeGroup == nil {
+		return defaults
+	}
+	return sd.processors.NodeGroupConfigProcessor.GetOptions(nodeGroup, defaults)
+}
+
+// isRebalanceCandidate returns true if the node should be removed as soon as its pods fit
+// on other nodes, e.g. to move them back to spot capacity.
+func (sd *ScaleDown) isRebalanceCandidate(node *apiv1.Node, timestamp time.Time) bool {
+	nodeGroup := sd.nodeGroupForNode(node)
+	if nodeGroup == nil {
+		return false
+	}
+	return sd.processors.CapacityTypeProcessor.IsRebalanceCandidate(node, nodeGroup, sd.clusterStateRegistry, timestamp)
+}
+
 // GetCandidatesForScaleDown gets candidates for scale down.
 func (sd *ScaleDown) GetCandidatesForScaleDown() []*apiv1.Node {
 	return sd.unneededNodesList
@@ -410,16 +499,20 @@ func (sd *ScaleDown) UpdateUnneededNodes(
 		klog.V(4).Infof("Node %s - utilization %f", node.Name, utilInfo.Utilization)
 		utilizationMap[node.Name] = utilInfo
 
-		if utilInfo.Utilization >= sd.context.ScaleDownUtilizationThreshold {
-			klog.V(4).Infof("Node %s is not suitable for removal - utilization too big (%f)", node.Name, utilInfo.Utilization)
-			continue
+		if utilInfo.Utilization >= sd.nodeGroupOptions(node).ScaleDownUtilizationThreshold {
+			if !sd.isRebalanceCandidate(node, timestamp) {
+				klog.V(4).Infof("Node %s is not suitable for removal - utilization too big (%f)", node.Name, utilInfo.Utilization)
+				continue
+			}
+			klog.V(2).Infof("Node %s is considered for removal despite its utilization (%f) to rebalance capacity", node.Name, utilInfo.Utilization)
 		}
 		currentlyUnneededNodes = append(currentlyUnneededNodes, node)
 	}
 
 	emptyNodes := make(map[string]bool)
 
-	emptyNodesList := getEmptyNodesNoResourceLimits(currentlyUnneededNodes, pods, len(currentlyUnneededNodes), sd.context.CloudProvider)
+	emptyNodesList := getEmptyNodesNoResourceLimits(currentlyUnneededNodes, pods, len(currentlyUnneededNodes), sd.context.CloudProvider,
+		sd.processors.ScalingWindowProcessor, timestamp)
 	for _, node := range emptyNodesList {
 		emptyNodes[node.Name] = true
 	}
@@ -432,6 +525,7 @@ func (sd *ScaleDown) UpdateUnneededNodes(
 	}
 
 	// Phase2 - check which nodes can be probably removed using fast drain.
+	sd.sortBySavings(currentlyUnneededNonEmptyNodes, utilizationMap, timestamp)
 	currentCandidates, currentNonCandidates := sd.chooseCandidates(currentlyUnneededNonEmptyNodes)
 
 	// Look for nodes to remove in the current candidates
@@ -562,6 +656,44 @@ func (sd *ScaleDown) chooseCandidates(nodes []*apiv1.Node) ([]*apiv1.Node, []*ap
 	return currentCandidates, currentNonCandidates
 }
 
+// sortBySavings orders nodes by the savings from removing them, if the cloud provider supports
+// pricing. The savings are the price of the node until the end of its current billing period
+// weighted by the fraction of it left unused, so that expensive nodes are removed first unless
+// they are well utilized. Nodes with equal savings are ordered by the end of their current billing
+// period, the soonest first, as removing them wastes the least of already paid time.
+func (sd *ScaleDown) sortBySavings(nodes []*apiv1.Node, utilizationMap map[string]simulator.UtilizationInfo, timestamp time.Time) {
+	pricingModel, err := sd.context.CloudProvider.Pricing()
+	if err != nil {
+		return
+	}
+	savings := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		price, err := pricingModel.NodePrice(node, timestamp, billingPeriodEnd(node, timestamp))
+		if err != nil {
+			klog.Warningf("Failed to get price of node %s, ignoring prices in scale-down: %v", node.Name, err)
+			return
+		}
+		savings[node.Name] = price * (1 - utilizationMap[node.Name].Utilization)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if savings[nodes[i].Name] != savings[nodes[j].Name] {
+			return savings[nodes[i].Name] > savings[nodes[j].Name]
+		}
+		return billingPeriodEnd(nodes[i], timestamp).Before(billingPeriodEnd(nodes[j], timestamp))
+	})
+}
+
+// billingPeriodEnd returns the end of the current billing period of the node, counted
+// from its creation.
+func billingPeriodEnd(node *apiv1.Node, timestamp time.Time) time.Time {
+	created := node.CreationTimestamp.Time
+	if created.IsZero() || created.After(timestamp) {
+		return timestamp.Add(NodeBillingPeriod)
+	}
+	elapsed := timestamp.Sub(created) % NodeBillingPeriod
+	return timestamp.Add(NodeBillingPeriod - elapsed)
+}
+
 func (sd *ScaleDown) mapNodesToStatusScaleDownNodes(nodes []*apiv1.Node, nodeGroups map[string]cloudprovider.NodeGroup, evictedPodLists map[string][]*apiv1.Pod) []*status.ScaleDownNode {
 	var result []*status.ScaleDownNode
 	for _, node := range nodes {
@@ -582,6 +714,7 @@ func (sd *ScaleDown) SoftTaintUnneededNodes(allNodes []*apiv1.Node) (errors []er
 	timeBudget := sd.context.AutoscalingOptions.MaxBulkSoftTaintTime
 	skippedNodes := 0
 	startTime := now()
+	dryRunSoftTaints := make(map[string]bool)
 	for _, node := range allNodes {
 		if deletetaint.HasToBeDeletedTaint(node) {
 			// Do not consider nodes that are scheduled to be deleted
@@ -590,6 +723,22 @@ func (sd *ScaleDown) SoftTaintUnneededNodes(allNodes []*apiv1.Node) (errors []er
 		alreadyTainted := deletetaint.HasDeletionCandidateTaint(node)
 		_, unneeded := sd.unneededNodes[node.Name]
 
+		if sd.context.DryRun {
+			// No API calls are made in dry-run mode, only changes of the taints nodes
+			// would have are recorded.
+			if tainted, found := sd.dryRunSoftTaints[node.Name]; found {
+				alreadyTainted = tainted
+			}
+			dryRunSoftTaints[node.Name] = unneeded
+			if unneeded && !alreadyTainted {
+				recordDryRunAction(sd.context, metrics.DryRunTaint, 1, "would mark node %s as deletion candidate", node.Name)
+			}
+			if !unneeded && alreadyTainted {
+				recordDryRunAction(sd.context, metrics.DryRunUntaint, 1, "would unmark node %s as deletion candidate", node.Name)
+			}
+			continue
+		}
+
 		// Check if expected taints match existing taints
 		if unneeded != alreadyTainted {
 			if apiCallBudget <= 0 || now().Sub(startTime) >= timeBudget {
@@ -613,6 +762,9 @@ func (sd *ScaleDown) SoftTaintUnneededNodes(allNodes []*apiv1.Node) (errors []er
 			}
 		}
 	}
+	if sd.context.DryRun {
+		sd.dryRunSoftTaints = dryRunSoftTaints
+	}
 	if skippedNodes > 0 {
 		klog.V(4).Infof("Skipped adding/removing soft taints on %v nodes - API call limit exceeded", skippedNodes)
 	}
@@ -648,6 +800,11 @@ func (sd *ScaleDown) TryToScaleDown(allNodes []*apiv1.Node, pods []*apiv1.Pod, p
 
 			klog.V(2).Infof("%s was unneeded for %s", node.Name, currentTime.Sub(val).String())
 
+			if sd.nodeDeleteStatus.IsNodeBeingDeleted(node.Name) {
+				klog.V(4).Infof("Skipping %s - node is already being deleted", node.Name)
+				continue
+			}
+
 			// Check if node is marked with no scale down annotation.
 			if hasNoScaleDownAnnotation(node) {
 				klog.V(4).Infof("Skipping %s - scale down disabled annotation found", node.Name)
@@ -657,23 +814,29 @@ func (sd *ScaleDown) TryToScaleDown(allNodes []*apiv1.Node, pods []*apiv1.Pod, p
 			ready, _, _ := kube_util.GetReadinessState(node)
 			readinessMap[node.Name] = ready
 
-			// Check how long the node was underutilized.
-			if ready && !val.Add(sd.context.ScaleDownUnneededTime).Before(currentTime) {
+			nodeGroup, err := sd.context.CloudProvider.NodeGroupForNode(node)
+			if err != nil {
+				klog.Errorf("Error while checking node group for %s: %v", node.Name, err)
+				continue
+			}
+			if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+				klog.V(4).Infof("Skipping %s - no node group config", node.Name)
 				continue
 			}
 
-			// Unready nodes may be deleted after a different time than underutilized nodes.
-			if !ready && !val.Add(sd.context.ScaleDownUnreadyTime).Before(currentTime) {
+			nodeGroupOptions := sd.processors.NodeGroupConfigProcessor.GetOptions(nodeGroup, sd.context.NodeGroupDefaults())
+			if !nodeGroupOptions.ScaleDownEnabled {
+				klog.V(4).Infof("Skipping %s - scale down disabled for node group %s", node.Name, nodeGroup.Id())
 				continue
 			}
 
-			nodeGroup, err := sd.context.CloudProvider.NodeGroupForNode(node)
-			if err != nil {
-				klog.Errorf("Error while checking node group for %s: %v", node.Name, err)
+			// Check how long the node was underutilized.
+			if ready && !val.Add(nodeGroupOptions.ScaleDownUnneededTime).Before(currentTime) {
 				continue
 			}
-			if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
-				klog.V(4).Infof("Skipping %s - no node group config", node.Name)
+
+			// Unready nodes may be deleted after a different time than underutilized nodes.
+			if !ready && !val.Add(nodeGroupOptions.ScaleDownUnreadyTime).Before(currentTime) {
 				continue
 			}
 
@@ -683,7 +846,12 @@ func (sd *ScaleDown) TryToScaleDown(allNodes []*apiv1.Node, pods []*apiv1.Pod, p
 				continue
 			}
 
-			if size <= nodeGroup.MinSize() {
+			if sd.processors.ScalingWindowProcessor.IsScaleDownBlocked(nodeGroup, currentTime) {
+				klog.V(1).Infof("Skipping %s - scale down blocked by a scaling window", node.Name)
+				continue
+			}
+
+			if size <= sd.processors.ScalingWindowProcessor.GetMinSize(nodeGroup, currentTime) {
 				klog.V(1).Infof("Skipping %s - node group min size reached", node.Name)
 				continue
 			}
@@ -710,10 +878,14 @@ func (sd *ScaleDown) TryToScaleDown(allNodes []*apiv1.Node, pods []*apiv1.Pod, p
 		return scaleDownStatus, nil
 	}
 
+	sd.sortBySavings(candidates, sd.nodeUtilizationMap, currentTime)
+
 	// Trying to delete empty nodes in bulk. If there are no empty nodes then CA will
 	// try to delete not-so-empty nodes, possibly killing some pods and allowing them
 	// to recreate on other nodes.
-	emptyNodes := getEmptyNodes(candidates, pods, sd.context.MaxEmptyBulkDelete, scaleDownResourcesLeft, sd.context.CloudProvider)
+	budget := sd.newScaleDownBudget(nodeGroupSize)
+	emptyNodes := getEmptyNodes(candidates, pods, sd.context.MaxEmptyBulkDelete, scaleDownResourcesLeft, budget, sd.context.CloudProvider,
+		sd.processors.ScalingWindowProcessor, currentTime)
 	if len(emptyNodes) > 0 {
 		nodeDeletionStart := time.Now()
 		confirmation := make(chan errors.AutoscalerError, len(emptyNodes))
@@ -729,66 +901,163 @@ func (sd *ScaleDown) TryToScaleDown(allNodes []*apiv1.Node, pods []*apiv1.Pod, p
 		return scaleDownStatus, err.AddPrefix("failed to delete at least one empty node: ")
 	}
 
+	drainSlots := sd.drainSlotsLeft()
+	if drainSlots <= 0 {
+		klog.V(1).Infof("No node to remove - %d nodes are already being drained", sd.nodeDeleteStatus.DeletionsInProgress())
+		scaleDownStatus.Result = status.ScaleDownInProgress
+		return scaleDownStatus, nil
+	}
+
 	findNodesToRemoveStart := time.Now()
 	// Only scheduled non expendable pods are taken into account and have to be moved.
 	nonExpendablePods := filterOutExpendablePods(pods, sd.context.ExpendablePodsPriorityCutoff)
-	// We look for only 1 node so new hints may be incomplete.
-	nodesToRemove, _, _, err := simulator.FindNodesToRemove(candidates, nodesWithoutMaster, nonExpendablePods, sd.context.ListerRegistry,
-		sd.context.PredicateChecker, 1, false,
-		sd.podLocationHints, sd.usageTracker, time.Now(), pdbs)
+	// Nodes being drained can't take pods of other removed nodes.
+	destinationNodes, beingDeleted := sd.splitNodesBeingDeleted(nodesWithoutMaster)
+	// Pods not evicted yet from the nodes being drained are going to take capacity of the other nodes.
+	nonExpendablePods = simulator.ReschedulePodsOfRemovedNodes(beingDeleted, destinationNodes, nonExpendablePods,
+		sd.context.PredicateChecker, currentTime)
+	// All chosen nodes are removed at once, so their pods have to fit on the remaining nodes together.
+	// We look for only a few nodes so new hints may be incomplete.
+	nodesToRemove, _, _, err := simulator.FindNodesToRemoveTogether(candidates, destinationNodes, nonExpendablePods, sd.context.ListerRegistry,
+		sd.context.PredicateChecker, drainSlots, sd.podLocationHints, sd.usageTracker, time.Now(), pdbs)
 	findNodesToRemoveDuration = time.Now().Sub(findNodesToRemoveStart)
 
 	if err != nil {
 		scaleDownStatus.Result = status.ScaleDownError
 		return scaleDownStatus, err.AddPrefix("Find node to remove failed: ")
 	}
+	nodesToRemove = sd.limitNodesToRemove(nodesToRemove, candidateNodeGroups, nodeGroupSize, scaleDownResourcesLeft, resourcesWithLimits, budget, currentTime)
 	if len(nodesToRemove) == 0 {
 		klog.V(1).Infof("No node to remove")
 		scaleDownStatus.Result = status.ScaleDownNoNodeDeleted
 		return scaleDownStatus, nil
 	}
-	toRemove := nodesToRemove[0]
-	utilization := sd.nodeUtilizationMap[toRemove.Node.Name]
-	podNames := make([]string, 0, len(toRemove.PodsToReschedule))
-	for _, pod := range toRemove.PodsToReschedule {
-		podNames = append(podNames, pod.Namespace+"/"+pod.Name)
-	}
-	klog.V(0).Infof("Scale-down: removing node %s, utilization: %v, pods to reschedule: %s", toRemove.Node.Name, utilization,
-		strings.Join(podNames, ","))
-	sd.context.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDown", "Scale-down: removing node %s, utilization: %v, pods to reschedule: %s",
-		toRemove.Node.Name, utilization, strings.Join(podNames, ","))
-
-	// Nothing super-bad should happen if the node is removed from tracker prematurely.
-	simulator.RemoveNodeFromTracker(sd.usageTracker, toRemove.Node.Name, sd.unneededNodes)
-	nodeDeletionStart := time.Now()
-
-	// Starting deletion.
-	nodeDeletionDuration = time.Now().Sub(nodeDeletionStart)
-	sd.nodeDeleteStatus.SetDeleteInProgress(true)
-
-	go func() {
-		// Finishing the delete process once this goroutine is over.
-		var err error
-		defer func() { sd.nodeDeleteStatus.AddNodeDeleteResult(toRemove.Node.Name, err) }()
-		defer sd.nodeDeleteStatus.SetDeleteInProgress(false)
-		err = sd.deleteNode(toRemove.Node, toRemove.PodsToReschedule)
-		if err != nil {
-			klog.Errorf("Failed to delete %s: %v", toRemove.Node.Name, err)
-			return
-		}
-		nodeGroup := candidateNodeGroups[toRemove.Node.Name]
-		if readinessMap[toRemove.Node.Name] {
-			metrics.RegisterScaleDown(1, gpu.GetGpuTypeForMetrics(gpuLabel, availableGPUTypes, toRemove.Node, nodeGroup), metrics.Underutilized)
-		} else {
-			metrics.RegisterScaleDown(1, gpu.GetGpuTypeForMetrics(gpuLabel, availableGPUTypes, toRemove.Node, nodeGroup), metrics.Unready)
+
+	removedNodes := make([]*apiv1.Node, 0, len(nodesToRemove))
+	evictedPods := make(map[string][]*apiv1.Pod, len(nodesToRemove))
+	for _, toRemove := range nodesToRemove {
+		// Headroom pods are virtual, they only reserve capacity and are never evicted.
+		toRemove.PodsToReschedule = headroom.FilterOutHeadroomPods(toRemove.PodsToReschedule)
+		utilization := sd.nodeUtilizationMap[toRemove.Node.Name]
+		podNames := make([]string, 0, len(toRemove.PodsToReschedule))
+		for _, pod := range toRemove.PodsToReschedule {
+			podNames = append(podNames, pod.Namespace+"/"+pod.Name)
 		}
-	}()
+		klog.V(0).Infof("Scale-down: removing node %s, utilization: %v, pods to reschedule: %s", toRemove.Node.Name, utilization,
+			strings.Join(podNames, ","))
+		sd.context.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDown", "Scale-down: removing node %s, utilization: %v, pods to reschedule: %s",
+			toRemove.Node.Name, utilization, strings.Join(podNames, ","))
+
+		// Nothing super-bad should happen if the node is removed from tracker prematurely.
+		simulator.RemoveNodeFromTracker(sd.usageTracker, toRemove.Node.Name, sd.unneededNodes)
+
+		// Starting deletion.
+		sd.nodeDeleteStatus.StartDeletion(toRemove.Node, candidateNodeGroups[toRemove.Node.Name])
+		go func(toRemove simulator.NodeToBeRemoved) {
+			// Finishing the delete process once this goroutine is over.
+			var err error
+			defer func() { sd.nodeDeleteStatus.AddNodeDeleteResult(toRemove.Node.Name, err) }()
+			defer sd.nodeDeleteStatus.FinishDeletion(toRemove.Node.Name)
+			err = sd.deleteNode(toRemove.Node, toRemove.PodsToReschedule)
+			if err != nil {
+				klog.Errorf("Failed to delete %s: %v", toRemove.Node.Name, err)
+				return
+			}
+			if sd.context.DryRun {
+				return
+			}
+			nodeGroup := candidateNodeGroups[toRemove.Node.Name]
+			if readinessMap[toRemove.Node.Name] {
+				metrics.RegisterScaleDown(1, gpu.GetGpuTypeForMetrics(gpuLabel, availableGPUTypes, toRemove.Node, nodeGroup), metrics.Underutilized)
+			} else {
+				metrics.RegisterScaleDown(1, gpu.GetGpuTypeForMetrics(gpuLabel, availableGPUTypes, toRemove.Node, nodeGroup), metrics.Unready)
+			}
+		}(toRemove)
 
-	scaleDownStatus.ScaledDownNodes = sd.mapNodesToStatusScaleDownNodes([]*apiv1.Node{toRemove.Node}, candidateNodeGroups, map[string][]*apiv1.Pod{toRemove.Node.Name: toRemove.PodsToReschedule})
+		removedNodes = append(removedNodes, toRemove.Node)
+		evictedPods[toRemove.Node.Name] = toRemove.PodsToReschedule
+	}
+
+	scaleDownStatus.ScaledDownNodes = sd.mapNodesToStatusScaleDownNodes(removedNodes, candidateNodeGroups, evictedPods)
 	scaleDownStatus.Result = status.ScaleDownNodeDeleteStarted
 	return scaleDownStatus, nil
 }
 
+// splitNodesBeingDeleted returns the nodes that aren't being deleted and the names of the ones that are.
+func (sd *ScaleDown) splitNodesBeingDeleted(nodes []*apiv1.Node) ([]*apiv1.Node, map[string]bool) {
+	remaining := make([]*apiv1.Node, 0, len(nodes))
+	beingDeleted := make(map[string]bool)
+	for _, node := range nodes {
+		if sd.nodeDeleteStatus.IsNodeBeingDeleted(node.Name) {
+			beingDeleted[node.Name] = true
+		} else {
+			remaining = append(remaining, node)
+		}
+	}
+	return remaining, beingDeleted
+}
+
+// drainSlotsLeft returns how many more non-empty nodes can be drained at the same time as the nodes
+// already being deleted.
+func (sd *ScaleDown) drainSlotsLeft() int {
+	maxParallelDrains := sd.context.MaxDrainParallelism
+	if maxParallelDrains < 1 {
+		maxParallelDrains = 1
+	}
+	return maxParallelDrains - sd.nodeDeleteStatus.DeletionsInProgress()
+}
+
+// newScaleDownBudget creates a scale-down budget from which the nodes already being deleted are taken.
+func (sd *ScaleDown) newScaleDownBudget(nodeGroupSize map[string]int) *scaleDownBudget {
+	budget := newScaleDownBudget(sd.context.AutoscalingOptions, sd.processors.NodeGroupConfigProcessor)
+	for _, deletion := range sd.nodeDeleteStatus.nodeDeletions() {
+		if deletion.nodeGroup == nil || reflect.ValueOf(deletion.nodeGroup).IsNil() {
+			continue
+		}
+		budget.takeInProgress(deletion.node, deletion.nodeGroup, nodeGroupSize[deletion.nodeGroup.Id()])
+	}
+	return budget
+}
+
+// limitNodesToRemove drops the nodes which, removed together with the nodes before them, would
+// take their node groups below the minimum size, the cluster below the resource limits or
+// exceed the scale-down budget of their node groups or zones.
+func (sd *ScaleDown) limitNodesToRemove(nodesToRemove []simulator.NodeToBeRemoved, candidateNodeGroups map[string]cloudprovider.NodeGroup,
+	nodeGroupSize map[string]int, scaleDownResourcesLeft scaleDownResourcesLimits, resourcesWithLimits []string,
+	budget *scaleDownBudget, currentTime time.Time) []simulator.NodeToBeRemoved {
+	resourcesLeft := copyScaleDownResourcesLimits(scaleDownResourcesLeft)
+	sizeLeft := make(map[string]int)
+	result := make([]simulator.NodeToBeRemoved, 0, len(nodesToRemove))
+	for _, toRemove := range nodesToRemove {
+		nodeGroup := candidateNodeGroups[toRemove.Node.Name]
+		size, found := sizeLeft[nodeGroup.Id()]
+		if !found {
+			size = nodeGroupSize[nodeGroup.Id()]
+		}
+		if size <= sd.processors.ScalingWindowProcessor.GetMinSize(nodeGroup, currentTime) {
+			klog.V(1).Infof("Skipping %s - node group min size reached", toRemove.Node.Name)
+			continue
+		}
+		delta, err := computeScaleDownResourcesDelta(sd.context.CloudProvider, toRemove.Node, nodeGroup, resourcesWithLimits)
+		if err != nil {
+			klog.Errorf("Error getting node resources: %v", err)
+			continue
+		}
+		if checkResult := resourcesLeft.checkScaleDownDeltaWithinLimits(delta); checkResult.exceeded {
+			klog.V(4).Infof("Skipping %s - minimal limit exceeded for %v", toRemove.Node.Name, checkResult.exceededResources)
+			continue
+		}
+		if !budget.tryTake(toRemove.Node, nodeGroup, nodeGroupSize[nodeGroup.Id()]) {
+			klog.V(4).Infof("Skipping %s - scale-down budget of its node group or zone exhausted", toRemove.Node.Name)
+			continue
+		}
+		resourcesLeft.tryDecrementLimitsByDelta(delta)
+		sizeLeft[nodeGroup.Id()] = size - 1
+		result = append(result, toRemove)
+	}
+	return result
+}
+
 // updateScaleDownMetrics registers duration of different parts of scale down.
 // Separates time spent on finding nodes to remove, deleting nodes and other operations.
 func updateScaleDownMetrics(scaleDownStart time.Time, findNodesToRemoveDuration *time.Duration, nodeDeletionDuration *time.Duration) {
@@ -800,17 +1069,19 @@ func updateScaleDownMetrics(scaleDownStart time.Time, findNodesToRemoveDuration
 }
 
 func getEmptyNodesNoResourceLimits(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDelete int,
-	cloudProvider cloudprovider.CloudProvider) []*apiv1.Node {
-	return getEmptyNodes(candidates, pods, maxEmptyBulkDelete, noScaleDownLimitsOnResources(), cloudProvider)
+	cloudProvider cloudprovider.CloudProvider, scalingWindowProcessor scalingwindows.ScalingWindowProcessor, now time.Time) []*apiv1.Node {
+	return getEmptyNodes(candidates, pods, maxEmptyBulkDelete, noScaleDownLimitsOnResources(), nil, cloudProvider, scalingWindowProcessor, now)
 }
 
 // This functions finds empty nodes among passed candidates and returns a list of empty nodes
-// that can be deleted at the same time.
+// that can be deleted at the same time. A nil budget doesn't limit the nodes.
 func getEmptyNodes(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDelete int,
-	resourcesLimits scaleDownResourcesLimits, cloudProvider cloudprovider.CloudProvider) []*apiv1.Node {
+	resourcesLimits scaleDownResourcesLimits, budget *scaleDownBudget, cloudProvider cloudprovider.CloudProvider,
+	scalingWindowProcessor scalingwindows.ScalingWindowProcessor, now time.Time) []*apiv1.Node {
 
 	emptyNodes := simulator.FindEmptyNodesToRemove(candidates, pods)
 	availabilityMap := make(map[string]int)
+	sizeMap := make(map[string]int)
 	result := make([]*apiv1.Node, 0)
 	resourcesLimitsCopy := copyScaleDownResourcesLimits(resourcesLimits) // we do not want to modify input parameter
 	resourcesNames := sets.StringKeySet(resourcesLimits).List()
@@ -833,11 +1104,12 @@ func getEmptyNodes(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDele
 				klog.Errorf("Failed to get size for %s: %v ", nodeGroup.Id(), err)
 				continue
 			}
-			available = size - nodeGroup.MinSize()
+			available = size - scalingWindowProcessor.GetMinSize(nodeGroup, now)
 			if available < 0 {
 				available = 0
 			}
 			availabilityMap[nodeGroup.Id()] = available
+			sizeMap[nodeGroup.Id()] = size
 		}
 		if available > 0 {
 			resourcesDelta, err := computeScaleDownResourcesDelta(cloudProvider, node, nodeGroup, resourcesNames)
@@ -845,10 +1117,15 @@ func getEmptyNodes(candidates []*apiv1.Node, pods []*apiv1.Pod, maxEmptyBulkDele
 				klog.Errorf("Error: %v", err)
 				continue
 			}
-			checkResult := resourcesLimitsCopy.tryDecrementLimitsByDelta(resourcesDelta)
+			checkResult := resourcesLimitsCopy.checkScaleDownDeltaWithinLimits(resourcesDelta)
 			if checkResult.exceeded {
 				continue
 			}
+			if !budget.tryTake(node, nodeGroup, sizeMap[nodeGroup.Id()]) {
+				klog.V(4).Infof("Skipping %s - scale-down budget of its node group or zone exhausted", node.Name)
+				continue
+			}
+			resourcesLimitsCopy.tryDecrementLimitsByDelta(resourcesDelta)
 			available--
 			availabilityMap[nodeGroup.Id()] = available
 			result = append(result, node)
@@ -865,6 +1142,12 @@ func (sd *ScaleDown) scheduleDeleteEmptyNodes(emptyNodes []*apiv1.Node, cp cloud
 	recorder kube_record.EventRecorder, readinessMap map[string]bool,
 	candidateNodeGroups map[string]cloudprovider.NodeGroup, confirmation chan errors.AutoscalerError) {
 	for _, node := range emptyNodes {
+		if sd.context.DryRun {
+			recordDryRunAction(sd.context, metrics.DryRunScaleDown, 1, "Scale-down: would remove empty node %s", node.Name)
+			simulator.RemoveNodeFromTracker(sd.usageTracker, node.Name, sd.unneededNodes)
+			confirmation <- nil
+			continue
+		}
 		klog.V(0).Infof("Scale-down: removing empty node %s", node.Name)
 		sd.context.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDownEmpty", "Scale-down: removing empty node %s", node.Name)
 		simulator.RemoveNodeFromTracker(sd.usageTracker, node.Name, sd.unneededNodes)
@@ -926,6 +1209,11 @@ func (sd *ScaleDown) waitForEmptyNodesDeleted(emptyNodes []*apiv1.Node, confirma
 }
 
 func (sd *ScaleDown) deleteNode(node *apiv1.Node, pods []*apiv1.Pod) errors.AutoscalerError {
+	if sd.context.DryRun {
+		recordDryRunAction(sd.context, metrics.DryRunScaleDown, 1, "Scale-down: would drain node %s evicting %d pods and remove it", node.Name, len(pods))
+		return nil
+	}
+
 	deleteSuccessful := false
 	drainSuccessful := false
 
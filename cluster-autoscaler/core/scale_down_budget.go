@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"math"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+)
+
+// scaleDownBudget limits how many nodes of each node group and of each zone are removed
+// at the same time, so that a single scale-down doesn't take away most of the capacity
+// of a node group or a zone.
+type scaleDownBudget struct {
+	options          config.AutoscalingOptions
+	configProcessor  nodegroupconfig.NodeGroupConfigProcessor
+	nodeGroupBudgets map[string]int
+	zoneBudgets      map[string]int
+}
+
+func newScaleDownBudget(options config.AutoscalingOptions, configProcessor nodegroupconfig.NodeGroupConfigProcessor) *scaleDownBudget {
+	return &scaleDownBudget{
+		options:          options,
+		configProcessor:  configProcessor,
+		nodeGroupBudgets: make(map[string]int),
+		zoneBudgets:      make(map[string]int),
+	}
+}
+
+// tryTake checks whether the node can be removed together with the nodes taken before
+// and, if so, takes it from the budgets of its node group and zone. size is the current
+// size of the node group.
+func (b *scaleDownBudget) tryTake(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup, size int) bool {
+	if b == nil {
+		return true
+	}
+	groupLeft, found := b.nodeGroupBudgets[nodeGroup.Id()]
+	if !found {
+		groupLeft = b.nodeGroupBudget(nodeGroup, size)
+	}
+	zone := node.Labels[apiv1.LabelZoneFailureDomain]
+	zoneLeft, found := b.zoneBudgets[zone]
+	if !found {
+		zoneLeft = b.options.MaxDisruptedNodesPerZone
+	}
+	if groupLeft == 0 || (b.limitsZone(zone) && zoneLeft <= 0) {
+		return false
+	}
+	if groupLeft > 0 {
+		groupLeft--
+	}
+	b.nodeGroupBudgets[nodeGroup.Id()] = groupLeft
+	if b.limitsZone(zone) {
+		b.zoneBudgets[zone] = zoneLeft - 1
+	}
+	return true
+}
+
+// takeInProgress takes a node that is already being deleted from the budgets of its node
+// group and zone, even if they are exhausted. size is the current size of the node group.
+func (b *scaleDownBudget) takeInProgress(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup, size int) {
+	groupLeft, found := b.nodeGroupBudgets[nodeGroup.Id()]
+	if !found {
+		groupLeft = b.nodeGroupBudget(nodeGroup, size)
+	}
+	if groupLeft > 0 {
+		groupLeft--
+	}
+	b.nodeGroupBudgets[nodeGroup.Id()] = groupLeft
+	zone := node.Labels[apiv1.LabelZoneFailureDomain]
+	if b.limitsZone(zone) {
+		zoneLeft, found := b.zoneBudgets[zone]
+		if !found {
+			zoneLeft = b.options.MaxDisruptedNodesPerZone
+		}
+		b.zoneBudgets[zone] = zoneLeft - 1
+	}
+}
+
+// nodeGroupBudget returns the number of nodes of the node group that can be removed at
+// the same time, or -1 if there is no limit. At least one node can always be removed.
+func (b *scaleDownBudget) nodeGroupBudget(nodeGroup cloudprovider.NodeGroup, size int) int {
+	percentage := b.configProcessor.GetOptions(nodeGroup, b.options.NodeGroupDefaults()).MaxDisruptedNodesPercentage
+	if percentage <= 0 {
+		return -1
+	}
+	budget := int(math.Floor(float64(size) * percentage / 100))
+	if budget < 1 {
+		budget = 1
+	}
+	return budget
+}
+
+func (b *scaleDownBudget) limitsZone(zone string) bool {
+	return zone != "" && b.options.MaxDisruptedNodesPerZone > 0
+}
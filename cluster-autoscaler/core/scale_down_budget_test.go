@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildZonalTestNode(name, zone string) *apiv1.Node {
+	node := BuildTestNode(name, 1000, 1000)
+	SetNodeReadyState(node, true, time.Time{})
+	if zone != "" {
+		node.Labels[apiv1.LabelZoneFailureDomain] = zone
+	}
+	return node
+}
+
+func TestScaleDownBudgetTryTake(t *testing.T) {
+	testCases := []struct {
+		description string
+		percentage  float64
+		perZone     int
+		zones       []string
+		expected    []bool
+	}{
+		{
+			description: "no budgets",
+			zones:       []string{"a", "a", "a", "a"},
+			expected:    []bool{true, true, true, true},
+		},
+		{
+			description: "node group percentage",
+			percentage:  50,
+			zones:       []string{"a", "b", "c", "a"},
+			expected:    []bool{true, true, false, false},
+		},
+		{
+			description: "node group percentage below one node",
+			percentage:  10,
+			zones:       []string{"a", "b", "c", "a"},
+			expected:    []bool{true, false, false, false},
+		},
+		{
+			description: "zone limit",
+			perZone:     1,
+			zones:       []string{"a", "a", "b", ""},
+			expected:    []bool{true, false, true, true},
+		},
+		{
+			description: "both budgets",
+			percentage:  50,
+			perZone:     1,
+			zones:       []string{"a", "a", "b", "c"},
+			expected:    []bool{true, false, true, false},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 0, 10, len(tc.zones))
+			options := config.AutoscalingOptions{
+				MaxDisruptedNodesPercentage: tc.percentage,
+				MaxDisruptedNodesPerZone:    tc.perZone,
+			}
+			budget := newScaleDownBudget(options, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+			for i, zone := range tc.zones {
+				node := buildZonalTestNode(fmt.Sprintf("n%d", i), zone)
+				assert.Equal(t, tc.expected[i], budget.tryTake(node, provider.GetNodeGroup("ng1"), len(tc.zones)), "node %d", i)
+			}
+		})
+	}
+
+	var noBudget *scaleDownBudget
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	assert.True(t, noBudget.tryTake(buildZonalTestNode("n1", "a"), provider.GetNodeGroup("ng1"), 1))
+}
+
+func TestScaleDownBudgetTakeInProgress(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 4)
+	ng1 := provider.GetNodeGroup("ng1")
+	options := config.AutoscalingOptions{
+		MaxDisruptedNodesPercentage: 50,
+		MaxDisruptedNodesPerZone:    1,
+	}
+
+	// Nodes being deleted use up the budgets even if they are already exhausted.
+	budget := newScaleDownBudget(options, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	budget.takeInProgress(buildZonalTestNode("n1", "a"), ng1, 4)
+	budget.takeInProgress(buildZonalTestNode("n2", "a"), ng1, 4)
+	assert.False(t, budget.tryTake(buildZonalTestNode("n3", "b"), ng1, 4))
+
+	budget = newScaleDownBudget(options, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	budget.takeInProgress(buildZonalTestNode("n1", "a"), ng1, 4)
+	assert.False(t, budget.tryTake(buildZonalTestNode("n2", "a"), ng1, 4))
+	assert.True(t, budget.tryTake(buildZonalTestNode("n3", "b"), ng1, 4))
+}
+
+func TestGetEmptyNodesWithBudget(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 4)
+	provider.AddNodeGroup("ng2", 0, 10, 2)
+	nodes := []*apiv1.Node{
+		buildZonalTestNode("ng1_a1", "a"),
+		buildZonalTestNode("ng1_a2", "a"),
+		buildZonalTestNode("ng1_b1", "b"),
+		buildZonalTestNode("ng1_b2", "b"),
+		buildZonalTestNode("ng2_a1", "a"),
+		buildZonalTestNode("ng2_c1", "c"),
+	}
+	for _, node := range nodes[:4] {
+		provider.AddNode("ng1", node)
+	}
+	for _, node := range nodes[4:] {
+		provider.AddNode("ng2", node)
+	}
+
+	options := config.AutoscalingOptions{
+		MaxDisruptedNodesPercentage: 50,
+		MaxDisruptedNodesPerZone:    1,
+	}
+	budget := newScaleDownBudget(options, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	emptyNodes := getEmptyNodes(nodes, []*apiv1.Pod{}, 10, noScaleDownLimitsOnResources(), budget, provider,
+		scalingwindows.NewDefaultScalingWindowProcessor(), time.Now())
+	names := make([]string, 0)
+	for _, node := range emptyNodes {
+		names = append(names, node.Name)
+	}
+	// ng1 can lose 2 nodes and each zone a single node.
+	assert.Equal(t, []string{"ng1_a1", "ng1_b1", "ng2_c1"}, names)
+
+	emptyNodes = getEmptyNodes(nodes, []*apiv1.Pod{}, 10, noScaleDownLimitsOnResources(), nil, provider,
+		scalingwindows.NewDefaultScalingWindowProcessor(), time.Now())
+	assert.Equal(t, 6, len(emptyNodes))
+}
@@ -28,16 +28,22 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
 	kube_client "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
+	kube_record "k8s.io/client-go/tools/record"
 
 	"strconv"
 
@@ -123,14 +129,15 @@ func TestFindUnneededNodes(t *testing.T) {
 	provider.AddNode("ng1", n9)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.35,
 		ExpendablePodsPriorityCutoff:  10,
 		UnremovableNodeRecheckTimeout: 5 * time.Minute,
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	sd := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	sd.UpdateUnneededNodes([]*apiv1.Node{n1, n2, n3, n4, n5, n7, n8, n9}, []*apiv1.Node{n1, n2, n3, n4, n5, n6, n7, n8, n9},
 		[]*apiv1.Pod{p1, p2, p3, p4, p5, p6}, time.Now(), nil)
 
@@ -235,13 +242,14 @@ func TestPodsWithPrioritiesFindUnneededNodes(t *testing.T) {
 	provider.AddNode("ng1", n4)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.35,
 		ExpendablePodsPriorityCutoff:  10,
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	sd := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	sd.UpdateUnneededNodes([]*apiv1.Node{n1, n2, n3, n4}, []*apiv1.Node{n1, n2, n3, n4},
 		[]*apiv1.Pod{p1, p2, p3, p4, p5, p6, p7}, time.Now(), nil)
@@ -256,6 +264,55 @@ func TestPodsWithPrioritiesFindUnneededNodes(t *testing.T) {
 	assert.Equal(t, 4, len(sd.nodeUtilizationMap))
 }
 
+type thresholdNodeGroupConfigProcessor struct {
+	nodegroupconfig.NoOpNodeGroupConfigProcessor
+	thresholds map[string]float64
+}
+
+func (p *thresholdNodeGroupConfigProcessor) GetOptions(nodeGroup cloudprovider.NodeGroup, defaults config.NodeGroupAutoscalingOptions) config.NodeGroupAutoscalingOptions {
+	if threshold, found := p.thresholds[nodeGroup.Id()]; found {
+		defaults.ScaleDownUtilizationThreshold = threshold
+	}
+	return defaults
+}
+
+func TestFindUnneededNodesPerNodeGroupThreshold(t *testing.T) {
+	ownerRef := GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+	p1 := BuildTestPod("p1", 400, 0)
+	p1.Spec.NodeName = "n1"
+	p1.OwnerReferences = ownerRef
+	p2 := BuildTestPod("p2", 400, 0)
+	p2.Spec.NodeName = "n2"
+	p2.OwnerReferences = ownerRef
+
+	n1 := BuildTestNode("n1", 1000, 10)
+	n2 := BuildTestNode("n2", 1000, 10)
+	SetNodeReadyState(n1, true, time.Time{})
+	SetNodeReadyState(n2, true, time.Time{})
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng2", n2)
+
+	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
+		ScaleDownUtilizationThreshold: 0.5,
+	}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
+	processors := ca_processors.TestProcessors()
+	processors.NodeGroupConfigProcessor = &thresholdNodeGroupConfigProcessor{thresholds: map[string]float64{"ng2": 0.3}}
+
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), processors.NodeGroupConfigProcessor)
+	sd := NewScaleDown(&context, processors, clusterStateRegistry)
+
+	sd.UpdateUnneededNodes([]*apiv1.Node{n1, n2}, []*apiv1.Node{n1, n2}, []*apiv1.Pod{p1, p2}, time.Now(), nil)
+	assert.Equal(t, 1, len(sd.unneededNodes))
+	_, found := sd.unneededNodes["n1"]
+	assert.True(t, found)
+}
+
 func TestFindUnneededMaxCandidates(t *testing.T) {
 	provider := testprovider.NewTestCloudProvider(nil, nil)
 	provider.AddNodeGroup("ng1", 1, 100, 2)
@@ -283,6 +340,7 @@ func TestFindUnneededMaxCandidates(t *testing.T) {
 	numCandidates := 30
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:                 true,
 		ScaleDownUtilizationThreshold:    0.35,
 		ScaleDownNonEmptyCandidatesCount: numCandidates,
 		ScaleDownCandidatesPoolRatio:     1,
@@ -290,8 +348,8 @@ func TestFindUnneededMaxCandidates(t *testing.T) {
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	sd := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	sd.UpdateUnneededNodes(nodes, nodes, pods, time.Now(), nil)
 	assert.Equal(t, numCandidates, len(sd.unneededNodes))
@@ -320,6 +378,109 @@ func TestFindUnneededMaxCandidates(t *testing.T) {
 	assert.NotContains(t, sd.unneededNodes, deleted)
 }
 
+type testNodePriceModel struct {
+	prices map[string]float64
+}
+
+func (m *testNodePriceModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	return m.prices[node.Name] * endTime.Sub(startTime).Hours(), nil
+}
+
+func (m *testNodePriceModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestFindUnneededPrefersExpensiveNodes(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 100, 6)
+	provider.SetPricingModel(&testNodePriceModel{prices: map[string]float64{"n0": 1, "n1": 1, "n2": 3, "n3": 1, "n4": 2, "n5": 1}})
+
+	ownerRef := GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+	nodes := make([]*apiv1.Node, 0)
+	pods := make([]*apiv1.Pod, 0)
+	for i := 0; i < 6; i++ {
+		n := BuildTestNode(fmt.Sprintf("n%v", i), 1000, 10)
+		SetNodeReadyState(n, true, time.Time{})
+		provider.AddNode("ng1", n)
+		nodes = append(nodes, n)
+
+		p := BuildTestPod(fmt.Sprintf("p%v", i), 100, 0)
+		p.Spec.NodeName = n.Name
+		p.OwnerReferences = ownerRef
+		pods = append(pods, p)
+	}
+
+	options := config.AutoscalingOptions{
+		ScaleDownEnabled:                 true,
+		ScaleDownUtilizationThreshold:    0.35,
+		ScaleDownNonEmptyCandidatesCount: 2,
+		ScaleDownCandidatesPoolRatio:     1,
+		ScaleDownCandidatesPoolMinCount:  1000,
+	}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
+
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
+
+	sd.UpdateUnneededNodes(nodes, nodes, pods, time.Now(), nil)
+	assert.Equal(t, 2, len(sd.unneededNodes))
+	assert.Contains(t, sd.unneededNodes, "n2")
+	assert.Contains(t, sd.unneededNodes, "n4")
+}
+
+func TestSortBySavings(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	context := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, &fake.Clientset{}, nil, provider)
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), nil)
+
+	now := time.Now()
+	cheap := BuildTestNode("cheap", 1000, 10)
+	expensive := BuildTestNode("expensive", 1000, 10)
+	expensiveEmpty := BuildTestNode("expensive-empty", 1000, 10)
+	utilization := map[string]simulator.UtilizationInfo{
+		"cheap":           {Utilization: 0.1},
+		"expensive":       {Utilization: 0.3},
+		"expensive-empty": {Utilization: 0.2},
+	}
+
+	// Without pricing the order is kept.
+	nodes := []*apiv1.Node{cheap, expensive, expensiveEmpty}
+	sd.sortBySavings(nodes, utilization, now)
+	assert.Equal(t, []*apiv1.Node{cheap, expensive, expensiveEmpty}, nodes)
+
+	provider.SetPricingModel(&testNodePriceModel{prices: map[string]float64{"cheap": 1, "expensive": 5, "expensive-empty": 5}})
+	sd.sortBySavings(nodes, utilization, now)
+	assert.Equal(t, []*apiv1.Node{expensiveEmpty, expensive, cheap}, nodes)
+
+	// Well utilized expensive nodes save less than unused cheap ones.
+	utilization["expensive"] = simulator.UtilizationInfo{Utilization: 0.9}
+	sd.sortBySavings(nodes, utilization, now)
+	assert.Equal(t, []*apiv1.Node{expensiveEmpty, cheap, expensive}, nodes)
+
+	// Nodes are priced until the end of their billing period.
+	utilization["expensive"] = simulator.UtilizationInfo{Utilization: 0.2}
+	expensive.CreationTimestamp = metav1.NewTime(now.Add(-50 * time.Minute))
+	expensiveEmpty.CreationTimestamp = metav1.NewTime(now.Add(-10 * time.Minute))
+	sd.sortBySavings(nodes, utilization, now)
+	assert.Equal(t, []*apiv1.Node{expensiveEmpty, cheap, expensive}, nodes)
+
+	// Nodes with equal savings are ordered by the end of their billing period.
+	expensive.CreationTimestamp = metav1.NewTime(now.Add(-45 * time.Minute))
+	expensiveEmpty.CreationTimestamp = metav1.NewTime(now.Add(-15 * time.Minute))
+	provider.SetPricingModel(&testNodePriceModel{prices: map[string]float64{"cheap": 1, "expensive": 6, "expensive-empty": 2}})
+	sd.sortBySavings(nodes, utilization, now)
+	assert.Equal(t, []*apiv1.Node{expensive, expensiveEmpty, cheap}, nodes)
+}
+
+func TestBillingPeriodEnd(t *testing.T) {
+	now := time.Now()
+	node := BuildTestNode("n1", 1000, 10)
+	assert.Equal(t, now.Add(time.Hour), billingPeriodEnd(node, now))
+
+	node.CreationTimestamp = metav1.NewTime(now.Add(-150 * time.Minute))
+	assert.Equal(t, now.Add(30*time.Minute), billingPeriodEnd(node, now))
+}
+
 func TestFindUnneededEmptyNodes(t *testing.T) {
 	provider := testprovider.NewTestCloudProvider(nil, nil)
 	provider.AddNodeGroup("ng1", 1, 100, 100)
@@ -349,6 +510,7 @@ func TestFindUnneededEmptyNodes(t *testing.T) {
 	numCandidates := 30
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:                 true,
 		ScaleDownUtilizationThreshold:    0.35,
 		ScaleDownNonEmptyCandidatesCount: numCandidates,
 		ScaleDownCandidatesPoolRatio:     1.0,
@@ -356,8 +518,8 @@ func TestFindUnneededEmptyNodes(t *testing.T) {
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	sd := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	sd.UpdateUnneededNodes(nodes, nodes, pods, time.Now(), nil)
 	for _, node := range sd.unneededNodesList {
@@ -393,6 +555,7 @@ func TestFindUnneededNodePool(t *testing.T) {
 	numCandidates := 30
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:                 true,
 		ScaleDownUtilizationThreshold:    0.35,
 		ScaleDownNonEmptyCandidatesCount: numCandidates,
 		ScaleDownCandidatesPoolRatio:     0.1,
@@ -400,8 +563,8 @@ func TestFindUnneededNodePool(t *testing.T) {
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	sd := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	sd.UpdateUnneededNodes(nodes, nodes, pods, time.Now(), nil)
 	assert.NotEmpty(t, sd.unneededNodes)
@@ -535,8 +698,8 @@ func TestDeleteNode(t *testing.T) {
 			// build context
 			context := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, fakeClient, nil, provider)
 
-			clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-			sd := NewScaleDown(&context, clusterStateRegistry)
+			clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+			sd := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 			// attempt delete
 			err := sd.deleteNode(n1, pods)
@@ -757,6 +920,7 @@ func TestScaleDown(t *testing.T) {
 	assert.NotNil(t, provider)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.5,
 		ScaleDownUnneededTime:         time.Minute,
 		MaxGracefulTerminationSec:     60,
@@ -764,12 +928,12 @@ func TestScaleDown(t *testing.T) {
 	}
 	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
 	assert.NoError(t, err)
-	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
 
 	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1, n2},
 		[]*apiv1.Node{n1, n2}, []*apiv1.Pod{p1, p2, p3}, time.Now().Add(-5*time.Minute), nil)
 	scaleDownStatus, err := scaleDown.TryToScaleDown([]*apiv1.Node{n1, n2}, []*apiv1.Pod{p1, p2, p3}, nil, time.Now())
@@ -780,6 +944,226 @@ func TestScaleDown(t *testing.T) {
 	assert.Equal(t, n1.Name, getStringFromChan(updatedNodes))
 }
 
+func TestScaleDownParallelDrains(t *testing.T) {
+	testCases := []struct {
+		description     string
+		maxParallelism  int
+		minSize         int
+		inProgress      []string
+		expectedResult  status.ScaleDownResult
+		expectedDeleted []string
+	}{
+		{
+			description:     "single drain by default",
+			maxParallelism:  0,
+			minSize:         1,
+			expectedResult:  status.ScaleDownNodeDeleteStarted,
+			expectedDeleted: []string{"n1"},
+		},
+		{
+			description:     "parallel drains",
+			maxParallelism:  3,
+			minSize:         1,
+			expectedResult:  status.ScaleDownNodeDeleteStarted,
+			expectedDeleted: []string{"n1", "n2"},
+		},
+		{
+			description:     "parallel drains limited by min size",
+			maxParallelism:  3,
+			minSize:         3,
+			expectedResult:  status.ScaleDownNodeDeleteStarted,
+			expectedDeleted: []string{"n1"},
+		},
+		{
+			description:     "parallel drains limited by deletions in progress",
+			maxParallelism:  2,
+			minSize:         1,
+			inProgress:      []string{"n4"},
+			expectedResult:  status.ScaleDownNodeDeleteStarted,
+			expectedDeleted: []string{"n1"},
+		},
+		{
+			description:    "no drain slots left",
+			maxParallelism: 1,
+			minSize:        1,
+			inProgress:     []string{"n4"},
+			expectedResult: status.ScaleDownInProgress,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			deletedNodes := make(chan string, 10)
+			fakeClient := &fake.Clientset{}
+
+			job := batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job",
+					Namespace: "default",
+					SelfLink:  "/apivs/batch/v1/namespaces/default/jobs/job",
+				},
+			}
+			nodes := make([]*apiv1.Node, 0)
+			for _, name := range []string{"n1", "n2", "n3", "n4"} {
+				node := BuildTestNode(name, 1000, 1000)
+				SetNodeReadyState(node, true, time.Time{})
+				nodes = append(nodes, node)
+			}
+			// Pods of n1 and n2 fit on n3 and n4 only if they are moved to different nodes.
+			p1 := BuildTestPod("p1", 300, 0)
+			p1.OwnerReferences = GenerateOwnerReferences(job.Name, "Job", "batch/v1", "")
+			p1.Spec.NodeName = "n1"
+			p2 := BuildTestPod("p2", 300, 0)
+			p2.OwnerReferences = GenerateOwnerReferences(job.Name, "Job", "batch/v1", "")
+			p2.Spec.NodeName = "n2"
+			p3 := BuildTestPod("p3", 600, 0)
+			p3.Spec.NodeName = "n3"
+			p4 := BuildTestPod("p4", 600, 0)
+			p4.Spec.NodeName = "n4"
+			pods := []*apiv1.Pod{p1, p2, p3, p4}
+
+			fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.NewNotFound(apiv1.Resource("pod"), "whatever")
+			})
+			fakeClient.Fake.AddReactor("get", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+				getAction := action.(core.GetAction)
+				for _, node := range nodes {
+					if node.Name == getAction.GetName() {
+						return true, node, nil
+					}
+				}
+				return true, nil, fmt.Errorf("wrong node: %v", getAction.GetName())
+			})
+			fakeClient.Fake.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+			fakeClient.Fake.AddReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+				update := action.(core.UpdateAction)
+				return true, update.GetObject(), nil
+			})
+
+			provider := testprovider.NewTestCloudProvider(nil, func(nodeGroup string, node string) error {
+				deletedNodes <- node
+				return nil
+			})
+			provider.AddNodeGroup("ng1", tc.minSize, 10, 4)
+			for _, node := range nodes {
+				provider.AddNode("ng1", node)
+			}
+
+			options := config.AutoscalingOptions{
+				ScaleDownEnabled:              true,
+				ScaleDownUtilizationThreshold: 0.5,
+				ScaleDownUnneededTime:         time.Minute,
+				MaxGracefulTerminationSec:     60,
+				MaxDrainParallelism:           tc.maxParallelism,
+			}
+			jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
+			assert.NoError(t, err)
+			registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
+
+			context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
+			recorder := kube_record.NewFakeRecorder(100)
+			context.Recorder = recorder
+			context.LogRecorder, _ = utils.NewStatusMapRecorder(fakeClient, "kube-system", recorder, false)
+
+			clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+			scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
+			scaleDown.UpdateUnneededNodes(nodes, nodes, pods, time.Now().Add(-5*time.Minute), nil)
+			for _, node := range nodes {
+				for _, name := range tc.inProgress {
+					if node.Name == name {
+						scaleDown.nodeDeleteStatus.StartDeletion(node, provider.GetNodeGroup("ng1"))
+					}
+				}
+			}
+			// Pods of the nodes being deleted were already evicted.
+			remainingPods := make([]*apiv1.Pod, 0, len(pods))
+			for _, pod := range pods {
+				if !scaleDown.nodeDeleteStatus.IsNodeBeingDeleted(pod.Spec.NodeName) {
+					remainingPods = append(remainingPods, pod)
+				}
+			}
+			scaleDownStatus, err := scaleDown.TryToScaleDown(nodes, remainingPods, nil, time.Now())
+			for _, name := range tc.inProgress {
+				scaleDown.nodeDeleteStatus.FinishDeletion(name)
+			}
+			waitForDeleteToFinish(t, scaleDown)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, scaleDownStatus.Result)
+			assert.Equal(t, len(tc.expectedDeleted), len(scaleDownStatus.ScaledDownNodes))
+
+			deleted := make([]string, 0)
+			for range tc.expectedDeleted {
+				deleted = append(deleted, getStringFromChan(deletedNodes))
+			}
+			assertEqualSet(t, tc.expectedDeleted, deleted)
+			assert.Equal(t, nothingReturned, getStringFromChanImmediately(deletedNodes))
+		})
+	}
+}
+
+func TestScaleDownPodsOfDrainsInProgress(t *testing.T) {
+	fakeClient := &fake.Clientset{}
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job",
+			Namespace: "default",
+			SelfLink:  "/apivs/batch/v1/namespaces/default/jobs/job",
+		},
+	}
+	nodes := make([]*apiv1.Node, 0)
+	for _, name := range []string{"n1", "n2", "n3"} {
+		node := BuildTestNode(name, 1000, 1000)
+		SetNodeReadyState(node, true, time.Time{})
+		nodes = append(nodes, node)
+	}
+	// n1 is being drained and p1 wasn't evicted yet. p2 of the new candidate n2 fits on n3,
+	// but p1 only fits on n2, so n2 has to stay.
+	p1 := BuildTestPod("p1", 500, 0)
+	p1.OwnerReferences = GenerateOwnerReferences(job.Name, "Job", "batch/v1", "")
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 400, 0)
+	p2.OwnerReferences = GenerateOwnerReferences(job.Name, "Job", "batch/v1", "")
+	p2.Spec.NodeName = "n2"
+	p3 := BuildTestPod("p3", 600, 0)
+	p3.Spec.NodeName = "n3"
+	pods := []*apiv1.Pod{p1, p2, p3}
+
+	deletedNodes := make(chan string, 10)
+	provider := testprovider.NewTestCloudProvider(nil, func(nodeGroup string, node string) error {
+		deletedNodes <- node
+		return nil
+	})
+	provider.AddNodeGroup("ng1", 1, 10, 3)
+	for _, node := range nodes {
+		provider.AddNode("ng1", node)
+	}
+
+	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
+		ScaleDownUtilizationThreshold: 0.5,
+		ScaleDownUnneededTime:         time.Minute,
+		MaxGracefulTerminationSec:     60,
+		MaxDrainParallelism:           2,
+	}
+	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
+	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
+
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
+	scaleDown.UpdateUnneededNodes(nodes, nodes, pods, time.Now().Add(-5*time.Minute), nil)
+	scaleDown.nodeDeleteStatus.StartDeletion(nodes[0], provider.GetNodeGroup("ng1"))
+	scaleDownStatus, err := scaleDown.TryToScaleDown(nodes, pods, nil, time.Now())
+	scaleDown.nodeDeleteStatus.FinishDeletion("n1")
+	waitForDeleteToFinish(t, scaleDown)
+	assert.NoError(t, err)
+	assert.Equal(t, status.ScaleDownNoNodeDeleted, scaleDownStatus.Result)
+	assert.Equal(t, nothingReturned, getStringFromChanImmediately(deletedNodes))
+}
+
 func waitForDeleteToFinish(t *testing.T, sd *ScaleDown) {
 	for start := time.Now(); time.Since(start) < 20*time.Second; time.Sleep(100 * time.Millisecond) {
 		if !sd.nodeDeleteStatus.IsDeleteInProgress() {
@@ -812,6 +1196,7 @@ func assertSubset(t *testing.T, a []string, b []string) {
 }
 
 var defaultScaleDownOptions = config.AutoscalingOptions{
+	ScaleDownEnabled:              true,
 	ScaleDownUtilizationThreshold: 0.5,
 	ScaleDownUnneededTime:         time.Minute,
 	MaxGracefulTerminationSec:     60,
@@ -980,8 +1365,8 @@ func simpleScaleDownEmpty(t *testing.T, config *scaleTestConfig) {
 
 	context := NewScaleTestAutoscalingContext(config.options, fakeClient, nil, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	scaleDown.UpdateUnneededNodes(nodes,
 		nodes, []*apiv1.Pod{}, time.Now().Add(-5*time.Minute), nil)
 	scaleDownStatus, err := scaleDown.TryToScaleDown(nodes, []*apiv1.Pod{}, nil, time.Now())
@@ -1048,6 +1433,7 @@ func TestNoScaleDownUnready(t *testing.T) {
 	provider.AddNode("ng1", n2)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.5,
 		ScaleDownUnneededTime:         time.Minute,
 		ScaleDownUnreadyTime:          time.Hour,
@@ -1056,8 +1442,8 @@ func TestNoScaleDownUnready(t *testing.T) {
 	context := NewScaleTestAutoscalingContext(options, fakeClient, nil, provider)
 
 	// N1 is unready so it requires a bigger unneeded time.
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1, n2},
 		[]*apiv1.Node{n1, n2}, []*apiv1.Pod{p2}, time.Now().Add(-5*time.Minute), nil)
 	scaleDownStatus, err := scaleDown.TryToScaleDown([]*apiv1.Node{n1, n2}, []*apiv1.Pod{p2}, nil, time.Now())
@@ -1079,7 +1465,7 @@ func TestNoScaleDownUnready(t *testing.T) {
 
 	// N1 has been unready for 2 hours, ok to delete.
 	context.CloudProvider = provider
-	scaleDown = NewScaleDown(&context, clusterStateRegistry)
+	scaleDown = NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1, n2}, []*apiv1.Node{n1, n2},
 		[]*apiv1.Pod{p2}, time.Now().Add(-2*time.Hour), nil)
 	scaleDownStatus, err = scaleDown.TryToScaleDown([]*apiv1.Node{n1, n2}, []*apiv1.Pod{p2}, nil, time.Now())
@@ -1148,6 +1534,7 @@ func TestScaleDownNoMove(t *testing.T) {
 	assert.NotNil(t, provider)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.5,
 		ScaleDownUnneededTime:         time.Minute,
 		ScaleDownUnreadyTime:          time.Hour,
@@ -1155,12 +1542,12 @@ func TestScaleDownNoMove(t *testing.T) {
 	}
 	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
 	assert.NoError(t, err)
-	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
 
 	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1, n2}, []*apiv1.Node{n1, n2},
 		[]*apiv1.Pod{p1, p2}, time.Now().Add(5*time.Minute), nil)
 	scaleDownStatus, err := scaleDown.TryToScaleDown([]*apiv1.Node{n1, n2}, []*apiv1.Pod{p1, p2}, nil, time.Now())
@@ -1396,6 +1783,7 @@ func TestSoftTaint(t *testing.T) {
 	assert.NotNil(t, provider)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.5,
 		ScaleDownUnneededTime:         10 * time.Minute,
 		MaxGracefulTerminationSec:     60,
@@ -1404,12 +1792,12 @@ func TestSoftTaint(t *testing.T) {
 	}
 	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
 	assert.NoError(t, err)
-	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
 
 	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	// Test no superfluous nodes
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1000, n2000},
@@ -1456,6 +1844,75 @@ func TestSoftTaint(t *testing.T) {
 	assert.Equal(t, 0, countDeletionCandidateTaints(t, fakeClient))
 }
 
+func TestSoftTaintDryRun(t *testing.T) {
+	n1000 := BuildTestNode("n1000", 1000, 1000)
+	SetNodeReadyState(n1000, true, time.Time{})
+	n2000 := BuildTestNode("n2000", 2000, 1000)
+	SetNodeReadyState(n2000, true, time.Time{})
+
+	p700 := BuildTestPod("p700", 700, 0)
+	p700.Spec.NodeName = "n1000"
+
+	fakeClient := fake.NewSimpleClientset()
+	_, err := fakeClient.CoreV1().Nodes().Create(n1000)
+	assert.NoError(t, err)
+	_, err = fakeClient.CoreV1().Nodes().Create(n2000)
+	assert.NoError(t, err)
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", n1000)
+	provider.AddNode("ng1", n2000)
+
+	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
+		ScaleDownUtilizationThreshold: 0.5,
+		ScaleDownUnneededTime:         10 * time.Minute,
+		MaxGracefulTerminationSec:     60,
+		MaxBulkSoftTaintCount:         1,
+		MaxBulkSoftTaintTime:          3 * time.Second,
+		DryRun:                        true,
+	}
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
+	fakeRecorder := kube_record.NewFakeRecorder(10)
+	context.LogRecorder, err = utils.NewStatusMapRecorder(fakeClient, "kube-system", fakeRecorder, true)
+	assert.NoError(t, err)
+	countEvents := func() int {
+		count := 0
+		for {
+			select {
+			case <-fakeRecorder.Events:
+				count++
+			default:
+				return count
+			}
+		}
+	}
+
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
+
+	// Both nodes become unneeded, taints are recorded once regardless of the API call budget.
+	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1000, n2000},
+		[]*apiv1.Node{n1000, n2000}, []*apiv1.Pod{}, time.Now().Add(-5*time.Minute), nil)
+	assert.Empty(t, scaleDown.SoftTaintUnneededNodes(getAllNodes(t, fakeClient)))
+	assert.Equal(t, 2, countEvents())
+	assert.Empty(t, scaleDown.SoftTaintUnneededNodes(getAllNodes(t, fakeClient)))
+	assert.Equal(t, 0, countEvents())
+	assert.Equal(t, 0, countDeletionCandidateTaints(t, fakeClient))
+
+	// One node is needed again.
+	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1000, n2000},
+		[]*apiv1.Node{n1000, n2000}, []*apiv1.Pod{p700}, time.Now().Add(-5*time.Minute), nil)
+	assert.Empty(t, scaleDown.SoftTaintUnneededNodes(getAllNodes(t, fakeClient)))
+	assert.Equal(t, 1, countEvents())
+	assert.Empty(t, scaleDown.SoftTaintUnneededNodes(getAllNodes(t, fakeClient)))
+	assert.Equal(t, 0, countEvents())
+	assert.Equal(t, map[string]bool{"n1000": false, "n2000": true}, scaleDown.dryRunSoftTaints)
+	assert.Equal(t, 0, countDeletionCandidateTaints(t, fakeClient))
+}
+
 func TestSoftTaintTimeLimit(t *testing.T) {
 	job := batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1506,6 +1963,7 @@ func TestSoftTaintTimeLimit(t *testing.T) {
 	assert.NotNil(t, provider)
 
 	options := config.AutoscalingOptions{
+		ScaleDownEnabled:              true,
 		ScaleDownUtilizationThreshold: 0.5,
 		ScaleDownUnneededTime:         10 * time.Minute,
 		MaxGracefulTerminationSec:     60,
@@ -1514,12 +1972,12 @@ func TestSoftTaintTimeLimit(t *testing.T) {
 	}
 	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{&job})
 	assert.NoError(t, err)
-	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, jobLister, nil, nil, nil, nil)
 
 	context := NewScaleTestAutoscalingContext(options, fakeClient, registry, provider)
 
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
-	scaleDown := NewScaleDown(&context, clusterStateRegistry)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	scaleDown := NewScaleDown(&context, ca_processors.TestProcessors(), clusterStateRegistry)
 
 	// Test bulk taint
 	scaleDown.UpdateUnneededNodes([]*apiv1.Node{n1, n2},
@@ -128,7 +128,7 @@ func (p *mockAutoprovisioningNodeGroupManager) RemoveUnneededNodeGroups(context
 		}
 		nodes, err := nodeGroup.Nodes()
 		assert.NoError(p.t, err)
-		if len(nodes) > 0 {
+		if len(nodes) > 0 || context.DryRun {
 			continue
 		}
 		err = nodeGroup.Delete()
@@ -36,6 +36,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/glogx"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
 	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 
 	"k8s.io/klog"
@@ -244,9 +245,10 @@ var (
 
 // ScaleUp tries to scale the cluster up. Return true if it found a way to increase the size,
 // false if it didn't and error if an error occurred. Assumes that all nodes in the cluster are
-// ready and in sync with instance groups.
+// ready and in sync with instance groups. Scheduled pods are used to keep pods with zone
+// anti-affinity out of the zones of the pods they conflict with.
 func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.AutoscalingProcessors, clusterStateRegistry *clusterstate.ClusterStateRegistry, unschedulablePods []*apiv1.Pod,
-	nodes []*apiv1.Node, daemonSets []*appsv1.DaemonSet, nodeInfos map[string]*schedulernodeinfo.NodeInfo) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	nodes []*apiv1.Node, scheduledPods []*apiv1.Pod, daemonSets []*appsv1.DaemonSet, nodeInfos map[string]*schedulernodeinfo.NodeInfo) (*status.ScaleUpStatus, errors.AutoscalerError) {
 	// From now on we only care about unschedulable pods that were marked after the newest
 	// node became available for the scheduler.
 	if len(unschedulablePods) == 0 {
@@ -287,6 +289,8 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 		return &status.ScaleUpStatus{Result: status.ScaleUpError}, errLimits.AddPrefix("Could not compute total resources: ")
 	}
 
+	scheduledPodsInZone := podsByZone(nodes, scheduledPods)
+
 	upcomingNodes := make([]*schedulernodeinfo.NodeInfo, 0)
 	for nodeGroup, numberOfNodes := range clusterStateRegistry.GetUpcomingNodes() {
 		nodeTemplate, found := nodeInfos[nodeGroup]
@@ -310,6 +314,7 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 		if errProc != nil {
 			return &status.ScaleUpStatus{Result: status.ScaleUpError}, errors.ToAutoscalerError(errors.InternalError, errProc)
 		}
+		nodeGroups = buildTemplatesOfNodeGroupsToCreate(context, processors, nodeGroups, nodeInfos, daemonSets)
 	}
 
 	podsPredicatePassingCheckFunctions := getPodsPredicatePassingCheckFunctions(context, unschedulablePods, nodeInfos)
@@ -404,8 +409,9 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 
 		if len(option.Pods) > 0 {
 			estimator := context.EstimatorBuilder(context.PredicateChecker)
-			option.NodeCount = estimator.Estimate(option.Pods, nodeInfo, upcomingNodes)
-			if option.NodeCount > 0 {
+			zone := scheduler_util.GetZone(nodeInfo.Node())
+			option.NodeCount, option.Pods = estimator.Estimate(option.Pods, nodeInfo, upcomingNodes, scheduledPodsInZone[zone])
+			if option.NodeCount > 0 && len(option.Pods) > 0 {
 				expansionOptions = append(expansionOptions, option)
 			} else {
 				klog.V(2).Infof("No need for any nodes in %s", nodeGroup.Id())
@@ -420,6 +426,8 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 		return &status.ScaleUpStatus{Result: status.ScaleUpNoOptionsAvailable, PodsRemainUnschedulable: getRemainingPods(podsRemainUnschedulable, skippedNodeGroups)}, nil
 	}
 
+	expansionOptions = processors.CapacityTypeProcessor.FilterOptions(expansionOptions, nodeInfos, clusterStateRegistry, now)
+
 	// Pick some expansion option.
 	bestOption := context.ExpanderStrategy.BestOption(expansionOptions, nodeInfos)
 	if bestOption != nil && bestOption.NodeCount > 0 {
@@ -429,6 +437,13 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 		}
 		klog.V(1).Infof("Estimated %d nodes needed in %s", bestOption.NodeCount, bestOption.NodeGroup.Id())
 
+		// The estimation leaves out pods that can't run in the zone of the best option, but they
+		// may still be spread to the zones of similar node groups.
+		podsToSpread := bestOption.Pods
+		if podsPassing, err := getPodsPassingPredicates(bestOption.NodeGroup.Id()); err == nil {
+			podsToSpread = podsPassing
+		}
+
 		newNodes := bestOption.NodeCount
 
 		if context.MaxNodesTotal > 0 && len(nodes)+newNodes+len(upcomingNodes) > context.MaxNodesTotal {
@@ -441,7 +456,7 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 			}
 		}
 
-		if !bestOption.NodeGroup.Exist() {
+		if !bestOption.NodeGroup.Exist() && !context.DryRun {
 			oldId := bestOption.NodeGroup.Id()
 			createNodeGroupResult, err := processors.NodeGroupManager.CreateNodeGroup(context, bestOption.NodeGroup)
 			if err != nil {
@@ -451,7 +466,7 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 
 			// If possible replace candidate node-info with node info based on crated node group. The latter
 			// one should be more in line with nodes which will be created by node group.
-			mainCreatedNodeInfo, err := getNodeInfoFromTemplate(createNodeGroupResult.MainCreatedNodeGroup, daemonSets, context.PredicateChecker)
+			mainCreatedNodeInfo, err := getNodeInfoFromTemplate(createNodeGroupResult.MainCreatedNodeGroup, daemonSets, context.PredicateChecker, processors.TemplateNodeInfoProcessor)
 			if err == nil {
 				nodeInfos[createNodeGroupResult.MainCreatedNodeGroup.Id()] = mainCreatedNodeInfo
 			} else {
@@ -465,7 +480,7 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 			}
 
 			for _, nodeGroup := range createNodeGroupResult.ExtraCreatedNodeGroups {
-				nodeInfo, err := getNodeInfoFromTemplate(nodeGroup, daemonSets, context.PredicateChecker)
+				nodeInfo, err := getNodeInfoFromTemplate(nodeGroup, daemonSets, context.PredicateChecker, processors.TemplateNodeInfoProcessor)
 
 				if err != nil {
 					klog.Warningf("Cannot build node info for newly created extra node group %v; balancing similar node groups will not work; err=%v", nodeGroup.Id(), err)
@@ -490,18 +505,14 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 				"No node info for best expansion option!")
 		}
 
-		// apply upper limits for CPU and memory
-		newNodes, err = applyScaleUpResourcesLimits(context.CloudProvider, newNodes, scaleUpResourcesLeft, nodeInfo, bestOption.NodeGroup, resourceLimiter)
-		if err != nil {
-			return &status.ScaleUpStatus{Result: status.ScaleUpError}, err
-		}
-
 		targetNodeGroups := []cloudprovider.NodeGroup{bestOption.NodeGroup}
 		if context.BalanceSimilarNodeGroups {
 			similarNodeGroups, typedErr := processors.NodeGroupSetProcessor.FindSimilarNodeGroups(context, bestOption.NodeGroup, nodeInfos)
 			if typedErr != nil {
 				return &status.ScaleUpStatus{Result: status.ScaleUpError}, typedErr.AddPrefix("Failed to find matching node groups: ")
 			}
+			// Pods passing predicates include the volume topology check, so similar node groups
+			// in zones that don't match the pods' bound volumes are dropped here.
 			similarNodeGroups = filterNodeGroupsByPods(similarNodeGroups, bestOption.Pods, getPodsPassingPredicates)
 			for _, ng := range similarNodeGroups {
 				if clusterStateRegistry.IsNodeGroupSafeToScaleUp(ng, now) {
@@ -524,8 +535,34 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 				klog.V(1).Infof("Splitting scale-up between %v similar node groups: {%v}", len(targetNodeGroups), buffer.String())
 			}
 		}
+
+		// Pods spread across zones need nodes in the zones of several similar node groups.
+		zoneNodes, spreadPods := zoneSpreadNodes(context, targetNodeGroups, nodeInfos, podsToSpread, scheduledPodsInZone)
+		podsTriggeredScaleUp := bestOption.Pods
+		if zoneNodes != nil {
+			podsTriggeredScaleUp = mergePods(bestOption.Pods, spreadPods)
+		}
+		spreadNodes := 0
+		for _, count := range zoneNodes {
+			spreadNodes += count
+		}
+		if spreadNodes > newNodes {
+			klog.V(1).Infof("Pods spread across zones need %d nodes in %d node groups", spreadNodes, len(zoneNodes))
+			newNodes = spreadNodes
+			if context.MaxNodesTotal > 0 && len(nodes)+newNodes+len(upcomingNodes) > context.MaxNodesTotal {
+				klog.V(1).Infof("Capping size to max cluster total size (%d)", context.MaxNodesTotal)
+				newNodes = context.MaxNodesTotal - len(nodes) - len(upcomingNodes)
+			}
+		}
+
+		// apply upper limits for CPU and memory
+		newNodes, err = applyScaleUpResourcesLimits(context.CloudProvider, newNodes, scaleUpResourcesLeft, nodeInfo, bestOption.NodeGroup, resourceLimiter)
+		if err != nil {
+			return &status.ScaleUpStatus{Result: status.ScaleUpError}, err
+		}
+
 		scaleUpInfos, typedErr := processors.NodeGroupSetProcessor.BalanceScaleUpBetweenGroups(
-			context, targetNodeGroups, newNodes)
+			context, targetNodeGroups, newNodes, zoneNodes)
 		if typedErr != nil {
 			return &status.ScaleUpStatus{Result: status.ScaleUpError}, typedErr
 		}
@@ -535,6 +572,7 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 			if typedErr != nil {
 				return &status.ScaleUpStatus{Result: status.ScaleUpError}, typedErr
 			}
+			processors.CapacityTypeProcessor.RegisterScaleUp(info.Group, now)
 		}
 
 		clusterStateRegistry.Recalculate()
@@ -542,8 +580,8 @@ func ScaleUp(context *context.AutoscalingContext, processors *ca_processors.Auto
 				Result:                  status.ScaleUpSuccessful,
 				ScaleUpInfos:            scaleUpInfos,
 				PodsRemainUnschedulable: getRemainingPods(podsRemainUnschedulable, skippedNodeGroups),
-				PodsTriggeredScaleUp:    bestOption.Pods,
-				PodsAwaitEvaluation:     getPodsAwaitingEvaluation(unschedulablePods, podsRemainUnschedulable, bestOption.Pods)},
+				PodsTriggeredScaleUp:    podsTriggeredScaleUp,
+				PodsAwaitEvaluation:     getPodsAwaitingEvaluation(unschedulablePods, podsRemainUnschedulable, podsTriggeredScaleUp)},
 			nil
 	}
 
@@ -638,6 +676,28 @@ func getRemainingPods(schedulingErrors map[*apiv1.Pod]map[string]status.Reasons,
 	return remaining
 }
 
+// buildTemplatesOfNodeGroupsToCreate rebuilds templates of node groups that don't exist yet,
+// e.g. ones added by node autoprovisioning, the same way as templates of existing node groups,
+// so that they include DaemonSet pods and overrides of TemplateNodeInfoProcessor. Node groups
+// whose template can't be built are dropped.
+func buildTemplatesOfNodeGroupsToCreate(context *context.AutoscalingContext, processors *ca_processors.AutoscalingProcessors,
+	nodeGroups []cloudprovider.NodeGroup, nodeInfos map[string]*schedulernodeinfo.NodeInfo, daemonSets []*appsv1.DaemonSet) []cloudprovider.NodeGroup {
+	result := make([]cloudprovider.NodeGroup, 0, len(nodeGroups))
+	for _, nodeGroup := range nodeGroups {
+		if !nodeGroup.Exist() {
+			nodeInfo, err := getNodeInfoFromTemplate(nodeGroup, daemonSets, context.PredicateChecker, processors.TemplateNodeInfoProcessor)
+			if err != nil {
+				klog.Warningf("Failed to build template of node group %s: %v", nodeGroup.Id(), err)
+				delete(nodeInfos, nodeGroup.Id())
+				continue
+			}
+			nodeInfos[nodeGroup.Id()] = nodeInfo
+		}
+		result = append(result, nodeGroup)
+	}
+	return result
+}
+
 func getPodsAwaitingEvaluation(allPods []*apiv1.Pod, unschedulable map[*apiv1.Pod]map[string]status.Reasons, bestOption []*apiv1.Pod) []*apiv1.Pod {
 	awaitsEvaluation := make(map[*apiv1.Pod]bool, len(allPods))
 	for _, pod := range allPods {
@@ -686,6 +746,10 @@ groupsloop:
 }
 
 func executeScaleUp(context *context.AutoscalingContext, clusterStateRegistry *clusterstate.ClusterStateRegistry, info nodegroupset.ScaleUpInfo, gpuType string, now time.Time) errors.AutoscalerError {
+	if context.DryRun {
+		// Recorded by recordDryRunScaleUps once the whole scale-up is known.
+		return nil
+	}
 	klog.V(0).Infof("Scale-up: setting group %s size to %d", info.Group.Id(), info.NewSize)
 	context.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaledUpGroup",
 		"Scale-up: setting group %s size to %d", info.Group.Id(), info.NewSize)
@@ -29,6 +29,8 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/estimator"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
@@ -36,6 +38,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
 
@@ -382,7 +385,7 @@ func simpleScaleUpTest(t *testing.T, config *scaleTestConfig) {
 	}
 
 	podLister := kube_util.NewTestPodLister(pods)
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
 		expandedGroups <- groupSizeChange{groupName: nodeGroup, sizeChange: increase}
@@ -413,8 +416,8 @@ func simpleScaleUpTest(t *testing.T, config *scaleTestConfig) {
 	}
 	context.ExpanderStrategy = expander
 
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
-	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 
 	extraPods := make([]*apiv1.Pod, len(config.extraPods))
@@ -425,7 +428,7 @@ func simpleScaleUpTest(t *testing.T, config *scaleTestConfig) {
 
 	processors := ca_processors.TestProcessors()
 
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, extraPods, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, extraPods, nodes, pods, []*appsv1.DaemonSet{}, nodeInfos)
 	processors.ScaleUpStatusProcessor.Process(&context, scaleUpStatus)
 	assert.NoError(t, err)
 	assert.True(t, scaleUpStatus.WasSuccessful())
@@ -481,7 +484,7 @@ func TestScaleUpNodeComingNoScale(t *testing.T) {
 	p2.Spec.NodeName = "n2"
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{p1, p2})
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
 		t.Fatalf("No expansion is expected, but increased %s by %d", nodeGroup, increase)
@@ -500,12 +503,12 @@ func TestScaleUpNodeComingNoScale(t *testing.T) {
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
 
 	nodes := []*apiv1.Node{n1, n2}
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	clusterState := clusterstate.NewClusterStateRegistry(
 		provider,
 		clusterstate.ClusterStateRegistryConfig{MaxNodeProvisionTime: 5 * time.Minute},
 		context.LogRecorder,
-		newBackoff())
+		newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng2"), 1, time.Now())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 
@@ -513,7 +516,7 @@ func TestScaleUpNodeComingNoScale(t *testing.T) {
 
 	processors := ca_processors.TestProcessors()
 
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 	assert.NoError(t, err)
 	// A node is already coming - no need for scale up.
 	assert.False(t, scaleUpStatus.WasSuccessful())
@@ -531,7 +534,7 @@ func TestScaleUpNodeComingHasScale(t *testing.T) {
 	p2.Spec.NodeName = "n2"
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{p1, p2})
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	expandedGroups := make(chan string, 10)
 	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
@@ -546,14 +549,14 @@ func TestScaleUpNodeComingHasScale(t *testing.T) {
 	context := NewScaleTestAutoscalingContext(defaultOptions, &fake.Clientset{}, listers, provider)
 
 	nodes := []*apiv1.Node{n1, n2}
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	clusterState := clusterstate.NewClusterStateRegistry(
 		provider,
 		clusterstate.ClusterStateRegistryConfig{
 			MaxNodeProvisionTime: 5 * time.Minute,
 		},
 		context.LogRecorder,
-		newBackoff())
+		newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.RegisterOrUpdateScaleUp(provider.GetNodeGroup("ng2"), 1, time.Now())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 
@@ -561,7 +564,7 @@ func TestScaleUpNodeComingHasScale(t *testing.T) {
 	p4 := BuildTestPod("p-new", 550, 0)
 
 	processors := ca_processors.TestProcessors()
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3, p4}, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3, p4}, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 
 	assert.NoError(t, err)
 	// Two nodes needed but one node is already coming, so it should increase by one.
@@ -581,7 +584,7 @@ func TestScaleUpUnhealthy(t *testing.T) {
 	p2.Spec.NodeName = "n2"
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{p1, p2})
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
 		t.Fatalf("No expansion is expected, but increased %s by %d", nodeGroup, increase)
@@ -600,13 +603,13 @@ func TestScaleUpUnhealthy(t *testing.T) {
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
 
 	nodes := []*apiv1.Node{n1, n2}
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
-	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 	p3 := BuildTestPod("p-new", 550, 0)
 
 	processors := ca_processors.TestProcessors()
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 
 	assert.NoError(t, err)
 	// Node group is unhealthy.
@@ -621,7 +624,7 @@ func TestScaleUpNoHelp(t *testing.T) {
 	p1.Spec.NodeName = "n1"
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{p1})
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
 		t.Fatalf("No expansion is expected")
@@ -639,13 +642,13 @@ func TestScaleUpNoHelp(t *testing.T) {
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
 
 	nodes := []*apiv1.Node{n1}
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
-	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 	p3 := BuildTestPod("p-new", 500, 0)
 
 	processors := ca_processors.TestProcessors()
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p3}, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 	processors.ScaleUpStatusProcessor.Process(&context, scaleUpStatus)
 
 	assert.NoError(t, err)
@@ -659,6 +662,70 @@ func TestScaleUpNoHelp(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("NotTriggerScaleUp"), event)
 }
 
+func TestScaleUpVolumeZone(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(func(string, int) error {
+		return nil
+	}, nil)
+	nodes := make([]*apiv1.Node, 0)
+	for gid, zone := range map[string]string{"ng-a": "zone-a", "ng-b": "zone-b"} {
+		provider.AddNodeGroup(gid, 1, 10, 1)
+		node := BuildTestNode(gid+"-node", 1000, 1000)
+		node.Labels[apiv1.LabelZoneFailureDomain] = zone
+		SetNodeReadyState(node, true, time.Now())
+		nodes = append(nodes, node)
+		provider.AddNode(gid, node)
+	}
+
+	var pvcs []*apiv1.PersistentVolumeClaim
+	var pvs []*apiv1.PersistentVolume
+	var pods []*apiv1.Pod
+	for _, zone := range []string{"zone-b", "zone-c"} {
+		pvs = append(pvs, &apiv1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-" + zone, Labels: map[string]string{apiv1.LabelZoneFailureDomain: zone}},
+		})
+		pvcs = append(pvcs, &apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim-" + zone, Namespace: "default"},
+			Spec:       apiv1.PersistentVolumeClaimSpec{VolumeName: "pv-" + zone},
+		})
+		pod := BuildTestPod("p-"+zone, 500, 0)
+		pod.Spec.Volumes = []apiv1.Volume{{
+			Name: "data",
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "claim-" + zone},
+			},
+		}}
+		pods = append(pods, pod)
+	}
+	pvcLister, err := kube_util.NewTestPersistentVolumeClaimLister(pvcs)
+	assert.NoError(t, err)
+	pvLister, err := kube_util.NewTestPersistentVolumeLister(pvs)
+	assert.NoError(t, err)
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, pvcLister, pvLister)
+
+	context := NewScaleTestAutoscalingContext(defaultOptions, &fake.Clientset{}, listers, provider)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	scaleUpStatus, typedErr := ScaleUp(&context, ca_processors.TestProcessors(), clusterState, pods, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
+	assert.NoError(t, typedErr)
+	assert.True(t, scaleUpStatus.WasSuccessful())
+	assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+	assert.Equal(t, "ng-b", scaleUpStatus.ScaleUpInfos[0].Group.Id())
+	assert.Equal(t, []*apiv1.Pod{pods[0]}, scaleUpStatus.PodsTriggeredScaleUp)
+
+	// No node group runs in the zone of the second pod's volume.
+	assert.Equal(t, 1, len(scaleUpStatus.PodsRemainUnschedulable))
+	noScaleUpInfo := scaleUpStatus.PodsRemainUnschedulable[0]
+	assert.Equal(t, pods[1], noScaleUpInfo.Pod)
+	for _, gid := range []string{"ng-a", "ng-b"} {
+		reasons, found := noScaleUpInfo.RejectedNodeGroups[gid]
+		assert.True(t, found)
+		assert.Equal(t, []string{"volume pv-zone-c is bound to zone zone-c"}, reasons.Reasons())
+	}
+}
+
 func TestScaleUpBalanceGroups(t *testing.T) {
 	provider := testprovider.NewTestCloudProvider(func(string, int) error {
 		return nil
@@ -693,7 +760,7 @@ func TestScaleUpBalanceGroups(t *testing.T) {
 	}
 
 	podLister := kube_util.NewTestPodLister(podList)
-	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	options := config.AutoscalingOptions{
 		EstimatorName:            estimator.BinpackingEstimatorName,
@@ -703,8 +770,8 @@ func TestScaleUpBalanceGroups(t *testing.T) {
 	}
 	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
 
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker)
-	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
 
 	pods := make([]*apiv1.Pod, 0)
@@ -713,7 +780,7 @@ func TestScaleUpBalanceGroups(t *testing.T) {
 	}
 
 	processors := ca_processors.TestProcessors()
-	scaleUpStatus, typedErr := ScaleUp(&context, processors, clusterState, pods, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, typedErr := ScaleUp(&context, processors, clusterState, pods, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 
 	assert.NoError(t, typedErr)
 	assert.True(t, scaleUpStatus.WasSuccessful())
@@ -730,6 +797,64 @@ func TestScaleUpBalanceGroups(t *testing.T) {
 	assert.Equal(t, 2, ng3size)
 }
 
+func TestScaleUpBalanceGroupsVolumeZone(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(func(string, int) error {
+		return nil
+	}, nil)
+	nodes := make([]*apiv1.Node, 0)
+	for gid, zone := range map[string]string{"ng-a": "zone-a", "ng-b": "zone-b", "ng-c": "zone-c"} {
+		provider.AddNodeGroup(gid, 1, 10, 1)
+		node := BuildTestNode(gid+"-node", 100, 1000)
+		node.Labels[apiv1.LabelZoneFailureDomain] = zone
+		SetNodeReadyState(node, true, time.Now())
+		nodes = append(nodes, node)
+		provider.AddNode(gid, node)
+	}
+
+	pvLister, err := kube_util.NewTestPersistentVolumeLister([]*apiv1.PersistentVolume{{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-zone-b", Labels: map[string]string{apiv1.LabelZoneFailureDomain: "zone-b"}},
+	}})
+	assert.NoError(t, err)
+	pvcLister, err := kube_util.NewTestPersistentVolumeClaimLister([]*apiv1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim-zone-b", Namespace: "default"},
+		Spec:       apiv1.PersistentVolumeClaimSpec{VolumeName: "pv-zone-b"},
+	}})
+	assert.NoError(t, err)
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, pvcLister, pvLister)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:            estimator.BinpackingEstimatorName,
+		BalanceSimilarNodeGroups: true,
+		MaxCoresTotal:            config.DefaultMaxClusterCores,
+		MaxMemoryTotal:           config.DefaultMaxClusterMemory,
+	}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	pods := make([]*apiv1.Pod, 0)
+	for i := 0; i < 2; i++ {
+		pod := BuildTestPod(fmt.Sprintf("test-pod-%v", i), 80, 0)
+		pod.Spec.Volumes = []apiv1.Volume{{
+			Name: "data",
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "claim-zone-b"},
+			},
+		}}
+		pods = append(pods, pod)
+	}
+
+	scaleUpStatus, typedErr := ScaleUp(&context, ca_processors.TestProcessors(), clusterState, pods, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
+	assert.NoError(t, typedErr)
+	assert.True(t, scaleUpStatus.WasSuccessful())
+	// Similar node groups in other zones can't run the pods and are left out of balancing.
+	assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+	assert.Equal(t, "ng-b", scaleUpStatus.ScaleUpInfos[0].Group.Id())
+	assert.Equal(t, 3, scaleUpStatus.ScaleUpInfos[0].NewSize)
+}
+
 func TestScaleUpAutoprovisionedNodeGroup(t *testing.T) {
 	createdGroups := make(chan string, 10)
 	expandedGroups := make(chan string, 10)
@@ -761,22 +886,61 @@ func TestScaleUpAutoprovisionedNodeGroup(t *testing.T) {
 	}
 	context := NewScaleTestAutoscalingContext(options, fakeClient, nil, provider)
 
-	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 
 	processors := ca_processors.TestProcessors()
 	processors.NodeGroupListProcessor = &mockAutoprovisioningNodeGroupListProcessor{t}
 	processors.NodeGroupManager = &mockAutoprovisioningNodeGroupManager{t}
 
 	nodes := []*apiv1.Node{}
-	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, context.ListerRegistry, []*appsv1.DaemonSet{}, context.PredicateChecker)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, context.ListerRegistry, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 
-	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p1}, nodes, []*appsv1.DaemonSet{}, nodeInfos)
+	scaleUpStatus, err := ScaleUp(&context, processors, clusterState, []*apiv1.Pod{p1}, nodes, nil, []*appsv1.DaemonSet{}, nodeInfos)
 	assert.NoError(t, err)
 	assert.True(t, scaleUpStatus.WasSuccessful())
 	assert.Equal(t, "autoprovisioned-T1", getStringFromChan(createdGroups))
 	assert.Equal(t, "autoprovisioned-T1-1", getStringFromChan(expandedGroups))
 }
 
+func TestBuildTemplatesOfNodeGroupsToCreate(t *testing.T) {
+	t1 := BuildTestNode("t1", 4000, 1000000)
+	SetNodeReadyState(t1, true, time.Time{})
+	ti1 := schedulernodeinfo.NewNodeInfo()
+	ti1.SetNode(t1)
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil, nil, []string{"T1", "T2"},
+		map[string]*schedulernodeinfo.NodeInfo{"T1": ti1})
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	context := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, &fake.Clientset{}, nil, provider)
+
+	autoprovisioned, err := provider.NewNodeGroup("T1", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	withoutTemplate, err := provider.NewNodeGroup("T2", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	existingTemplate := schedulernodeinfo.NewNodeInfo()
+	nodeInfos := map[string]*schedulernodeinfo.NodeInfo{
+		"ng1":                existingTemplate,
+		autoprovisioned.Id(): ti1,
+		withoutTemplate.Id(): ti1,
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ds", Namespace: "kube-system"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Image: "foo/bar"}}},
+			},
+		},
+	}
+	nodeGroups := buildTemplatesOfNodeGroupsToCreate(&context, ca_processors.TestProcessors(),
+		[]cloudprovider.NodeGroup{provider.GetNodeGroup("ng1"), autoprovisioned, withoutTemplate}, nodeInfos, []*appsv1.DaemonSet{ds})
+
+	assert.Equal(t, []cloudprovider.NodeGroup{provider.GetNodeGroup("ng1"), autoprovisioned}, nodeGroups)
+	assert.Equal(t, existingTemplate, nodeInfos["ng1"])
+	assert.Equal(t, 1, len(nodeInfos[autoprovisioned.Id()].Pods()))
+	assert.NotContains(t, nodeInfos, withoutTemplate.Id())
+}
+
 func TestCheckScaleUpDeltaWithinLimits(t *testing.T) {
 	type testcase struct {
 		limits            scaleUpResourcesLimits
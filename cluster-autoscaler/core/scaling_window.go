@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+// scaleUpToScalingWindowMinSizes increases node groups whose target size is below the min size
+// raised by an active scaling window, within the cluster-wide node count and resource limits.
+// Returns the executed scale-ups. In dry-run mode nothing is executed and the scale-ups that
+// would be are returned for the caller to record.
+func scaleUpToScalingWindowMinSizes(context *context.AutoscalingContext, processors *ca_processors.AutoscalingProcessors,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, nodes []*apiv1.Node, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+	now time.Time) ([]nodegroupset.ScaleUpInfo, errors.AutoscalerError) {
+
+	nodeGroups := context.CloudProvider.NodeGroups()
+	totalSize := 0
+	for _, nodeGroup := range nodeGroups {
+		size, err := nodeGroup.TargetSize()
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		totalSize += size
+	}
+
+	var scaleUps []nodegroupset.ScaleUpInfo
+	gpuLabel := context.CloudProvider.GPULabel()
+	availableGPUTypes := context.CloudProvider.GetAvailableGPUTypes()
+	var resourceLimiter *cloudprovider.ResourceLimiter
+	var scaleUpResourcesLeft scaleUpResourcesLimits
+	for _, nodeGroup := range nodeGroups {
+		minSize := processors.ScalingWindowProcessor.GetMinSize(nodeGroup, now)
+		if minSize <= nodeGroup.MinSize() {
+			continue
+		}
+		size, err := nodeGroup.TargetSize()
+		if err != nil {
+			return scaleUps, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		if size >= minSize {
+			continue
+		}
+		if !clusterStateRegistry.IsNodeGroupSafeToScaleUp(nodeGroup, now) {
+			klog.Warningf("Skipping scaling window scale-up of %s - node group is not ready for scale-up", nodeGroup.Id())
+			continue
+		}
+		newSize := minSize
+		if context.MaxNodesTotal > 0 && totalSize+newSize-size > context.MaxNodesTotal {
+			klog.V(1).Infof("Capping scaling window scale-up of %s to max cluster total size (%d)", nodeGroup.Id(), context.MaxNodesTotal)
+			newSize = context.MaxNodesTotal - totalSize + size
+		}
+		if newSize <= size {
+			continue
+		}
+		nodeInfo, found := nodeInfos[nodeGroup.Id()]
+		if !found {
+			klog.Warningf("Skipping scaling window scale-up of %s - no node info", nodeGroup.Id())
+			continue
+		}
+
+		if scaleUpResourcesLeft == nil {
+			var typedErr errors.AutoscalerError
+			resourceLimiter, scaleUpResourcesLeft, typedErr = computeScalingWindowResourcesLeft(context, nodes, nodeInfos)
+			if typedErr != nil {
+				return scaleUps, typedErr
+			}
+		}
+		delta, typedErr := computeScaleUpResourcesDelta(context.CloudProvider, nodeInfo, nodeGroup, resourceLimiter)
+		if typedErr != nil {
+			return scaleUps, typedErr
+		}
+		if checkResult := scaleUpResourcesLeft.checkScaleUpDeltaWithinLimits(delta); checkResult.exceeded {
+			klog.V(1).Infof("Skipping scaling window scale-up of %s - max cluster limits reached for %v", nodeGroup.Id(), checkResult.exceededResources)
+			continue
+		}
+		newNodes, typedErr := applyScaleUpResourcesLimits(context.CloudProvider, newSize-size, scaleUpResourcesLeft, nodeInfo, nodeGroup, resourceLimiter)
+		if typedErr != nil {
+			return scaleUps, typedErr
+		}
+		newSize = size + newNodes
+
+		gpuType := gpu.GetGpuTypeForMetrics(gpuLabel, availableGPUTypes, nodeInfo.Node(), nodeGroup)
+		klog.V(1).Infof("Node group %s is below its scaling window min size %d", nodeGroup.Id(), minSize)
+		info := nodegroupset.ScaleUpInfo{
+			Group:       nodeGroup,
+			CurrentSize: size,
+			NewSize:     newSize,
+			MaxSize:     nodeGroup.MaxSize(),
+		}
+		if !context.DryRun {
+			if typedErr := executeScaleUp(context, clusterStateRegistry, info, gpuType, now); typedErr != nil {
+				return scaleUps, typedErr
+			}
+		}
+		totalSize += newNodes
+		for resource, resourceDelta := range delta {
+			if left, found := scaleUpResourcesLeft[resource]; found {
+				scaleUpResourcesLeft[resource] = left - int64(newNodes)*resourceDelta
+			}
+		}
+		scaleUps = append(scaleUps, info)
+	}
+	return scaleUps, nil
+}
+
+// computeScalingWindowResourcesLeft returns the resource limiter of the cloud provider and
+// the resources left below its max limits.
+func computeScalingWindowResourcesLeft(context *context.AutoscalingContext, nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulernodeinfo.NodeInfo) (*cloudprovider.ResourceLimiter, scaleUpResourcesLimits, errors.AutoscalerError) {
+	nodesFromNotAutoscaledGroups, typedErr := filterOutNodesFromNotAutoscaledGroups(nodes, context.CloudProvider)
+	if typedErr != nil {
+		return nil, nil, typedErr.AddPrefix("failed to filter out nodes which are from not autoscaled groups: ")
+	}
+	resourceLimiter, err := context.CloudProvider.GetResourceLimiter()
+	if err != nil {
+		return nil, nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+	}
+	scaleUpResourcesLeft, typedErr := computeScaleUpResourcesLeftLimits(context.CloudProvider, context.CloudProvider.NodeGroups(),
+		nodeInfos, nodesFromNotAutoscaledGroups, resourceLimiter)
+	if typedErr != nil {
+		return nil, nil, typedErr.AddPrefix("Could not compute total resources: ")
+	}
+	return resourceLimiter, scaleUpResourcesLeft, nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScalingWindows = `
+windows:
+- name: business-hours
+  nodeGroups: ["^ng1$"]
+  schedule: "0 9 * * *"
+  duration: 8h
+  minSize: 5
+  blockScaleDown: true
+- name: batch
+  nodeGroups: ["^ng2$"]
+  schedule: "0 9 * * *"
+  duration: 8h
+  minSize: 3
+`
+
+func TestScaleUpToScalingWindowMinSizes(t *testing.T) {
+	windows, err := scalingwindows.ParseConfig([]byte(testScalingWindows))
+	assert.NoError(t, err)
+	processors := ca_processors.TestProcessors()
+	processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+
+	now := time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	scaleUps := make(map[string]int)
+	provider := testprovider.NewTestCloudProvider(func(id string, delta int) error {
+		scaleUps[id] += delta
+		return nil
+	}, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNodeGroup("ng2", 0, 10, 0)
+	provider.AddNodeGroup("ng3", 0, 10, 0)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng1", n2)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false)
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{MaxNodesTotal: 6},
+		CloudProvider:      provider,
+		LogRecorder:        fakeLogRecorder,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
+		MaxNodeProvisionTime: 15 * time.Minute,
+	}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, clusterState.UpdateNodes([]*apiv1.Node{n1, n2}, nil, now))
+
+	nodeInfos := buildScalingWindowTestNodeInfos("ng1", "ng2", "ng3")
+
+	// Both node groups are scaled up, but only up to the cluster limit.
+	windowScaleUps, typedErr := scaleUpToScalingWindowMinSizes(context, processors, clusterState, []*apiv1.Node{n1, n2}, nodeInfos, now)
+	assert.NoError(t, typedErr)
+	assert.Len(t, windowScaleUps, 2)
+	assert.Len(t, scaleUps, 2)
+	assert.Equal(t, 4, scaleUps["ng1"]+scaleUps["ng2"])
+
+	// Nothing to do outside of the windows.
+	scaleUps = make(map[string]int)
+	windowScaleUps, typedErr = scaleUpToScalingWindowMinSizes(context, processors, clusterState, []*apiv1.Node{n1, n2}, nodeInfos, now.Add(8*time.Hour))
+	assert.NoError(t, typedErr)
+	assert.Empty(t, windowScaleUps)
+	assert.Empty(t, scaleUps)
+}
+
+func TestScaleUpToScalingWindowMinSizesResourceLimits(t *testing.T) {
+	windows, err := scalingwindows.ParseConfig([]byte(testScalingWindows))
+	assert.NoError(t, err)
+	processors := ca_processors.TestProcessors()
+	processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+
+	now := time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	scaleUps := make(map[string]int)
+	provider := testprovider.NewTestCloudProvider(func(id string, delta int) error {
+		scaleUps[id] += delta
+		return nil
+	}, nil)
+	provider.SetResourceLimiter(cloudprovider.NewResourceLimiter(
+		map[string]int64{cloudprovider.ResourceNameCores: 0, cloudprovider.ResourceNameMemory: 0},
+		map[string]int64{cloudprovider.ResourceNameCores: 4, cloudprovider.ResourceNameMemory: 1000000}))
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNodeGroup("ng2", 0, 10, 0)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng1", n2)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false)
+	context := &context.AutoscalingContext{
+		CloudProvider: provider,
+		LogRecorder:   fakeLogRecorder,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
+		MaxNodeProvisionTime: 15 * time.Minute,
+	}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, clusterState.UpdateNodes([]*apiv1.Node{n1, n2}, nil, now))
+
+	// Only 2 of the 6 requested cores are left below the cluster limit.
+	windowScaleUps, typedErr := scaleUpToScalingWindowMinSizes(context, processors, clusterState, []*apiv1.Node{n1, n2},
+		buildScalingWindowTestNodeInfos("ng1", "ng2"), now)
+	assert.NoError(t, typedErr)
+	assert.NotEmpty(t, windowScaleUps)
+	assert.Equal(t, 2, scaleUps["ng1"]+scaleUps["ng2"])
+}
+
+func TestScaleUpToScalingWindowMinSizesDryRun(t *testing.T) {
+	windows, err := scalingwindows.ParseConfig([]byte(testScalingWindows))
+	assert.NoError(t, err)
+	processors := ca_processors.TestProcessors()
+	processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+
+	now := time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+
+	provider := testprovider.NewTestCloudProvider(func(id string, delta int) error {
+		return fmt.Errorf("unexpected scale-up of %s by %d", id, delta)
+	}, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false)
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{DryRun: true},
+		CloudProvider:      provider,
+		LogRecorder:        fakeLogRecorder,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
+		MaxNodeProvisionTime: 15 * time.Minute,
+	}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, clusterState.UpdateNodes([]*apiv1.Node{n1}, nil, now))
+
+	// The scale-up is only returned to be recorded.
+	windowScaleUps, typedErr := scaleUpToScalingWindowMinSizes(context, processors, clusterState, []*apiv1.Node{n1},
+		buildScalingWindowTestNodeInfos("ng1"), now)
+	assert.NoError(t, typedErr)
+	assert.Len(t, windowScaleUps, 1)
+	assert.Equal(t, 5, windowScaleUps[0].NewSize)
+	targetSize, _ := provider.GetNodeGroup("ng1").TargetSize()
+	assert.Equal(t, 1, targetSize)
+	assert.Empty(t, clusterState.GetUpcomingNodes())
+}
+
+func buildScalingWindowTestNodeInfos(nodeGroups ...string) map[string]*schedulernodeinfo.NodeInfo {
+	nodeInfos := make(map[string]*schedulernodeinfo.NodeInfo)
+	for _, nodeGroup := range nodeGroups {
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(BuildTestNode(nodeGroup+"-template", 1000, 1000))
+		nodeInfos[nodeGroup] = nodeInfo
+	}
+	return nodeInfos
+}
+
+func TestGetPotentiallyUnneededNodesWithScalingWindows(t *testing.T) {
+	windows, err := scalingwindows.ParseConfig([]byte(testScalingWindows))
+	assert.NoError(t, err)
+	processors := ca_processors.TestProcessors()
+	processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	ng2_1 := BuildTestNode("ng2-1", 1000, 1000)
+	ng2_2 := BuildTestNode("ng2-2", 1000, 1000)
+	ng2_3 := BuildTestNode("ng2-3", 1000, 1000)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 3)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng2", ng2_1)
+	provider.AddNode("ng2", ng2_2)
+	provider.AddNode("ng2", ng2_3)
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{ScaleDownEnabled: true},
+		CloudProvider:      provider,
+	}
+	nodes := []*apiv1.Node{ng1_1, ng2_1, ng2_2, ng2_3}
+
+	inWindow := time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)
+	assert.Empty(t, getPotentiallyUnneededNodes(context, processors, nodes, inWindow))
+
+	outsideWindow := time.Date(2019, 6, 3, 20, 0, 0, 0, time.UTC)
+	assert.Len(t, getPotentiallyUnneededNodes(context, processors, nodes, outsideWindow), 4)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// iterationState holds objects observed during a single autoscaler iteration.
+// Fields are left empty if the iteration ended before computing them.
+type iterationState struct {
+	time               time.Time
+	allNodes           []*apiv1.Node
+	nodeInfosForGroups map[string]*schedulernodeinfo.NodeInfo
+	scheduledPods      []*apiv1.Pod
+	unschedulablePods  []*apiv1.Pod
+	podsToHelp         []*apiv1.Pod
+}
+
+// ExportSnapshot returns the cluster as seen by the last iteration of the autoscaler.
+// Workload controllers and pod disruption budgets are listed at the time of the call.
+// If an iteration is in progress, the call blocks until it finishes.
+func (a *StaticAutoscaler) ExportSnapshot() (*snapshot.ClusterSnapshot, error) {
+	a.iterationLock.Lock()
+	defer a.iterationLock.Unlock()
+
+	state := a.lastIteration
+	result := snapshot.NewClusterSnapshot(time.Now())
+	result.Nodes = state.allNodes
+	result.Pods = append(append([]*apiv1.Pod{}, state.scheduledPods...), state.unschedulablePods...)
+
+	if err := a.listWorkloads(result); err != nil {
+		return nil, err
+	}
+
+	nodesInGroups := make(map[string][]string)
+	for _, node := range state.allNodes {
+		nodeGroup, err := a.CloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node group for %s: %v", node.Name, err)
+		}
+		if nodeGroup == nil {
+			continue
+		}
+		nodesInGroups[nodeGroup.Id()] = append(nodesInGroups[nodeGroup.Id()], node.Name)
+	}
+
+	autoscalerState := &snapshot.AutoscalerState{
+		LastIterationTime: state.time,
+		UnschedulablePods: podKeys(state.unschedulablePods),
+		PodsToHelp:        podKeys(state.podsToHelp),
+	}
+	for _, nodeGroup := range a.CloudProvider.NodeGroups() {
+		id := nodeGroup.Id()
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get target size of %s: %v", id, err)
+		}
+		exported := &snapshot.NodeGroup{
+			Id:         id,
+			MinSize:    nodeGroup.MinSize(),
+			MaxSize:    nodeGroup.MaxSize(),
+			TargetSize: targetSize,
+			Nodes:      nodesInGroups[id],
+		}
+		if nodeInfo, found := state.nodeInfosForGroups[id]; found {
+			exported.Template = &snapshot.NodeTemplate{Node: nodeInfo.Node(), Pods: nodeInfo.Pods()}
+		}
+		if exported.Template == nil && len(exported.Nodes) == 0 {
+			klog.Warningf("Skipping node group %s in snapshot: no template and no nodes", id)
+			continue
+		}
+		result.NodeGroups = append(result.NodeGroups, exported)
+
+		readiness, _ := a.clusterStateRegistry.GetNodeGroupReadiness(id)
+		acceptable, _ := a.clusterStateRegistry.GetAcceptableRange(id)
+		autoscalerState.NodeGroups = append(autoscalerState.NodeGroups, &snapshot.NodeGroupState{
+			Id:                id,
+			Healthy:           a.clusterStateRegistry.IsNodeGroupHealthy(id),
+			BackedOff:         a.clusterStateRegistry.IsNodeGroupBackedOff(nodeGroup, state.time),
+			ScaleUpInProgress: a.clusterStateRegistry.IsNodeGroupScalingUp(id),
+			Readiness: snapshot.Readiness{
+				Ready:            readiness.Ready,
+				Unready:          readiness.Unready,
+				Deleted:          readiness.Deleted,
+				LongNotStarted:   readiness.LongNotStarted,
+				NotStarted:       readiness.NotStarted,
+				Registered:       readiness.Registered,
+				LongUnregistered: readiness.LongUnregistered,
+				Unregistered:     readiness.Unregistered,
+			},
+			MinNodes:      acceptable.MinNodes,
+			MaxNodes:      acceptable.MaxNodes,
+			CurrentTarget: acceptable.CurrentTarget,
+		})
+	}
+
+	for _, unregistered := range a.clusterStateRegistry.GetUnregisteredNodes() {
+		autoscalerState.UnregisteredNodes = append(autoscalerState.UnregisteredNodes, &snapshot.UnregisteredNode{
+			Name:              unregistered.Node.Name,
+			UnregisteredSince: unregistered.UnregisteredSince,
+		})
+	}
+	for name, since := range a.scaleDown.unneededNodes {
+		autoscalerState.UnneededNodes = append(autoscalerState.UnneededNodes, &snapshot.UnneededNode{
+			Name:          name,
+			UnneededSince: since,
+		})
+	}
+	sort.Slice(autoscalerState.UnneededNodes, func(i, j int) bool {
+		return autoscalerState.UnneededNodes[i].Name < autoscalerState.UnneededNodes[j].Name
+	})
+	result.Autoscaler = autoscalerState
+
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("exported snapshot is invalid: %v", err)
+	}
+	return result, nil
+}
+
+// listWorkloads adds pod disruption budgets and workload controllers to the snapshot.
+func (a *StaticAutoscaler) listWorkloads(result *snapshot.ClusterSnapshot) error {
+	var err error
+	if result.PodDisruptionBudgets, err = a.PodDisruptionBudgetLister().List(); err != nil {
+		return fmt.Errorf("failed to list pod disruption budgets: %v", err)
+	}
+	if result.DaemonSets, err = a.DaemonSetLister().List(labels.Everything()); err != nil {
+		return fmt.Errorf("failed to list daemon sets: %v", err)
+	}
+	if result.ReplicationControllers, err = a.ReplicationControllerLister().List(labels.Everything()); err != nil {
+		return fmt.Errorf("failed to list replication controllers: %v", err)
+	}
+	if result.Jobs, err = a.JobLister().List(labels.Everything()); err != nil {
+		return fmt.Errorf("failed to list jobs: %v", err)
+	}
+	if result.ReplicaSets, err = a.ReplicaSetLister().List(labels.Everything()); err != nil {
+		return fmt.Errorf("failed to list replica sets: %v", err)
+	}
+	if result.StatefulSets, err = a.StatefulSetLister().List(labels.Everything()); err != nil {
+		return fmt.Errorf("failed to list stateful sets: %v", err)
+	}
+	return nil
+}
+
+func podKeys(pods []*apiv1.Pod) []string {
+	keys := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		keys = append(keys, pod.Namespace+"/"+pod.Name)
+	}
+	return keys
+}
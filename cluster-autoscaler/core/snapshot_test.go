@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes/fake"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportSnapshot(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	p1 := BuildTestPod("p1", 600, 100)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 700, 100)
+	p3 := BuildTestPod("p3", 400, 100)
+	p3.Spec.NodeName = "n2"
+	p3.OwnerReferences = GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+
+	tn := BuildTestNode("tn", 1000, 1000)
+	tni := schedulernodeinfo.NewNodeInfo()
+	tni.SetNode(tn)
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(
+		func(id string, delta int) error { return nil },
+		func(id string, name string) error { return nil },
+		nil, nil,
+		nil, map[string]*schedulernodeinfo.NodeInfo{"ng1": tni})
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng1", n2)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:                       estimator.BinpackingEstimatorName,
+		ScaleDownEnabled:                    true,
+		ScaleDownUtilizationThreshold:       0.5,
+		MaxNodesTotal:                       2,
+		MaxCoresTotal:                       10,
+		MaxMemoryTotal:                      100000,
+		ScaleDownUnreadyTime:                time.Minute,
+		ScaleDownUnneededTime:               time.Minute,
+		FilterOutSchedulablePodsUsesPacking: true,
+	}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
+	daemonSetLister, err := kube_util.NewTestDaemonSetLister([]*appsv1.DaemonSet{})
+	assert.NoError(t, err)
+	rcLister, err := kube_util.NewTestReplicationControllerLister([]*apiv1.ReplicationController{})
+	assert.NoError(t, err)
+	jobLister, err := kube_util.NewTestJobLister([]*batchv1.Job{})
+	assert.NoError(t, err)
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{})
+	assert.NoError(t, err)
+	ssLister, err := kube_util.NewTestStatefulSetLister([]*appsv1.StatefulSet{})
+	assert.NoError(t, err)
+	nodeLister := kube_util.NewTestNodeLister([]*apiv1.Node{n1, n2})
+	context.ListerRegistry = kube_util.NewListerRegistry(nodeLister, nodeLister,
+		kube_util.NewTestPodLister([]*apiv1.Pod{p1, p3}), kube_util.NewTestPodLister([]*apiv1.Pod{p2}),
+		kube_util.NewTestPodDisruptionBudgetLister(nil), daemonSetLister,
+		rcLister, jobLister, rsLister, ssLister, nil, nil)
+
+	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
+		OkTotalUnreadyCount:  1,
+		MaxNodeProvisionTime: 10 * time.Second,
+	}
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterStateConfig, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	autoscaler := &StaticAutoscaler{
+		AutoscalingContext:    &context,
+		clusterStateRegistry:  clusterState,
+		lastScaleUpTime:       now,
+		lastScaleDownFailTime: now,
+		scaleDown:             NewScaleDown(&context, ca_processors.TestProcessors(), clusterState),
+		processors:            ca_processors.TestProcessors(),
+		initialized:           true,
+		nodeInfoCache:         make(map[string]*schedulernodeinfo.NodeInfo),
+	}
+
+	// Max nodes total reached, p2 stays pending and n2 is unneeded.
+	err = autoscaler.RunOnce(now)
+	assert.NoError(t, err)
+
+	exported, err := autoscaler.ExportSnapshot()
+	assert.NoError(t, err)
+	assert.Len(t, exported.Nodes, 2)
+	assert.Len(t, exported.Pods, 3)
+	assert.Len(t, exported.NodeGroups, 1)
+	assert.Equal(t, []string{"n1", "n2"}, exported.NodeGroups[0].Nodes)
+	assert.Equal(t, 2, exported.NodeGroups[0].TargetSize)
+	assert.NotNil(t, exported.NodeGroups[0].Template)
+
+	state := exported.Autoscaler
+	assert.NotNil(t, state)
+	assert.Equal(t, now, state.LastIterationTime)
+	assert.Equal(t, []string{"default/p2"}, state.UnschedulablePods)
+	assert.Equal(t, []string{"default/p2"}, state.PodsToHelp)
+	assert.Len(t, state.NodeGroups, 1)
+	assert.True(t, state.NodeGroups[0].Healthy)
+	assert.Equal(t, 2, state.NodeGroups[0].Readiness.Ready)
+	assert.Len(t, state.UnneededNodes, 1)
+	assert.Equal(t, "n2", state.UnneededNodes[0].Name)
+
+	// The exported snapshot can be loaded back.
+	var buf bytes.Buffer
+	assert.NoError(t, exported.Write(&buf))
+	loaded, err := snapshot.Load(&buf)
+	assert.NoError(t, err)
+	assert.NoError(t, loaded.Validate())
+	assert.Len(t, loaded.Autoscaler.UnneededNodes, 1)
+
+	// Virtual headroom pods are not exported.
+	cpu := resource.MustParse("500m")
+	autoscaler.processors.PodListProcessor = headroom.NewHeadroomPodListProcessor([]headroom.Spec{{Name: "spare", CPU: &cpu, Replicas: 2}})
+	err = autoscaler.RunOnce(now.Add(time.Minute))
+	assert.NoError(t, err)
+
+	exported, err = autoscaler.ExportSnapshot()
+	assert.NoError(t, err)
+	assert.Len(t, exported.Pods, 3)
+	for _, pod := range exported.Pods {
+		assert.False(t, headroom.IsHeadroomPod(pod), "headroom pod %s exported", pod.Name)
+	}
+	assert.Equal(t, []string{"default/p2"}, exported.Autoscaler.UnschedulablePods)
+	assert.Equal(t, []string{"default/p2"}, exported.Autoscaler.PodsToHelp)
+	assert.NoError(t, exported.Validate())
+}
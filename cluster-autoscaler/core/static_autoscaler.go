@@ -18,6 +18,7 @@ package core
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
@@ -65,10 +67,18 @@ type StaticAutoscaler struct {
 	lastScaleDownDeleteTime time.Time
 	lastScaleDownFailTime   time.Time
 	scaleDown               *ScaleDown
+	consolidation           *Consolidation
 	processors              *ca_processors.AutoscalingProcessors
 	initialized             bool
 	// Caches nodeInfo computed for previously seen nodes
 	nodeInfoCache map[string]*schedulernodeinfo.NodeInfo
+	// Scale-ups recorded in dry-run mode, so that they are not recorded again in every loop
+	dryRunScaleUps map[string]string
+	// Scaling window scale-ups recorded in dry-run mode, so that they are not recorded again in every loop
+	dryRunWindowScaleUps map[string]string
+	// Held for the duration of RunOnce, so that snapshots are never exported mid-iteration.
+	iterationLock sync.Mutex
+	lastIteration iterationState
 }
 
 // NewStaticAutoscaler creates an instance of Autoscaler filled with provided parameters
@@ -84,13 +94,15 @@ func NewStaticAutoscaler(
 	autoscalingContext := context.NewAutoscalingContext(opts, predicateChecker, autoscalingKubeClients, cloudProvider, expanderStrategy, estimatorBuilder)
 
 	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
-		MaxTotalUnreadyPercentage: opts.MaxTotalUnreadyPercentage,
-		OkTotalUnreadyCount:       opts.OkTotalUnreadyCount,
-		MaxNodeProvisionTime:      opts.MaxNodeProvisionTime,
+		MaxTotalUnreadyPercentage:       opts.MaxTotalUnreadyPercentage,
+		OkTotalUnreadyCount:             opts.OkTotalUnreadyCount,
+		MaxNodeProvisionTime:            opts.MaxNodeProvisionTime,
+		AdaptiveNodeProvisionTimeFactor: opts.AdaptiveNodeProvisionTimeFactor,
 	}
-	clusterStateRegistry := clusterstate.NewClusterStateRegistry(autoscalingContext.CloudProvider, clusterStateConfig, autoscalingContext.LogRecorder, backoff)
+	clusterStateRegistry := clusterstate.NewClusterStateRegistry(autoscalingContext.CloudProvider, clusterStateConfig, autoscalingContext.LogRecorder, backoff,
+		processors.NodeGroupConfigProcessor)
 
-	scaleDown := NewScaleDown(autoscalingContext, clusterStateRegistry)
+	scaleDown := NewScaleDown(autoscalingContext, processors, clusterStateRegistry)
 
 	return &StaticAutoscaler{
 		AutoscalingContext:      autoscalingContext,
@@ -99,6 +111,7 @@ func NewStaticAutoscaler(
 		lastScaleDownDeleteTime: time.Now(),
 		lastScaleDownFailTime:   time.Now(),
 		scaleDown:               scaleDown,
+		consolidation:           NewConsolidation(scaleDown),
 		processors:              processors,
 		clusterStateRegistry:    clusterStateRegistry,
 		nodeInfoCache:           make(map[string]*schedulernodeinfo.NodeInfo),
@@ -115,6 +128,12 @@ func (a *StaticAutoscaler) cleanUpIfRequired() {
 	// CA can die at any time. Removing taints that might have been left from the previous run.
 	if readyNodes, err := a.ReadyNodeLister().List(); err != nil {
 		klog.Errorf("Failed to list ready nodes, not cleaning up taints: %v", err)
+	} else if a.DryRun {
+		for _, node := range readyNodes {
+			if deletetaint.HasToBeDeletedTaint(node) || deletetaint.HasDeletionCandidateTaint(node) {
+				recordDryRunAction(a.AutoscalingContext, metrics.DryRunUntaint, 1, "would remove scale-down taints from node %s", node.Name)
+			}
+		}
 	} else {
 		deletetaint.CleanAllToBeDeleted(readyNodes, a.AutoscalingContext.ClientSet, a.Recorder)
 		if a.AutoscalingContext.AutoscalingOptions.MaxBulkSoftTaintCount == 0 {
@@ -127,6 +146,10 @@ func (a *StaticAutoscaler) cleanUpIfRequired() {
 
 // RunOnce iterates over node groups and scales them up/down if necessary
 func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError {
+	a.iterationLock.Lock()
+	defer a.iterationLock.Unlock()
+	a.lastIteration = iterationState{time: currentTime}
+
 	a.cleanUpIfRequired()
 
 	unschedulablePodLister := a.UnschedulablePodLister()
@@ -142,6 +165,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 	if typedErr != nil {
 		return typedErr
 	}
+	a.lastIteration.allNodes = allNodes
 	if a.actOnEmptyCluster(allNodes, readyNodes, currentTime) {
 		return nil
 	}
@@ -158,12 +182,15 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		klog.Errorf("Failed to refresh cloud provider config: %v", err)
 		return errors.ToAutoscalerError(errors.CloudProviderError, err)
 	}
+	a.processors.NodeGroupConfigProcessor.Refresh()
 
 	nodeInfosForGroups, autoscalerError := getNodeInfosForGroups(
-		readyNodes, a.nodeInfoCache, autoscalingContext.CloudProvider, autoscalingContext.ListerRegistry, daemonsets, autoscalingContext.PredicateChecker)
+		readyNodes, a.nodeInfoCache, autoscalingContext.CloudProvider, autoscalingContext.ListerRegistry, daemonsets, autoscalingContext.PredicateChecker,
+		a.processors.TemplateNodeInfoProcessor)
 	if autoscalerError != nil {
 		return autoscalerError.AddPrefix("failed to build node infos for node groups: ")
 	}
+	a.lastIteration.nodeInfosForGroups = nodeInfosForGroups
 
 	typedErr = a.updateClusterState(allNodes, nodeInfosForGroups, currentTime)
 	if typedErr != nil {
@@ -180,8 +207,12 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		// Update status information when the loop is done (regardless of reason)
 		if autoscalingContext.WriteStatusConfigMap {
 			status := a.clusterStateRegistry.GetStatus(currentTime)
+			statusMessage := status.GetReadableString()
+			if autoscalingContext.DryRun {
+				statusMessage += dryRunStatusString(scaleUpStatus, scaleDownStatus)
+			}
 			utils.WriteStatusConfigMap(autoscalingContext.ClientSet, autoscalingContext.ConfigNamespace,
-				status.GetReadableString(), a.AutoscalingContext.LogRecorder)
+				statusMessage, a.AutoscalingContext.LogRecorder)
 		}
 
 		// This deferred processor execution allows the processors to handle a situation when a scale-(up|down)
@@ -204,7 +235,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 	unregisteredNodes := a.clusterStateRegistry.GetUnregisteredNodes()
 	if len(unregisteredNodes) > 0 {
 		klog.V(1).Infof("%d unregistered nodes present", len(unregisteredNodes))
-		removedAny, err := removeOldUnregisteredNodes(unregisteredNodes, autoscalingContext, currentTime, autoscalingContext.LogRecorder)
+		removedAny, err := removeOldUnregisteredNodes(unregisteredNodes, autoscalingContext, a.clusterStateRegistry, currentTime, autoscalingContext.LogRecorder)
 		// There was a problem with removing unregistered nodes. Retry in the next loop.
 		if err != nil {
 			if removedAny {
@@ -234,7 +265,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 	// Check if there has been a constant difference between the number of nodes in k8s and
 	// the number of nodes on the cloud provider side.
 	// TODO: andrewskim - add protection for ready AWS nodes.
-	fixedSomething, err := fixNodeGroupSize(autoscalingContext, a.clusterStateRegistry, currentTime)
+	fixedSomething, err := fixNodeGroupSize(autoscalingContext, a.clusterStateRegistry, a.processors.NodeGroupConfigProcessor, currentTime)
 	if err != nil {
 		klog.Errorf("Failed to fix node group sizes: %v", err)
 		return errors.ToAutoscalerError(errors.CloudProviderError, err)
@@ -244,6 +275,22 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		return nil
 	}
 
+	windowScaleUps, typedErr := scaleUpToScalingWindowMinSizes(autoscalingContext, a.processors, a.clusterStateRegistry, readyNodes, nodeInfosForGroups, currentTime)
+	if typedErr != nil {
+		klog.Errorf("Failed to scale up node groups to scaling window min sizes: %v", typedErr)
+		return typedErr
+	}
+	if a.DryRun {
+		// No nodes were added, so the iteration goes on as if the node groups were at their window min sizes.
+		a.dryRunWindowScaleUps = recordDryRunScaleUps(autoscalingContext,
+			&status.ScaleUpStatus{Result: status.ScaleUpSuccessful, ScaleUpInfos: windowScaleUps}, a.dryRunWindowScaleUps)
+	} else if len(windowScaleUps) > 0 {
+		klog.V(0).Infof("Some node groups were scaled up to scaling window min sizes, skipping the iteration")
+		a.lastScaleUpTime = currentTime
+		scaleDownStatus.Result = status.ScaleDownInCooldown
+		return nil
+	}
+
 	metrics.UpdateLastTime(metrics.Autoscaling, time.Now())
 
 	allUnschedulablePods, err := unschedulablePodLister.List()
@@ -259,6 +306,10 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		return errors.ToAutoscalerError(errors.ApiCallError, err)
 	}
 
+	// Snapshots hold the pods as listed, without headroom and forecast pods added by the processors.
+	a.lastIteration.scheduledPods = allScheduled
+	a.lastIteration.unschedulablePods = allUnschedulablePods
+
 	allUnschedulablePods, allScheduled, err = a.processors.PodListProcessor.Process(a.AutoscalingContext, allUnschedulablePods, allScheduled, allNodes)
 	if err != nil {
 		klog.Errorf("Failed to process pod list: %v", err)
@@ -312,9 +363,11 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 
 	// finally, filter out pods that are too "young" to safely be considered for a scale-up (delay is configurable)
 	unschedulablePodsToHelp = a.filterOutYoungPods(unschedulablePodsToHelp, currentTime)
+	a.lastIteration.podsToHelp = headroom.FilterOutHeadroomPods(unschedulablePodsToHelp)
 
 	if len(unschedulablePodsToHelp) == 0 {
 		scaleUpStatus.Result = status.ScaleUpNotNeeded
+		a.dryRunScaleUps = nil
 		klog.V(1).Info("No unschedulable pods")
 	} else if a.MaxNodesTotal > 0 && len(readyNodes) >= a.MaxNodesTotal {
 		scaleUpStatus.Result = status.ScaleUpNoOptionsAvailable
@@ -331,10 +384,14 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		scaleUpStart := time.Now()
 		metrics.UpdateLastTime(metrics.ScaleUp, scaleUpStart)
 
-		scaleUpStatus, typedErr = ScaleUp(autoscalingContext, a.processors, a.clusterStateRegistry, unschedulablePodsToHelp, readyNodes, daemonsets, nodeInfosForGroups)
+		scaleUpStatus, typedErr = ScaleUp(autoscalingContext, a.processors, a.clusterStateRegistry, unschedulablePodsToHelp, readyNodes, allScheduled, daemonsets, nodeInfosForGroups)
 
 		metrics.UpdateDurationFromStart(metrics.ScaleUp, scaleUpStart)
 
+		if a.DryRun {
+			a.dryRunScaleUps = recordDryRunScaleUps(autoscalingContext, scaleUpStatus, a.dryRunScaleUps)
+		}
+
 		if a.processors != nil && a.processors.ScaleUpStatusProcessor != nil {
 			a.processors.ScaleUpStatusProcessor.Process(autoscalingContext, scaleUpStatus)
 			scaleUpStatusProcessorAlreadyCalled = true
@@ -344,7 +401,8 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 			klog.Errorf("Failed to scale up: %v", typedErr)
 			return typedErr
 		}
-		if scaleUpStatus.Result == status.ScaleUpSuccessful {
+		// In dry-run mode no nodes were added, so scale-down is simulated as if there was no scale-up.
+		if scaleUpStatus.Result == status.ScaleUpSuccessful && !a.DryRun {
 			a.lastScaleUpTime = currentTime
 			// No scale down in this iteration.
 			scaleDownStatus.Result = status.ScaleDownInCooldown
@@ -352,7 +410,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		}
 	}
 
-	if a.ScaleDownEnabled {
+	if a.isScaleDownEnabled() {
 		pdbs, err := pdbLister.List()
 		if err != nil {
 			scaleDownStatus.Result = status.ScaleDownError
@@ -365,7 +423,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 		klog.V(4).Infof("Calculating unneeded nodes")
 
 		scaleDown.CleanUp(currentTime)
-		potentiallyUnneeded := getPotentiallyUnneededNodes(autoscalingContext, allNodes)
+		potentiallyUnneeded := getPotentiallyUnneededNodes(autoscalingContext, a.processors, allNodes, currentTime)
 
 		typedErr := scaleDown.UpdateUnneededNodes(allNodes, potentiallyUnneeded, append(allScheduled, unschedulableWaitingForLowerPriorityPreemption...), currentTime, pdbs)
 		if typedErr != nil {
@@ -386,28 +444,31 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 			a.lastScaleUpTime.Add(a.ScaleDownDelayAfterAdd).After(currentTime) ||
 			a.lastScaleDownFailTime.Add(a.ScaleDownDelayAfterFailure).After(currentTime) ||
 			a.lastScaleDownDeleteTime.Add(a.ScaleDownDelayAfterDelete).After(currentTime)
+		// New drains are started only while fewer than MaxDrainParallelism nodes are being deleted.
+		drainsAtLimit := scaleDown.drainSlotsLeft() <= 0
 		// In dry run only utilization is updated
-		calculateUnneededOnly := scaleDownInCooldown || scaleDown.nodeDeleteStatus.IsDeleteInProgress()
+		calculateUnneededOnly := scaleDownInCooldown || drainsAtLimit
 
 		klog.V(4).Infof("Scale down status: unneededOnly=%v lastScaleUpTime=%s "+
-			"lastScaleDownDeleteTime=%v lastScaleDownFailTime=%s scaleDownForbidden=%v isDeleteInProgress=%v",
+			"lastScaleDownDeleteTime=%v lastScaleDownFailTime=%s scaleDownForbidden=%v deletionsInProgress=%v",
 			calculateUnneededOnly, a.lastScaleUpTime, a.lastScaleDownDeleteTime, a.lastScaleDownFailTime,
-			scaleDownForbidden, scaleDown.nodeDeleteStatus.IsDeleteInProgress())
+			scaleDownForbidden, scaleDown.nodeDeleteStatus.DeletionsInProgress())
 
 		if scaleDownInCooldown {
 			scaleDownStatus.Result = status.ScaleDownInCooldown
-		} else if scaleDown.nodeDeleteStatus.IsDeleteInProgress() {
+		} else if drainsAtLimit {
 			scaleDownStatus.Result = status.ScaleDownInProgress
 		} else {
 			klog.V(4).Infof("Starting scale down")
 
 			// We want to delete unneeded Node Groups only if there was no recent scale up,
-			// and there is no current delete in progress and there was no recent errors.
+			// the limit of parallel drains isn't reached and there was no recent errors.
+			// In dry-run mode the manager only records the node groups it would delete.
 			a.processors.NodeGroupManager.RemoveUnneededNodeGroups(autoscalingContext)
 
 			scaleDownStart := time.Now()
 			metrics.UpdateLastTime(metrics.ScaleDown, scaleDownStart)
-			scaleDownStatus, typedErr := scaleDown.TryToScaleDown(allNodes, allScheduled, pdbs, currentTime)
+			scaleDownStatus, typedErr = scaleDown.TryToScaleDown(allNodes, allScheduled, pdbs, currentTime)
 			metrics.UpdateDurationFromStart(metrics.ScaleDown, scaleDownStart)
 
 			if scaleDownStatus.Result == status.ScaleDownNodeDeleted {
@@ -421,6 +482,14 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) errors.AutoscalerError
 				scaleDown.SoftTaintUnneededNodes(allNodes)
 			}
 
+			if typedErr == nil && (a.ConsolidationEnabled || a.SpotFallbackEnabled) &&
+				(scaleDownStatus.Result == status.ScaleDownNoNodeDeleted ||
+					scaleDownStatus.Result == status.ScaleDownNoUnneeded) {
+				if err := a.consolidation.TryToConsolidate(allNodes, allScheduled, pdbs, nodeInfosForGroups, currentTime); err != nil {
+					klog.Errorf("Failed to consolidate nodes: %v", err)
+				}
+			}
+
 			if a.processors != nil && a.processors.ScaleDownStatusProcessor != nil {
 				a.processors.ScaleDownStatusProcessor.Process(autoscalingContext, scaleDownStatus)
 				scaleDownStatusProcessorAlreadyCalled = true
@@ -464,6 +533,9 @@ func (a *StaticAutoscaler) deleteCreatedNodesWithErrors() {
 		nodeGroup := nodeGroups[nodeGroupId]
 		if nodeGroup == nil {
 			err = fmt.Errorf("Node group %s not found", nodeGroup)
+		} else if a.DryRun {
+			recordDryRunAction(a.AutoscalingContext, metrics.DryRunScaleDown, len(nodesToBeDeleted),
+				"would delete %d nodes from node group %s because of create errors", len(nodesToBeDeleted), nodeGroupId)
 		} else {
 			err = nodeGroup.DeleteNodes(nodesToBeDeleted)
 		}
@@ -576,3 +648,17 @@ func allPodsAreNew(pods []*apiv1.Pod, currentTime time.Time) bool {
 	found, oldest := getOldestCreateTimeWithGpu(pods)
 	return found && oldest.Add(unschedulablePodWithGpuTimeBuffer).After(currentTime)
 }
+
+// isScaleDownEnabled returns true if scale-down is enabled globally or for at least one node group.
+func (a *StaticAutoscaler) isScaleDownEnabled() bool {
+	if a.ScaleDownEnabled {
+		return true
+	}
+	defaults := a.NodeGroupDefaults()
+	for _, nodeGroup := range a.CloudProvider.NodeGroups() {
+		if a.processors.NodeGroupConfigProcessor.GetOptions(nodeGroup, defaults).ScaleDownEnabled {
+			return true
+		}
+	}
+	return false
+}
@@ -30,6 +30,9 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/daemonset"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
@@ -237,6 +240,14 @@ func checkPodsSchedulableOnNode(context *context.AutoscalingContext, pods []*api
 				klog.V(2).Infof("Pod %s can't be scheduled on %s, predicate failed: %v", pod.Name, nodeGroupId, err.VerboseError())
 			}
 		}
+		// Pods of the same controller can use volumes in different zones, so the volume
+		// topology is checked for each pod separately.
+		if err == nil {
+			if err = simulator.CheckVolumeTopology(pod, nodeInfo.Node(), context.ListerRegistry); err != nil {
+				schedulingErrors[pod] = err
+				glogx.V(2).UpTo(loggingQuota).Infof("Pod %s can't be scheduled on %s: %v", pod.Name, nodeGroupId, err.VerboseError())
+			}
+		}
 	}
 
 	glogx.V(2).Over(loggingQuota).Infof("%v other pods can't be scheduled on %s.", -loggingQuota.Left(), nodeGroupId)
@@ -248,7 +259,7 @@ func checkPodsSchedulableOnNode(context *context.AutoscalingContext, pods []*api
 //
 // TODO(mwielgus): Review error policy - sometimes we may continue with partial errors.
 func getNodeInfosForGroups(nodes []*apiv1.Node, nodeInfoCache map[string]*schedulernodeinfo.NodeInfo, cloudProvider cloudprovider.CloudProvider, listers kube_util.ListerRegistry,
-	daemonsets []*appsv1.DaemonSet, predicateChecker *simulator.PredicateChecker) (map[string]*schedulernodeinfo.NodeInfo, errors.AutoscalerError) {
+	daemonsets []*appsv1.DaemonSet, predicateChecker *simulator.PredicateChecker, templateNodeInfoProcessor nodeinfos.TemplateNodeInfoProcessor) (map[string]*schedulernodeinfo.NodeInfo, errors.AutoscalerError) {
 	result := make(map[string]*schedulernodeinfo.NodeInfo)
 	seenGroups := make(map[string]bool)
 
@@ -312,7 +323,7 @@ func getNodeInfosForGroups(nodes []*apiv1.Node, nodeInfoCache map[string]*schedu
 
 		// No good template, trying to generate one. This is called only if there are no
 		// working nodes in the node groups. By default CA tries to use a real-world example.
-		nodeInfo, err := getNodeInfoFromTemplate(nodeGroup, daemonsets, predicateChecker)
+		nodeInfo, err := getNodeInfoFromTemplate(nodeGroup, daemonsets, predicateChecker, templateNodeInfoProcessor)
 		if err != nil {
 			if err == cloudprovider.ErrNotImplemented {
 				continue
@@ -353,13 +364,23 @@ func getNodeInfosForGroups(nodes []*apiv1.Node, nodeInfoCache map[string]*schedu
 	return result, nil
 }
 
-// getNodeInfoFromTemplate returns NodeInfo object built base on TemplateNodeInfo returned by NodeGroup.TemplateNodeInfo().
-func getNodeInfoFromTemplate(nodeGroup cloudprovider.NodeGroup, daemonsets []*appsv1.DaemonSet, predicateChecker *simulator.PredicateChecker) (*schedulernodeinfo.NodeInfo, errors.AutoscalerError) {
+// getNodeInfoFromTemplate returns NodeInfo object built base on TemplateNodeInfo returned by NodeGroup.TemplateNodeInfo(),
+// adjusted by templateNodeInfoProcessor. If the cloud provider can't build templates, templateNodeInfoProcessor
+// is asked to build one.
+func getNodeInfoFromTemplate(nodeGroup cloudprovider.NodeGroup, daemonsets []*appsv1.DaemonSet, predicateChecker *simulator.PredicateChecker,
+	templateNodeInfoProcessor nodeinfos.TemplateNodeInfoProcessor) (*schedulernodeinfo.NodeInfo, errors.AutoscalerError) {
 	id := nodeGroup.Id()
 	baseNodeInfo, err := nodeGroup.TemplateNodeInfo()
+	if err == cloudprovider.ErrNotImplemented {
+		baseNodeInfo, err = templateNodeInfoProcessor.Build(nodeGroup)
+	}
 	if err != nil {
 		return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
 	}
+	baseNodeInfo, err = templateNodeInfoProcessor.Process(nodeGroup, baseNodeInfo)
+	if err != nil {
+		return nil, errors.ToAutoscalerError(errors.InternalError, err)
+	}
 
 	pods := daemonset.GetDaemonSetPodsForNode(baseNodeInfo, daemonsets, predicateChecker)
 	pods = append(pods, baseNodeInfo.Pods()...)
@@ -460,20 +481,20 @@ func sanitizeTemplateNode(node *apiv1.Node, nodeGroup string) (*apiv1.Node, erro
 
 // Removes unregistered nodes if needed. Returns true if anything was removed and error if such occurred.
 func removeOldUnregisteredNodes(unregisteredNodes []clusterstate.UnregisteredNode, context *context.AutoscalingContext,
-	currentTime time.Time, logRecorder *utils.LogEventRecorder) (bool, error) {
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, currentTime time.Time, logRecorder *utils.LogEventRecorder) (bool, error) {
 	removedAny := false
 	for _, unregisteredNode := range unregisteredNodes {
-		if unregisteredNode.UnregisteredSince.Add(context.MaxNodeProvisionTime).Before(currentTime) {
+		nodeGroup, err := context.CloudProvider.NodeGroupForNode(unregisteredNode.Node)
+		if err != nil {
+			klog.Warningf("Failed to get node group for %s: %v", unregisteredNode.Node.Name, err)
+			continue
+		}
+		if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			klog.Warningf("No node group for node %s, skipping", unregisteredNode.Node.Name)
+			continue
+		}
+		if unregisteredNode.UnregisteredSince.Add(clusterStateRegistry.NodeProvisionTimeout(nodeGroup)).Before(currentTime) {
 			klog.V(0).Infof("Removing unregistered node %v", unregisteredNode.Node.Name)
-			nodeGroup, err := context.CloudProvider.NodeGroupForNode(unregisteredNode.Node)
-			if err != nil {
-				klog.Warningf("Failed to get node group for %s: %v", unregisteredNode.Node.Name, err)
-				return removedAny, err
-			}
-			if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
-				klog.Warningf("No node group for node %s, skipping", unregisteredNode.Node.Name)
-				continue
-			}
 			size, err := nodeGroup.TargetSize()
 			if err != nil {
 				klog.Warningf("Failed to get node group size; unregisteredNode=%v; nodeGroup=%v; err=%v", unregisteredNode.Node.Name, nodeGroup.Id(), err)
@@ -483,6 +504,10 @@ func removeOldUnregisteredNodes(unregisteredNodes []clusterstate.UnregisteredNod
 				klog.Warningf("Failed to remove node %s: node group min size reached, skipping unregistered node removal", unregisteredNode.Node.Name)
 				continue
 			}
+			if context.DryRun {
+				recordDryRunAction(context, metrics.DryRunScaleDown, 1, "would remove unregistered node %v", unregisteredNode.Node.Name)
+				continue
+			}
 			err = nodeGroup.DeleteNodes([]*apiv1.Node{unregisteredNode.Node})
 			if err != nil {
 				klog.Warningf("Failed to remove node %s: %v", unregisteredNode.Node.Name, err)
@@ -501,16 +526,23 @@ func removeOldUnregisteredNodes(unregisteredNodes []clusterstate.UnregisteredNod
 // Sets the target size of node groups to the current number of nodes in them
 // if the difference was constant for a prolonged time. Returns true if managed
 // to fix something.
-func fixNodeGroupSize(context *context.AutoscalingContext, clusterStateRegistry *clusterstate.ClusterStateRegistry, currentTime time.Time) (bool, error) {
+func fixNodeGroupSize(context *context.AutoscalingContext, clusterStateRegistry *clusterstate.ClusterStateRegistry,
+	nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor, currentTime time.Time) (bool, error) {
 	fixed := false
 	for _, nodeGroup := range context.CloudProvider.NodeGroups() {
 		incorrectSize := clusterStateRegistry.GetIncorrectNodeGroupSize(nodeGroup.Id())
 		if incorrectSize == nil {
 			continue
 		}
-		if incorrectSize.FirstObserved.Add(context.MaxNodeProvisionTime).Before(currentTime) {
+		maxNodeProvisionTime := nodeGroupConfigProcessor.GetOptions(nodeGroup, context.NodeGroupDefaults()).MaxNodeProvisionTime
+		if incorrectSize.FirstObserved.Add(maxNodeProvisionTime).Before(currentTime) {
 			delta := incorrectSize.CurrentSize - incorrectSize.ExpectedSize
 			if delta < 0 {
+				if context.DryRun {
+					recordDryRunAction(context, metrics.DryRunNodeGroupResize, 1, "would decrease size of %s, expected=%d current=%d delta=%d",
+						nodeGroup.Id(), incorrectSize.ExpectedSize, incorrectSize.CurrentSize, delta)
+					continue
+				}
 				klog.V(0).Infof("Decreasing size of %s, expected=%d current=%d delta=%d", nodeGroup.Id(),
 					incorrectSize.ExpectedSize,
 					incorrectSize.CurrentSize,
@@ -527,8 +559,11 @@ func fixNodeGroupSize(context *context.AutoscalingContext, clusterStateRegistry
 
 // getPotentiallyUnneededNodes returns nodes that are:
 // - managed by the cluster autoscaler
-// - in groups with size > min size
-func getPotentiallyUnneededNodes(context *context.AutoscalingContext, nodes []*apiv1.Node) []*apiv1.Node {
+// - in groups with size > min size, taking active scaling windows into account
+// - in groups with scale-down not blocked by a scaling window
+// - in groups with scale-down enabled
+func getPotentiallyUnneededNodes(context *context.AutoscalingContext, processors *ca_processors.AutoscalingProcessors,
+	nodes []*apiv1.Node, now time.Time) []*apiv1.Node {
 	result := make([]*apiv1.Node, 0, len(nodes))
 
 	nodeGroupSize := getNodeGroupSizeMap(context.CloudProvider)
@@ -548,7 +583,15 @@ func getPotentiallyUnneededNodes(context *context.AutoscalingContext, nodes []*a
 			klog.Errorf("Error while checking node group size %s: group size not found", nodeGroup.Id())
 			continue
 		}
-		if size <= nodeGroup.MinSize() {
+		if !processors.NodeGroupConfigProcessor.GetOptions(nodeGroup, context.NodeGroupDefaults()).ScaleDownEnabled {
+			klog.V(1).Infof("Skipping %s - scale down disabled for node group %s", node.Name, nodeGroup.Id())
+			continue
+		}
+		if processors.ScalingWindowProcessor.IsScaleDownBlocked(nodeGroup, now) {
+			klog.V(1).Infof("Skipping %s - scale down blocked by a scaling window", node.Name)
+			continue
+		}
+		if size <= processors.ScalingWindowProcessor.GetMinSize(nodeGroup, now) {
 			klog.V(1).Infof("Skipping %s - node group min size reached", node.Name)
 			continue
 		}
@@ -21,11 +21,15 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
@@ -33,6 +37,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	kube_record "k8s.io/client-go/tools/record"
@@ -409,12 +414,12 @@ func TestGetNodeInfosForGroups(t *testing.T) {
 	provider2.AddNodeGroup("ng5", 1, 10, 1) // Nodegroup without nodes.
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
-	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	predicateChecker := simulator.NewTestPredicateChecker()
 
 	res, err := getNodeInfosForGroups([]*apiv1.Node{unready4, unready3, ready2, ready1}, nil,
-		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker)
+		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	assert.NoError(t, err)
 	assert.Equal(t, 4, len(res))
 	info, found := res["ng1"]
@@ -432,11 +437,77 @@ func TestGetNodeInfosForGroups(t *testing.T) {
 
 	// Test for a nodegroup without nodes and TemplateNodeInfo not implemented by cloud proivder
 	res, err = getNodeInfosForGroups([]*apiv1.Node{}, nil, provider2, registry,
-		[]*appsv1.DaemonSet{}, predicateChecker)
+		[]*appsv1.DaemonSet{}, predicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(res))
 }
 
+func TestGetNodeInfosForGroupsTemplateOverride(t *testing.T) {
+	tn := BuildTestNode("tn", 5000, 5000)
+	tni := schedulernodeinfo.NewNodeInfo()
+	tni.SetNode(tn)
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(
+		nil, nil, nil, nil, nil,
+		map[string]*schedulernodeinfo.NodeInfo{"ng1": tni, "ng2": tni})
+	provider.AddNodeGroup("ng1", 0, 10, 0)
+	provider.AddNodeGroup("ng2", 0, 10, 0)
+
+	overrides := map[string]*nodeinfos.TemplateOverride{
+		"ng1": {
+			NodeGroup:         "ng1",
+			Labels:            map[string]string{"accelerator": "gpu"},
+			ExtendedResources: apiv1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")},
+		},
+	}
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	res, err := getNodeInfosForGroups([]*apiv1.Node{}, nil, provider, registry, []*appsv1.DaemonSet{},
+		simulator.NewTestPredicateChecker(), nodeinfos.NewOverrideTemplateNodeInfoProcessor(overrides))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(res))
+
+	node := res["ng1"].Node()
+	assertEqualNodeCapacities(t, tn, node)
+	assert.Equal(t, "gpu", node.Labels["accelerator"])
+	gpus := node.Status.Allocatable["nvidia.com/gpu"]
+	assert.Equal(t, int64(2), gpus.Value())
+	assert.NotContains(t, res["ng2"].Node().Labels, "accelerator")
+	// The template of the cloud provider is not modified.
+	assert.NotContains(t, tn.Labels, "accelerator")
+}
+
+func TestGetNodeInfosForGroupsTemplateOverrideWithoutTemplates(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 0)
+	provider.AddNodeGroup("ng2", 0, 10, 0)
+	provider.AddNodeGroup("ng3", 0, 10, 0)
+
+	overrides := map[string]*nodeinfos.TemplateOverride{
+		"ng1": {
+			NodeGroup:   "ng1",
+			Labels:      map[string]string{"accelerator": "gpu"},
+			Allocatable: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("4"), apiv1.ResourceMemory: resource.MustParse("16Gi")},
+		},
+		// Without allocatable resources the override can't describe a whole node.
+		"ng2": {
+			NodeGroup: "ng2",
+			Labels:    map[string]string{"accelerator": "gpu"},
+		},
+	}
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	res, err := getNodeInfosForGroups([]*apiv1.Node{}, nil, provider, registry, []*appsv1.DaemonSet{},
+		simulator.NewTestPredicateChecker(), nodeinfos.NewOverrideTemplateNodeInfoProcessor(overrides))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+
+	node := res["ng1"].Node()
+	assert.Equal(t, "gpu", node.Labels["accelerator"])
+	assert.Equal(t, int64(4000), node.Status.Allocatable.Cpu().MilliValue())
+	memory := resource.MustParse("16Gi")
+	assert.Equal(t, memory.Value(), node.Status.Allocatable.Memory().Value())
+}
+
 func TestGetNodeInfosForGroupsCache(t *testing.T) {
 	ready1 := BuildTestNode("n1", 1000, 1000)
 	SetNodeReadyState(ready1, true, time.Now())
@@ -477,7 +548,7 @@ func TestGetNodeInfosForGroupsCache(t *testing.T) {
 	provider1.AddNode("ng4", ready6)
 
 	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
-	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil)
+	registry := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	predicateChecker := simulator.NewTestPredicateChecker()
 
@@ -485,7 +556,7 @@ func TestGetNodeInfosForGroupsCache(t *testing.T) {
 
 	// Fill cache
 	res, err := getNodeInfosForGroups([]*apiv1.Node{unready4, unready3, ready2, ready1}, nodeInfoCache,
-		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker)
+		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	assert.NoError(t, err)
 	// Check results
 	assert.Equal(t, 4, len(res))
@@ -520,7 +591,7 @@ func TestGetNodeInfosForGroupsCache(t *testing.T) {
 
 	// Check cache with all nodes removed
 	res, err = getNodeInfosForGroups([]*apiv1.Node{}, nodeInfoCache,
-		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker)
+		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	assert.NoError(t, err)
 	// Check results
 	assert.Equal(t, 2, len(res))
@@ -545,7 +616,7 @@ func TestGetNodeInfosForGroupsCache(t *testing.T) {
 	nodeInfoCache = map[string]*schedulernodeinfo.NodeInfo{"ng4": infoNg4Node6}
 	// Check if cache was used
 	res, err = getNodeInfosForGroups([]*apiv1.Node{ready1, ready2}, nodeInfoCache,
-		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker)
+		provider1, registry, []*appsv1.DaemonSet{}, predicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(res))
 	info, found = res["ng2"]
@@ -584,7 +655,8 @@ func TestRemoveOldUnregisteredNodes(t *testing.T) {
 	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
 		MaxTotalUnreadyPercentage: 10,
 		OkTotalUnreadyCount:       1,
-	}, fakeLogRecorder, newBackoff())
+		MaxNodeProvisionTime:      45 * time.Minute,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	err := clusterState.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now.Add(-time.Hour))
 	assert.NoError(t, err)
 
@@ -598,16 +670,39 @@ func TestRemoveOldUnregisteredNodes(t *testing.T) {
 	assert.Equal(t, 1, len(unregisteredNodes))
 
 	// Nothing should be removed. The unregistered node is not old enough.
-	removed, err := removeOldUnregisteredNodes(unregisteredNodes, context, now.Add(-50*time.Minute), fakeLogRecorder)
+	removed, err := removeOldUnregisteredNodes(unregisteredNodes, context, clusterState, now.Add(-50*time.Minute), fakeLogRecorder)
 	assert.NoError(t, err)
 	assert.False(t, removed)
 
 	// ng1_2 should be removed.
-	removed, err = removeOldUnregisteredNodes(unregisteredNodes, context, now, fakeLogRecorder)
+	removed, err = removeOldUnregisteredNodes(unregisteredNodes, context, clusterState, now, fakeLogRecorder)
 	assert.NoError(t, err)
 	assert.True(t, removed)
 	deletedNode := getStringFromChan(deletedNodes)
 	assert.Equal(t, "ng1/ng1-2", deletedNode)
+
+	// A node whose node group can't be found doesn't keep other nodes from being removed.
+	broken := BuildTestNode("broken", 1000, 1000)
+	context.CloudProvider = &nodeGroupForNodeErrorProvider{TestCloudProvider: provider, nodeName: broken.Name}
+	provider.GetNodeGroup("ng1").(*testprovider.TestNodeGroup).SetTargetSize(2)
+	unregisteredNodes = append([]clusterstate.UnregisteredNode{{Node: broken, UnregisteredSince: now.Add(-time.Hour)}}, unregisteredNodes...)
+	removed, err = removeOldUnregisteredNodes(unregisteredNodes, context, clusterState, now, fakeLogRecorder)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, "ng1/ng1-2", getStringFromChan(deletedNodes))
+}
+
+// nodeGroupForNodeErrorProvider fails to find the node group of the node with the given name.
+type nodeGroupForNodeErrorProvider struct {
+	*testprovider.TestCloudProvider
+	nodeName string
+}
+
+func (p *nodeGroupForNodeErrorProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	if node.Name == p.nodeName {
+		return nil, fmt.Errorf("node group of %s not found", node.Name)
+	}
+	return p.TestCloudProvider.NodeGroupForNode(node)
 }
 
 func TestSanitizeNodeInfo(t *testing.T) {
@@ -681,7 +776,7 @@ func TestRemoveFixNodeTargetSize(t *testing.T) {
 	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
 		MaxTotalUnreadyPercentage: 10,
 		OkTotalUnreadyCount:       1,
-	}, fakeLogRecorder, newBackoff())
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	err := clusterState.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now.Add(-time.Hour))
 	assert.NoError(t, err)
 
@@ -693,12 +788,12 @@ func TestRemoveFixNodeTargetSize(t *testing.T) {
 	}
 
 	// Nothing should be fixed. The incorrect size state is not old enough.
-	removed, err := fixNodeGroupSize(context, clusterState, now.Add(-50*time.Minute))
+	removed, err := fixNodeGroupSize(context, clusterState, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(), now.Add(-50*time.Minute))
 	assert.NoError(t, err)
 	assert.False(t, removed)
 
 	// Node group should be decreased.
-	removed, err = fixNodeGroupSize(context, clusterState, now)
+	removed, err = fixNodeGroupSize(context, clusterState, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(), now)
 	assert.NoError(t, err)
 	assert.True(t, removed)
 	change := getStringFromChan(sizeChanges)
@@ -718,10 +813,13 @@ func TestGetPotentiallyUnneededNodes(t *testing.T) {
 	provider.AddNode("ng2", ng2_1)
 
 	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			ScaleDownEnabled: true,
+		},
 		CloudProvider: provider,
 	}
 
-	result := getPotentiallyUnneededNodes(context, []*apiv1.Node{ng1_1, ng1_2, ng2_1, noNg})
+	result := getPotentiallyUnneededNodes(context, ca_processors.TestProcessors(), []*apiv1.Node{ng1_1, ng1_2, ng2_1, noNg}, time.Now())
 	assert.Equal(t, 2, len(result))
 	ok1 := result[0].Name == "ng1-1" && result[1].Name == "ng1-2"
 	ok2 := result[1].Name == "ng1-1" && result[0].Name == "ng1-2"
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// podsByZone returns the pods running on the given nodes by the zone of their node. Pods of
+// nodes without a zone are left out.
+func podsByZone(nodes []*apiv1.Node, pods []*apiv1.Pod) map[string][]*apiv1.Pod {
+	zones := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if zone := scheduler_util.GetZone(node); zone != "" {
+			zones[node.Name] = zone
+		}
+	}
+	result := make(map[string][]*apiv1.Pod)
+	for _, pod := range pods {
+		if zone, found := zones[pod.Spec.NodeName]; found {
+			result[zone] = append(result[zone], pod)
+		}
+	}
+	return result
+}
+
+// zoneSpreadNodes assigns the pods that have to be spread across zones because of their zone
+// anti-affinity to the zones of the given node groups, so that conflicting pods end up in different
+// zones, and returns the number of new nodes each node group needs for the pods assigned to it,
+// by node group id, and the assigned pods. Pods are never assigned to zones of scheduled pods
+// they conflict with. Returns nil if the pods don't need to be spread.
+func zoneSpreadNodes(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup,
+	nodeInfos map[string]*schedulernodeinfo.NodeInfo, pods []*apiv1.Pod, scheduledPodsInZone map[string][]*apiv1.Pod) (map[string]int, []*apiv1.Pod) {
+
+	zones := make(map[string]string)
+	for _, group := range groups {
+		if nodeInfo, found := nodeInfos[group.Id()]; found {
+			if zone := scheduler_util.GetZone(nodeInfo.Node()); zone != "" {
+				zones[group.Id()] = zone
+			}
+		}
+	}
+	if len(zones) < 2 {
+		return nil, nil
+	}
+
+	podsInGroup := make(map[string][]*apiv1.Pod)
+	podsInZone := make(map[string][]*apiv1.Pod)
+	spreadPods := make([]*apiv1.Pod, 0)
+	for _, pod := range pods {
+		if !scheduler_util.HasZoneAntiAffinity(pod) {
+			continue
+		}
+		// The pod goes to the node group with the fewest spread pods among the zones it can run in.
+		var target cloudprovider.NodeGroup
+		for _, group := range groups {
+			zone, found := zones[group.Id()]
+			if !found || scheduler_util.PodConflictsInZone(pod, podsInZone[zone]) ||
+				scheduler_util.PodConflictsInZone(pod, scheduledPodsInZone[zone]) {
+				continue
+			}
+			if target == nil || len(podsInGroup[group.Id()]) < len(podsInGroup[target.Id()]) {
+				target = group
+			}
+		}
+		if target == nil {
+			klog.V(4).Infof("No zone left for pod %s/%s among similar node groups", pod.Namespace, pod.Name)
+			continue
+		}
+		podsInGroup[target.Id()] = append(podsInGroup[target.Id()], pod)
+		podsInZone[zones[target.Id()]] = append(podsInZone[zones[target.Id()]], pod)
+		spreadPods = append(spreadPods, pod)
+	}
+	if len(podsInGroup) < 2 {
+		return nil, nil
+	}
+
+	result := make(map[string]int, len(podsInGroup))
+	for id, groupPods := range podsInGroup {
+		estimator := context.EstimatorBuilder(context.PredicateChecker)
+		result[id], _ = estimator.Estimate(groupPods, nodeInfos[id], nil, nil)
+	}
+	return result, spreadPods
+}
+
+// mergePods returns the pods of the first list followed by the pods of the second list that
+// are not in the first one.
+func mergePods(pods, morePods []*apiv1.Pod) []*apiv1.Pod {
+	seen := make(map[*apiv1.Pod]bool, len(pods))
+	result := make([]*apiv1.Pod, 0, len(pods)+len(morePods))
+	for _, pod := range pods {
+		seen[pod] = true
+		result = append(result, pod)
+	}
+	for _, pod := range morePods {
+		if !seen[pod] {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildZoneSpreadPod(name string, cpu int64, app string) *apiv1.Pod {
+	pod := BuildTestPod(name, cpu, 0)
+	pod.Labels = map[string]string{"app": app}
+	pod.Spec.Affinity = &apiv1.Affinity{
+		PodAntiAffinity: &apiv1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				TopologyKey:   apiv1.LabelZoneFailureDomain,
+			}},
+		},
+	}
+	return pod
+}
+
+// zoneSpreadScaleUp scales up three similar node groups in zones a, b and c for the pods and
+// returns the new sizes of the node groups. The pod running in zone a gets the runningInZoneA labels.
+func zoneSpreadScaleUp(t *testing.T, pods []*apiv1.Pod, runningInZoneA map[string]string) map[string]int {
+	provider := testprovider.NewTestCloudProvider(func(string, int) error {
+		return nil
+	}, nil)
+
+	zones := map[string]string{"ng-a": "zone-a", "ng-b": "zone-b", "ng-c": "zone-c"}
+	sizes := map[string]int{"ng-a": 1, "ng-b": 1, "ng-c": 3}
+	nodes := make([]*apiv1.Node, 0)
+	podList := make([]*apiv1.Pod, 0)
+	for gid, zone := range zones {
+		provider.AddNodeGroup(gid, 1, 10, sizes[gid])
+		for i := 0; i < sizes[gid]; i++ {
+			node := BuildTestNode(fmt.Sprintf("%v-node-%v", gid, i), 1000, 1000)
+			node.Labels[apiv1.LabelZoneFailureDomain] = zone
+			SetNodeReadyState(node, true, time.Now())
+			nodes = append(nodes, node)
+			provider.AddNode(gid, node)
+
+			pod := BuildTestPod(fmt.Sprintf("%v-pod-%v", gid, i), 900, 0)
+			pod.Spec.NodeName = node.Name
+			if zone == "zone-a" {
+				pod.Labels = runningInZoneA
+			}
+			podList = append(podList, pod)
+		}
+	}
+
+	podLister := kube_util.NewTestPodLister(podList)
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	options := config.AutoscalingOptions{
+		EstimatorName:            estimator.BinpackingEstimatorName,
+		BalanceSimilarNodeGroups: true,
+		MaxCoresTotal:            config.DefaultMaxClusterCores,
+		MaxMemoryTotal:           config.DefaultMaxClusterMemory,
+	}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider)
+	nodeInfos, _ := getNodeInfosForGroups(nodes, nil, provider, listers, []*appsv1.DaemonSet{}, context.PredicateChecker, nodeinfos.NewDefaultTemplateNodeInfoProcessor())
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	scaleUpStatus, typedErr := ScaleUp(&context, ca_processors.TestProcessors(), clusterState, pods, nodes, podList, []*appsv1.DaemonSet{}, nodeInfos)
+	assert.NoError(t, typedErr)
+	assert.True(t, scaleUpStatus.WasSuccessful())
+
+	newSizes := make(map[string]int)
+	for _, info := range scaleUpStatus.ScaleUpInfos {
+		newSizes[info.Group.Id()] = info.NewSize
+	}
+	return newSizes
+}
+
+func TestScaleUpZoneSpread(t *testing.T) {
+	// Three web pods have to run in different zones, so each zone needs a node although
+	// all of them would fit on a single node.
+	pods := []*apiv1.Pod{
+		buildZoneSpreadPod("web-0", 300, "web"),
+		buildZoneSpreadPod("web-1", 300, "web"),
+		buildZoneSpreadPod("web-2", 300, "web"),
+	}
+	assert.Equal(t, map[string]int{"ng-a": 2, "ng-b": 2, "ng-c": 4}, zoneSpreadScaleUp(t, pods, nil))
+}
+
+func TestScaleUpZoneSpreadWithRunningPods(t *testing.T) {
+	// A web pod already runs in zone a, so the new web pods need nodes in zones b and c.
+	pods := []*apiv1.Pod{
+		buildZoneSpreadPod("web-0", 300, "web"),
+		buildZoneSpreadPod("web-1", 300, "web"),
+	}
+	assert.Equal(t, map[string]int{"ng-b": 2, "ng-c": 4}, zoneSpreadScaleUp(t, pods, map[string]string{"app": "web"}))
+}
+
+func TestZoneSpreadNodes(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	nodeInfos := make(map[string]*schedulernodeinfo.NodeInfo)
+	for gid, zone := range map[string]string{"ng-a": "zone-a", "ng-b": "zone-b", "ng-none": ""} {
+		provider.AddNodeGroup(gid, 0, 10, 0)
+		node := BuildTestNode(gid+"-template", 1000, 1000)
+		if zone != "" {
+			node.Labels[apiv1.LabelZoneFailureDomain] = zone
+		}
+		SetNodeReadyState(node, true, time.Now())
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos[gid] = nodeInfo
+	}
+	options := config.AutoscalingOptions{EstimatorName: estimator.BinpackingEstimatorName}
+	context := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider)
+	groups := provider.NodeGroups()
+
+	// Pods without zone anti-affinity don't need spreading.
+	pods := []*apiv1.Pod{BuildTestPod("p1", 100, 0), BuildTestPod("p2", 100, 0)}
+	zoneNodes, spreadPods := zoneSpreadNodes(&context, groups, nodeInfos, pods, nil)
+	assert.Nil(t, zoneNodes)
+	assert.Nil(t, spreadPods)
+
+	// Two zones for three web pods and two db pods.
+	pods = []*apiv1.Pod{
+		buildZoneSpreadPod("web-0", 600, "web"),
+		buildZoneSpreadPod("web-1", 600, "web"),
+		buildZoneSpreadPod("web-2", 600, "web"),
+		buildZoneSpreadPod("db-0", 600, "db"),
+		buildZoneSpreadPod("db-1", 600, "db"),
+	}
+	zoneNodes, spreadPods = zoneSpreadNodes(&context, groups, nodeInfos, pods, nil)
+	assert.Equal(t, map[string]int{"ng-a": 2, "ng-b": 2}, zoneNodes)
+	assert.Equal(t, 4, len(spreadPods))
+
+	// Web pods can't go to zone a if a web pod runs there already.
+	running := map[string][]*apiv1.Pod{"zone-a": {buildZoneSpreadPod("web-running", 600, "web")}}
+	zoneNodes, spreadPods = zoneSpreadNodes(&context, groups, nodeInfos, pods, running)
+	assert.Equal(t, map[string]int{"ng-a": 1, "ng-b": 2}, zoneNodes)
+	assert.Equal(t, 3, len(spreadPods))
+}
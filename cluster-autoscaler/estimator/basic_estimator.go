@@ -97,8 +97,10 @@ func (basicEstimator *BasicNodeEstimator) GetDebug() string {
 	return buffer.String()
 }
 
-// Estimate estimates the number needed of nodes of the given shape.
-func (basicEstimator *BasicNodeEstimator) Estimate(pods []*apiv1.Pod, nodeInfo *schedulernodeinfo.NodeInfo, upcomingNodes []*schedulernodeinfo.NodeInfo) int {
+// Estimate estimates the number needed of nodes of the given shape. Zones are ignored, all pods
+// are assumed to be scheduled.
+func (basicEstimator *BasicNodeEstimator) Estimate(pods []*apiv1.Pod, nodeInfo *schedulernodeinfo.NodeInfo, upcomingNodes []*schedulernodeinfo.NodeInfo,
+	podsInZone []*apiv1.Pod) (int, []*apiv1.Pod) {
 	for _, pod := range pods {
 		basicEstimator.Add(pod)
 	}
@@ -144,7 +146,7 @@ func (basicEstimator *BasicNodeEstimator) Estimate(pods []*apiv1.Pod, nodeInfo *
 	for _, count := range basicEstimator.portSum {
 		result = maxInt(result, count-len(upcomingNodes))
 	}
-	return result
+	return result, pods
 }
 
 // GetCount returns number of pods included in the estimation.
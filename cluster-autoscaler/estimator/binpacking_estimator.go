@@ -50,30 +50,54 @@ func NewBinpackingNodeEstimator(predicateChecker *simulator.PredicateChecker) *B
 // will be cpu thus the estimated overprovisioning of 11/9 * optimal + 6/9 should be
 // still be maintained.
 // It is assumed that all pods from the given list can fit to nodeTemplate.
-// Returns the number of nodes needed to accommodate all pods from the list.
+// All new nodes are in the zone of nodeTemplate, so pods that cannot share a zone with podsInZone,
+// the pods already running there, or with pods placed on the new nodes because of their zone
+// anti-affinity are skipped; they need nodes from other zones.
+// Returns the number of nodes needed to accommodate the pods from the list and the pods that
+// are not skipped.
 func (estimator *BinpackingNodeEstimator) Estimate(pods []*apiv1.Pod, nodeTemplate *schedulernodeinfo.NodeInfo,
-	upcomingNodes []*schedulernodeinfo.NodeInfo) int {
+	upcomingNodes []*schedulernodeinfo.NodeInfo, podsInZone []*apiv1.Pod) (int, []*apiv1.Pod) {
 
 	podInfos := calculatePodScore(pods, nodeTemplate)
 	sort.Slice(podInfos, func(i, j int) bool { return podInfos[i].score > podInfos[j].score })
 
 	newNodes := make([]*schedulernodeinfo.NodeInfo, 0)
 	newNodes = append(newNodes, upcomingNodes...)
+	zonal := schedulerUtils.GetZone(nodeTemplate.Node()) != ""
+	podsInZone = append([]*apiv1.Pod{}, podsInZone...)
+	scheduled := make(map[*apiv1.Pod]bool, len(pods))
 
 	for _, podInfo := range podInfos {
+		conflictsInZone := zonal && schedulerUtils.PodConflictsInZone(podInfo.pod, podsInZone)
 		found := false
 		for i, nodeInfo := range newNodes {
+			if i >= len(upcomingNodes) && conflictsInZone {
+				break
+			}
 			if err := estimator.predicateChecker.CheckPredicates(podInfo.pod, nil, nodeInfo); err == nil {
 				found = true
 				newNodes[i] = schedulerUtils.NodeWithPod(nodeInfo, podInfo.pod)
+				if i >= len(upcomingNodes) {
+					podsInZone = append(podsInZone, podInfo.pod)
+				}
 				break
 			}
 		}
-		if !found {
+		if !found && !conflictsInZone {
 			newNodes = append(newNodes, schedulerUtils.NodeWithPod(nodeTemplate, podInfo.pod))
+			podsInZone = append(podsInZone, podInfo.pod)
+			found = true
+		}
+		scheduled[podInfo.pod] = found
+	}
+
+	scheduledPods := make([]*apiv1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if scheduled[pod] {
+			scheduledPods = append(scheduledPods, pod)
 		}
 	}
-	return len(newNodes) - len(upcomingNodes)
+	return len(newNodes) - len(upcomingNodes), scheduledPods
 }
 
 // Calculates score for all pods and returns podInfo structure.
@@ -17,11 +17,13 @@ limitations under the License.
 package estimator
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
@@ -55,7 +57,7 @@ func TestBinpackingEstimate(t *testing.T) {
 
 	nodeInfo := schedulernodeinfo.NewNodeInfo()
 	nodeInfo.SetNode(node)
-	estimate := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{})
+	estimate, _ := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{}, nil)
 	assert.Equal(t, 5, estimate)
 }
 
@@ -84,7 +86,7 @@ func TestBinpackingEstimateComingNodes(t *testing.T) {
 
 	nodeInfo := schedulernodeinfo.NewNodeInfo()
 	nodeInfo.SetNode(node)
-	estimate := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{nodeInfo, nodeInfo})
+	estimate, _ := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{nodeInfo, nodeInfo}, nil)
 	// 5 - 2 nodes that are coming.
 	assert.Equal(t, 3, estimate)
 }
@@ -118,6 +120,49 @@ func TestBinpackingEstimateWithPorts(t *testing.T) {
 
 	nodeInfo := schedulernodeinfo.NewNodeInfo()
 	nodeInfo.SetNode(node)
-	estimate := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{})
+	estimate, _ := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{}, nil)
 	assert.Equal(t, 8, estimate)
 }
+
+func TestBinpackingEstimateZoneAntiAffinity(t *testing.T) {
+	estimator := NewBinpackingNodeEstimator(simulator.NewTestPredicateChecker())
+
+	pods := make([]*apiv1.Pod, 0)
+	for i := 0; i < 3; i++ {
+		pod := BuildTestPod(fmt.Sprintf("web-%d", i), 400, 0)
+		pod.Labels = map[string]string{"app": "web"}
+		pod.Spec.Affinity = &apiv1.Affinity{
+			PodAntiAffinity: &apiv1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					TopologyKey:   apiv1.LabelZoneFailureDomain,
+				}},
+			},
+		}
+		pods = append(pods, pod)
+	}
+	pods = append(pods, BuildTestPod("other", 400, 0))
+	node := BuildTestNode("template", 1000, 1000)
+	SetNodeReadyState(node, true, time.Time{})
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	// Without a zone the anti-affinity doesn't apply.
+	estimate, scheduled := estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{}, nil)
+	assert.Equal(t, 2, estimate)
+	assert.Equal(t, pods, scheduled)
+
+	// A single web pod can run in the zone of the template.
+	node.Labels[apiv1.LabelZoneFailureDomain] = "zone-a"
+	estimate, scheduled = estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{}, nil)
+	assert.Equal(t, 1, estimate)
+	assert.Equal(t, 2, len(scheduled))
+	assert.Equal(t, pods[3], scheduled[1])
+
+	// No web pod can run in the zone if a web pod already runs there.
+	running := BuildTestPod("web-running", 400, 0)
+	running.Labels = map[string]string{"app": "web"}
+	estimate, scheduled = estimator.Estimate(pods, nodeInfo, []*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{running})
+	assert.Equal(t, 1, estimate)
+	assert.Equal(t, []*apiv1.Pod{pods[3]}, scheduled)
+}
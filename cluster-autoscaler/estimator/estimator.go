@@ -41,7 +41,11 @@ var AvailableEstimators = []string{BinpackingEstimatorName, deprecated(BasicEsti
 
 // Estimator calculates the number of nodes of given type needed to schedule pods.
 type Estimator interface {
-	Estimate([]*apiv1.Pod, *schedulernodeinfo.NodeInfo, []*schedulernodeinfo.NodeInfo) int
+	// Estimate returns the number of nodes like the template needed to schedule the pods in addition
+	// to the upcoming nodes, and the pods that the nodes would schedule. podsInZone are the pods
+	// already running in the zone of the template, which pods with zone anti-affinity can't share.
+	Estimate(pods []*apiv1.Pod, nodeTemplate *schedulernodeinfo.NodeInfo, upcomingNodes []*schedulernodeinfo.NodeInfo,
+		podsInZone []*apiv1.Pod) (int, []*apiv1.Pod)
 }
 
 // EstimatorBuilder creates a new estimator object.
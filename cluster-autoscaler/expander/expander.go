@@ -24,7 +24,7 @@ import (
 
 var (
 	// AvailableExpanders is a list of available expander options
-	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName}
+	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName, GRPCExpanderName}
 	// RandomExpanderName selects a node group at random
 	RandomExpanderName = "random"
 	// MostPodsExpanderName selects a node group that fits the most pods
@@ -36,6 +36,8 @@ var (
 	PriceBasedExpanderName = "price"
 	// PriorityBasedExpanderName selects a node group based on a user-configured priorities assigned to group names
 	PriorityBasedExpanderName = "priority"
+	// GRPCExpanderName delegates the choice of node group to an external gRPC service
+	GRPCExpanderName = "grpc"
 )
 
 // Option describes an option to expand the cluster.
@@ -50,3 +52,9 @@ type Option struct {
 type Strategy interface {
 	BestOption(options []Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) *Option
 }
+
+// Filter describes an interface for narrowing down options to the equally good ones when scaling up.
+// Filters can be chained, each of them breaking the ties left by the previous one.
+type Filter interface {
+	BestOptions(options []Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) []Option
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"testing"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticFilter struct {
+	keep  map[string]bool
+	calls int
+}
+
+func (f *staticFilter) BestOptions(options []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) []expander.Option {
+	f.calls++
+	var result []expander.Option
+	for _, option := range options {
+		if f.keep[option.Debug] {
+			result = append(result, option)
+		}
+	}
+	return result
+}
+
+type firstOptionStrategy struct{}
+
+func (firstOptionStrategy) BestOption(options []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) *expander.Option {
+	if len(options) == 0 {
+		return nil
+	}
+	return &options[0]
+}
+
+func TestChainStrategy(t *testing.T) {
+	a := expander.Option{Debug: "a"}
+	b := expander.Option{Debug: "b"}
+	c := expander.Option{Debug: "c"}
+	options := []expander.Option{a, b, c}
+
+	first := &staticFilter{keep: map[string]bool{"b": true, "c": true}}
+	second := &staticFilter{keep: map[string]bool{"c": true}}
+	third := &staticFilter{keep: map[string]bool{"a": true}}
+	strategy := newChainStrategy([]expander.Filter{first, second, third}, firstOptionStrategy{})
+
+	// The second filter breaks the tie, the third one is not consulted.
+	assert.Equal(t, c, *strategy.BestOption(options, nil))
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+	assert.Equal(t, 0, third.calls)
+
+	// Ties left by all filters are resolved by the fallback.
+	strategy = newChainStrategy([]expander.Filter{first}, firstOptionStrategy{})
+	assert.Equal(t, b, *strategy.BestOption(options, nil))
+
+	// Earlier filters take precedence.
+	strategy = newChainStrategy([]expander.Filter{third, second}, firstOptionStrategy{})
+	assert.Equal(t, a, *strategy.BestOption(options, nil))
+	strategy = newChainStrategy([]expander.Filter{second, third}, firstOptionStrategy{})
+	assert.Equal(t, c, *strategy.BestOption(options, nil))
+
+	// No option is chosen if a filter rejects all of them.
+	strategy = newChainStrategy([]expander.Filter{&staticFilter{}}, firstOptionStrategy{})
+	assert.Nil(t, strategy.BestOption(options, nil))
+}
+
+func TestExpanderStrategyFromStrings(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+	ng1 := expander.Option{NodeGroup: provider.GetNodeGroup("ng1"), Debug: "ng1", Pods: []*apiv1.Pod{{}}}
+	ng2 := expander.Option{NodeGroup: provider.GetNodeGroup("ng2"), Debug: "ng2", Pods: []*apiv1.Pod{{}, {}}}
+
+	build := func(names ...string) (expander.Strategy, error) {
+		strategy, err := ExpanderStrategyFromStrings(names, provider, nil, nil, "kube-system",
+			nodegroupconfig.NewDefaultNodeGroupConfigProcessor(), config.GRPCExpanderOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return strategy, nil
+	}
+
+	strategy, err := build(expander.MostPodsExpanderName, expander.RandomExpanderName)
+	assert.NoError(t, err)
+	assert.Equal(t, ng2, *strategy.BestOption([]expander.Option{ng1, ng2}, nil))
+
+	_, err = build(expander.MostPodsExpanderName, expander.MostPodsExpanderName)
+	assert.Error(t, err)
+	_, err = build("cheapest")
+	assert.Error(t, err)
+	_, err = build()
+	assert.Error(t, err)
+
+	// Names are trimmed, empty names are rejected.
+	strategy, err = build(" "+expander.MostPodsExpanderName, expander.RandomExpanderName+" ")
+	assert.NoError(t, err)
+	assert.Equal(t, ng2, *strategy.BestOption([]expander.Option{ng1, ng2}, nil))
+	_, err = build(expander.MostPodsExpanderName, " ")
+	assert.Error(t, err)
+	_, err = build("")
+	assert.Error(t, err)
+}
+
+func TestWithGRPCFallback(t *testing.T) {
+	assert.Equal(t, []string{"grpc"}, withGRPCFallback([]string{"grpc"}, nil))
+	assert.Equal(t, []string{"grpc", "least-waste", "random"},
+		withGRPCFallback([]string{"grpc"}, []string{"least-waste", "random"}))
+	assert.Equal(t, []string{"priority", "grpc", "random"},
+		withGRPCFallback([]string{"priority", "grpc"}, []string{"priority", "random"}))
+	assert.Equal(t, []string{"grpc", "most-pods"},
+		withGRPCFallback([]string{"grpc", "most-pods"}, []string{"least-waste"}))
+}
@@ -17,34 +17,94 @@ limitations under the License.
 package factory
 
 import (
+	"strings"
+
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/mostpods"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/price"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/priority"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/waste"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
 )
 
-// ExpanderStrategyFromString creates an expander.Strategy according to its name
-func ExpanderStrategyFromString(expanderFlag string, cloudProvider cloudprovider.CloudProvider,
+// ExpanderStrategyFromStrings creates an expander.Strategy chaining the expanders with the given names.
+// Each expander narrows down the options left by the previous one, remaining ties are broken at random.
+func ExpanderStrategyFromStrings(expanderFlags []string, cloudProvider cloudprovider.CloudProvider,
 	autoscalingKubeClients *context.AutoscalingKubeClients, kubeClient kube_client.Interface,
-	configNamespace string) (expander.Strategy, errors.AutoscalerError) {
+	configNamespace string, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor,
+	grpcExpanderOptions config.GRPCExpanderOptions) (expander.Strategy, errors.AutoscalerError) {
+	if len(expanderFlags) == 0 {
+		return nil, errors.NewAutoscalerError(errors.InternalError, "No expander specified")
+	}
+	expanderFlags = withGRPCFallback(expanderFlags, grpcExpanderOptions.Fallback)
+	seenExpanders := make(map[string]bool)
+	filters := make([]expander.Filter, 0, len(expanderFlags))
+	for _, expanderFlag := range expanderFlags {
+		expanderFlag = strings.TrimSpace(expanderFlag)
+		if expanderFlag == "" {
+			return nil, errors.NewAutoscalerError(errors.InternalError, "Empty expander name")
+		}
+		if seenExpanders[expanderFlag] {
+			return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s was specified multiple times", expanderFlag)
+		}
+		seenExpanders[expanderFlag] = true
+		filter, err := expanderFilterFromString(expanderFlag, cloudProvider, autoscalingKubeClients, kubeClient,
+			configNamespace, nodeGroupConfigProcessor, grpcExpanderOptions)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return newChainStrategy(filters, random.NewStrategy()), nil
+}
+
+// withGRPCFallback appends the deprecated grpc fallback expanders to the chain if grpc is its last expander.
+// Expanders listed after grpc are the preferred way of configuring the fallback and take precedence.
+func withGRPCFallback(expanderFlags []string, fallback []string) []string {
+	if len(fallback) == 0 {
+		return expanderFlags
+	}
+	if strings.TrimSpace(expanderFlags[len(expanderFlags)-1]) != expander.GRPCExpanderName {
+		klog.Warningf("Ignoring grpc expander fallback %v, it is only used if grpc is the last expander", fallback)
+		return expanderFlags
+	}
+	listed := make(map[string]bool)
+	for _, name := range expanderFlags {
+		listed[strings.TrimSpace(name)] = true
+	}
+	result := append([]string{}, expanderFlags...)
+	for _, name := range fallback {
+		if !listed[strings.TrimSpace(name)] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func expanderFilterFromString(expanderFlag string, cloudProvider cloudprovider.CloudProvider,
+	autoscalingKubeClients *context.AutoscalingKubeClients, kubeClient kube_client.Interface,
+	configNamespace string, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor,
+	grpcExpanderOptions config.GRPCExpanderOptions) (expander.Filter, errors.AutoscalerError) {
 	switch expanderFlag {
 	case expander.RandomExpanderName:
-		return random.NewStrategy(), nil
+		return random.NewFilter(), nil
 	case expander.MostPodsExpanderName:
-		return mostpods.NewStrategy(), nil
+		return mostpods.NewFilter(), nil
 	case expander.LeastWasteExpanderName:
-		return waste.NewStrategy(), nil
+		return waste.NewFilter(), nil
 	case expander.PriceBasedExpanderName:
 		if _, err := cloudProvider.Pricing(); err != nil {
 			return nil, err
 		}
-		return price.NewStrategy(cloudProvider,
+		return price.NewFilter(cloudProvider,
 			price.NewSimplePreferredNodeProvider(autoscalingKubeClients.AllNodeLister()),
 			price.SimpleNodeUnfitness), nil
 	case expander.PriorityBasedExpanderName:
@@ -53,7 +113,13 @@ func ExpanderStrategyFromString(expanderFlag string, cloudProvider cloudprovider
 		if err != nil {
 			return nil, errors.ToAutoscalerError(errors.InternalError, err)
 		}
-		return priority.NewStrategy(initialPriorities, priorityChangesChan, autoscalingKubeClients.LogRecorder)
+		return priority.NewFilter(initialPriorities, priorityChangesChan, autoscalingKubeClients.LogRecorder, nodeGroupConfigProcessor)
+	case expander.GRPCExpanderName:
+		filter, err := grpcplugin.NewFilter(grpcExpanderOptions.URL, grpcExpanderOptions.Cert, grpcExpanderOptions.Timeout)
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.InternalError, err)
+		}
+		return filter, nil
 	}
 	return nil, errors.NewAutoscalerError(errors.InternalError, "Expander %s not supported", expanderFlag)
 }
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin/protos"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+type grpcFilter struct {
+	client  protos.ExpanderClient
+	timeout time.Duration
+}
+
+// NewFilter returns an expansion filter that delegates the choice to an external gRPC
+// service listening on url. If cert is not empty, the connection is secured with TLS using
+// the given CA certificate file. If the service fails, does not answer within timeout or
+// returns an unknown node group, all options are passed on to the next expander.
+func NewFilter(url, cert string, timeout time.Duration) (expander.Filter, error) {
+	dialOption := grpc.WithInsecure()
+	if cert != "" {
+		creds, err := credentials.NewClientTLSFromFile(cert, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gRPC expander certificate %s: %v", cert, err)
+		}
+		dialOption = grpc.WithTransportCredentials(creds)
+	}
+	conn, err := grpc.Dial(url, dialOption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gRPC expander %s: %v", url, err)
+	}
+	return &grpcFilter{
+		client:  protos.NewExpanderClient(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// BestOptions returns the option chosen by the external service.
+func (g *grpcFilter) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) []expander.Option {
+	if len(expansionOptions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+	response, err := g.client.BestOption(ctx, buildRequest(expansionOptions, nodeInfo))
+	if err != nil {
+		klog.Warningf("gRPC expander failed, falling back to next expander: %v", err)
+		return expansionOptions
+	}
+	for _, option := range expansionOptions {
+		if option.NodeGroup.Id() == response.NodeGroupId {
+			return []expander.Option{option}
+		}
+	}
+	if response.NodeGroupId != "" {
+		klog.Warningf("gRPC expander chose unknown node group %s, falling back to next expander", response.NodeGroupId)
+	}
+	return expansionOptions
+}
+
+func buildRequest(expansionOptions []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) *protos.BestOptionRequest {
+	request := &protos.BestOptionRequest{}
+	for _, option := range expansionOptions {
+		id := option.NodeGroup.Id()
+		protoOption := &protos.Option{
+			NodeGroupId: id,
+			NodeCount:   int32(option.NodeCount),
+			Debug:       option.Debug,
+		}
+		for _, pod := range option.Pods {
+			data, err := pod.Marshal()
+			if err != nil {
+				klog.Errorf("Failed to serialize pod %s/%s for gRPC expander: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+			protoOption.Pods = append(protoOption.Pods, data)
+		}
+		if info, found := nodeInfo[id]; found && info.Node() != nil {
+			protoOption.Template = buildNodeTemplate(info)
+		}
+		request.Options = append(request.Options, protoOption)
+	}
+	return request
+}
+
+func buildNodeTemplate(info *schedulernodeinfo.NodeInfo) *protos.NodeTemplate {
+	node := info.Node()
+	template := &protos.NodeTemplate{
+		Labels:      make(map[string]string, len(node.Labels)),
+		Allocatable: make(map[string]string, len(node.Status.Allocatable)),
+		PodCount:    int32(len(info.Pods())),
+	}
+	for key, value := range node.Labels {
+		template.Labels[key] = value
+	}
+	for resourceName, quantity := range node.Status.Allocatable {
+		value := quantity
+		template.Allocatable[string(resourceName)] = value.String()
+	}
+	for _, taint := range node.Spec.Taints {
+		template.Taints = append(template.Taints, taint.ToString())
+	}
+	sort.Strings(template.Taints)
+	return template
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin/protos"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type stubExpanderServer struct {
+	choice   string
+	err      error
+	requests []*protos.BestOptionRequest
+}
+
+func (s *stubExpanderServer) BestOption(ctx context.Context, in *protos.BestOptionRequest) (*protos.BestOptionResponse, error) {
+	s.requests = append(s.requests, in)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &protos.BestOptionResponse{NodeGroupId: s.choice}, nil
+}
+
+func startStubServer(t *testing.T, stub *stubExpanderServer) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	protos.RegisterExpanderServer(server, stub)
+	go server.Serve(listener)
+	return listener.Addr().String(), server.Stop
+}
+
+func buildTestOptions() ([]expander.Option, map[string]*schedulernodeinfo.NodeInfo) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+
+	template := BuildTestNode("template", 2000, 4000)
+	template.Labels = map[string]string{"pool": "spot"}
+	template.Spec.Taints = []apiv1.Taint{{Key: "spot", Value: "true", Effect: apiv1.TaintEffectNoSchedule}}
+	ds := BuildTestPod("ds", 100, 100)
+	nodeInfo := schedulernodeinfo.NewNodeInfo(ds)
+	nodeInfo.SetNode(template)
+
+	options := []expander.Option{
+		{NodeGroup: provider.GetNodeGroup("ng1"), NodeCount: 2, Debug: "ng1", Pods: []*apiv1.Pod{BuildTestPod("p1", 500, 500)}},
+		{NodeGroup: provider.GetNodeGroup("ng2"), NodeCount: 1, Debug: "ng2"},
+	}
+	return options, map[string]*schedulernodeinfo.NodeInfo{"ng1": nodeInfo}
+}
+
+func TestGrpcFilterUsesServiceChoice(t *testing.T) {
+	stub := &stubExpanderServer{choice: "ng2"}
+	url, stop := startStubServer(t, stub)
+	defer stop()
+
+	filter, err := NewFilter(url, "", 5*time.Second)
+	assert.NoError(t, err)
+	options, nodeInfos := buildTestOptions()
+
+	best := filter.BestOptions(options, nodeInfos)
+	assert.Len(t, best, 1)
+	assert.Equal(t, "ng2", best[0].NodeGroup.Id())
+
+	assert.Len(t, stub.requests, 1)
+	request := stub.requests[0]
+	assert.Len(t, request.Options, 2)
+	ng1 := request.Options[0]
+	assert.Equal(t, "ng1", ng1.NodeGroupId)
+	assert.Equal(t, int32(2), ng1.NodeCount)
+	assert.Equal(t, "ng1", ng1.Debug)
+	assert.Len(t, ng1.Pods, 1)
+	pod := &apiv1.Pod{}
+	assert.NoError(t, pod.Unmarshal(ng1.Pods[0]))
+	assert.Equal(t, "p1", pod.Name)
+	assert.Equal(t, map[string]string{"pool": "spot"}, ng1.Template.Labels)
+	assert.Equal(t, "2", ng1.Template.Allocatable[string(apiv1.ResourceCPU)])
+	assert.Equal(t, []string{"spot=true:NoSchedule"}, ng1.Template.Taints)
+	assert.Equal(t, int32(1), ng1.Template.PodCount)
+	assert.Nil(t, request.Options[1].Template)
+}
+
+func TestGrpcFilterPassesAllOptionsOnFailure(t *testing.T) {
+	stub := &stubExpanderServer{}
+	url, stop := startStubServer(t, stub)
+	options, nodeInfos := buildTestOptions()
+	filter, err := NewFilter(url, "", 5*time.Second)
+	assert.NoError(t, err)
+
+	// No choice.
+	assert.Equal(t, options, filter.BestOptions(options, nodeInfos))
+
+	// Unknown node group.
+	stub.choice = "ng3"
+	assert.Equal(t, options, filter.BestOptions(options, nodeInfos))
+
+	// Service error.
+	stub.err = fmt.Errorf("no prices")
+	assert.Equal(t, options, filter.BestOptions(options, nodeInfos))
+	assert.Len(t, stub.requests, 3)
+
+	// Service unreachable.
+	stop()
+	assert.Equal(t, options, filter.BestOptions(options, nodeInfos))
+	assert.Len(t, stub.requests, 3)
+
+	assert.Nil(t, filter.BestOptions(nil, nodeInfos))
+}
+
+func TestNewGrpcFilterMissingCert(t *testing.T) {
+	_, err := NewFilter("127.0.0.1:1", "/nonexistent/ca.crt", time.Second)
+	assert.Error(t, err)
+}
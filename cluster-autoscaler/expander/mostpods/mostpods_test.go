@@ -41,3 +41,13 @@ func TestMostPods(t *testing.T) {
 	assert.NotEqual(t, *ret, eo0)
 	assert.True(t, assert.ObjectsAreEqual(*ret, eo1) || assert.ObjectsAreEqual(*ret, eo1b))
 }
+
+func TestMostPodsFilter(t *testing.T) {
+	f := NewFilter()
+
+	eo0 := expander.Option{Debug: "EO0"}
+	eo1 := expander.Option{Debug: "EO1", Pods: []*apiv1.Pod{nil}}
+	eo1b := expander.Option{Debug: "EO1b", Pods: []*apiv1.Pod{nil}}
+	assert.Equal(t, []expander.Option{eo1, eo1b}, f.BestOptions([]expander.Option{eo0, eo1, eo1b}, nil))
+	assert.Empty(t, f.BestOptions(nil, nil))
+}
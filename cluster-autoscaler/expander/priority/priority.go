@@ -23,8 +23,10 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	ca_config "k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -41,15 +43,37 @@ type priority struct {
 	okConfigUpdates  int
 	badConfigUpdates int
 	logRecorder      EventRecorder
+	// nodeGroupConfigProcessor provides per node group priorities that take precedence over the configmap.
+	nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor
 }
 
 // NewStrategy returns an expansion strategy that picks node groups based on user-defined priorities
 func NewStrategy(initialPriorities string, priorityChangesChan <-chan watch.Event,
-	logRecorder EventRecorder) (expander.Strategy, errors.AutoscalerError) {
+	logRecorder EventRecorder, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) (expander.Strategy, errors.AutoscalerError) {
+	res, err := newPriority(initialPriorities, priorityChangesChan, logRecorder, nodeGroupConfigProcessor)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewFilter returns an expansion filter that picks the node groups with the highest user-defined priority
+func NewFilter(initialPriorities string, priorityChangesChan <-chan watch.Event,
+	logRecorder EventRecorder, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) (expander.Filter, errors.AutoscalerError) {
+	res, err := newPriority(initialPriorities, priorityChangesChan, logRecorder, nodeGroupConfigProcessor)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func newPriority(initialPriorities string, priorityChangesChan <-chan watch.Event,
+	logRecorder EventRecorder, nodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor) (*priority, errors.AutoscalerError) {
 	res := &priority{
-		fallbackStrategy: random.NewStrategy(),
-		changesChan:      priorityChangesChan,
-		logRecorder:      logRecorder,
+		fallbackStrategy:         random.NewStrategy(),
+		changesChan:              priorityChangesChan,
+		logRecorder:              logRecorder,
+		nodeGroupConfigProcessor: nodeGroupConfigProcessor,
 	}
 	if err := res.parsePrioritiesYAMLString(initialPriorities); err != nil {
 		return nil, errors.ToAutoscalerError(errors.InternalError, err)
@@ -131,42 +155,80 @@ func (p *priority) BestOption(expansionOptions []expander.Option, nodeInfo map[s
 		return nil
 	}
 
+	return p.fallbackStrategy.BestOption(p.BestOptions(expansionOptions, nodeInfo), nodeInfo)
+}
+
+// BestOptions returns the options with the highest priority, or all of them if none has a priority.
+func (p *priority) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) []expander.Option {
+	if len(expansionOptions) <= 0 {
+		return nil
+	}
+
 	maxPrio := -1
 	best := []expander.Option{}
+	nodeGroupPriorities := p.nodeGroupPriorities(expansionOptions)
 	p.padlock.RLock()
 	for _, option := range expansionOptions {
 		id := option.NodeGroup.Id()
-		found := false
-		for prio, nameRegexpList := range p.priorities {
-			if prio < maxPrio {
-				continue
-			}
-			if !p.groupIDMatchesList(id, nameRegexpList) {
-				continue
-			}
-			if prio > maxPrio {
-				maxPrio = prio
-				best = nil
-			}
-			best = append(best, option)
-			found = true
-			break
+		prio, found := nodeGroupPriorities[id]
+		if !found {
+			prio, found = p.configMapPriority(id)
 		}
 		if !found {
 			msg := fmt.Sprintf("Priority expander: node group %s not found in priority expander configuration. "+
 				"The group won't be used.", id)
 			p.logConfigWarning("PriorityConfigMapNotMatchedGroup", msg)
+			continue
+		}
+		if prio < maxPrio {
+			continue
 		}
+		if prio > maxPrio {
+			maxPrio = prio
+			best = nil
+		}
+		best = append(best, option)
 	}
 	p.padlock.RUnlock()
 
 	if len(best) == 0 {
 		msg := "Priority expander: no priorities info found for any of the expansion options. Falling back to random choice."
 		p.logConfigWarning("PriorityConfigMapNoGroupMatched", msg)
-		return p.fallbackStrategy.BestOption(expansionOptions, nodeInfo)
+		return expansionOptions
+	}
+
+	return best
+}
+
+// nodeGroupPriorities returns priorities set for the node groups of the options themselves.
+// They win over priorities from the configmap.
+func (p *priority) nodeGroupPriorities(expansionOptions []expander.Option) map[string]int {
+	result := make(map[string]int)
+	if p.nodeGroupConfigProcessor == nil {
+		return result
+	}
+	for _, option := range expansionOptions {
+		options := p.nodeGroupConfigProcessor.GetOptions(option.NodeGroup, ca_config.NodeGroupAutoscalingOptions{})
+		if options.ExpanderPriority != nil {
+			result[option.NodeGroup.Id()] = *options.ExpanderPriority
+		}
 	}
+	return result
+}
 
-	return p.fallbackStrategy.BestOption(best, nodeInfo)
+// configMapPriority returns the highest priority from the configmap matching the node group.
+// Must be called with padlock held.
+func (p *priority) configMapPriority(id string) (int, bool) {
+	maxPrio, found := 0, false
+	for prio, nameRegexpList := range p.priorities {
+		if found && prio <= maxPrio {
+			continue
+		}
+		if p.groupIDMatchesList(id, nameRegexpList) {
+			maxPrio, found = prio, true
+		}
+	}
+	return maxPrio, found
 }
 
 func (p *priority) groupIDMatchesList(id string, nameRegexpList []*regexp.Regexp) bool {
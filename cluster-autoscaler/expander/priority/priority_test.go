@@ -31,7 +31,9 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	ca_config "k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 )
 
 const (
@@ -93,7 +95,7 @@ func getStrategyInstance(t *testing.T, config string) (expander.Strategy, chan w
 	c := make(chan watch.Event)
 
 	r := newTestRecorder()
-	s, err := NewStrategy(config, c, r)
+	s, err := NewStrategy(config, c, r, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	assert.Nil(t, err)
 	return s, c, r, err
 }
@@ -190,13 +192,55 @@ func TestPriorityExpanderCorrecltySkipsBadChangeConfig(t *testing.T) {
 	}
 }
 
+type testNodeGroupConfigProcessor struct {
+	nodegroupconfig.NoOpNodeGroupConfigProcessor
+	priorities map[string]int
+}
+
+func (p *testNodeGroupConfigProcessor) GetOptions(nodeGroup cloudprovider.NodeGroup, defaults ca_config.NodeGroupAutoscalingOptions) ca_config.NodeGroupAutoscalingOptions {
+	if prio, found := p.priorities[nodeGroup.Id()]; found {
+		defaults.ExpanderPriority = &prio
+	}
+	return defaults
+}
+
+func TestPriorityExpanderUsesNodeGroupPriorities(t *testing.T) {
+	processor := &testNodeGroupConfigProcessor{priorities: map[string]int{
+		eoT2Micro.NodeGroup.Id(): 100,
+		eoT3Large.NodeGroup.Id(): 5,
+	}}
+	s, err := NewStrategy(config, make(chan watch.Event), newTestRecorder(), processor)
+	assert.Nil(t, err)
+
+	// Node group priority wins over the configmap.
+	ret := s.BestOption([]expander.Option{eoT2Large, eoT2Micro, eoM44XLarge}, nil)
+	assert.Equal(t, eoT2Micro, *ret)
+	// Node group priority is compared with configmap priorities of other groups.
+	ret = s.BestOption([]expander.Option{eoT2Large, eoT3Large}, nil)
+	assert.Equal(t, eoT2Large, *ret)
+}
+
+func TestPriorityFilterReturnsTies(t *testing.T) {
+	f, err := NewFilter(config, make(chan watch.Event), newTestRecorder(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.Nil(t, err)
+
+	best := f.BestOptions([]expander.Option{eoT2Micro, eoT2Large, eoT3Large}, nil)
+	assert.Equal(t, []expander.Option{eoT2Large, eoT3Large}, best)
+
+	// All options are returned if none of them has a priority.
+	f, err = NewFilter(notMatchingConfig, make(chan watch.Event), newTestRecorder(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.Nil(t, err)
+	best = f.BestOptions([]expander.Option{eoT2Large, eoT3Large}, nil)
+	assert.Equal(t, []expander.Option{eoT2Large, eoT3Large}, best)
+}
+
 func TestPriorityExpanderFailsToStartWithEmptyConfig(t *testing.T) {
-	_, err := NewStrategy("", nil, &utils.LogEventRecorder{})
+	_, err := NewStrategy("", nil, &utils.LogEventRecorder{}, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	assert.NotNil(t, err)
 }
 
 func TestPriorityExpanderFailsToStartWithBadConfig(t *testing.T) {
-	_, err := NewStrategy("not_really_yaml: 34 : 43", nil, &utils.LogEventRecorder{})
+	_, err := NewStrategy("not_really_yaml: 34 : 43", nil, &utils.LogEventRecorder{}, nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
 	assert.NotNil(t, err)
 }
 
@@ -31,6 +31,20 @@ func NewStrategy() expander.Strategy {
 	return &random{}
 }
 
+// NewFilter returns an expansion filter that randomly picks one of the node groups
+func NewFilter() expander.Filter {
+	return &random{}
+}
+
+// BestOptions selects a single option at random
+func (r *random) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) []expander.Option {
+	best := r.BestOption(expansionOptions, nodeInfo)
+	if best == nil {
+		return nil
+	}
+	return []expander.Option{*best}
+}
+
 // RandomExpansion Selects from the expansion options at random
 func (r *random) BestOption(expansionOptions []expander.Option, nodeInfo map[string]*schedulernodeinfo.NodeInfo) *expander.Option {
 	if len(expansionOptions) <= 0 {
@@ -120,3 +120,31 @@ func TestLeastWaste(t *testing.T) {
 	ret = e.BestOption([]expander.Option{balancedOption, highmemOption, lowcpuOption}, nodeMap)
 	assert.Equal(t, *ret, lowcpuOption)
 }
+
+func TestLeastWasteFilter(t *testing.T) {
+	f := NewFilter()
+	nodeMap := map[string]*schedulernodeinfo.NodeInfo{
+		"small-a": makeNodeInfo(1000, 1000, 100),
+		"small-b": makeNodeInfo(1000, 1000, 100),
+		"big":     makeNodeInfo(4000, 4000, 100),
+	}
+	pod := &apiv1.Pod{
+		Spec: apiv1.PodSpec{
+			Containers: []apiv1.Container{
+				{
+					Resources: apiv1.ResourceRequirements{
+						Requests: apiv1.ResourceList{
+							apiv1.ResourceCPU:    *resource.NewMilliQuantity(500, resource.DecimalSI),
+							apiv1.ResourceMemory: *resource.NewQuantity(500, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+	smallA := expander.Option{NodeGroup: &FakeNodeGroup{"small-a"}, NodeCount: 1, Pods: []*apiv1.Pod{pod}}
+	smallB := expander.Option{NodeGroup: &FakeNodeGroup{"small-b"}, NodeCount: 1, Pods: []*apiv1.Pod{pod}}
+	big := expander.Option{NodeGroup: &FakeNodeGroup{"big"}, NodeCount: 1, Pods: []*apiv1.Pod{pod}}
+
+	assert.Equal(t, []expander.Option{smallA, smallB}, f.BestOptions([]expander.Option{big, smallA, smallB}, nodeMap))
+}
@@ -37,9 +37,19 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/forecast"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+	"k8s.io/client-go/dynamic"
 	kube_client "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -120,10 +130,14 @@ var (
 	maxBulkSoftTaintCount      = flag.Int("max-bulk-soft-taint-count", 10, "Maximum number of nodes that can be tainted/untainted PreferNoSchedule at the same time. Set to 0 to turn off such tainting.")
 	maxBulkSoftTaintTime       = flag.Duration("max-bulk-soft-taint-time", 3*time.Second, "Maximum duration of tainting/untainting nodes as PreferNoSchedule at the same time.")
 	maxEmptyBulkDeleteFlag     = flag.Int("max-empty-bulk-delete", 10, "Maximum number of empty nodes that can be deleted at the same time.")
+	maxDrainParallelismFlag    = flag.Int("max-drain-parallelism", 1, "Maximum number of non empty nodes that can be drained and deleted at the same time.")
+	maxDisruptedNodesPercent   = flag.Float64("max-disrupted-nodes-percentage", 0, "Maximum percentage of nodes of a node group that can be removed at the same time. At least one node can always be removed. 0 means no limit.")
+	maxDisruptedNodesPerZone   = flag.Int("max-disrupted-nodes-per-zone", 0, "Maximum number of nodes of a zone that can be removed at the same time. 0 means no limit.")
 	maxGracefulTerminationFlag = flag.Int("max-graceful-termination-sec", 10*60, "Maximum number of seconds CA waits for pod termination when trying to scale down a node.")
 	maxTotalUnreadyPercentage  = flag.Float64("max-total-unready-percentage", 45, "Maximum percentage of unready nodes in the cluster.  After this is exceeded, CA halts operations")
 	okTotalUnreadyCount        = flag.Int("ok-total-unready-count", 3, "Number of allowed unready nodes, irrespective of max-total-unready-percentage")
 	maxNodeProvisionTime       = flag.Duration("max-node-provision-time", 15*time.Minute, "Maximum time CA waits for node to be provisioned")
+	nodeProvisionTimeFactor    = flag.Float64("adaptive-node-provision-time-factor", 0, "If positive, CA waits for new nodes of node groups with enough history for this multiple of the p99 time their nodes took to become ready, instead of max-node-provision-time. 0 disables it.")
 	nodeGroupsFlag             = multiStringFlag(
 		"nodes",
 		"sets min,max size and other configuration data for a node group in a format accepted by cloud provider. Can be used multiple times. Format: <min>:<max>:<other...>")
@@ -139,7 +153,11 @@ var (
 		"Type of resource estimator to be used in scale up. Available values: ["+strings.Join(estimator.AvailableEstimators, ",")+"]")
 
 	expanderFlag = flag.String("expander", expander.RandomExpanderName,
-		"Type of node group expander to be used in scale up. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]")
+		"Comma separated list of node group expanders to be used in scale up, each breaking the ties left by the previous one. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]")
+	grpcExpanderURL      = flag.String("grpc-expander-url", "", "URL of the external service used by the grpc expander")
+	grpcExpanderCert     = flag.String("grpc-expander-cert", "", "Path to the CA certificate verifying the grpc expander service. Empty for an insecure connection")
+	grpcExpanderTimeout  = flag.Duration("grpc-expander-timeout", 5*time.Second, "Maximum time the grpc expander waits for the external service to choose a node group")
+	grpcExpanderFallback = flag.String("grpc-expander-fallback", "", "Deprecated: list the expanders after grpc in --expander instead. Comma separated list of expanders consulted in order when the grpc expander service fails")
 
 	ignoreDaemonSetsUtilization = flag.Bool("ignore-daemonsets-utilization", false,
 		"Should CA ignore DaemonSet pods when calculating resource utilization for scaling down")
@@ -149,6 +167,7 @@ var (
 	writeStatusConfigMapFlag         = flag.Bool("write-status-configmap", true, "Should CA write status information to a configmap")
 	maxInactivityTimeFlag            = flag.Duration("max-inactivity", 10*time.Minute, "Maximum time from last recorded autoscaler activity before automatic restart")
 	maxFailingTimeFlag               = flag.Duration("max-failing-time", 15*time.Minute, "Maximum time from last recorded successful autoscaler run before automatic restart")
+	snapshotEndpointEnabled          = flag.Bool("snapshot-endpoint-enabled", false, "Should CA serve its view of the cluster under /snapshot on the metrics address. The snapshot contains full pod specs and can be loaded by the simulator.")
 	balanceSimilarNodeGroupsFlag     = flag.Bool("balance-similar-node-groups", false, "Detect similar node groups and balance the number of nodes between them")
 	nodeAutoprovisioningEnabled      = flag.Bool("node-autoprovisioning-enabled", false, "Should CA autoprovision node groups when needed")
 	maxAutoprovisionedNodeGroupCount = flag.Int("max-autoprovisioned-node-group-count", 15, "The maximum number of autoprovisioned groups in the cluster.")
@@ -161,6 +180,28 @@ var (
 		"Filtering out schedulable pods before CA scale up by trying to pack the schedulable pods on free capacity on existing nodes."+
 			"Setting it to false employs a more lenient filtering approach that does not try to pack the pods on the nodes."+
 			"Pods with nominatedNodeName set are always filtered out.")
+	dryRun                              = flag.Bool("dry-run", false, "Only record scale-up and scale-down decisions as events, metrics and status configmap entries, without modifying nodes or node groups.")
+	scalingWindowsConfig                = flag.String("scaling-windows-config", "", "Path to a file with cron scheduled scaling windows raising node group min sizes or blocking scale-down. Empty to disable.")
+	headroomConfig                      = flag.String("headroom-config", "", "Path to a file with spare capacity to keep free in the cluster or in particular node groups. Empty to disable.")
+	nodeGroupAutoscalingPoliciesEnabled = flag.Bool("node-group-autoscaling-policies-enabled", false,
+		"Should CA override autoscaling options of node groups with NodeGroupAutoscalingPolicy objects. Requires the NodeGroupAutoscalingPolicy CRD to be installed.")
+	awsSpotPriceDiscount = flag.Float64("aws-spot-price-discount", 0,
+		"Fraction of the on-demand price saved by AWS spot instances, e.g. 0.7 if spot instances cost 30% of on-demand ones. Used by the price expander on AWS.")
+	awsAutoprovisioningConfig = flag.String("aws-autoprovisioning-config", "",
+		"Path to a file with the launch templates and zones of ASGs created by node autoprovisioning on AWS. Used only if node-autoprovisioning-enabled is set.")
+	spotFallbackEnabled = flag.Bool("spot-fallback-enabled", false,
+		"Should CA prefer scaling up spot node groups, fall back to on-demand node groups when spot node groups run out of resources or fail to provision nodes, and move workloads back to spot afterwards.")
+	spotFallbackDuration = flag.Duration("spot-fallback-duration", 15*time.Minute,
+		"How long CA scales up on-demand instead of spot node groups after a spot node group failed to scale up. Used only if spot-fallback-enabled is set.")
+	consolidationEnabled = flag.Bool("consolidation-enabled", false,
+		"Should CA replace underutilized nodes whose pods don't fit on other nodes with fewer nodes from another node group.")
+	maxConsolidationNodes = flag.Int("max-consolidation-nodes", 10, "Maximum number of nodes replaced in a single consolidation.")
+	forecastEnabled       = flag.Bool("forecast-enabled", false,
+		"Should CA learn the daily and weekly pattern of resources requested by pods and provision nodes ahead of predicted demand.")
+	forecastLookahead = flag.Duration("forecast-lookahead", 10*time.Minute,
+		"How far ahead CA provisions nodes for predicted demand. Used only if forecast-enabled is set.")
+	nodeTemplateOverridesConfig = flag.String("node-template-overrides-config", "",
+		"Path to a file with labels, taints and resources of nodes of particular node groups, merged into node group templates used to scale up from zero. Empty to disable.")
 )
 
 func createAutoscalingOptions() config.AutoscalingOptions {
@@ -181,21 +222,32 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		klog.Fatalf("Failed to parse flags: %v", err)
 	}
 
+	var grpcFallback []string
+	if *grpcExpanderFallback != "" {
+		klog.Warning("--grpc-expander-fallback is deprecated, list the fallback expanders after grpc in --expander instead")
+		grpcFallback = strings.Split(*grpcExpanderFallback, ",")
+	}
+
 	return config.AutoscalingOptions{
 		CloudConfig:                         *cloudConfig,
+		KubeConfigPath:                      *kubeConfigFile,
 		CloudProviderName:                   *cloudProviderFlag,
 		NodeGroupAutoDiscovery:              *nodeGroupAutoDiscoveryFlag,
 		MaxTotalUnreadyPercentage:           *maxTotalUnreadyPercentage,
 		OkTotalUnreadyCount:                 *okTotalUnreadyCount,
 		EstimatorName:                       *estimatorFlag,
-		ExpanderName:                        *expanderFlag,
+		ExpanderNames:                       *expanderFlag,
 		IgnoreDaemonSetsUtilization:         *ignoreDaemonSetsUtilization,
 		IgnoreMirrorPodsUtilization:         *ignoreMirrorPodsUtilization,
 		MaxBulkSoftTaintCount:               *maxBulkSoftTaintCount,
 		MaxBulkSoftTaintTime:                *maxBulkSoftTaintTime,
 		MaxEmptyBulkDelete:                  *maxEmptyBulkDeleteFlag,
+		MaxDrainParallelism:                 *maxDrainParallelismFlag,
+		MaxDisruptedNodesPercentage:         *maxDisruptedNodesPercent,
+		MaxDisruptedNodesPerZone:            *maxDisruptedNodesPerZone,
 		MaxGracefulTerminationSec:           *maxGracefulTerminationFlag,
 		MaxNodeProvisionTime:                *maxNodeProvisionTime,
+		AdaptiveNodeProvisionTimeFactor:     *nodeProvisionTimeFactor,
 		MaxNodesTotal:                       *maxNodesTotal,
 		MaxCoresTotal:                       maxCoresTotal,
 		MinCoresTotal:                       minCoresTotal,
@@ -224,6 +276,18 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		Regional:                            *regional,
 		NewPodScaleUpDelay:                  *newPodScaleUpDelay,
 		FilterOutSchedulablePodsUsesPacking: *filterOutSchedulablePodsUsesPacking,
+		DryRun:                              *dryRun,
+		AWSSpotPriceDiscount:                *awsSpotPriceDiscount,
+		AWSAutoprovisioningConfig:           *awsAutoprovisioningConfig,
+		ConsolidationEnabled:                *consolidationEnabled,
+		SpotFallbackEnabled:                 *spotFallbackEnabled,
+		MaxConsolidationNodes:               *maxConsolidationNodes,
+		GRPCExpander: config.GRPCExpanderOptions{
+			URL:      *grpcExpanderURL,
+			Cert:     *grpcExpanderCert,
+			Timeout:  *grpcExpanderTimeout,
+			Fallback: grpcFallback,
+		},
 	}
 }
 
@@ -275,6 +339,49 @@ func buildAutoscaler() (core.Autoscaler, error) {
 	kubeClient := createKubeClient(getKubeConfig())
 	eventsKubeClient := createKubeClient(getKubeConfig())
 	processors := ca_processors.DefaultProcessors()
+	if *scalingWindowsConfig != "" {
+		windows, err := scalingwindows.LoadConfigFile(*scalingWindowsConfig)
+		if err != nil {
+			return nil, err
+		}
+		processors.ScalingWindowProcessor = scalingwindows.NewScheduledScalingWindowProcessor(windows)
+	}
+	podListProcessors := make([]pods.PodListProcessor, 0)
+	if *forecastEnabled {
+		podListProcessors = append(podListProcessors, forecast.NewForecastPodListProcessor(*forecastLookahead))
+	}
+	if *headroomConfig != "" {
+		specs, err := headroom.LoadConfigFile(*headroomConfig)
+		if err != nil {
+			return nil, err
+		}
+		podListProcessors = append(podListProcessors, headroom.NewHeadroomPodListProcessor(specs))
+	}
+	if len(podListProcessors) > 0 {
+		processors.PodListProcessor = pods.NewCombinedPodListProcessor(podListProcessors)
+	}
+	if *nodeTemplateOverridesConfig != "" {
+		overrides, err := nodeinfos.LoadConfigFile(*nodeTemplateOverridesConfig)
+		if err != nil {
+			return nil, err
+		}
+		processors.TemplateNodeInfoProcessor = nodeinfos.NewOverrideTemplateNodeInfoProcessor(overrides)
+	}
+	if *nodeGroupAutoscalingPoliciesEnabled {
+		dynamicClient, err := dynamic.NewForConfig(getKubeConfig())
+		if err != nil {
+			return nil, err
+		}
+		policyLister := nodegroupconfig.NewPolicyLister(dynamicClient, make(chan struct{}))
+		processors.NodeGroupConfigProcessor = nodegroupconfig.NewPolicyNodeGroupConfigProcessor(policyLister)
+	}
+	if *spotFallbackEnabled {
+		processors.CapacityTypeProcessor = capacitytype.NewSpotFallbackProcessor(*spotFallbackDuration)
+	}
+	if *nodeAutoprovisioningEnabled {
+		processors.NodeGroupListProcessor = nodegroups.NewAutoprovisioningNodeGroupListProcessor()
+		processors.NodeGroupManager = nodegroups.NewAutoprovisioningNodeGroupManager()
+	}
 	opts := core.AutoscalerOptions{
 		AutoscalingOptions: autoscalingOptions,
 		KubeClient:         kubeClient,
@@ -289,7 +396,7 @@ func buildAutoscaler() (core.Autoscaler, error) {
 	return core.NewAutoscaler(opts)
 }
 
-func run(healthCheck *metrics.HealthCheck) {
+func run(healthCheck *metrics.HealthCheck, snapshotHandler *snapshot.Handler) {
 	metrics.RegisterAll()
 
 	autoscaler, err := buildAutoscaler()
@@ -300,6 +407,9 @@ func run(healthCheck *metrics.HealthCheck) {
 	// Register signal handlers for graceful shutdown.
 	registerSignalHandlers(autoscaler)
 
+	// Start serving snapshots.
+	snapshotHandler.SetExporter(autoscaler)
+
 	// Start updating health check endpoint.
 	healthCheck.StartMonitoring()
 
@@ -334,18 +444,22 @@ func main() {
 	leaderelectionconfig.BindFlags(&leaderElection, pflag.CommandLine)
 	kube_flag.InitFlags()
 	healthCheck := metrics.NewHealthCheck(*maxInactivityTimeFlag, *maxFailingTimeFlag)
+	snapshotHandler := snapshot.NewHandler()
 
 	klog.V(1).Infof("Cluster Autoscaler %s", ClusterAutoscalerVersion)
 
 	go func() {
 		http.Handle("/metrics", prometheus.Handler())
 		http.Handle("/health-check", healthCheck)
+		if *snapshotEndpointEnabled {
+			http.Handle("/snapshot", snapshotHandler)
+		}
 		err := http.ListenAndServe(*address, nil)
 		klog.Fatalf("Failed to start metrics: %v", err)
 	}()
 
 	if !leaderElection.LeaderElect {
-		run(healthCheck)
+		run(healthCheck, snapshotHandler)
 	} else {
 		id, err := os.Hostname()
 		if err != nil {
@@ -384,7 +498,7 @@ func main() {
 				OnStartedLeading: func(_ ctx.Context) {
 					// Since we are committing a suicide after losing
 					// mastership, we can safely ignore the argument.
-					run(healthCheck)
+					run(healthCheck, snapshotHandler)
 				},
 				OnStoppedLeading: func() {
 					klog.Fatalf("lost master")
@@ -33,6 +33,9 @@ type NodeScaleDownReason string
 // FailedScaleUpReason describes reason of failed scale-up
 type FailedScaleUpReason string
 
+// FailedConsolidationReason describes reason of failed consolidation
+type FailedConsolidationReason string
+
 // FunctionLabel is a name of Cluster Autoscaler operation for which
 // we measure duration
 type FunctionLabel string
@@ -40,6 +43,12 @@ type FunctionLabel string
 // NodeGroupType describes node group relation to CA
 type NodeGroupType string
 
+// DryRunAction describes an action skipped in dry-run mode
+type DryRunAction string
+
+// ProvisioningStage describes how far a node added by scale-up got
+type ProvisioningStage string
+
 const (
 	caNamespace           = "cluster_autoscaler"
 	readyLabel            = "ready"
@@ -54,12 +63,39 @@ const (
 	Empty NodeScaleDownReason = "empty"
 	// Unready node was removed
 	Unready NodeScaleDownReason = "unready"
+	// Consolidated node was replaced by fewer nodes from another node group
+	Consolidated NodeScaleDownReason = "consolidated"
 
 	// APIError caused scale-up to fail
 	APIError FailedScaleUpReason = "apiCallError"
 	// Timeout was encountered when trying to scale-up
 	Timeout FailedScaleUpReason = "timeout"
 
+	// NewNodesNotReady caused consolidation to fail when the new nodes weren't ready in time
+	NewNodesNotReady FailedConsolidationReason = "newNodesNotReady"
+	// NodesUnremovable caused consolidation to fail when the old nodes couldn't be removed after the scale-up
+	NodesUnremovable FailedConsolidationReason = "nodesUnremovable"
+
+	// DryRunScaleUp is a node that would be added by scale-up
+	DryRunScaleUp DryRunAction = "scaleUp"
+	// DryRunScaleDown is a node that would be removed by scale-down
+	DryRunScaleDown DryRunAction = "scaleDown"
+	// DryRunTaint is a taint that would be added to a node
+	DryRunTaint DryRunAction = "taint"
+	// DryRunUntaint is a taint that would be removed from a node
+	DryRunUntaint DryRunAction = "untaint"
+	// DryRunNodeGroupCreation is a node group that would be created
+	DryRunNodeGroupCreation DryRunAction = "nodeGroupCreation"
+	// DryRunNodeGroupDeletion is a node group that would be deleted
+	DryRunNodeGroupDeletion DryRunAction = "nodeGroupDeletion"
+	// DryRunNodeGroupResize is a node group target size that would be fixed
+	DryRunNodeGroupResize DryRunAction = "nodeGroupResize"
+
+	// NodeRegistered is a node that registered in Kubernetes
+	NodeRegistered ProvisioningStage = "registered"
+	// NodeReady is a node that became ready
+	NodeReady ProvisioningStage = "ready"
+
 	// autoscaledGroup is managed by CA
 	autoscaledGroup NodeGroupType = "autoscaled"
 	// autoprovisionedGroup have been created by CA (Node Autoprovisioning),
@@ -175,6 +211,14 @@ var (
 		}, []string{"reason"},
 	)
 
+	failedConsolidationCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "failed_consolidations_total",
+			Help:      "Number of consolidations abandoned after scaling up their target node group.",
+		}, []string{"reason"},
+	)
+
 	scaleDownCount = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: caNamespace,
@@ -191,6 +235,14 @@ var (
 		}, []string{"reason", "gpu_name"},
 	)
 
+	dryRunActionsCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "dry_run_actions_total",
+			Help:      "Number of actions CA would have executed if it was not running in dry-run mode, by action.",
+		}, []string{"action"},
+	)
+
 	evictionsCount = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: caNamespace,
@@ -207,6 +259,15 @@ var (
 		},
 	)
 
+	nodeProvisioningDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: caNamespace,
+			Name:      "node_provisioning_duration_seconds",
+			Help:      "Time from scale-up to registration or readiness of added nodes, by node group and stage.",
+			Buckets:   []float64{30, 60, 90, 120, 180, 240, 300, 420, 600, 900, 1200, 1800, 2700, 3600},
+		}, []string{"node_group", "stage"},
+	)
+
 	/**** Metrics related to NodeAutoprovisioning ****/
 	napEnabled = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -245,10 +306,13 @@ func RegisterAll() {
 	prometheus.MustRegister(scaleUpCount)
 	prometheus.MustRegister(gpuScaleUpCount)
 	prometheus.MustRegister(failedScaleUpCount)
+	prometheus.MustRegister(failedConsolidationCount)
 	prometheus.MustRegister(scaleDownCount)
 	prometheus.MustRegister(gpuScaleDownCount)
+	prometheus.MustRegister(dryRunActionsCount)
 	prometheus.MustRegister(evictionsCount)
 	prometheus.MustRegister(unneededNodesCount)
+	prometheus.MustRegister(nodeProvisioningDuration)
 	prometheus.MustRegister(napEnabled)
 	prometheus.MustRegister(nodeGroupCreationCount)
 	prometheus.MustRegister(nodeGroupDeletionCount)
@@ -324,6 +388,11 @@ func RegisterFailedScaleUp(reason FailedScaleUpReason) {
 	failedScaleUpCount.WithLabelValues(string(reason)).Inc()
 }
 
+// RegisterFailedConsolidation records a consolidation abandoned after its scale-up
+func RegisterFailedConsolidation(reason FailedConsolidationReason) {
+	failedConsolidationCount.WithLabelValues(string(reason)).Inc()
+}
+
 // RegisterScaleDown records number of nodes removed by scale down
 func RegisterScaleDown(nodesCount int, gpuType string, reason NodeScaleDownReason) {
 	scaleDownCount.WithLabelValues(string(reason)).Add(float64(nodesCount))
@@ -332,11 +401,21 @@ func RegisterScaleDown(nodesCount int, gpuType string, reason NodeScaleDownReaso
 	}
 }
 
+// RegisterDryRunAction records number of actions skipped in dry-run mode
+func RegisterDryRunAction(action DryRunAction, count int) {
+	dryRunActionsCount.WithLabelValues(string(action)).Add(float64(count))
+}
+
 // RegisterEvictions records number of evicted pods
 func RegisterEvictions(podsCount int) {
 	evictionsCount.Add(float64(podsCount))
 }
 
+// RegisterNodeProvisioningDuration records how long a node added to the node group took to reach the stage
+func RegisterNodeProvisioningDuration(nodeGroup string, stage ProvisioningStage, duration time.Duration) {
+	nodeProvisioningDuration.WithLabelValues(nodeGroup, string(stage)).Observe(duration.Seconds())
+}
+
 // UpdateUnneededNodesCount records number of currently unneeded nodes
 func UpdateUnneededNodesCount(nodesCount int) {
 	unneededNodesCount.Set(float64(nodesCount))
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitytype
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// CapacityType describes how the instances of a node are billed and whether they can be
+// reclaimed by the cloud provider.
+type CapacityType string
+
+const (
+	// OnDemand instances are not reclaimed by the cloud provider.
+	OnDemand CapacityType = "on-demand"
+	// Spot instances, also called preemptible, may be unavailable or reclaimed at any time.
+	Spot CapacityType = "spot"
+
+	// CapacityTypeLabel can be set on nodes, e.g. through node group templates, to
+	// explicitly choose the capacity type of a node group. Valid values are "spot" and "on-demand".
+	CapacityTypeLabel = "cluster-autoscaler.kubernetes.io/capacity-type"
+)
+
+// spotLabels are labels set by cloud providers and common tools on spot nodes, mapped to
+// the values marking a node as spot.
+var spotLabels = map[string][]string{
+	"eks.amazonaws.com/capacityType":   {"spot"},
+	"cloud.google.com/gke-preemptible": {"true"},
+	"cloud.google.com/gke-spot":        {"true"},
+	// Azure low-priority scale sets predate spot ones and are reclaimed the same way.
+	"kubernetes.azure.com/scalesetpriority": {"spot", "low"},
+	"node.kubernetes.io/lifecycle":          {"spot"},
+}
+
+// GetCapacityType returns the capacity type of the node based on its labels. Nodes
+// without any known spot label are on-demand.
+func GetCapacityType(node *apiv1.Node) CapacityType {
+	if node == nil {
+		return OnDemand
+	}
+	if value, found := node.Labels[CapacityTypeLabel]; found {
+		if CapacityType(strings.ToLower(value)) == Spot {
+			return Spot
+		}
+		return OnDemand
+	}
+	for label, spotValues := range spotLabels {
+		value, found := node.Labels[label]
+		if !found {
+			continue
+		}
+		for _, spotValue := range spotValues {
+			if strings.EqualFold(value, spotValue) {
+				return Spot
+			}
+		}
+	}
+	return OnDemand
+}
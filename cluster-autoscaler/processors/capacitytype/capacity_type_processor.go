@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitytype
+
+import (
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// CapacityTypeProcessor steers scale-ups and scale-downs between spot and on-demand node groups.
+type CapacityTypeProcessor interface {
+	// FilterOptions returns the scale-up options the expander should choose from.
+	FilterOptions(options []expander.Option, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+		clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) []expander.Option
+	// RegisterScaleUp records a scale-up of the node group.
+	RegisterScaleUp(nodeGroup cloudprovider.NodeGroup, now time.Time)
+	// IsRebalanceCandidate returns true if the node should be removed as soon as its pods
+	// fit on other nodes, regardless of its utilization.
+	IsRebalanceCandidate(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup,
+		clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) bool
+	// CleanUp cleans up the processor's internal structures.
+	CleanUp()
+}
+
+// NoOpCapacityTypeProcessor treats spot and on-demand node groups alike.
+type NoOpCapacityTypeProcessor struct {
+}
+
+// NewDefaultCapacityTypeProcessor creates an instance of CapacityTypeProcessor.
+func NewDefaultCapacityTypeProcessor() CapacityTypeProcessor {
+	return &NoOpCapacityTypeProcessor{}
+}
+
+// FilterOptions returns all options.
+func (p *NoOpCapacityTypeProcessor) FilterOptions(options []expander.Option, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) []expander.Option {
+	return options
+}
+
+// RegisterScaleUp does nothing.
+func (p *NoOpCapacityTypeProcessor) RegisterScaleUp(nodeGroup cloudprovider.NodeGroup, now time.Time) {
+}
+
+// IsRebalanceCandidate always returns false.
+func (p *NoOpCapacityTypeProcessor) IsRebalanceCandidate(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) bool {
+	return false
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *NoOpCapacityTypeProcessor) CleanUp() {
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitytype
+
+import (
+	"testing"
+
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCapacityType(t *testing.T) {
+	testCases := []struct {
+		labels   map[string]string
+		expected CapacityType
+	}{
+		{nil, OnDemand},
+		{map[string]string{"pool": "spot"}, OnDemand},
+		{map[string]string{CapacityTypeLabel: "spot"}, Spot},
+		{map[string]string{CapacityTypeLabel: "Spot"}, Spot},
+		{map[string]string{CapacityTypeLabel: "on-demand", "cloud.google.com/gke-preemptible": "true"}, OnDemand},
+		{map[string]string{"eks.amazonaws.com/capacityType": "SPOT"}, Spot},
+		{map[string]string{"eks.amazonaws.com/capacityType": "ON_DEMAND"}, OnDemand},
+		{map[string]string{"cloud.google.com/gke-preemptible": "true"}, Spot},
+		{map[string]string{"cloud.google.com/gke-spot": "true"}, Spot},
+		{map[string]string{"kubernetes.azure.com/scalesetpriority": "spot"}, Spot},
+		{map[string]string{"kubernetes.azure.com/scalesetpriority": "low"}, Spot},
+		{map[string]string{"kubernetes.azure.com/scalesetpriority": "regular"}, OnDemand},
+		{map[string]string{"node.kubernetes.io/lifecycle": "spot"}, Spot},
+		{map[string]string{"node.kubernetes.io/lifecycle": "normal"}, OnDemand},
+	}
+	for _, tc := range testCases {
+		node := BuildTestNode("n", 1000, 1000)
+		node.Labels = tc.labels
+		assert.Equal(t, tc.expected, GetCapacityType(node), "labels: %v", tc.labels)
+	}
+	assert.Equal(t, OnDemand, GetCapacityType(nil))
+}
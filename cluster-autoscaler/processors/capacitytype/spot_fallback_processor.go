@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitytype
+
+import (
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// maxFallbackWindows is the number of fallback periods remembered for each on-demand node group.
+const maxFallbackWindows = 10
+
+// fallbackWindow is a period in which an on-demand node group was scaled up instead of spot
+// node groups. Until is zero while the fallback lasts.
+type fallbackWindow struct {
+	from  time.Time
+	until time.Time
+}
+
+func (w *fallbackWindow) contains(t time.Time) bool {
+	return !t.Before(w.from) && (w.until.IsZero() || !t.After(w.until))
+}
+
+// SpotFallbackProcessor prefers scaling up spot node groups. After a spot node group runs
+// out of resources or fails to provision nodes within MaxNodeProvisionTime, on-demand node
+// groups are scaled up instead for fallbackDuration. Once the fallback ends, on-demand nodes
+// added during it are rebalance candidates: they are removed as soon as their pods fit on other
+// nodes, and consolidation scales up spot node groups for their pods and drains them otherwise.
+type SpotFallbackProcessor struct {
+	fallbackDuration time.Duration
+	fallbackActive   bool
+	// fallbackCheckTime is the time for which fallbackActive was last computed.
+	fallbackCheckTime time.Time
+	capacityTypes     map[string]CapacityType
+	fallbackWindows   map[string][]*fallbackWindow
+}
+
+// NewSpotFallbackProcessor creates a processor falling back to on-demand node groups for
+// fallbackDuration after a failed scale-up of a spot node group.
+func NewSpotFallbackProcessor(fallbackDuration time.Duration) CapacityTypeProcessor {
+	return &SpotFallbackProcessor{
+		fallbackDuration: fallbackDuration,
+		capacityTypes:    make(map[string]CapacityType),
+		fallbackWindows:  make(map[string][]*fallbackWindow),
+	}
+}
+
+// FilterOptions returns the options of the preferred capacity type, i.e. on-demand during
+// a fallback and spot otherwise. If there are no such options, all options are returned.
+func (p *SpotFallbackProcessor) FilterOptions(options []expander.Option, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) []expander.Option {
+	for nodeGroupId, nodeInfo := range nodeInfos {
+		p.capacityTypes[nodeGroupId] = GetCapacityType(nodeInfo.Node())
+	}
+
+	preferred := Spot
+	if p.updateFallback(clusterStateRegistry, now) {
+		preferred = OnDemand
+	}
+	filtered := make([]expander.Option, 0, len(options))
+	for _, option := range options {
+		if p.capacityTypes[option.NodeGroup.Id()] == preferred {
+			filtered = append(filtered, option)
+		}
+	}
+	if len(filtered) == 0 {
+		return options
+	}
+	if len(filtered) < len(options) {
+		klog.V(2).Infof("Limiting scale-up to %d out of %d options with %s capacity", len(filtered), len(options), preferred)
+	}
+	return filtered
+}
+
+// RegisterScaleUp starts a fallback window for the node group if it is an on-demand node
+// group scaled up during a fallback.
+func (p *SpotFallbackProcessor) RegisterScaleUp(nodeGroup cloudprovider.NodeGroup, now time.Time) {
+	if !p.fallbackActive || p.capacityTypes[nodeGroup.Id()] != OnDemand {
+		return
+	}
+	windows := p.fallbackWindows[nodeGroup.Id()]
+	if len(windows) > 0 && windows[len(windows)-1].until.IsZero() {
+		return
+	}
+	klog.V(1).Infof("Node group %s scaled up as a fallback for spot capacity", nodeGroup.Id())
+	windows = append(windows, &fallbackWindow{from: now})
+	if len(windows) > maxFallbackWindows {
+		windows = windows[len(windows)-maxFallbackWindows:]
+	}
+	p.fallbackWindows[nodeGroup.Id()] = windows
+}
+
+// IsRebalanceCandidate returns true if the node was created in an on-demand node group
+// during a fallback that has already ended.
+func (p *SpotFallbackProcessor) IsRebalanceCandidate(node *apiv1.Node, nodeGroup cloudprovider.NodeGroup,
+	clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) bool {
+	if p.updateFallback(clusterStateRegistry, now) {
+		return false
+	}
+	for _, window := range p.fallbackWindows[nodeGroup.Id()] {
+		if window.contains(node.CreationTimestamp.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *SpotFallbackProcessor) CleanUp() {
+}
+
+// updateFallback checks whether a spot node group recently ran out of resources or timed out
+// provisioning nodes, and closes open fallback windows if none did. Returns true if
+// the fallback is active. The result is computed once for each timestamp, so that scale-down
+// doesn't go through all scale-up failures for every node.
+func (p *SpotFallbackProcessor) updateFallback(clusterStateRegistry *clusterstate.ClusterStateRegistry, now time.Time) bool {
+	if now.Equal(p.fallbackCheckTime) {
+		return p.fallbackActive
+	}
+	p.fallbackCheckTime = now
+	p.fallbackActive = false
+	for nodeGroupId, failure := range clusterStateRegistry.GetScaleUpFailures() {
+		if p.capacityTypes[nodeGroupId] != Spot || now.Sub(failure.Time) >= p.fallbackDuration {
+			continue
+		}
+		if failure.ErrorClass == cloudprovider.OutOfResourcesErrorClass || failure.ErrorCode == clusterstate.TimeoutErrorCode {
+			p.fallbackActive = true
+			return true
+		}
+	}
+	for nodeGroupId, windows := range p.fallbackWindows {
+		if last := windows[len(windows)-1]; last.until.IsZero() {
+			klog.V(1).Infof("Spot capacity fallback for node group %s ended", nodeGroupId)
+			last.until = now
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitytype
+
+import (
+	"testing"
+	"time"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildNodeInfo(name string, labels map[string]string) *schedulernodeinfo.NodeInfo {
+	node := BuildTestNode(name, 1000, 1000)
+	node.Labels = labels
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	return nodeInfo
+}
+
+func buildReadyNode(name string, created time.Time) *apiv1.Node {
+	node := BuildTestNode(name, 1000, 1000)
+	node.CreationTimestamp = metav1.NewTime(created)
+	SetNodeReadyState(node, true, created)
+	return node
+}
+
+func optionIds(options []expander.Option) []string {
+	ids := make([]string, 0, len(options))
+	for _, option := range options {
+		ids = append(ids, option.NodeGroup.Id())
+	}
+	return ids
+}
+
+func TestSpotFallbackProcessor(t *testing.T) {
+	now := time.Now()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("spot", 0, 10, 2)
+	provider.AddNodeGroup("ondemand", 0, 10, 1)
+	spotNode := buildReadyNode("spot-1", now.Add(-time.Hour))
+	provider.AddNode("spot", spotNode)
+	onDemandNode := buildReadyNode("ondemand-1", now.Add(-time.Hour))
+	provider.AddNode("ondemand", onDemandNode)
+	spot := provider.GetNodeGroup("spot")
+	onDemand := provider.GetNodeGroup("ondemand")
+	nodeInfos := map[string]*schedulernodeinfo.NodeInfo{
+		"spot":     buildNodeInfo("spot-template", map[string]string{"eks.amazonaws.com/capacityType": "SPOT"}),
+		"ondemand": buildNodeInfo("ondemand-template", nil),
+	}
+
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(&fake.Clientset{}, "kube-system", kube_record.NewFakeRecorder(5), false)
+	csr := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+		MaxNodeProvisionTime:      2 * time.Minute,
+	}, fakeLogRecorder, backoff.NewIdBasedExponentialBackoff(time.Minute, time.Hour, time.Hour),
+		nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, csr.UpdateNodes([]*apiv1.Node{spotNode, onDemandNode}, nodeInfos, now))
+
+	processor := NewSpotFallbackProcessor(15 * time.Minute)
+	options := []expander.Option{{NodeGroup: onDemand, NodeCount: 1}, {NodeGroup: spot, NodeCount: 1}}
+
+	// Spot is preferred while it is healthy.
+	assert.Equal(t, []string{"spot"}, optionIds(processor.FilterOptions(options, nodeInfos, csr, now)))
+	assert.Equal(t, []string{"ondemand"}, optionIds(processor.FilterOptions(options[:1], nodeInfos, csr, now)))
+	processor.RegisterScaleUp(onDemand, now)
+
+	// Spot nodes fail to register in time.
+	csr.RegisterOrUpdateScaleUp(spot, 1, now.Add(-3*time.Minute))
+	assert.NoError(t, csr.UpdateNodes([]*apiv1.Node{spotNode, onDemandNode}, nodeInfos, now))
+	now = now.Add(time.Minute)
+	assert.Equal(t, []string{"ondemand"}, optionIds(processor.FilterOptions(options, nodeInfos, csr, now)))
+	assert.Equal(t, []string{"spot"}, optionIds(processor.FilterOptions(options[1:], nodeInfos, csr, now)))
+	processor.RegisterScaleUp(spot, now)
+	processor.RegisterScaleUp(onDemand, now)
+
+	// Nodes are not moved back to spot during the fallback.
+	fallbackNode := buildReadyNode("ondemand-2", now.Add(time.Minute))
+	now = now.Add(5 * time.Minute)
+	assert.False(t, processor.IsRebalanceCandidate(fallbackNode, onDemand, csr, now))
+
+	// After the fallback, spot is preferred again and nodes added during the fallback are rebalanced.
+	now = now.Add(10 * time.Minute)
+	assert.Equal(t, []string{"spot"}, optionIds(processor.FilterOptions(options, nodeInfos, csr, now)))
+	assert.True(t, processor.IsRebalanceCandidate(fallbackNode, onDemand, csr, now))
+	assert.False(t, processor.IsRebalanceCandidate(onDemandNode, onDemand, csr, now))
+	assert.False(t, processor.IsRebalanceCandidate(buildReadyNode("spot-2", now.Add(-10*time.Minute)), spot, csr, now))
+
+	// Scale-ups after the fallback do not add rebalance candidates.
+	processor.RegisterScaleUp(onDemand, now)
+	assert.False(t, processor.IsRebalanceCandidate(buildReadyNode("ondemand-3", now.Add(time.Minute)), onDemand, csr, now.Add(time.Minute)))
+}
+
+func TestSpotFallbackProcessorIgnoresOtherFailures(t *testing.T) {
+	now := time.Now()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("spot", 0, 10, 0)
+	provider.AddNodeGroup("ondemand", 0, 10, 0)
+	spot := provider.GetNodeGroup("spot")
+	onDemand := provider.GetNodeGroup("ondemand")
+	nodeInfos := map[string]*schedulernodeinfo.NodeInfo{
+		"spot":     buildNodeInfo("spot-template", map[string]string{CapacityTypeLabel: "spot"}),
+		"ondemand": buildNodeInfo("ondemand-template", nil),
+	}
+
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(&fake.Clientset{}, "kube-system", kube_record.NewFakeRecorder(5), false)
+	csr := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+	}, fakeLogRecorder, backoff.NewIdBasedExponentialBackoff(time.Minute, time.Hour, time.Hour),
+		nodegroupconfig.NewDefaultNodeGroupConfigProcessor())
+	assert.NoError(t, csr.UpdateNodes([]*apiv1.Node{}, nodeInfos, now))
+	csr.RegisterFailedScaleUp(spot, "cloudProviderError", now)
+
+	processor := NewSpotFallbackProcessor(15 * time.Minute)
+	options := []expander.Option{{NodeGroup: onDemand, NodeCount: 1}, {NodeGroup: spot, NodeCount: 1}}
+	assert.Equal(t, []string{"spot"}, optionIds(processor.FilterOptions(options, nodeInfos, csr, now)))
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	kube_client "k8s.io/client-go/kubernetes"
+
+	"k8s.io/klog"
+)
+
+const (
+	// HistoryConfigMapName is the name of the ConfigMap keeping the demand history between restarts.
+	HistoryConfigMapName = "cluster-autoscaler-forecast"
+	// historyConfigMapKey is the key of the demand history in the ConfigMap.
+	historyConfigMapKey = "history"
+	// headroomName is the name of the headroom reserving predicted demand.
+	headroomName = "forecast"
+	// maxPlaceholderPods limits the number of virtual pods reserving predicted demand.
+	maxPlaceholderPods = 500
+)
+
+// ForecastPodListProcessor learns the daily and weekly pattern of the resources requested by
+// all pods in the cluster and reserves the demand expected within lookahead ahead of time, so
+// nodes for a recurring spike are provisioned before the pods are created.
+//
+// Total demand rather than only unschedulable pods is recorded, so that the pattern doesn't
+// fade away once nodes are provisioned in time and pods no longer wait for them. The demand
+// missing from the current one is reserved by virtual headroom pods, which fill free capacity
+// of existing nodes first and trigger scale-up for the rest.
+type ForecastPodListProcessor struct {
+	lookahead time.Duration
+	history   *History
+	loaded    bool
+	now       func() time.Time
+}
+
+// NewForecastPodListProcessor creates a processor reserving the demand predicted within lookahead.
+func NewForecastPodListProcessor(lookahead time.Duration) pods.PodListProcessor {
+	return &ForecastPodListProcessor{
+		lookahead: lookahead,
+		history:   NewHistory(),
+		now:       time.Now,
+	}
+}
+
+// Process records the current demand and adds virtual pods reserving the predicted demand.
+func (p *ForecastPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod, allScheduled []*apiv1.Pod, nodes []*apiv1.Node) ([]*apiv1.Pod, []*apiv1.Pod, error) {
+	now := p.now()
+	if !p.loaded && context.ClientSet != nil {
+		history, err := LoadHistory(context.ClientSet, context.ConfigNamespace)
+		if err != nil {
+			klog.Warningf("Failed to load demand history, starting from scratch: %v", err)
+		} else if history != nil {
+			p.history = history
+		}
+		p.loaded = true
+	}
+
+	realPods := append(headroom.FilterOutHeadroomPods(unschedulablePods), headroom.FilterOutHeadroomPods(allScheduled)...)
+	current := PodsDemand(realPods)
+	if p.history.Record(current, now) && context.ClientSet != nil {
+		if err := SaveHistory(context.ClientSet, context.ConfigNamespace, p.history); err != nil {
+			klog.Warningf("Failed to save demand history: %v", err)
+		}
+	}
+
+	predicted, found := p.history.Predict(now, now.Add(p.lookahead))
+	if !found {
+		return unschedulablePods, allScheduled, nil
+	}
+	spec, needed := placeholderSpec(predicted, current)
+	if !needed {
+		return unschedulablePods, allScheduled, nil
+	}
+	klog.V(1).Infof("Reserving predicted demand of %v CPU and %v memory in %d blocks", spec.CPU, spec.Memory, spec.Replicas)
+	return headroom.NewHeadroomPodListProcessor([]headroom.Spec{spec}).Process(context, unschedulablePods, allScheduled, nodes)
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *ForecastPodListProcessor) CleanUp() {
+}
+
+// placeholderSpec returns the headroom reserving the predicted demand missing from the current
+// one, in blocks the size of an average pod at the predicted time. Returns false if no demand
+// is missing.
+func placeholderSpec(predicted, current Demand) (headroom.Spec, bool) {
+	missingCPU := math.Max(predicted.MilliCPU-current.MilliCPU, 0)
+	missingMemory := math.Max(predicted.Memory-current.Memory, 0)
+	if predicted.Pods < 1 || (missingCPU < 1 && missingMemory < 1) {
+		return headroom.Spec{}, false
+	}
+	replicas := 0.0
+	if averageCPU := predicted.MilliCPU / predicted.Pods; averageCPU >= 1 {
+		replicas = math.Max(replicas, math.Ceil(missingCPU/averageCPU))
+	}
+	if averageMemory := predicted.Memory / predicted.Pods; averageMemory >= 1 {
+		replicas = math.Max(replicas, math.Ceil(missingMemory/averageMemory))
+	}
+	replicas = math.Min(math.Max(replicas, 1), maxPlaceholderPods)
+
+	spec := headroom.Spec{Name: headroomName, Replicas: int(replicas)}
+	if cpu := int64(missingCPU / replicas); cpu > 0 {
+		spec.CPU = resource.NewMilliQuantity(cpu, resource.DecimalSI)
+	}
+	if memory := int64(missingMemory / replicas); memory > 0 {
+		spec.Memory = resource.NewQuantity(memory, resource.BinarySI)
+	}
+	if spec.CPU == nil && spec.Memory == nil {
+		return headroom.Spec{}, false
+	}
+	return spec, true
+}
+
+// LoadHistory reads the demand history from its ConfigMap. Returns nil if there is no saved history.
+func LoadHistory(kubeClient kube_client.Interface, namespace string) (*History, error) {
+	configMap, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(HistoryConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	history := &History{}
+	if err := json.Unmarshal([]byte(configMap.Data[historyConfigMapKey]), history); err != nil {
+		return nil, fmt.Errorf("failed to parse demand history: %v", err)
+	}
+	if !history.valid() {
+		return nil, fmt.Errorf("demand history has unexpected number of slots")
+	}
+	return history, nil
+}
+
+// SaveHistory writes the demand history to its ConfigMap, creating the ConfigMap if needed.
+func SaveHistory(kubeClient kube_client.Interface, namespace string, history *History) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	maps := kubeClient.CoreV1().ConfigMaps(namespace)
+	configMap, err := maps.Get(HistoryConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		_, err = maps.Create(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: HistoryConfigMapName},
+			Data:       map[string]string{historyConfigMapKey: string(data)},
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[historyConfigMapKey] = string(data)
+	_, err = maps.Update(configMap)
+	return err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceholderSpec(t *testing.T) {
+	predicted := Demand{MilliCPU: 4000, Memory: 4000, Pods: 8}
+
+	spec, needed := placeholderSpec(predicted, Demand{MilliCPU: 1000, Memory: 3000, Pods: 4})
+	assert.True(t, needed)
+	// 3000m CPU are missing, an average pod requests 500m.
+	assert.Equal(t, 6, spec.Replicas)
+	assert.Equal(t, int64(500), spec.CPU.MilliValue())
+	assert.Equal(t, int64(166), spec.Memory.Value())
+
+	spec, needed = placeholderSpec(predicted, Demand{MilliCPU: 5000, Memory: 3500, Pods: 10})
+	assert.True(t, needed)
+	assert.Equal(t, 1, spec.Replicas)
+	assert.Nil(t, spec.CPU)
+	assert.Equal(t, int64(500), spec.Memory.Value())
+
+	_, needed = placeholderSpec(predicted, Demand{MilliCPU: 5000, Memory: 5000, Pods: 10})
+	assert.False(t, needed)
+	_, needed = placeholderSpec(Demand{}, Demand{})
+	assert.False(t, needed)
+}
+
+func TestForecastPodListProcessor(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, time.Time{})
+	p1 := BuildTestPod("p1", 500, 0)
+	p1.Spec.NodeName = "n1"
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNode("ng1", n1)
+
+	kubeClient := fake.NewSimpleClientset()
+	context := &context.AutoscalingContext{
+		AutoscalingOptions:     config.AutoscalingOptions{ConfigNamespace: "kube-system"},
+		AutoscalingKubeClients: context.AutoscalingKubeClients{ClientSet: kubeClient},
+		CloudProvider:          provider,
+		PredicateChecker:       simulator.NewTestPredicateChecker(),
+	}
+
+	// Three days with a spike of 2500m CPU in 5 pods at 8:00.
+	monday := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	history := NewHistory()
+	recordDays(history, monday, 3, Demand{MilliCPU: 2500, Pods: 5})
+	assert.NoError(t, SaveHistory(kubeClient, "kube-system", history))
+
+	// Nothing is reserved long before the spike.
+	now := monday.AddDate(0, 0, 3).Add(5*time.Hour + 55*time.Minute)
+	processor := NewForecastPodListProcessor(10 * time.Minute).(*ForecastPodListProcessor)
+	processor.now = func() time.Time { return now }
+	unschedulable, scheduled, err := processor.Process(context, []*apiv1.Pod{}, []*apiv1.Pod{p1}, []*apiv1.Node{n1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{p1}, scheduled)
+	assert.Empty(t, unschedulable)
+
+	// Completed slots are saved.
+	now = now.Add(SlotDuration)
+	_, _, err = processor.Process(context, []*apiv1.Pod{}, []*apiv1.Pod{p1}, []*apiv1.Node{n1})
+	assert.NoError(t, err)
+	saved, err := LoadHistory(kubeClient, "kube-system")
+	assert.NoError(t, err)
+	daily, _ := slotIndices(now.Add(-SlotDuration))
+	assert.Equal(t, 4, saved.Daily[daily].Observations)
+
+	// The spike is reserved ahead of time by a restarted processor.
+	now = monday.AddDate(0, 0, 3).Add(7*time.Hour + 55*time.Minute)
+	processor = NewForecastPodListProcessor(10 * time.Minute).(*ForecastPodListProcessor)
+	processor.now = func() time.Time { return now }
+	unschedulable, scheduled, err = processor.Process(context, []*apiv1.Pod{}, []*apiv1.Pod{p1}, []*apiv1.Node{n1})
+	assert.NoError(t, err)
+	// 2000m CPU are missing in blocks of 500m. One block fits on n1, the rest is pending.
+	assert.Equal(t, 2, len(scheduled))
+	assert.True(t, headroom.IsHeadroomPod(scheduled[1]))
+	assert.Equal(t, "n1", scheduled[1].Spec.NodeName)
+	assert.Equal(t, 3, len(unschedulable))
+	for _, pod := range unschedulable {
+		assert.True(t, headroom.IsHeadroomPod(pod))
+	}
+}
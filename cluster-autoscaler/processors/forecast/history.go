@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SlotDuration is the resolution of the demand history.
+	SlotDuration = 15 * time.Minute
+	// dailySlots is the number of slots in a day.
+	dailySlots = int(24 * time.Hour / SlotDuration)
+	// weeklySlots is the number of slots in a week.
+	weeklySlots = 7 * dailySlots
+	// minDailyObservations is the number of days a daily slot has to be observed before it is used for predictions.
+	minDailyObservations = 3
+	// minWeeklyObservations is the number of weeks a weekly slot has to be observed before it is preferred over the daily one.
+	minWeeklyObservations = 2
+	// smoothingFactor is the weight of the latest observation in the moving average of a slot.
+	smoothingFactor = 0.3
+)
+
+// Demand is the amount of resources requested by pods.
+type Demand struct {
+	MilliCPU float64 `json:"milliCPU"`
+	Memory   float64 `json:"memory"`
+	Pods     float64 `json:"pods"`
+}
+
+// PodsDemand returns the resources requested by the given pods.
+func PodsDemand(pods []*apiv1.Pod) Demand {
+	result := Demand{Pods: float64(len(pods))}
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			if request, found := container.Resources.Requests[apiv1.ResourceCPU]; found {
+				result.MilliCPU += float64(request.MilliValue())
+			}
+			if request, found := container.Resources.Requests[apiv1.ResourceMemory]; found {
+				result.Memory += float64(request.Value())
+			}
+		}
+	}
+	return result
+}
+
+func maxDemand(a, b Demand) Demand {
+	if b.MilliCPU > a.MilliCPU {
+		a.MilliCPU = b.MilliCPU
+	}
+	if b.Memory > a.Memory {
+		a.Memory = b.Memory
+	}
+	if b.Pods > a.Pods {
+		a.Pods = b.Pods
+	}
+	return a
+}
+
+// slotStats is the moving average of the peak demand observed in a slot.
+type slotStats struct {
+	Average      Demand `json:"average"`
+	Observations int    `json:"observations"`
+}
+
+func (s *slotStats) observe(peak Demand) {
+	if s.Observations == 0 {
+		s.Average = peak
+	} else {
+		s.Average.MilliCPU += smoothingFactor * (peak.MilliCPU - s.Average.MilliCPU)
+		s.Average.Memory += smoothingFactor * (peak.Memory - s.Average.Memory)
+		s.Average.Pods += smoothingFactor * (peak.Pods - s.Average.Pods)
+	}
+	s.Observations++
+}
+
+// History keeps the peak demand of the cluster in every slot of a day and of a week.
+// Times are in UTC.
+type History struct {
+	Daily  []slotStats `json:"daily"`
+	Weekly []slotStats `json:"weekly"`
+	// CurrentSlot is the start of the slot that is being observed.
+	CurrentSlot time.Time `json:"currentSlot"`
+	// CurrentPeak is the peak demand in the current slot so far.
+	CurrentPeak Demand `json:"currentPeak"`
+}
+
+// NewHistory creates an empty history.
+func NewHistory() *History {
+	return &History{
+		Daily:  make([]slotStats, dailySlots),
+		Weekly: make([]slotStats, weeklySlots),
+	}
+}
+
+// valid returns true if the history has the expected number of slots, e.g. after it was restored.
+func (h *History) valid() bool {
+	return len(h.Daily) == dailySlots && len(h.Weekly) == weeklySlots
+}
+
+func slotIndices(slot time.Time) (int, int) {
+	slot = slot.UTC()
+	daily := (slot.Hour()*60 + slot.Minute()) / int(SlotDuration/time.Minute)
+	return daily, int(slot.Weekday())*dailySlots + daily
+}
+
+// Record adds the current demand to the history. Returns true if a slot was completed,
+// i.e. the history has changed in a way worth saving.
+func (h *History) Record(demand Demand, now time.Time) bool {
+	slot := now.UTC().Truncate(SlotDuration)
+	completed := false
+	if slot.After(h.CurrentSlot) {
+		if !h.CurrentSlot.IsZero() {
+			daily, weekly := slotIndices(h.CurrentSlot)
+			h.Daily[daily].observe(h.CurrentPeak)
+			h.Weekly[weekly].observe(h.CurrentPeak)
+			completed = true
+		}
+		h.CurrentSlot = slot
+		h.CurrentPeak = Demand{}
+	}
+	h.CurrentPeak = maxDemand(h.CurrentPeak, demand)
+	return completed
+}
+
+// Predict returns the highest demand expected between from and to. Weekly slots are used
+// once they were observed for enough weeks, daily slots otherwise. Returns false if there
+// is not enough history for any slot in the period.
+func (h *History) Predict(from, to time.Time) (Demand, bool) {
+	var result Demand
+	found := false
+	for slot := from.UTC().Truncate(SlotDuration); !slot.After(to); slot = slot.Add(SlotDuration) {
+		daily, weekly := slotIndices(slot)
+		var stats slotStats
+		if h.Weekly[weekly].Observations >= minWeeklyObservations {
+			stats = h.Weekly[weekly]
+		} else if h.Daily[daily].Observations >= minDailyObservations {
+			stats = h.Daily[daily]
+		} else {
+			continue
+		}
+		result = maxDemand(result, stats.Average)
+		found = true
+	}
+	return result, found
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordDays records a morning spike of the given demand at 8:00-8:15 and no demand
+// otherwise, every day starting with the given Monday.
+func recordDays(history *History, monday time.Time, days int, spike Demand) {
+	for day := 0; day < days; day++ {
+		start := monday.AddDate(0, 0, day)
+		for slot := time.Duration(0); slot < 24*time.Hour; slot += SlotDuration {
+			demand := Demand{}
+			if slot == 8*time.Hour {
+				demand = spike
+			}
+			history.Record(demand, start.Add(slot))
+			history.Record(demand, start.Add(slot+time.Minute))
+		}
+	}
+}
+
+func TestPodsDemand(t *testing.T) {
+	p1 := BuildTestPod("p1", 100, 1000)
+	p2 := BuildTestPod("p2", 300, 0)
+	assert.Equal(t, Demand{MilliCPU: 400, Memory: 1000, Pods: 2}, PodsDemand([]*apiv1.Pod{p1, p2}))
+	assert.Equal(t, Demand{}, PodsDemand(nil))
+}
+
+func TestHistoryRecord(t *testing.T) {
+	history := NewHistory()
+	start := time.Date(2019, 4, 1, 8, 0, 0, 0, time.UTC)
+	assert.False(t, history.Record(Demand{MilliCPU: 100}, start))
+	assert.False(t, history.Record(Demand{MilliCPU: 300}, start.Add(5*time.Minute)))
+	assert.False(t, history.Record(Demand{MilliCPU: 200}, start.Add(10*time.Minute)))
+	assert.Equal(t, 300.0, history.CurrentPeak.MilliCPU)
+
+	assert.True(t, history.Record(Demand{MilliCPU: 50}, start.Add(SlotDuration)))
+	daily, weekly := slotIndices(start)
+	assert.Equal(t, 32, daily)
+	assert.Equal(t, dailySlots+32, weekly)
+	assert.Equal(t, slotStats{Average: Demand{MilliCPU: 300}, Observations: 1}, history.Daily[daily])
+	assert.Equal(t, slotStats{Average: Demand{MilliCPU: 300}, Observations: 1}, history.Weekly[weekly])
+	assert.Equal(t, 50.0, history.CurrentPeak.MilliCPU)
+}
+
+func TestHistoryPredict(t *testing.T) {
+	monday := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	spike := Demand{MilliCPU: 4000, Memory: 8000, Pods: 20}
+	history := NewHistory()
+
+	// Two days are not enough to trust the daily pattern.
+	recordDays(history, monday, 2, spike)
+	_, found := history.Predict(monday.AddDate(0, 0, 2).Add(7*time.Hour+50*time.Minute), monday.AddDate(0, 0, 2).Add(8*time.Hour))
+	assert.False(t, found)
+
+	recordDays(history, monday.AddDate(0, 0, 2), 1, spike)
+	wednesday := monday.AddDate(0, 0, 3)
+	predicted, found := history.Predict(wednesday.Add(7*time.Hour+50*time.Minute), wednesday.Add(8*time.Hour))
+	assert.True(t, found)
+	assert.Equal(t, spike, predicted)
+	predicted, found = history.Predict(wednesday.Add(6*time.Hour), wednesday.Add(7*time.Hour))
+	assert.True(t, found)
+	assert.Equal(t, Demand{}, predicted)
+
+	// A quiet Saturday morning is learned once seen on two weekends.
+	history = NewHistory()
+	for week := 0; week < 2; week++ {
+		start := monday.AddDate(0, 0, 7*week)
+		recordDays(history, start, 5, spike)
+		recordDays(history, start.AddDate(0, 0, 5), 2, Demand{})
+	}
+	saturday := monday.AddDate(0, 0, 19)
+	predicted, found = history.Predict(saturday.Add(8*time.Hour), saturday.Add(8*time.Hour))
+	assert.True(t, found)
+	assert.Equal(t, Demand{}, predicted)
+	monday = monday.AddDate(0, 0, 21)
+	predicted, found = history.Predict(monday.Add(8*time.Hour), monday.Add(8*time.Hour))
+	assert.True(t, found)
+	assert.Equal(t, spike, predicted)
+}
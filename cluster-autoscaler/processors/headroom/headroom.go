@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// Spec is the user-facing definition of spare capacity kept in the cluster.
+//
+// Headroom is either given as resources, kept free in Replicas blocks of CPU and Memory each,
+// or as a number of free nodes of a node group.
+type Spec struct {
+	// Name identifies the headroom in logs and in names of headroom pods.
+	Name string `json:"name"`
+	// NodeGroup restricts the headroom to nodes of the node group with the given id.
+	// Empty means the headroom may be kept anywhere in the cluster.
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	// Nodes is the number of free nodes of NodeGroup to keep.
+	Nodes int `json:"nodes,omitempty"`
+	// CPU is the amount of CPU kept free in a single block.
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// Memory is the amount of memory kept free in a single block.
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// Replicas is the number of blocks. Defaults to 1.
+	Replicas int `json:"replicas,omitempty"`
+}
+
+// Config is the content of the headroom configuration file.
+type Config struct {
+	Headroom []Spec `json:"headroom"`
+}
+
+// Validate checks that the spec is complete and not ambiguous.
+func (s *Spec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("headroom without name")
+	}
+	hasResources := s.CPU != nil || s.Memory != nil
+	if s.Nodes < 0 || s.Replicas < 0 {
+		return fmt.Errorf("headroom %s has negative nodes or replicas", s.Name)
+	}
+	if s.Nodes > 0 {
+		if s.NodeGroup == "" {
+			return fmt.Errorf("headroom %s sets nodes without node group", s.Name)
+		}
+		if hasResources || s.Replicas > 0 {
+			return fmt.Errorf("headroom %s sets both nodes and resources", s.Name)
+		}
+		return nil
+	}
+	if !hasResources {
+		return fmt.Errorf("headroom %s sets neither nodes nor resources", s.Name)
+	}
+	for resourceName, quantity := range map[apiv1.ResourceName]*resource.Quantity{apiv1.ResourceCPU: s.CPU, apiv1.ResourceMemory: s.Memory} {
+		if quantity != nil && quantity.Sign() <= 0 {
+			return fmt.Errorf("headroom %s has non-positive %s", s.Name, resourceName)
+		}
+	}
+	return nil
+}
+
+// ParseConfig parses and validates a YAML or JSON headroom configuration.
+func ParseConfig(data []byte) ([]Spec, error) {
+	config := Config{}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse headroom config: %v", err)
+	}
+	names := make(map[string]bool)
+	for i := range config.Headroom {
+		spec := &config.Headroom[i]
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("headroom %s defined more than once", spec.Name)
+		}
+		names[spec.Name] = true
+		if spec.Nodes == 0 && spec.Replicas == 0 {
+			spec.Replicas = 1
+		}
+	}
+	return config.Headroom, nil
+}
+
+// LoadConfigFile reads headroom specs from the given file.
+func LoadConfigFile(path string) ([]Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headroom config %s: %v", path, err)
+	}
+	return ParseConfig(data)
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+const (
+	// PodAnnotationKey marks virtual pods reserving headroom. The value is the headroom name.
+	PodAnnotationKey = "cluster-autoscaler.kubernetes.io/headroom"
+	// podNamespace is the namespace of virtual headroom pods. They are never created in the API server.
+	podNamespace = "default"
+	// controllerKind is the kind of the virtual controller owning headroom pods.
+	controllerKind = "Headroom"
+)
+
+// IsHeadroomPod returns true if the pod is a virtual pod reserving headroom.
+func IsHeadroomPod(pod *apiv1.Pod) bool {
+	_, found := pod.Annotations[PodAnnotationKey]
+	return found
+}
+
+// FilterOutHeadroomPods returns pods that are not virtual headroom pods.
+func FilterOutHeadroomPods(allPods []*apiv1.Pod) []*apiv1.Pod {
+	result := make([]*apiv1.Pod, 0, len(allPods))
+	for _, pod := range allPods {
+		if !IsHeadroomPod(pod) {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+// HeadroomPodListProcessor reserves spare capacity by adding virtual pods to the pod lists.
+// Headroom pods fitting on existing nodes are added to scheduled pods, so scale-down keeps
+// enough free capacity to reschedule them. The rest is added to unschedulable pods and
+// triggers scale-up.
+type HeadroomPodListProcessor struct {
+	specs []Spec
+}
+
+// NewHeadroomPodListProcessor creates a processor keeping the given headroom.
+func NewHeadroomPodListProcessor(specs []Spec) pods.PodListProcessor {
+	return &HeadroomPodListProcessor{specs: specs}
+}
+
+// Process adds virtual headroom pods to the pod lists.
+func (p *HeadroomPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod, allScheduled []*apiv1.Pod, nodes []*apiv1.Node) ([]*apiv1.Pod, []*apiv1.Pod, error) {
+	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(allScheduled, nodes)
+	schedulableNodes := make([]*apiv1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if ready, _, _ := kube_util.GetReadinessState(node); !ready || deletetaint.HasToBeDeletedTaint(node) {
+			continue
+		}
+		schedulableNodes = append(schedulableNodes, node)
+	}
+	sort.Slice(schedulableNodes, func(i, j int) bool {
+		return schedulableNodes[i].Name < schedulableNodes[j].Name
+	})
+
+	for _, spec := range p.specs {
+		var nodeGroup cloudprovider.NodeGroup
+		candidates := schedulableNodes
+		if spec.NodeGroup != "" {
+			var err error
+			nodeGroup, candidates, err = nodeGroupNodes(context.CloudProvider, spec.NodeGroup, schedulableNodes)
+			if err != nil {
+				klog.Warningf("Skipping headroom %s: %v", spec.Name, err)
+				continue
+			}
+		}
+		headroomPods, err := buildPods(spec, nodeGroup, candidates, nodeNameToNodeInfo)
+		if err != nil {
+			klog.Warningf("Skipping headroom %s: %v", spec.Name, err)
+			continue
+		}
+		pending := 0
+		for _, pod := range headroomPods {
+			if nodeName, found := fitPod(context, pod, candidates, nodeNameToNodeInfo); found {
+				pod.Spec.NodeName = nodeName
+				nodeNameToNodeInfo[nodeName].AddPod(pod)
+				allScheduled = append(allScheduled, pod)
+			} else {
+				unschedulablePods = append(unschedulablePods, pod)
+				pending++
+			}
+		}
+		klog.V(2).Infof("Headroom %s: %d of %d blocks fit on existing nodes", spec.Name, len(headroomPods)-pending, len(headroomPods))
+	}
+	return unschedulablePods, allScheduled, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *HeadroomPodListProcessor) CleanUp() {
+}
+
+func fitPod(context *context.AutoscalingContext, pod *apiv1.Pod, nodes []*apiv1.Node, nodeNameToNodeInfo map[string]*schedulernodeinfo.NodeInfo) (string, bool) {
+	for _, node := range nodes {
+		nodeInfo, found := nodeNameToNodeInfo[node.Name]
+		if !found {
+			continue
+		}
+		if err := context.PredicateChecker.CheckPredicates(pod, nil, nodeInfo); err == nil {
+			return node.Name, true
+		}
+	}
+	return "", false
+}
+
+// nodeGroupNodes returns the node group with the given id and those of nodes that belong to it.
+func nodeGroupNodes(cloudProvider cloudprovider.CloudProvider, id string, nodes []*apiv1.Node) (cloudprovider.NodeGroup, []*apiv1.Node, error) {
+	var nodeGroup cloudprovider.NodeGroup
+	for _, candidate := range cloudProvider.NodeGroups() {
+		if candidate.Id() == id {
+			nodeGroup = candidate
+			break
+		}
+	}
+	if nodeGroup == nil {
+		return nil, nil, fmt.Errorf("node group %s not found", id)
+	}
+	result := make([]*apiv1.Node, 0)
+	for _, node := range nodes {
+		candidate, err := cloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			return nil, nil, err
+		}
+		if candidate == nil || reflect.ValueOf(candidate).IsNil() || candidate.Id() != id {
+			continue
+		}
+		result = append(result, node)
+	}
+	return nodeGroup, result, nil
+}
+
+// buildPods creates virtual pods reserving the headroom. Pods of a node group headroom are
+// restricted to nodes with the labels of the node group's nodes. A free node is reserved by
+// a pod requesting the node allocatable, except for resources used by DaemonSet and mirror pods.
+func buildPods(spec Spec, nodeGroup cloudprovider.NodeGroup, nodeGroupNodes []*apiv1.Node,
+	nodeNameToNodeInfo map[string]*schedulernodeinfo.NodeInfo) ([]*apiv1.Pod, error) {
+
+	requests := apiv1.ResourceList{}
+	if spec.CPU != nil {
+		requests[apiv1.ResourceCPU] = *spec.CPU
+	}
+	if spec.Memory != nil {
+		requests[apiv1.ResourceMemory] = *spec.Memory
+	}
+	replicas := spec.Replicas
+	var nodeSelector map[string]string
+	if nodeGroup != nil {
+		var node *apiv1.Node
+		var nodePods []*apiv1.Pod
+		if len(nodeGroupNodes) > 0 {
+			node = nodeGroupNodes[0]
+			if nodeInfo, found := nodeNameToNodeInfo[node.Name]; found {
+				nodePods = systemPods(nodeInfo.Pods())
+			}
+		} else {
+			nodeInfo, err := nodeGroup.TemplateNodeInfo()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get template of node group %s: %v", nodeGroup.Id(), err)
+			}
+			node = nodeInfo.Node()
+			nodePods = nodeInfo.Pods()
+		}
+		nodeSelector = make(map[string]string)
+		for key, value := range node.Labels {
+			if key != apiv1.LabelHostname {
+				nodeSelector[key] = value
+			}
+		}
+		if spec.Nodes > 0 {
+			requests = freeNodeResources(node, nodePods)
+			replicas = spec.Nodes
+		}
+	}
+
+	controllerRef := metav1.OwnerReference{
+		Kind:       controllerKind,
+		Name:       spec.Name,
+		UID:        types.UID(fmt.Sprintf("headroom-%s", spec.Name)),
+		Controller: &[]bool{true}[0],
+	}
+	result := make([]*apiv1.Pod, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		name := fmt.Sprintf("headroom-%s-%d", spec.Name, i)
+		result = append(result, &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: podNamespace,
+				UID:       types.UID(name),
+				Annotations: map[string]string{
+					PodAnnotationKey:        spec.Name,
+					drain.PodSafeToEvictKey: "true",
+				},
+				OwnerReferences: []metav1.OwnerReference{controllerRef},
+			},
+			Spec: apiv1.PodSpec{
+				NodeSelector: nodeSelector,
+				Containers: []apiv1.Container{{
+					Name:      "headroom",
+					Resources: apiv1.ResourceRequirements{Requests: requests.DeepCopy()},
+				}},
+			},
+			Status: apiv1.PodStatus{
+				Phase: apiv1.PodPending,
+			},
+		})
+	}
+	return result, nil
+}
+
+// systemPods returns DaemonSet and mirror pods, which run on every new node of a node group.
+func systemPods(nodePods []*apiv1.Pod) []*apiv1.Pod {
+	result := make([]*apiv1.Pod, 0)
+	for _, pod := range nodePods {
+		if drain.IsMirrorPod(pod) {
+			result = append(result, pod)
+			continue
+		}
+		if ref := drain.ControllerRef(pod); ref != nil && ref.Kind == "DaemonSet" {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func freeNodeResources(node *apiv1.Node, nodePods []*apiv1.Pod) apiv1.ResourceList {
+	result := apiv1.ResourceList{}
+	for _, resourceName := range []apiv1.ResourceName{apiv1.ResourceCPU, apiv1.ResourceMemory} {
+		allocatable, found := node.Status.Allocatable[resourceName]
+		if !found {
+			continue
+		}
+		free := allocatable.DeepCopy()
+		for _, pod := range nodePods {
+			for _, container := range pod.Spec.Containers {
+				if request, found := container.Resources.Requests[resourceName]; found {
+					free.Sub(request)
+				}
+			}
+		}
+		if free.Sign() > 0 {
+			result[resourceName] = free
+		}
+	}
+	return result
+}
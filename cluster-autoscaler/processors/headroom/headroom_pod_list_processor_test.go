@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadroomPodListProcessor(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 2000, 2000)
+	SetNodeReadyState(n1, true, now)
+	n1.Labels = map[string]string{apiv1.LabelHostname: "n1", "pool": "ng1"}
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now)
+	n2.Labels = map[string]string{apiv1.LabelHostname: "n2", "pool": "ng2"}
+	unready := BuildTestNode("unready", 10000, 10000)
+	SetNodeReadyState(unready, false, now)
+
+	p1 := BuildTestPod("p1", 600, 600)
+	p1.Spec.NodeName = "n1"
+	ds := BuildTestPod("ds", 200, 100)
+	ds.Spec.NodeName = "n2"
+	ds.OwnerReferences = GenerateOwnerReferences("ds", "DaemonSet", "apps/v1", "")
+	pending := BuildTestPod("pending", 100, 100)
+
+	template := BuildTestNode("ng3-template", 4000, 4000)
+	template.Labels = map[string]string{apiv1.LabelHostname: "ng3-template", "pool": "ng3"}
+	templateInfo := schedulernodeinfo.NewNodeInfo(BuildTestPod("template-ds", 500, 500))
+	templateInfo.SetNode(template)
+
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil, nil, nil,
+		map[string]*schedulernodeinfo.NodeInfo{"ng3": templateInfo})
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 1)
+	provider.AddNodeGroup("ng3", 0, 10, 0)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng2", n2)
+	provider.AddNode("ng1", unready)
+
+	cpu := func(value string) *resource.Quantity {
+		quantity := resource.MustParse(value)
+		return &quantity
+	}
+	processor := NewHeadroomPodListProcessor([]Spec{
+		{Name: "cluster", CPU: cpu("1"), Replicas: 1},
+		{Name: "ng1", NodeGroup: "ng1", CPU: cpu("300m"), Replicas: 2},
+		{Name: "ng2", NodeGroup: "ng2", Nodes: 1},
+		{Name: "ng3", NodeGroup: "ng3", Nodes: 2},
+		{Name: "missing", NodeGroup: "missing", Nodes: 1},
+	})
+	context := &context.AutoscalingContext{
+		CloudProvider:    provider,
+		PredicateChecker: simulator.NewTestPredicateChecker(),
+	}
+
+	unschedulable, scheduled, err := processor.Process(context, []*apiv1.Pod{pending}, []*apiv1.Pod{p1, ds}, []*apiv1.Node{n1, n2, unready})
+	assert.NoError(t, err)
+
+	placement := make(map[string]string)
+	for _, pod := range scheduled {
+		if IsHeadroomPod(pod) {
+			placement[pod.Name] = pod.Spec.NodeName
+		}
+	}
+	// The cluster-wide block takes 1 CPU of n1, leaving room for a single ng1 block.
+	// The whole n2 is free except for the DaemonSet pod.
+	assert.Equal(t, map[string]string{
+		"headroom-cluster-0": "n1",
+		"headroom-ng1-0":     "n1",
+		"headroom-ng2-0":     "n2",
+	}, placement)
+	assert.Len(t, scheduled, 5)
+
+	pendingHeadroom := make(map[string]*apiv1.Pod)
+	for _, pod := range unschedulable {
+		if IsHeadroomPod(pod) {
+			pendingHeadroom[pod.Name] = pod
+		}
+	}
+	assert.Len(t, unschedulable, 4)
+	assert.Contains(t, pendingHeadroom, "headroom-ng1-1")
+	assert.Equal(t, map[string]string{"pool": "ng1"}, pendingHeadroom["headroom-ng1-1"].Spec.NodeSelector)
+	// Free nodes of a node group without nodes are sized after the template.
+	for _, name := range []string{"headroom-ng3-0", "headroom-ng3-1"} {
+		pod := pendingHeadroom[name]
+		assert.NotNil(t, pod, name)
+		assert.Equal(t, map[string]string{"pool": "ng3"}, pod.Spec.NodeSelector)
+		requests := pod.Spec.Containers[0].Resources.Requests
+		assert.Equal(t, int64(3500), requests.Cpu().MilliValue())
+		assert.Equal(t, int64(3500), requests.Memory().Value())
+	}
+
+	assert.Equal(t, []*apiv1.Pod{p1, ds}, FilterOutHeadroomPods(scheduled))
+}
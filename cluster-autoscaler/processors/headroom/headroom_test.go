@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	specs, err := ParseConfig([]byte(`
+headroom:
+- name: cluster-spare
+  cpu: 4
+  memory: 16Gi
+- name: batch-spare
+  nodeGroup: batch
+  nodes: 1
+`))
+	assert.NoError(t, err)
+	assert.Len(t, specs, 2)
+	assert.Equal(t, 1, specs[0].Replicas)
+	assert.Equal(t, int64(4000), specs[0].CPU.MilliValue())
+	assert.Equal(t, int64(16*1024*1024*1024), specs[0].Memory.Value())
+	assert.Equal(t, 0, specs[1].Replicas)
+
+	for _, config := range []string{
+		"headroom: [{cpu: 1}]",
+		"headroom: [{name: a}]",
+		"headroom: [{name: a, nodes: 1}]",
+		"headroom: [{name: a, nodeGroup: ng, nodes: 1, cpu: 1}]",
+		"headroom: [{name: a, cpu: 0}]",
+		"headroom: [{name: a, cpu: 1, replicas: -1}]",
+		"headroom: [{name: a, cpu: 1}, {name: a, memory: 1Gi}]",
+		"headroom: [{name: a, cpu: 1, unknown: 1}]",
+	} {
+		_, err := ParseConfig([]byte(config))
+		assert.Error(t, err, "config %s", config)
+	}
+}
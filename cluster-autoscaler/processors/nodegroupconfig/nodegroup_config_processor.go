@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupconfig
+
+import (
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+// NodeGroupConfigProcessor provides autoscaling options of a particular node group.
+type NodeGroupConfigProcessor interface {
+	// GetOptions returns autoscaling options of the node group. Options that are not
+	// overridden for the node group are taken from defaults.
+	GetOptions(nodeGroup cloudprovider.NodeGroup, defaults config.NodeGroupAutoscalingOptions) config.NodeGroupAutoscalingOptions
+	// HasMaxNodeProvisionTime returns true if MaxNodeProvisionTime is set for the node group
+	// rather than taken from defaults, even if the set value equals the default one.
+	HasMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) bool
+	// Refresh reloads the per node group overrides. It is called once per autoscaler loop.
+	Refresh()
+	// CleanUp cleans up the processor's internal structures.
+	CleanUp()
+}
+
+// NoOpNodeGroupConfigProcessor uses the same options for all node groups.
+type NoOpNodeGroupConfigProcessor struct {
+}
+
+// NewDefaultNodeGroupConfigProcessor creates an instance of NodeGroupConfigProcessor.
+func NewDefaultNodeGroupConfigProcessor() NodeGroupConfigProcessor {
+	return &NoOpNodeGroupConfigProcessor{}
+}
+
+// GetOptions returns the defaults.
+func (p *NoOpNodeGroupConfigProcessor) GetOptions(nodeGroup cloudprovider.NodeGroup, defaults config.NodeGroupAutoscalingOptions) config.NodeGroupAutoscalingOptions {
+	return defaults
+}
+
+// HasMaxNodeProvisionTime returns false, as no node group has its own options.
+func (p *NoOpNodeGroupConfigProcessor) HasMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) bool {
+	return false
+}
+
+// Refresh does nothing in NoOpNodeGroupConfigProcessor.
+func (p *NoOpNodeGroupConfigProcessor) Refresh() {
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *NoOpNodeGroupConfigProcessor) CleanUp() {
+}
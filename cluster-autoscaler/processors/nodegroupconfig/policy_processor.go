@@ -0,0 +1,268 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/apis/nodegroupautoscalingpolicy/v1alpha1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// PolicyLister lists NodeGroupAutoscalingPolicy objects.
+type PolicyLister interface {
+	List() ([]*v1alpha1.NodeGroupAutoscalingPolicy, error)
+}
+
+type dynamicPolicyLister struct {
+	store cache.Store
+}
+
+// NewPolicyLister builds a lister of NodeGroupAutoscalingPolicy objects backed by a reflector.
+func NewPolicyLister(client dynamic.Interface, stopChannel <-chan struct{}) PolicyLister {
+	resource := client.Resource(v1alpha1.SchemeGroupVersionResource)
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return resource.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return resource.Watch(options)
+		},
+	}
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	reflector := cache.NewReflector(listWatch, &unstructured.Unstructured{}, store, time.Hour)
+	go reflector.Run(stopChannel)
+	return &dynamicPolicyLister{store: store}
+}
+
+// List returns all policies. Policies that cannot be decoded are skipped.
+func (l *dynamicPolicyLister) List() ([]*v1alpha1.NodeGroupAutoscalingPolicy, error) {
+	result := make([]*v1alpha1.NodeGroupAutoscalingPolicy, 0)
+	for _, obj := range l.store.List() {
+		policy, err := policyFromUnstructured(obj.(*unstructured.Unstructured))
+		if err != nil {
+			klog.Warningf("Skipping invalid %s: %v", v1alpha1.Kind, err)
+			continue
+		}
+		result = append(result, policy)
+	}
+	return result, nil
+}
+
+func policyFromUnstructured(obj *unstructured.Unstructured) (*v1alpha1.NodeGroupAutoscalingPolicy, error) {
+	policy := &v1alpha1.NodeGroupAutoscalingPolicy{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+type compiledPolicy struct {
+	resourceVersion string
+	spec            v1alpha1.NodeGroupAutoscalingPolicySpec
+	nodeGroups      []*regexp.Regexp
+	valid           bool
+}
+
+// PolicyNodeGroupConfigProcessor overrides options of node groups selected by
+// NodeGroupAutoscalingPolicy objects. If several policies select a node group, each
+// option is taken from the first policy setting it, in the order of policy names.
+type PolicyNodeGroupConfigProcessor struct {
+	lister PolicyLister
+	lock   sync.Mutex
+	// compiled holds all listed policies by name, including invalid ones, so that
+	// each version of a policy is compiled and validated once.
+	compiled map[string]*compiledPolicy
+	// policies are the valid policies sorted by name, as of the last refresh.
+	policies  []*compiledPolicy
+	refreshed bool
+}
+
+// NewPolicyNodeGroupConfigProcessor creates a processor using policies from the given lister.
+func NewPolicyNodeGroupConfigProcessor(lister PolicyLister) NodeGroupConfigProcessor {
+	return &PolicyNodeGroupConfigProcessor{
+		lister:   lister,
+		compiled: make(map[string]*compiledPolicy),
+	}
+}
+
+// Refresh lists the policies. Policies that were removed are forgotten and new versions
+// of policies are compiled and validated. If policies cannot be listed, no overrides are
+// applied until the next refresh.
+func (p *PolicyNodeGroupConfigProcessor) Refresh() {
+	policies, err := p.lister.List()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.refreshed = true
+	if err != nil {
+		klog.Errorf("Failed to list %s objects: %v", v1alpha1.Kind, err)
+		p.policies = nil
+		return
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	compiled := make(map[string]*compiledPolicy, len(policies))
+	valid := make([]*compiledPolicy, 0, len(policies))
+	for _, policy := range policies {
+		c, found := p.compiled[policy.Name]
+		if !found || c.resourceVersion != policy.ResourceVersion {
+			c = compilePolicy(policy)
+		}
+		compiled[policy.Name] = c
+		if c.valid {
+			valid = append(valid, c)
+		}
+	}
+	p.compiled = compiled
+	p.policies = valid
+}
+
+// GetOptions returns options of the node group with overrides from matching policies applied.
+func (p *PolicyNodeGroupConfigProcessor) GetOptions(nodeGroup cloudprovider.NodeGroup, defaults config.NodeGroupAutoscalingOptions) config.NodeGroupAutoscalingOptions {
+	policies := p.currentPolicies()
+	var scaleDownEnabled, scaleDownUtilizationThreshold, scaleDownUnneededTime, scaleDownUnreadyTime, maxNodeProvisionTime, maxDisruptedNodesPercentage, expanderPriority bool
+	result := defaults
+	for _, policy := range policies {
+		if !policy.matches(nodeGroup.Id()) {
+			continue
+		}
+		spec := policy.spec
+		if spec.ScaleDownEnabled != nil && !scaleDownEnabled {
+			result.ScaleDownEnabled = *spec.ScaleDownEnabled
+			scaleDownEnabled = true
+		}
+		if spec.ScaleDownUtilizationThreshold != nil && !scaleDownUtilizationThreshold {
+			result.ScaleDownUtilizationThreshold = *spec.ScaleDownUtilizationThreshold
+			scaleDownUtilizationThreshold = true
+		}
+		if spec.ScaleDownUnneededTime != nil && !scaleDownUnneededTime {
+			result.ScaleDownUnneededTime = spec.ScaleDownUnneededTime.Duration
+			scaleDownUnneededTime = true
+		}
+		if spec.ScaleDownUnreadyTime != nil && !scaleDownUnreadyTime {
+			result.ScaleDownUnreadyTime = spec.ScaleDownUnreadyTime.Duration
+			scaleDownUnreadyTime = true
+		}
+		if spec.MaxNodeProvisionTime != nil && !maxNodeProvisionTime {
+			result.MaxNodeProvisionTime = spec.MaxNodeProvisionTime.Duration
+			maxNodeProvisionTime = true
+		}
+		if spec.MaxDisruptedNodesPercentage != nil && !maxDisruptedNodesPercentage {
+			result.MaxDisruptedNodesPercentage = *spec.MaxDisruptedNodesPercentage
+			maxDisruptedNodesPercentage = true
+		}
+		if spec.ExpanderPriority != nil && !expanderPriority {
+			priority := *spec.ExpanderPriority
+			result.ExpanderPriority = &priority
+			expanderPriority = true
+		}
+	}
+	return result
+}
+
+// HasMaxNodeProvisionTime returns true if a policy matching the node group sets MaxNodeProvisionTime.
+func (p *PolicyNodeGroupConfigProcessor) HasMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) bool {
+	for _, policy := range p.currentPolicies() {
+		if policy.spec.MaxNodeProvisionTime != nil && policy.matches(nodeGroup.Id()) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentPolicies returns the valid policies, listing them first if the processor
+// wasn't refreshed yet.
+func (p *PolicyNodeGroupConfigProcessor) currentPolicies() []*compiledPolicy {
+	p.lock.Lock()
+	refreshed := p.refreshed
+	p.lock.Unlock()
+	if !refreshed {
+		p.Refresh()
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.policies
+}
+
+func compilePolicy(policy *v1alpha1.NodeGroupAutoscalingPolicy) *compiledPolicy {
+	compiled := &compiledPolicy{resourceVersion: policy.ResourceVersion, spec: policy.Spec}
+	if err := validatePolicySpec(policy.Spec); err != nil {
+		klog.Warningf("Ignoring invalid %s %s: %v", v1alpha1.Kind, policy.Name, err)
+		return compiled
+	}
+	for _, expr := range policy.Spec.NodeGroups {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			klog.Warningf("Ignoring invalid node group expression %q in %s %s: %v", expr, v1alpha1.Kind, policy.Name, err)
+			continue
+		}
+		compiled.nodeGroups = append(compiled.nodeGroups, re)
+	}
+	compiled.valid = true
+	return compiled
+}
+
+func validatePolicySpec(spec v1alpha1.NodeGroupAutoscalingPolicySpec) error {
+	if spec.ScaleDownUtilizationThreshold != nil && (*spec.ScaleDownUtilizationThreshold < 0 || *spec.ScaleDownUtilizationThreshold > 1) {
+		return fmt.Errorf("scaleDownUtilizationThreshold must be between 0 and 1, got %v", *spec.ScaleDownUtilizationThreshold)
+	}
+	if spec.ScaleDownUnneededTime != nil && spec.ScaleDownUnneededTime.Duration < 0 {
+		return fmt.Errorf("scaleDownUnneededTime must not be negative, got %v", spec.ScaleDownUnneededTime.Duration)
+	}
+	if spec.ScaleDownUnreadyTime != nil && spec.ScaleDownUnreadyTime.Duration < 0 {
+		return fmt.Errorf("scaleDownUnreadyTime must not be negative, got %v", spec.ScaleDownUnreadyTime.Duration)
+	}
+	if spec.MaxNodeProvisionTime != nil && spec.MaxNodeProvisionTime.Duration <= 0 {
+		return fmt.Errorf("maxNodeProvisionTime must be positive, got %v", spec.MaxNodeProvisionTime.Duration)
+	}
+	if spec.MaxDisruptedNodesPercentage != nil && (*spec.MaxDisruptedNodesPercentage < 0 || *spec.MaxDisruptedNodesPercentage > 100) {
+		return fmt.Errorf("maxDisruptedNodesPercentage must be between 0 and 100, got %v", *spec.MaxDisruptedNodesPercentage)
+	}
+	if spec.ExpanderPriority != nil && *spec.ExpanderPriority < 0 {
+		return fmt.Errorf("expanderPriority must not be negative, got %d", *spec.ExpanderPriority)
+	}
+	return nil
+}
+
+// matches returns true if the policy selects the node group.
+func (c *compiledPolicy) matches(nodeGroupId string) bool {
+	for _, re := range c.nodeGroups {
+		if re.MatchString(nodeGroupId) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *PolicyNodeGroupConfigProcessor) CleanUp() {
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupconfig
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/apis/nodegroupautoscalingpolicy/v1alpha1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePolicyLister struct {
+	policies []*v1alpha1.NodeGroupAutoscalingPolicy
+	err      error
+}
+
+func (l *fakePolicyLister) List() ([]*v1alpha1.NodeGroupAutoscalingPolicy, error) {
+	return l.policies, l.err
+}
+
+func buildPolicy(name string, spec v1alpha1.NodeGroupAutoscalingPolicySpec) *v1alpha1.NodeGroupAutoscalingPolicy {
+	return &v1alpha1.NodeGroupAutoscalingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: "1"},
+		Spec:       spec,
+	}
+}
+
+func TestPolicyNodeGroupConfigProcessorGetOptions(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("gpu-pool", 0, 10, 1)
+	provider.AddNodeGroup("spot-pool", 0, 10, 1)
+	provider.AddNodeGroup("general-pool", 0, 10, 1)
+
+	disabled := false
+	threshold := 0.2
+	otherThreshold := 0.7
+	priority := 50
+	disruption := 20.0
+	lister := &fakePolicyLister{policies: []*v1alpha1.NodeGroupAutoscalingPolicy{
+		buildPolicy("spot", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:                    []string{"^spot-"},
+			ScaleDownUtilizationThreshold: &otherThreshold,
+			ScaleDownUnneededTime:         &metav1.Duration{Duration: time.Minute},
+			MaxDisruptedNodesPercentage:   &disruption,
+			ExpanderPriority:              &priority,
+		}),
+		buildPolicy("gpu", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:                    []string{"^gpu-", "["},
+			ScaleDownEnabled:              &disabled,
+			ScaleDownUtilizationThreshold: &threshold,
+			MaxNodeProvisionTime:          &metav1.Duration{Duration: time.Hour},
+		}),
+		buildPolicy("all", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:                    []string{".*"},
+			ScaleDownUtilizationThreshold: &otherThreshold,
+			ScaleDownUnreadyTime:          &metav1.Duration{Duration: 2 * time.Minute},
+		}),
+	}}
+	processor := NewPolicyNodeGroupConfigProcessor(lister)
+	defaults := config.NodeGroupAutoscalingOptions{
+		ScaleDownEnabled:              true,
+		ScaleDownUtilizationThreshold: 0.5,
+		ScaleDownUnneededTime:         10 * time.Minute,
+		ScaleDownUnreadyTime:          20 * time.Minute,
+		MaxNodeProvisionTime:          15 * time.Minute,
+	}
+
+	gpu := processor.GetOptions(provider.GetNodeGroup("gpu-pool"), defaults)
+	assert.False(t, gpu.ScaleDownEnabled)
+	// Policy "all" sorts before "gpu" and sets the threshold first.
+	assert.Equal(t, 0.7, gpu.ScaleDownUtilizationThreshold)
+	assert.Equal(t, 10*time.Minute, gpu.ScaleDownUnneededTime)
+	assert.Equal(t, 2*time.Minute, gpu.ScaleDownUnreadyTime)
+	assert.Equal(t, time.Hour, gpu.MaxNodeProvisionTime)
+	assert.True(t, processor.HasMaxNodeProvisionTime(provider.GetNodeGroup("gpu-pool")))
+	assert.Nil(t, gpu.ExpanderPriority)
+
+	spot := processor.GetOptions(provider.GetNodeGroup("spot-pool"), defaults)
+	assert.True(t, spot.ScaleDownEnabled)
+	assert.Equal(t, time.Minute, spot.ScaleDownUnneededTime)
+	assert.Equal(t, 15*time.Minute, spot.MaxNodeProvisionTime)
+	assert.False(t, processor.HasMaxNodeProvisionTime(provider.GetNodeGroup("spot-pool")))
+	assert.Equal(t, 20.0, spot.MaxDisruptedNodesPercentage)
+	assert.Equal(t, 50, *spot.ExpanderPriority)
+
+	general := processor.GetOptions(provider.GetNodeGroup("general-pool"), defaults)
+	expected := defaults
+	expected.ScaleDownUtilizationThreshold = 0.7
+	expected.ScaleDownUnreadyTime = 2 * time.Minute
+	assert.Equal(t, expected, general)
+
+	// Updated policies are recompiled.
+	lister.policies = []*v1alpha1.NodeGroupAutoscalingPolicy{
+		buildPolicy("gpu", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:       []string{"^general-"},
+			ScaleDownEnabled: &disabled,
+		}),
+	}
+	lister.policies[0].ResourceVersion = "2"
+	// Policies are listed once per refresh.
+	assert.False(t, processor.GetOptions(provider.GetNodeGroup("gpu-pool"), defaults).ScaleDownEnabled)
+	processor.Refresh()
+	assert.True(t, processor.GetOptions(provider.GetNodeGroup("gpu-pool"), defaults).ScaleDownEnabled)
+	assert.False(t, processor.GetOptions(provider.GetNodeGroup("general-pool"), defaults).ScaleDownEnabled)
+	// Removed policies are forgotten.
+	assert.Len(t, processor.(*PolicyNodeGroupConfigProcessor).compiled, 1)
+
+	// Defaults are used if policies cannot be listed.
+	lister.err = fmt.Errorf("no policies")
+	processor.Refresh()
+	assert.Equal(t, defaults, processor.GetOptions(provider.GetNodeGroup("general-pool"), defaults))
+}
+
+func TestPolicyNodeGroupConfigProcessorIgnoresInvalidPolicies(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+
+	threshold := 1.5
+	percentage := 120.0
+	priority := -1
+	validPriority := 10
+	lister := &fakePolicyLister{policies: []*v1alpha1.NodeGroupAutoscalingPolicy{
+		buildPolicy("a-threshold", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:                    []string{".*"},
+			ScaleDownUtilizationThreshold: &threshold,
+		}),
+		buildPolicy("b-unneeded-time", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:            []string{".*"},
+			ScaleDownUnneededTime: &metav1.Duration{Duration: -time.Minute},
+		}),
+		buildPolicy("c-provision-time", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:           []string{".*"},
+			MaxNodeProvisionTime: &metav1.Duration{},
+		}),
+		buildPolicy("d-disruption", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:                  []string{".*"},
+			MaxDisruptedNodesPercentage: &percentage,
+		}),
+		buildPolicy("e-priority", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:       []string{".*"},
+			ExpanderPriority: &priority,
+		}),
+		buildPolicy("f-valid", v1alpha1.NodeGroupAutoscalingPolicySpec{
+			NodeGroups:       []string{".*"},
+			ExpanderPriority: &validPriority,
+		}),
+	}}
+	processor := NewPolicyNodeGroupConfigProcessor(lister)
+	defaults := config.NodeGroupAutoscalingOptions{
+		ScaleDownUtilizationThreshold: 0.5,
+		ScaleDownUnneededTime:         10 * time.Minute,
+		MaxNodeProvisionTime:          15 * time.Minute,
+	}
+
+	expected := defaults
+	expected.ExpanderPriority = &validPriority
+	assert.Equal(t, expected, processor.GetOptions(provider.GetNodeGroup("ng1"), defaults))
+}
+
+func TestPolicyFromUnstructured(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.x-k8s.io/v1alpha1",
+		"kind":       "NodeGroupAutoscalingPolicy",
+		"metadata": map[string]interface{}{
+			"name":            "gpu",
+			"resourceVersion": "7",
+		},
+		"spec": map[string]interface{}{
+			"nodeGroups":                    []interface{}{"^gpu-"},
+			"scaleDownEnabled":              true,
+			"scaleDownUtilizationThreshold": 0.25,
+			"scaleDownUnneededTime":         "30m",
+			"expanderPriority":              int64(20),
+		},
+	}}
+	policy, err := policyFromUnstructured(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "gpu", policy.Name)
+	assert.Equal(t, "7", policy.ResourceVersion)
+	assert.Equal(t, []string{"^gpu-"}, policy.Spec.NodeGroups)
+	assert.True(t, *policy.Spec.ScaleDownEnabled)
+	assert.Equal(t, 0.25, *policy.Spec.ScaleDownUtilizationThreshold)
+	assert.Equal(t, 30*time.Minute, policy.Spec.ScaleDownUnneededTime.Duration)
+	assert.Nil(t, policy.Spec.ScaleDownUnreadyTime)
+	assert.Equal(t, 20, *policy.Spec.ExpanderPriority)
+
+	obj.Object["spec"].(map[string]interface{})["scaleDownUnneededTime"] = "soon"
+	_, err = policyFromUnstructured(obj)
+	assert.Error(t, err)
+}
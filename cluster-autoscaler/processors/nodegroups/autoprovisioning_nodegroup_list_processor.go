@@ -0,0 +1,187 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/labels"
+	"k8s.io/klog"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// kubernetesTaintPrefixes are prefixes of taints set by Kubernetes. Pods tolerating them
+// don't need autoprovisioned node groups with these taints.
+var kubernetesTaintPrefixes = []string{"node.kubernetes.io/", "node.cloudprovider.kubernetes.io/", "node.alpha.kubernetes.io/"}
+
+// AutoprovisioningNodeGroupListProcessor adds a node group that doesn't exist yet for every
+// machine type of the cloud provider to the node groups considered in scale-up. Unschedulable pods
+// are grouped by the taints they tolerate and the GPUs they request, and a node group is added
+// for every group and machine type. The new node groups get the labels required by most of the
+// pods of their group.
+type AutoprovisioningNodeGroupListProcessor struct {
+}
+
+// NewAutoprovisioningNodeGroupListProcessor creates an instance of AutoprovisioningNodeGroupListProcessor.
+func NewAutoprovisioningNodeGroupListProcessor() NodeGroupListProcessor {
+	return &AutoprovisioningNodeGroupListProcessor{}
+}
+
+// Process adds autoprovisioning candidates to node groups and their templates to node infos.
+func (p *AutoprovisioningNodeGroupListProcessor) Process(context *context.AutoscalingContext, nodeGroups []cloudprovider.NodeGroup, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
+	unschedulablePods []*apiv1.Pod) ([]cloudprovider.NodeGroup, map[string]*schedulernodeinfo.NodeInfo, error) {
+
+	if !context.NodeAutoprovisioningEnabled || len(unschedulablePods) == 0 {
+		return nodeGroups, nodeInfos, nil
+	}
+
+	autoprovisionedCount := 0
+	for _, nodeGroup := range context.CloudProvider.NodeGroups() {
+		if nodeGroup.Autoprovisioned() {
+			autoprovisionedCount++
+		}
+	}
+	if autoprovisionedCount >= context.MaxAutoprovisionedNodeGroupCount {
+		klog.V(4).Infof("Max autoprovisioned node group count reached: %d", autoprovisionedCount)
+		return nodeGroups, nodeInfos, nil
+	}
+
+	machineTypes, err := context.CloudProvider.GetAvailableMachineTypes()
+	if err != nil {
+		klog.Errorf("Failed to get available machine types: %v", err)
+		return nodeGroups, nodeInfos, nil
+	}
+
+	for _, requirements := range groupPodsByRequirements(unschedulablePods) {
+		bestLabels := labels.BestLabelSet(requirements.pods)
+		for _, machineType := range machineTypes {
+			nodeGroup, err := context.CloudProvider.NewNodeGroup(machineType, bestLabels, map[string]string{}, requirements.taints, requirements.extraResources)
+			if err != nil {
+				klog.V(4).Infof("Can't autoprovision node group of machine type %s: %v", machineType, err)
+				continue
+			}
+			nodeInfo, err := nodeGroup.TemplateNodeInfo()
+			if err != nil {
+				klog.Warningf("Failed to build template of node group %s: %v", nodeGroup.Id(), err)
+				continue
+			}
+			nodeInfos[nodeGroup.Id()] = nodeInfo
+			nodeGroups = append(nodeGroups, nodeGroup)
+		}
+	}
+	return nodeGroups, nodeInfos, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *AutoprovisioningNodeGroupListProcessor) CleanUp() {
+}
+
+// podRequirements are the taints and extra resources of node groups autoprovisioned for pods.
+type podRequirements struct {
+	taints         []apiv1.Taint
+	extraResources map[string]resource.Quantity
+	pods           []*apiv1.Pod
+}
+
+// groupPodsByRequirements groups pods by the taints they tolerate and the extra resources
+// they request, so that a node group can be autoprovisioned for each distinct set.
+func groupPodsByRequirements(pods []*apiv1.Pod) []*podRequirements {
+	result := make([]*podRequirements, 0)
+	byKey := make(map[string]*podRequirements)
+	for _, pod := range pods {
+		taints := taintsToleratedByPod(pod)
+		extraResources := extraResourcesRequestedByPod(pod)
+		key := requirementsKey(taints, extraResources)
+		requirements, found := byKey[key]
+		if !found {
+			requirements = &podRequirements{taints: taints, extraResources: extraResources}
+			byKey[key] = requirements
+			result = append(result, requirements)
+		}
+		requirements.pods = append(requirements.pods, pod)
+	}
+	return result
+}
+
+// taintsToleratedByPod returns NoSchedule taints matching the pod's tolerations. Tolerations
+// of all keys and of taints set by Kubernetes itself don't require tainted nodes.
+func taintsToleratedByPod(pod *apiv1.Pod) []apiv1.Taint {
+	taints := make([]apiv1.Taint, 0)
+	for _, toleration := range pod.Spec.Tolerations {
+		if toleration.Key == "" || isKubernetesTaint(toleration.Key) {
+			continue
+		}
+		if toleration.Effect != "" && toleration.Effect != apiv1.TaintEffectNoSchedule {
+			continue
+		}
+		taint := apiv1.Taint{Key: toleration.Key, Effect: apiv1.TaintEffectNoSchedule}
+		if toleration.Operator != apiv1.TolerationOpExists {
+			taint.Value = toleration.Value
+		}
+		taints = append(taints, taint)
+	}
+	sort.Slice(taints, func(i, j int) bool {
+		if taints[i].Key != taints[j].Key {
+			return taints[i].Key < taints[j].Key
+		}
+		return taints[i].Value < taints[j].Value
+	})
+	return taints
+}
+
+func isKubernetesTaint(key string) bool {
+	for _, prefix := range kubernetesTaintPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraResourcesRequestedByPod returns the GPUs requested by the pod.
+func extraResourcesRequestedByPod(pod *apiv1.Pod) map[string]resource.Quantity {
+	extraResources := make(map[string]resource.Quantity)
+	gpus := resource.Quantity{}
+	for _, container := range pod.Spec.Containers {
+		if request, found := container.Resources.Requests[gpu.ResourceNvidiaGPU]; found {
+			gpus.Add(request)
+		}
+	}
+	if !gpus.IsZero() {
+		extraResources[gpu.ResourceNvidiaGPU] = gpus
+	}
+	return extraResources
+}
+
+func requirementsKey(taints []apiv1.Taint, extraResources map[string]resource.Quantity) string {
+	parts := make([]string, 0, len(taints)+len(extraResources))
+	for _, taint := range taints {
+		parts = append(parts, fmt.Sprintf("taint:%s=%s:%s", taint.Key, taint.Value, taint.Effect))
+	}
+	for name, quantity := range extraResources {
+		parts = append(parts, fmt.Sprintf("resource:%s=%s", name, quantity.String()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoprovisioningNodeGroupListProcessor(t *testing.T) {
+	template := schedulernodeinfo.NewNodeInfo()
+	template.SetNode(BuildTestNode("t1", 1000, 1000))
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil, nil,
+		[]string{"small", "large"}, map[string]*schedulernodeinfo.NodeInfo{"large": template})
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	pod := BuildTestPod("p1", 100, 100)
+	pod.Spec.NodeSelector = map[string]string{"pool": "batch"}
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			NodeAutoprovisioningEnabled:      true,
+			MaxAutoprovisionedNodeGroupCount: 1,
+		},
+		CloudProvider: provider,
+	}
+	processor := NewAutoprovisioningNodeGroupListProcessor()
+
+	nodeGroups, nodeInfos, err := processor.Process(context, provider.NodeGroups(), map[string]*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{pod})
+	assert.NoError(t, err)
+	// There is no template of the small machine type.
+	assert.Equal(t, 2, len(nodeGroups))
+	autoprovisioned := nodeGroups[1]
+	assert.Equal(t, "autoprovisioned-large", autoprovisioned.Id())
+	assert.False(t, autoprovisioned.Exist())
+	assert.Equal(t, map[string]string{"pool": "batch"}, autoprovisioned.(*testprovider.TestNodeGroup).Labels())
+	assert.Equal(t, template, nodeInfos["autoprovisioned-large"])
+
+	// Pods tolerating a taint or requesting GPUs get node groups of their own.
+	context.MaxAutoprovisionedNodeGroupCount = 10
+	tolerating := BuildTestPod("p2", 100, 100)
+	tolerating.Spec.Tolerations = []apiv1.Toleration{
+		{Key: "dedicated", Operator: apiv1.TolerationOpEqual, Value: "batch", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/not-ready", Operator: apiv1.TolerationOpExists, Effect: apiv1.TaintEffectNoExecute},
+	}
+	gpuPod := BuildTestPod("p3", 100, 100)
+	RequestGpuForPod(gpuPod, 2)
+	nodeGroups, nodeInfos, err = processor.Process(context, []cloudprovider.NodeGroup{}, map[string]*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{pod, tolerating, gpuPod})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(nodeGroups))
+	assert.Equal(t, 3, len(nodeInfos))
+	assert.Equal(t, "autoprovisioned-large", nodeGroups[0].Id())
+	assert.Equal(t, "autoprovisioned-large-dedicated", nodeGroups[1].Id())
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}}, nodeGroups[1].(*testprovider.TestNodeGroup).Taints())
+	assert.Equal(t, "autoprovisioned-large-"+gpu.ResourceNvidiaGPU, nodeGroups[2].Id())
+	gpus := nodeGroups[2].(*testprovider.TestNodeGroup).ExtraResources()[gpu.ResourceNvidiaGPU]
+	assert.Equal(t, int64(2), gpus.Value())
+	context.MaxAutoprovisionedNodeGroupCount = 1
+
+	// No pending pods.
+	nodeGroups, _, err = processor.Process(context, provider.NodeGroups(), map[string]*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nodeGroups))
+
+	// Max autoprovisioned node group count reached.
+	provider.AddAutoprovisionedNodeGroup("ng2", 0, 10, 0, "large")
+	nodeGroups, _, err = processor.Process(context, []cloudprovider.NodeGroup{}, map[string]*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{pod})
+	assert.NoError(t, err)
+	assert.Empty(t, nodeGroups)
+
+	// Autoprovisioning disabled.
+	context.MaxAutoprovisionedNodeGroupCount = 10
+	context.NodeAutoprovisioningEnabled = false
+	nodeGroups, _, err = processor.Process(context, []cloudprovider.NodeGroup{}, map[string]*schedulernodeinfo.NodeInfo{}, []*apiv1.Pod{pod})
+	assert.NoError(t, err)
+	assert.Empty(t, nodeGroups)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/klog"
+)
+
+// AutoprovisioningNodeGroupManager creates node groups chosen for scale-up on the cloud
+// provider side and deletes autoprovisioned node groups once they have no nodes.
+type AutoprovisioningNodeGroupManager struct {
+}
+
+// NewAutoprovisioningNodeGroupManager creates an instance of AutoprovisioningNodeGroupManager.
+func NewAutoprovisioningNodeGroupManager() NodeGroupManager {
+	return &AutoprovisioningNodeGroupManager{}
+}
+
+// CreateNodeGroup creates the given node group on the cloud provider side.
+func (m *AutoprovisioningNodeGroupManager) CreateNodeGroup(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup) (CreateNodeGroupResult, errors.AutoscalerError) {
+	newNodeGroup, err := nodeGroup.Create()
+	if err != nil {
+		return CreateNodeGroupResult{}, errors.ToAutoscalerError(errors.CloudProviderError, err)
+	}
+	metrics.RegisterNodeGroupCreation()
+	return CreateNodeGroupResult{MainCreatedNodeGroup: newNodeGroup}, nil
+}
+
+// RemoveUnneededNodeGroups deletes autoprovisioned node groups with a target size of 0 and no nodes.
+// In dry-run mode the node groups are only recorded as ones that would be deleted.
+func (m *AutoprovisioningNodeGroupManager) RemoveUnneededNodeGroups(context *context.AutoscalingContext) error {
+	if !context.NodeAutoprovisioningEnabled {
+		return nil
+	}
+	for _, nodeGroup := range context.CloudProvider.NodeGroups() {
+		if !nodeGroup.Autoprovisioned() {
+			continue
+		}
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			klog.Errorf("Failed to get target size of node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		if targetSize > 0 {
+			continue
+		}
+		nodes, err := nodeGroup.Nodes()
+		if err != nil {
+			klog.Errorf("Failed to get nodes of node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		if len(nodes) > 0 {
+			continue
+		}
+		if context.DryRun {
+			klog.V(0).Infof("Dry-run: would delete autoprovisioned node group %s", nodeGroup.Id())
+			context.LogRecorder.Eventf(apiv1.EventTypeNormal, "DryRun", "Dry-run: would delete autoprovisioned node group %s", nodeGroup.Id())
+			metrics.RegisterDryRunAction(metrics.DryRunNodeGroupDeletion, 1)
+			continue
+		}
+		if err := nodeGroup.Delete(); err != nil {
+			klog.Errorf("Failed to delete node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		klog.V(1).Infof("Deleted autoprovisioned node group %s", nodeGroup.Id())
+		metrics.RegisterNodeGroupDeletion()
+	}
+	return nil
+}
+
+// CleanUp cleans up the manager's internal structures.
+func (m *AutoprovisioningNodeGroupManager) CleanUp() {
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"fmt"
+	"testing"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoprovisioningNodeGroupManager(t *testing.T) {
+	var created, deleted []string
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil,
+		func(id string) error {
+			created = append(created, id)
+			return nil
+		},
+		func(id string) error {
+			if id == "autoprovisioned-large" {
+				return fmt.Errorf("group in use")
+			}
+			deleted = append(deleted, id)
+			return nil
+		}, []string{"large"}, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 0)
+	provider.AddAutoprovisionedNodeGroup("empty", 0, 10, 0, "large")
+	provider.AddAutoprovisionedNodeGroup("scaling-up", 0, 10, 1, "large")
+	provider.AddAutoprovisionedNodeGroup("with-node", 0, 10, 0, "large")
+	provider.AddNode("with-node", BuildTestNode("n1", 1000, 1000))
+
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{NodeAutoprovisioningEnabled: true},
+		CloudProvider:      provider,
+	}
+	manager := NewAutoprovisioningNodeGroupManager()
+
+	nodeGroup, err := provider.NewNodeGroup("large", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	result, err := manager.CreateNodeGroup(context, nodeGroup)
+	assert.NoError(t, err)
+	assert.True(t, result.MainCreatedNodeGroup.Exist())
+	assert.Equal(t, []string{"autoprovisioned-large"}, created)
+
+	_, err = manager.CreateNodeGroup(context, result.MainCreatedNodeGroup)
+	assert.Error(t, err)
+
+	assert.NoError(t, manager.RemoveUnneededNodeGroups(context))
+	assert.Equal(t, []string{"empty"}, deleted)
+	assert.Nil(t, provider.GetNodeGroup("empty"))
+	// Failed deletions are retried in the next loop.
+	assert.NotNil(t, provider.GetNodeGroup("autoprovisioned-large"))
+	assert.NotNil(t, provider.GetNodeGroup("ng1"))
+	assert.NotNil(t, provider.GetNodeGroup("scaling-up"))
+	assert.NotNil(t, provider.GetNodeGroup("with-node"))
+}
+
+func TestAutoprovisioningNodeGroupManagerDryRun(t *testing.T) {
+	provider := testprovider.NewTestAutoprovisioningCloudProvider(nil, nil, nil,
+		func(id string) error {
+			t.Fatalf("Unexpected deletion of node group %s", id)
+			return nil
+		}, []string{"large"}, nil)
+	provider.AddAutoprovisionedNodeGroup("empty", 0, 10, 0, "large")
+
+	fakeRecorder := kube_record.NewFakeRecorder(5)
+	fakeLogRecorder, err := utils.NewStatusMapRecorder(fake.NewSimpleClientset(), "kube-system", fakeRecorder, true)
+	assert.NoError(t, err)
+	context := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{NodeAutoprovisioningEnabled: true, DryRun: true},
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			LogRecorder: fakeLogRecorder,
+		},
+		CloudProvider: provider,
+	}
+
+	assert.NoError(t, NewAutoprovisioningNodeGroupManager().RemoveUnneededNodeGroups(context))
+	assert.NotNil(t, provider.GetNodeGroup("empty"))
+	assert.Len(t, fakeRecorder.Events, 1)
+	assert.Contains(t, <-fakeRecorder.Events, "would delete autoprovisioned node group empty")
+}
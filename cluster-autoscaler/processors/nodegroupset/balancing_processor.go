@@ -68,15 +68,18 @@ func (b *BalancingNodeGroupSetProcessor) FindSimilarNodeGroups(context *context.
 }
 
 // BalanceScaleUpBetweenGroups distributes a given number of nodes between
-// given set of NodeGroups. The nodes are added to smallest group first, trying
-// to make the group sizes as evenly balanced as possible.
+// given set of NodeGroups. Each group first gets the number of nodes it
+// requires according to minNewNodes, e.g. for pods that have to run in its
+// zone. The remaining nodes are added to smallest group first, trying to make
+// the group sizes as evenly balanced as possible.
 //
 // Returns ScaleUpInfos for groups that need to be resized.
 //
 // MaxSize of each group will be respected. If newNodes > total free capacity
 // of all NodeGroups it will be capped to total capacity. In particular if all
 // group already have MaxSize, empty list will be returned.
-func (b *BalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+func (b *BalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int,
+	minNewNodes map[string]int) ([]ScaleUpInfo, errors.AutoscalerError) {
 	if len(groups) == 0 {
 		return []ScaleUpInfo{}, errors.NewAutoscalerError(
 			errors.InternalError, "Can't balance scale up between 0 groups")
@@ -110,6 +113,22 @@ func (b *BalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *co
 		newNodes = totalCapacity
 	}
 
+	// Nodes required by particular groups are added first.
+	for i := range scaleUpInfos {
+		info := &scaleUpInfos[i]
+		required := minNewNodes[info.Group.Id()]
+		if required > info.MaxSize-info.NewSize {
+			required = info.MaxSize - info.NewSize
+		}
+		if required > newNodes {
+			required = newNodes
+		}
+		if required > 0 {
+			info.NewSize += required
+			newNodes -= required
+		}
+	}
+
 	// The actual balancing algorithm.
 	// Sort the node groups by current size and just loop over nodes adding
 	// to smallest group. If a group hits max size remove it from the list
@@ -122,14 +141,14 @@ func (b *BalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *co
 	// have nodes to allocate.
 	//
 	// Loop invariants:
-	// 1. i < startIndex -> scaleUpInfos[i].CurrentSize == scaleUpInfos[i].MaxSize
-	// 2. i >= startIndex -> scaleUpInfos[i].CurrentSize < scaleUpInfos[i].MaxSize
+	// 1. i < startIndex -> scaleUpInfos[i].NewSize == scaleUpInfos[i].MaxSize
+	// 2. i >= startIndex -> scaleUpInfos[i].NewSize < scaleUpInfos[i].MaxSize
 	// 3. startIndex <= currentIndex < len(scaleUpInfos)
-	// 4. currentIndex <= i < j -> scaleUpInfos[i].CurrentSize <= scaleUpInfos[j].CurrentSize
-	// 5. startIndex <= i < j < currentIndex -> scaleUpInfos[i].CurrentSize == scaleUpInfos[j].CurrentSize
-	// 6. startIndex <= i < currentIndex <= j -> scaleUpInfos[i].CurrentSize <= scaleUpInfos[j].CurrentSize + 1
+	// 4. currentIndex <= i < j -> scaleUpInfos[i].NewSize <= scaleUpInfos[j].NewSize
+	// 5. startIndex <= i < j < currentIndex -> scaleUpInfos[i].NewSize == scaleUpInfos[j].NewSize
+	// 6. startIndex <= i < currentIndex <= j -> scaleUpInfos[i].NewSize <= scaleUpInfos[j].NewSize + 1
 	sort.Slice(scaleUpInfos, func(i, j int) bool {
-		return scaleUpInfos[i].CurrentSize < scaleUpInfos[j].CurrentSize
+		return scaleUpInfos[i].NewSize < scaleUpInfos[j].NewSize
 	})
 	startIndex := 0
 	currentIndex := 0
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfos
+
+import (
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+// TemplateNodeInfoProcessor adjusts NodeInfos built from NodeGroup.TemplateNodeInfo(),
+// before DaemonSet pods are added to them.
+type TemplateNodeInfoProcessor interface {
+	// Process returns the NodeInfo to use as a template of the node group.
+	Process(nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulernodeinfo.NodeInfo) (*schedulernodeinfo.NodeInfo, error)
+	// Build returns a NodeInfo for a node group whose cloud provider can't build templates, or
+	// cloudprovider.ErrNotImplemented if it can't build one either.
+	Build(nodeGroup cloudprovider.NodeGroup) (*schedulernodeinfo.NodeInfo, error)
+	// CleanUp cleans up the processor's internal structures.
+	CleanUp()
+}
+
+// NoOpTemplateNodeInfoProcessor uses templates from the cloud provider as they are.
+type NoOpTemplateNodeInfoProcessor struct {
+}
+
+// NewDefaultTemplateNodeInfoProcessor creates an instance of TemplateNodeInfoProcessor.
+func NewDefaultTemplateNodeInfoProcessor() TemplateNodeInfoProcessor {
+	return &NoOpTemplateNodeInfoProcessor{}
+}
+
+// Process returns the node info unchanged.
+func (p *NoOpTemplateNodeInfoProcessor) Process(nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulernodeinfo.NodeInfo) (*schedulernodeinfo.NodeInfo, error) {
+	return nodeInfo, nil
+}
+
+// Build returns cloudprovider.ErrNotImplemented.
+func (p *NoOpTemplateNodeInfoProcessor) Build(nodeGroup cloudprovider.NodeGroup) (*schedulernodeinfo.NodeInfo, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *NoOpTemplateNodeInfoProcessor) CleanUp() {
+}
+
+// OverrideTemplateNodeInfoProcessor merges user-defined overrides into templates of node
+// groups, so scale-from-zero doesn't depend on provider-specific conventions for
+// describing labels, taints and resources of the nodes.
+type OverrideTemplateNodeInfoProcessor struct {
+	overrides map[string]*TemplateOverride
+}
+
+// NewOverrideTemplateNodeInfoProcessor creates a processor applying the given overrides, keyed by node group id.
+func NewOverrideTemplateNodeInfoProcessor(overrides map[string]*TemplateOverride) TemplateNodeInfoProcessor {
+	return &OverrideTemplateNodeInfoProcessor{overrides: overrides}
+}
+
+// Process returns a copy of the node info with the override of the node group applied.
+func (p *OverrideTemplateNodeInfoProcessor) Process(nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulernodeinfo.NodeInfo) (*schedulernodeinfo.NodeInfo, error) {
+	override, found := p.overrides[nodeGroup.Id()]
+	if !found {
+		return nodeInfo, nil
+	}
+	klog.V(4).Infof("Applying template override to node group %s", nodeGroup.Id())
+	result := schedulernodeinfo.NewNodeInfo(nodeInfo.Pods()...)
+	if err := result.SetNode(override.Apply(nodeInfo.Node())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Build returns a node info built from the override of the node group, if it defines the
+// allocatable resources of the nodes.
+func (p *OverrideTemplateNodeInfoProcessor) Build(nodeGroup cloudprovider.NodeGroup) (*schedulernodeinfo.NodeInfo, error) {
+	override, found := p.overrides[nodeGroup.Id()]
+	if !found || len(override.Allocatable) == 0 {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	klog.V(4).Infof("Building template of node group %s from its template override", nodeGroup.Id())
+	result := schedulernodeinfo.NewNodeInfo(cloudprovider.BuildKubeProxy(nodeGroup.Id()))
+	if err := result.SetNode(override.BuildNode()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *OverrideTemplateNodeInfoProcessor) CleanUp() {
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfos
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	kubeletapis "k8s.io/kubernetes/pkg/kubelet/apis"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultMaxPods is the number of pods of template nodes built from overrides that don't set it.
+const defaultMaxPods = 110
+
+// TemplateOverride is the user-facing definition of properties of the nodes of a node group,
+// applied on top of the template node built by the cloud provider.
+type TemplateOverride struct {
+	// NodeGroup is the id of the node group the override applies to.
+	NodeGroup string `json:"nodeGroup"`
+	// Labels are added to the template node, replacing labels with the same keys.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Taints are added to the template node, replacing taints with the same keys and effects.
+	Taints []apiv1.Taint `json:"taints,omitempty"`
+	// Allocatable replaces the given allocatable resources of the template node. If the cloud
+	// provider can't build templates, the template node is built from the override when set.
+	Allocatable apiv1.ResourceList `json:"allocatable,omitempty"`
+	// ExtendedResources are added to both capacity and allocatable of the template node.
+	ExtendedResources apiv1.ResourceList `json:"extendedResources,omitempty"`
+	// CSIAttachLimits is the number of volumes of each CSI driver that can be attached to a node.
+	CSIAttachLimits map[string]int64 `json:"csiAttachLimits,omitempty"`
+}
+
+// Config is the content of the template overrides configuration file.
+type Config struct {
+	Templates []TemplateOverride `json:"templates"`
+}
+
+// Validate checks that the override is complete.
+func (o *TemplateOverride) Validate() error {
+	if o.NodeGroup == "" {
+		return fmt.Errorf("template override without node group")
+	}
+	for _, taint := range o.Taints {
+		if taint.Key == "" {
+			return fmt.Errorf("template override of %s has taint without key", o.NodeGroup)
+		}
+		switch taint.Effect {
+		case apiv1.TaintEffectNoSchedule, apiv1.TaintEffectPreferNoSchedule, apiv1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("template override of %s has taint %s with invalid effect %q", o.NodeGroup, taint.Key, taint.Effect)
+		}
+	}
+	for _, resources := range []apiv1.ResourceList{o.Allocatable, o.ExtendedResources} {
+		for name, quantity := range resources {
+			if quantity.Sign() < 0 {
+				return fmt.Errorf("template override of %s has negative %s", o.NodeGroup, name)
+			}
+		}
+	}
+	for driver, limit := range o.CSIAttachLimits {
+		if driver == "" || limit < 0 {
+			return fmt.Errorf("template override of %s has invalid attach limit of CSI driver %q", o.NodeGroup, driver)
+		}
+	}
+	return nil
+}
+
+// Apply returns a copy of the node with the override applied.
+func (o *TemplateOverride) Apply(node *apiv1.Node) *apiv1.Node {
+	result := node.DeepCopy()
+	if len(o.Labels) > 0 && result.Labels == nil {
+		result.Labels = make(map[string]string, len(o.Labels))
+	}
+	for key, value := range o.Labels {
+		result.Labels[key] = value
+	}
+
+	for _, taint := range o.Taints {
+		replaced := false
+		for i := range result.Spec.Taints {
+			if result.Spec.Taints[i].MatchTaint(&taint) {
+				result.Spec.Taints[i] = taint
+				replaced = true
+			}
+		}
+		if !replaced {
+			result.Spec.Taints = append(result.Spec.Taints, taint)
+		}
+	}
+
+	for name, quantity := range o.Allocatable {
+		setAllocatable(result, name, quantity)
+	}
+	for name, quantity := range o.ExtendedResources {
+		setCapacity(result, name, quantity)
+		setAllocatable(result, name, quantity)
+	}
+	for driver, limit := range o.CSIAttachLimits {
+		name := apiv1.ResourceName(volumeutil.GetCSIAttachLimitKey(driver))
+		quantity := *resource.NewQuantity(limit, resource.DecimalSI)
+		setCapacity(result, name, quantity)
+		setAllocatable(result, name, quantity)
+	}
+	return result
+}
+
+// BuildNode returns a template node with the allocatable resources of the override and the
+// override applied, for node groups whose cloud provider can't build templates.
+func (o *TemplateOverride) BuildNode() *apiv1.Node {
+	nodeName := fmt.Sprintf("template-node-for-%s-%d", o.NodeGroup, rand.Int63())
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:     nodeName,
+			SelfLink: fmt.Sprintf("/api/v1/nodes/%s", nodeName),
+			Labels: map[string]string{
+				kubeletapis.LabelArch: cloudprovider.DefaultArch,
+				kubeletapis.LabelOS:   cloudprovider.DefaultOS,
+				apiv1.LabelHostname:   nodeName,
+			},
+		},
+		Status: apiv1.NodeStatus{
+			Capacity: apiv1.ResourceList{
+				apiv1.ResourcePods: *resource.NewQuantity(defaultMaxPods, resource.DecimalSI),
+			},
+			Allocatable: apiv1.ResourceList{
+				apiv1.ResourcePods: *resource.NewQuantity(defaultMaxPods, resource.DecimalSI),
+			},
+			Conditions: cloudprovider.BuildReadyConditions(),
+		},
+	}
+	return o.Apply(node)
+}
+
+func setCapacity(node *apiv1.Node, name apiv1.ResourceName, quantity resource.Quantity) {
+	if node.Status.Capacity == nil {
+		node.Status.Capacity = apiv1.ResourceList{}
+	}
+	node.Status.Capacity[name] = quantity.DeepCopy()
+}
+
+// setAllocatable sets the allocatable resource, raising the capacity if it would be lower.
+func setAllocatable(node *apiv1.Node, name apiv1.ResourceName, quantity resource.Quantity) {
+	if node.Status.Allocatable == nil {
+		node.Status.Allocatable = apiv1.ResourceList{}
+	}
+	node.Status.Allocatable[name] = quantity.DeepCopy()
+	if capacity, found := node.Status.Capacity[name]; !found || capacity.Cmp(quantity) < 0 {
+		setCapacity(node, name, quantity)
+	}
+}
+
+// ParseConfig parses and validates a YAML or JSON template overrides configuration.
+func ParseConfig(data []byte) (map[string]*TemplateOverride, error) {
+	config := Config{}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse template overrides config: %v", err)
+	}
+	result := make(map[string]*TemplateOverride, len(config.Templates))
+	for i := range config.Templates {
+		override := &config.Templates[i]
+		if err := override.Validate(); err != nil {
+			return nil, err
+		}
+		if _, found := result[override.NodeGroup]; found {
+			return nil, fmt.Errorf("template override of %s defined more than once", override.NodeGroup)
+		}
+		result[override.NodeGroup] = override
+	}
+	return result, nil
+}
+
+// LoadConfigFile reads template overrides from the given file, keyed by node group id.
+func LoadConfigFile(path string) (map[string]*TemplateOverride, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template overrides config %s: %v", path, err)
+	}
+	return ParseConfig(data)
+}
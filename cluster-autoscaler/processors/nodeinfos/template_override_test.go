@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfos
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+templates:
+- nodeGroup: gpu-pool
+  labels:
+    accelerator: nvidia-tesla-k80
+  taints:
+  - key: dedicated
+    value: gpu
+    effect: NoSchedule
+  allocatable:
+    memory: 3Gi
+  extendedResources:
+    nvidia.com/gpu: 4
+  csiAttachLimits:
+    ebs.csi.aws.com: 25
+- nodeGroup: batch
+  labels:
+    workload: batch
+`
+
+func TestParseConfig(t *testing.T) {
+	overrides, err := ParseConfig([]byte(testConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(overrides))
+	gpu := overrides["gpu-pool"]
+	assert.Equal(t, map[string]string{"accelerator": "nvidia-tesla-k80"}, gpu.Labels)
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}, gpu.Taints)
+	assert.Equal(t, map[string]int64{"ebs.csi.aws.com": 25}, gpu.CSIAttachLimits)
+	assert.Equal(t, "batch", overrides["batch"].NodeGroup)
+
+	for name, config := range map[string]string{
+		"no node group":  "templates:\n- labels: {a: b}\n",
+		"duplicate":      "templates:\n- nodeGroup: a\n- nodeGroup: a\n",
+		"taint effect":   "templates:\n- nodeGroup: a\n  taints: [{key: k, effect: Sometimes}]\n",
+		"taint key":      "templates:\n- nodeGroup: a\n  taints: [{effect: NoSchedule}]\n",
+		"negative":       "templates:\n- nodeGroup: a\n  allocatable: {cpu: -1}\n",
+		"attach limit":   "templates:\n- nodeGroup: a\n  csiAttachLimits: {driver: -1}\n",
+		"unknown field":  "templates:\n- nodeGroup: a\n  capacity: {cpu: 1}\n",
+		"invalid format": "templates: a",
+	} {
+		_, err := ParseConfig([]byte(config))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApply(t *testing.T) {
+	overrides, err := ParseConfig([]byte(testConfig))
+	assert.NoError(t, err)
+
+	node := BuildTestNode("n1", 1000, 2*1024*1024*1024)
+	node.Labels = map[string]string{"accelerator": "none", "zone": "a"}
+	node.Spec.Taints = []apiv1.Taint{
+		{Key: "dedicated", Value: "none", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "other", Effect: apiv1.TaintEffectNoExecute},
+	}
+	result := overrides["gpu-pool"].Apply(node)
+
+	assert.Equal(t, map[string]string{"accelerator": "nvidia-tesla-k80", "zone": "a"}, result.Labels)
+	assert.Equal(t, []apiv1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "other", Effect: apiv1.TaintEffectNoExecute},
+	}, result.Spec.Taints)
+
+	memory := resource.MustParse("3Gi")
+	assert.Equal(t, memory.Value(), result.Status.Allocatable.Memory().Value())
+	// Capacity is raised to fit the allocatable.
+	assert.Equal(t, memory.Value(), result.Status.Capacity.Memory().Value())
+	assert.Equal(t, int64(1000), result.Status.Allocatable.Cpu().MilliValue())
+	gpus := result.Status.Allocatable["nvidia.com/gpu"]
+	assert.Equal(t, int64(4), gpus.Value())
+	gpus = result.Status.Capacity["nvidia.com/gpu"]
+	assert.Equal(t, int64(4), gpus.Value())
+	volumes := result.Status.Allocatable["attachable-volumes-csi-ebs.csi.aws.com"]
+	assert.Equal(t, int64(25), volumes.Value())
+
+	// The original node is not modified.
+	assert.Equal(t, "none", node.Labels["accelerator"])
+	assert.Equal(t, "none", node.Spec.Taints[0].Value)
+	_, found := node.Status.Allocatable["nvidia.com/gpu"]
+	assert.False(t, found)
+
+	// Labels are added to nodes without any.
+	result = overrides["batch"].Apply(BuildTestNode("n2", 1000, 1000))
+	assert.Equal(t, "batch", result.Labels["workload"])
+}
+
+func TestBuildNode(t *testing.T) {
+	overrides, err := ParseConfig([]byte(testConfig))
+	assert.NoError(t, err)
+
+	node := overrides["gpu-pool"].BuildNode()
+	assert.Equal(t, "nvidia-tesla-k80", node.Labels["accelerator"])
+	assert.Equal(t, node.Name, node.Labels[apiv1.LabelHostname])
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}, node.Spec.Taints)
+	memory := resource.MustParse("3Gi")
+	assert.Equal(t, memory.Value(), node.Status.Allocatable.Memory().Value())
+	assert.Equal(t, memory.Value(), node.Status.Capacity.Memory().Value())
+	assert.Equal(t, int64(defaultMaxPods), node.Status.Allocatable.Pods().Value())
+	gpus := node.Status.Allocatable["nvidia.com/gpu"]
+	assert.Equal(t, int64(4), gpus.Value())
+	assert.Equal(t, apiv1.NodeReady, node.Status.Conditions[0].Type)
+}
@@ -44,3 +44,33 @@ func (p *NoOpPodListProcessor) Process(context *context.AutoscalingContext, unsc
 // CleanUp cleans up the processor's internal structures.
 func (p *NoOpPodListProcessor) CleanUp() {
 }
+
+// CombinedPodListProcessor runs several PodListProcessors one after another, passing the
+// pod lists returned by each of them to the next one.
+type CombinedPodListProcessor struct {
+	processors []PodListProcessor
+}
+
+// NewCombinedPodListProcessor creates a processor running the given processors in order.
+func NewCombinedPodListProcessor(processors []PodListProcessor) PodListProcessor {
+	return &CombinedPodListProcessor{processors: processors}
+}
+
+// Process runs all processors in order. The first error stops processing.
+func (p *CombinedPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod, allScheduled []*apiv1.Pod, nodes []*apiv1.Node) ([]*apiv1.Pod, []*apiv1.Pod, error) {
+	var err error
+	for _, processor := range p.processors {
+		unschedulablePods, allScheduled, err = processor.Process(context, unschedulablePods, allScheduled, nodes)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return unschedulablePods, allScheduled, nil
+}
+
+// CleanUp cleans up the internal structures of all processors.
+func (p *CombinedPodListProcessor) CleanUp() {
+	for _, processor := range p.processors {
+		processor.CleanUp()
+	}
+}
@@ -23,6 +23,8 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPodListProcessor(t *testing.T) {
@@ -42,3 +44,30 @@ func TestPodListProcessor(t *testing.T) {
 	}
 
 }
+
+type appendingPodListProcessor struct {
+	pod *apiv1.Pod
+}
+
+func (p *appendingPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod, allScheduled []*apiv1.Pod, nodes []*apiv1.Node) ([]*apiv1.Pod, []*apiv1.Pod, error) {
+	return append(unschedulablePods, p.pod), allScheduled, nil
+}
+
+func (p *appendingPodListProcessor) CleanUp() {
+}
+
+func TestCombinedPodListProcessor(t *testing.T) {
+	context := &context.AutoscalingContext{}
+	p1 := BuildTestPod("p1", 40, 0)
+	p2 := BuildTestPod("p2", 400, 0)
+	p3 := BuildTestPod("p3", 400, 0)
+	podListProcessor := NewCombinedPodListProcessor([]PodListProcessor{
+		&appendingPodListProcessor{pod: p2},
+		NewDefaultPodListProcessor(),
+		&appendingPodListProcessor{pod: p3},
+	})
+	gotUnschedulablePods, gotAllScheduled, err := podListProcessor.Process(context, []*apiv1.Pod{p1}, []*apiv1.Pod{}, []*apiv1.Node{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{p1, p2, p3}, gotUnschedulablePods)
+	assert.Empty(t, gotAllScheduled)
+}
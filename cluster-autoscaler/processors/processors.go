@@ -17,9 +17,13 @@ limitations under the License.
 package processors
 
 import (
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitytype"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfos"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scalingwindows"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 )
 
@@ -40,6 +44,14 @@ type AutoscalingProcessors struct {
 	AutoscalingStatusProcessor status.AutoscalingStatusProcessor
 	// NodeGroupManager is responsible for creating/deleting node groups.
 	NodeGroupManager nodegroups.NodeGroupManager
+	// ScalingWindowProcessor is used to raise node group min sizes and block scale-down during scheduled time windows.
+	ScalingWindowProcessor scalingwindows.ScalingWindowProcessor
+	// NodeGroupConfigProcessor provides autoscaling options overridden per node group.
+	NodeGroupConfigProcessor nodegroupconfig.NodeGroupConfigProcessor
+	// CapacityTypeProcessor steers scale-ups and scale-downs between spot and on-demand node groups.
+	CapacityTypeProcessor capacitytype.CapacityTypeProcessor
+	// TemplateNodeInfoProcessor adjusts template NodeInfos of node groups without usable nodes.
+	TemplateNodeInfoProcessor nodeinfos.TemplateNodeInfoProcessor
 }
 
 // DefaultProcessors returns default set of processors.
@@ -52,6 +64,10 @@ func DefaultProcessors() *AutoscalingProcessors {
 		ScaleDownStatusProcessor:   status.NewDefaultScaleDownStatusProcessor(),
 		AutoscalingStatusProcessor: status.NewDefaultAutoscalingStatusProcessor(),
 		NodeGroupManager:           nodegroups.NewDefaultNodeGroupManager(),
+		ScalingWindowProcessor:     scalingwindows.NewDefaultScalingWindowProcessor(),
+		NodeGroupConfigProcessor:   nodegroupconfig.NewDefaultNodeGroupConfigProcessor(),
+		CapacityTypeProcessor:      capacitytype.NewDefaultCapacityTypeProcessor(),
+		TemplateNodeInfoProcessor:  nodeinfos.NewDefaultTemplateNodeInfoProcessor(),
 	}
 }
 
@@ -66,6 +82,10 @@ func TestProcessors() *AutoscalingProcessors {
 		ScaleDownStatusProcessor:   &status.NoOpScaleDownStatusProcessor{},
 		AutoscalingStatusProcessor: &status.NoOpAutoscalingStatusProcessor{},
 		NodeGroupManager:           nodegroups.NewDefaultNodeGroupManager(),
+		ScalingWindowProcessor:     &scalingwindows.NoOpScalingWindowProcessor{},
+		NodeGroupConfigProcessor:   &nodegroupconfig.NoOpNodeGroupConfigProcessor{},
+		CapacityTypeProcessor:      &capacitytype.NoOpCapacityTypeProcessor{},
+		TemplateNodeInfoProcessor:  &nodeinfos.NoOpTemplateNodeInfoProcessor{},
 	}
 }
 
@@ -78,4 +98,8 @@ func (ap *AutoscalingProcessors) CleanUp() {
 	ap.ScaleDownStatusProcessor.CleanUp()
 	ap.AutoscalingStatusProcessor.CleanUp()
 	ap.NodeGroupManager.CleanUp()
+	ap.ScalingWindowProcessor.CleanUp()
+	ap.NodeGroupConfigProcessor.CleanUp()
+	ap.CapacityTypeProcessor.CleanUp()
+	ap.TemplateNodeInfoProcessor.CleanUp()
 }
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalingwindows
+
+import (
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+)
+
+// ScalingWindowProcessor overrides node group limits during scheduled time windows.
+type ScalingWindowProcessor interface {
+	// GetMinSize returns the effective minimum size of the node group at the given time.
+	GetMinSize(nodeGroup cloudprovider.NodeGroup, now time.Time) int
+	// IsScaleDownBlocked returns true if nodes of the node group must not be removed at the given time.
+	IsScaleDownBlocked(nodeGroup cloudprovider.NodeGroup, now time.Time) bool
+	// CleanUp cleans up the processor's internal structures.
+	CleanUp()
+}
+
+// NoOpScalingWindowProcessor uses node group limits as they are.
+type NoOpScalingWindowProcessor struct {
+}
+
+// NewDefaultScalingWindowProcessor creates an instance of ScalingWindowProcessor.
+func NewDefaultScalingWindowProcessor() ScalingWindowProcessor {
+	return &NoOpScalingWindowProcessor{}
+}
+
+// GetMinSize returns the min size of the node group.
+func (p *NoOpScalingWindowProcessor) GetMinSize(nodeGroup cloudprovider.NodeGroup, now time.Time) int {
+	return nodeGroup.MinSize()
+}
+
+// IsScaleDownBlocked always returns false.
+func (p *NoOpScalingWindowProcessor) IsScaleDownBlocked(nodeGroup cloudprovider.NodeGroup, now time.Time) bool {
+	return false
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *NoOpScalingWindowProcessor) CleanUp() {
+}
+
+// ScheduledScalingWindowProcessor applies scaling windows defined with cron schedules.
+type ScheduledScalingWindowProcessor struct {
+	windows []*Window
+}
+
+// NewScheduledScalingWindowProcessor creates a processor applying the given windows.
+func NewScheduledScalingWindowProcessor(windows []*Window) ScalingWindowProcessor {
+	return &ScheduledScalingWindowProcessor{windows: windows}
+}
+
+// GetMinSize returns the largest min size of windows active for the node group, but
+// not less than the min size and not more than the max size of the node group.
+func (p *ScheduledScalingWindowProcessor) GetMinSize(nodeGroup cloudprovider.NodeGroup, now time.Time) int {
+	minSize := nodeGroup.MinSize()
+	for _, window := range p.windows {
+		if window.MinSize > minSize && window.AppliesTo(nodeGroup.Id()) && window.IsActive(now) {
+			minSize = window.MinSize
+		}
+	}
+	if minSize > nodeGroup.MaxSize() {
+		return nodeGroup.MaxSize()
+	}
+	return minSize
+}
+
+// IsScaleDownBlocked returns true if any window blocking scale-down is active for the node group.
+func (p *ScheduledScalingWindowProcessor) IsScaleDownBlocked(nodeGroup cloudprovider.NodeGroup, now time.Time) bool {
+	for _, window := range p.windows {
+		if window.BlockScaleDown && window.AppliesTo(nodeGroup.Id()) && window.IsActive(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *ScheduledScalingWindowProcessor) CleanUp() {
+}
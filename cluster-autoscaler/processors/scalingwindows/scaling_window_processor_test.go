@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalingwindows
+
+import (
+	"testing"
+	"time"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+windows:
+- name: business-hours
+  nodeGroups: ["^web-"]
+  schedule: "0 9 * * mon-fri"
+  duration: 8h
+  timeZone: Europe/Warsaw
+  minSize: 5
+  blockScaleDown: true
+- name: batch-prewarm
+  nodeGroups: ["^batch$", "^web-2$"]
+  schedule: "30 1 * * *"
+  duration: 2h
+  minSize: 20
+`
+
+func TestParseConfig(t *testing.T) {
+	windows, err := ParseConfig([]byte(testConfig))
+	assert.NoError(t, err)
+	assert.Len(t, windows, 2)
+	assert.Equal(t, "business-hours", windows[0].Name)
+	assert.True(t, windows[0].AppliesTo("web-1"))
+	assert.False(t, windows[0].AppliesTo("batch"))
+
+	for _, config := range []string{
+		"windows: [{name: w, nodeGroups: [a], schedule: '* * * * *', duration: 1h}]",
+		"windows: [{name: w, schedule: '* * * * *', duration: 1h, minSize: 1}]",
+		"windows: [{name: w, nodeGroups: [a], schedule: '* * * *', duration: 1h, minSize: 1}]",
+		"windows: [{name: w, nodeGroups: [a], schedule: '* * * * *', duration: 10s, minSize: 1}]",
+		"windows: [{name: w, nodeGroups: ['('], schedule: '* * * * *', duration: 1h, minSize: 1}]",
+		"windows: [{name: w, nodeGroups: [a], schedule: '* * * * *', duration: 1h, minSize: 1, timeZone: Mars/Olympus}]",
+		"windows: [{name: w, nodeGroups: [a], schedule: '* * * * *', duration: 1h, minSize: 1, unknown: true}]",
+	} {
+		_, err := ParseConfig([]byte(config))
+		assert.Error(t, err, "config %s", config)
+	}
+}
+
+func TestScheduledScalingWindowProcessor(t *testing.T) {
+	windows, err := ParseConfig([]byte(testConfig))
+	assert.NoError(t, err)
+	processor := NewScheduledScalingWindowProcessor(windows)
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("web-1", 1, 10, 1)
+	provider.AddNodeGroup("web-2", 2, 10, 2)
+	provider.AddNodeGroup("batch", 0, 15, 0)
+	web1 := provider.GetNodeGroup("web-1")
+	web2 := provider.GetNodeGroup("web-2")
+	batch := provider.GetNodeGroup("batch")
+
+	// Monday 2019-06-03, Warsaw is UTC+2 in June.
+	businessHours := time.Date(2019, 6, 3, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, 5, processor.GetMinSize(web1, businessHours))
+	assert.True(t, processor.IsScaleDownBlocked(web1, businessHours))
+	assert.Equal(t, 0, processor.GetMinSize(batch, businessHours))
+	assert.False(t, processor.IsScaleDownBlocked(batch, businessHours))
+
+	lastMinute := time.Date(2019, 6, 3, 14, 59, 59, 0, time.UTC)
+	assert.True(t, processor.IsScaleDownBlocked(web1, lastMinute))
+	afterHours := time.Date(2019, 6, 3, 15, 0, 0, 0, time.UTC)
+	assert.Equal(t, 1, processor.GetMinSize(web1, afterHours))
+	assert.False(t, processor.IsScaleDownBlocked(web1, afterHours))
+
+	// Saturday.
+	weekend := time.Date(2019, 6, 8, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 1, processor.GetMinSize(web1, weekend))
+
+	// Min size is capped at max size and the largest active window wins.
+	prewarm := time.Date(2019, 6, 3, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, 15, processor.GetMinSize(batch, prewarm))
+	assert.Equal(t, 10, processor.GetMinSize(web2, prewarm))
+	assert.False(t, processor.IsScaleDownBlocked(batch, prewarm))
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalingwindows
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/cron"
+	"sigs.k8s.io/yaml"
+)
+
+// WindowSpec is the user-facing definition of a scaling window.
+type WindowSpec struct {
+	// Name identifies the window in logs.
+	Name string `json:"name"`
+	// NodeGroups are regular expressions matched against node group ids.
+	NodeGroups []string `json:"nodeGroups"`
+	// Schedule is a cron expression describing when the window opens.
+	Schedule string `json:"schedule"`
+	// Duration is how long the window stays open after each activation.
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA time zone in which the schedule is evaluated. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// MinSize raises the min size of matching node groups while the window is open.
+	MinSize int `json:"minSize,omitempty"`
+	// BlockScaleDown prevents removing nodes of matching node groups while the window is open.
+	BlockScaleDown bool `json:"blockScaleDown,omitempty"`
+}
+
+// Config is the content of the scaling windows configuration file.
+type Config struct {
+	Windows []WindowSpec `json:"windows"`
+}
+
+// Window is a parsed and validated WindowSpec.
+type Window struct {
+	Name           string
+	MinSize        int
+	BlockScaleDown bool
+	nodeGroups     []*regexp.Regexp
+	schedule       *cron.Schedule
+	duration       time.Duration
+	location       *time.Location
+}
+
+// NewWindow validates the spec and builds a window from it.
+func NewWindow(spec WindowSpec) (*Window, error) {
+	if spec.MinSize <= 0 && !spec.BlockScaleDown {
+		return nil, fmt.Errorf("window %s neither sets minSize nor blocks scale-down", spec.Name)
+	}
+	if len(spec.NodeGroups) == 0 {
+		return nil, fmt.Errorf("window %s does not match any node groups", spec.Name)
+	}
+	if spec.Duration.Duration < time.Minute {
+		return nil, fmt.Errorf("window %s must last at least one minute, got %v", spec.Name, spec.Duration.Duration)
+	}
+	schedule, err := cron.Parse(spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("window %s has invalid schedule: %v", spec.Name, err)
+	}
+	location := time.UTC
+	if spec.TimeZone != "" {
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("window %s has invalid time zone: %v", spec.Name, err)
+		}
+	}
+	window := &Window{
+		Name:           spec.Name,
+		MinSize:        spec.MinSize,
+		BlockScaleDown: spec.BlockScaleDown,
+		schedule:       schedule,
+		duration:       spec.Duration.Duration,
+		location:       location,
+	}
+	for _, expr := range spec.NodeGroups {
+		nodeGroup, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("window %s has invalid node group expression %q: %v", spec.Name, expr, err)
+		}
+		window.nodeGroups = append(window.nodeGroups, nodeGroup)
+	}
+	return window, nil
+}
+
+// AppliesTo returns true if the window matches the node group.
+func (w *Window) AppliesTo(nodeGroupId string) bool {
+	for _, nodeGroup := range w.nodeGroups {
+		if nodeGroup.MatchString(nodeGroupId) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if the window opened less than its duration before now.
+func (w *Window) IsActive(now time.Time) bool {
+	activation, found := w.schedule.LastActivation(now.In(w.location), w.duration)
+	return found && now.Before(activation.Add(w.duration))
+}
+
+// ParseConfig parses a YAML or JSON scaling windows configuration.
+func ParseConfig(data []byte) ([]*Window, error) {
+	config := Config{}
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse scaling windows config: %v", err)
+	}
+	windows := make([]*Window, 0, len(config.Windows))
+	for _, spec := range config.Windows {
+		window, err := NewWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// LoadConfigFile reads scaling windows from the given file.
+func LoadConfigFile(path string) ([]*Window, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scaling windows config %s: %v", path, err)
+	}
+	return ParseConfig(data)
+}
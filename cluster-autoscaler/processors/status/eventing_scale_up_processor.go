@@ -22,6 +22,7 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
 )
 
 // EventingScaleUpStatusProcessor processes the state of the cluster after
@@ -33,11 +34,20 @@ type EventingScaleUpStatusProcessor struct{}
 // relevant events for pods depending on their post scale-up status.
 func (p *EventingScaleUpStatusProcessor) Process(context *context.AutoscalingContext, status *ScaleUpStatus) {
 	for _, noScaleUpInfo := range status.PodsRemainUnschedulable {
+		if headroom.IsHeadroomPod(noScaleUpInfo.Pod) {
+			continue
+		}
 		context.Recorder.Event(noScaleUpInfo.Pod, apiv1.EventTypeNormal, "NotTriggerScaleUp",
 			fmt.Sprintf("pod didn't trigger scale-up (it wouldn't fit if a new node is added): %s", ReasonsMessage(noScaleUpInfo)))
 	}
 	if len(status.ScaleUpInfos) > 0 {
-		for _, pod := range status.PodsTriggeredScaleUp {
+		for _, pod := range headroom.FilterOutHeadroomPods(status.PodsTriggeredScaleUp) {
+			if context.DryRun {
+				// The scale-up wasn't executed, the pod keeps waiting for it.
+				context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "WouldTriggerScaleUp",
+					"pod would trigger scale-up (dry-run): %v", status.ScaleUpInfos)
+				continue
+			}
 			context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "TriggeredScaleUp",
 				"pod triggered scale-up: %v", status.ScaleUpInfos)
 		}
@@ -22,6 +22,7 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/headroom"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	kube_record "k8s.io/client-go/tools/record"
@@ -42,6 +43,8 @@ func TestEventingScaleUpStatusProcessor(t *testing.T) {
 	p1 := BuildTestPod("p1", 0, 0)
 	p2 := BuildTestPod("p2", 0, 0)
 	p3 := BuildTestPod("p3", 0, 0)
+	headroomPod := BuildTestPod("headroom", 0, 0)
+	headroomPod.Annotations = map[string]string{headroom.PodAnnotationKey: "spare"}
 
 	notSchedulableReason := &testReason{"not schedulable"}
 	alsoNotSchedulableReason := &testReason{"also not schedulable"}
@@ -52,10 +55,12 @@ func TestEventingScaleUpStatusProcessor(t *testing.T) {
 	}
 
 	testCases := []struct {
-		caseName            string
-		state               *ScaleUpStatus
-		expectedTriggered   int
-		expectedNoTriggered int
+		caseName               string
+		state                  *ScaleUpStatus
+		dryRun                 bool
+		expectedTriggered      int
+		expectedNoTriggered    int
+		expectedWouldTriggered int
 	}{
 		{
 			caseName: "No scale up",
@@ -81,6 +86,32 @@ func TestEventingScaleUpStatusProcessor(t *testing.T) {
 			expectedTriggered:   1,
 			expectedNoTriggered: 2,
 		},
+		{
+			caseName: "Headroom pods",
+			state: &ScaleUpStatus{
+				ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{}},
+				PodsTriggeredScaleUp: []*apiv1.Pod{p3, headroomPod},
+				PodsRemainUnschedulable: []NoScaleUpInfo{
+					{p1, reasons, reasons},
+					{headroomPod, reasons, reasons},
+				},
+			},
+			expectedTriggered:   1,
+			expectedNoTriggered: 1,
+		},
+		{
+			caseName: "Dry-run scale up",
+			state: &ScaleUpStatus{
+				ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{}},
+				PodsTriggeredScaleUp: []*apiv1.Pod{p3},
+				PodsRemainUnschedulable: []NoScaleUpInfo{
+					{p1, reasons, reasons},
+				},
+			},
+			dryRun:                 true,
+			expectedNoTriggered:    1,
+			expectedWouldTriggered: 1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -90,13 +121,17 @@ func TestEventingScaleUpStatusProcessor(t *testing.T) {
 				Recorder: fakeRecorder,
 			},
 		}
+		context.DryRun = tc.dryRun
 		p.Process(context, tc.state)
 		triggered := 0
 		noTriggered := 0
+		wouldTriggered := 0
 		for eventsLeft := true; eventsLeft; {
 			select {
 			case event := <-fakeRecorder.Events:
-				if strings.Contains(event, "TriggeredScaleUp") {
+				if strings.Contains(event, "WouldTriggerScaleUp") {
+					wouldTriggered += 1
+				} else if strings.Contains(event, "TriggeredScaleUp") {
 					triggered += 1
 				} else if strings.Contains(event, "NotTriggerScaleUp") {
 					noTriggered += 1
@@ -109,6 +144,7 @@ func TestEventingScaleUpStatusProcessor(t *testing.T) {
 		}
 		assert.Equal(t, tc.expectedTriggered, triggered, "Test case '%v' failed.", tc.caseName)
 		assert.Equal(t, tc.expectedNoTriggered, noTriggered, "Test case '%v' failed.", tc.caseName)
+		assert.Equal(t, tc.expectedWouldTriggered, wouldTriggered, "Test case '%v' failed.", tc.caseName)
 	}
 }
 
@@ -74,6 +74,43 @@ func FindNodesToRemove(candidates []*apiv1.Node, allNodes []*apiv1.Node, pods []
 	timestamp time.Time,
 	podDisruptionBudgets []*policyv1.PodDisruptionBudget,
 ) (nodesToRemove []NodeToBeRemoved, unremovableNodes []*apiv1.Node, podReschedulingHints map[string]string, finalError errors.AutoscalerError) {
+	return findNodesToRemove(candidates, allNodes, pods, listers, predicateChecker, maxCount, fastCheck, false, false,
+		oldHints, usageTracker, timestamp, podDisruptionBudgets)
+}
+
+// FindNodesToRemoveTogether finds up to maxCount nodes that can be removed at the same time, using
+// the detailed checks. Unlike in FindNodesToRemove, pods are never moved to other nodes chosen for
+// removal and the pods moved from the nodes checked before are taken into account. Pods are moved
+// to candidates only if they don't fit elsewhere, so that more candidates stay removable.
+func FindNodesToRemoveTogether(candidates []*apiv1.Node, allNodes []*apiv1.Node, pods []*apiv1.Pod,
+	listers kube_util.ListerRegistry, predicateChecker *PredicateChecker, maxCount int,
+	oldHints map[string]string, usageTracker *UsageTracker,
+	timestamp time.Time,
+	podDisruptionBudgets []*policyv1.PodDisruptionBudget,
+) (nodesToRemove []NodeToBeRemoved, unremovableNodes []*apiv1.Node, podReschedulingHints map[string]string, finalError errors.AutoscalerError) {
+	return findNodesToRemove(candidates, allNodes, pods, listers, predicateChecker, maxCount, false, true, false,
+		oldHints, usageTracker, timestamp, podDisruptionBudgets)
+}
+
+// FindNodesToReplace finds up to maxCount nodes that can be removed at the same time, like
+// FindNodesToRemoveTogether, but pods are never moved to any of the candidates, as all of them
+// are going to be removed. Candidates whose pods don't fit on the other nodes are unremovable.
+func FindNodesToReplace(candidates []*apiv1.Node, allNodes []*apiv1.Node, pods []*apiv1.Pod,
+	listers kube_util.ListerRegistry, predicateChecker *PredicateChecker, maxCount int,
+	oldHints map[string]string, usageTracker *UsageTracker,
+	timestamp time.Time,
+	podDisruptionBudgets []*policyv1.PodDisruptionBudget,
+) (nodesToRemove []NodeToBeRemoved, unremovableNodes []*apiv1.Node, podReschedulingHints map[string]string, finalError errors.AutoscalerError) {
+	return findNodesToRemove(candidates, allNodes, pods, listers, predicateChecker, maxCount, false, true, true,
+		oldHints, usageTracker, timestamp, podDisruptionBudgets)
+}
+
+func findNodesToRemove(candidates []*apiv1.Node, allNodes []*apiv1.Node, pods []*apiv1.Pod,
+	listers kube_util.ListerRegistry, predicateChecker *PredicateChecker, maxCount int,
+	fastCheck bool, together bool, replace bool, oldHints map[string]string, usageTracker *UsageTracker,
+	timestamp time.Time,
+	podDisruptionBudgets []*policyv1.PodDisruptionBudget,
+) (nodesToRemove []NodeToBeRemoved, unremovableNodes []*apiv1.Node, podReschedulingHints map[string]string, finalError errors.AutoscalerError) {
 
 	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(pods, allNodes)
 	result := make([]NodeToBeRemoved, 0)
@@ -84,11 +121,18 @@ func FindNodesToRemove(candidates []*apiv1.Node, allNodes []*apiv1.Node, pods []
 		evaluationType = "Fast evaluation"
 	}
 	newHints := make(map[string]string, len(oldHints))
+	// Node infos are replaced when pods are moved to the node.
+	originalNodeInfos := nodeNameToNodeInfo
 
 candidateloop:
-	for _, node := range candidates {
+	for i, node := range candidates {
 		klog.V(2).Infof("%s: %s for removal", evaluationType, node.Name)
 
+		if together && nodeNameToNodeInfo[node.Name] != originalNodeInfos[node.Name] {
+			klog.V(2).Infof("%s: node %s takes pods of other removed nodes, skipping", evaluationType, node.Name)
+			continue candidateloop
+		}
+
 		var podsToRemove []*apiv1.Pod
 		var err error
 
@@ -110,10 +154,45 @@ candidateloop:
 			unremovable = append(unremovable, node)
 			continue candidateloop
 		}
-		findProblems := findPlaceFor(node.Name, podsToRemove, allNodes, nodeNameToNodeInfo, predicateChecker, oldHints, newHints,
-			usageTracker, timestamp)
+		removedNodes := map[string]bool{node.Name: true}
+		var newNodeInfos map[string]*schedulernodeinfo.NodeInfo
+		var findProblems error
+		if together {
+			for _, removed := range result {
+				removedNodes[removed.Node.Name] = true
+			}
+			avoidedNodes := make(map[string]bool, len(removedNodes)+len(candidates)-i)
+			for name := range removedNodes {
+				avoidedNodes[name] = true
+			}
+			avoidedCandidates := candidates[i+1:]
+			if replace {
+				avoidedCandidates = candidates
+			}
+			for _, candidate := range avoidedCandidates {
+				avoidedNodes[candidate.Name] = true
+			}
+			// The attempt may fail after placing some of the pods, so its hints and usage are
+			// recorded only if it succeeds.
+			scratchHints := make(map[string]string, len(podsToRemove))
+			newNodeInfos, findProblems = placePods(node.Name, avoidedNodes, podsToRemove, allNodes, nodeNameToNodeInfo, predicateChecker,
+				oldHints, scratchHints, NewUsageTracker(), timestamp)
+			if findProblems == nil {
+				for podKey, targetNode := range scratchHints {
+					newHints[podKey] = targetNode
+					usageTracker.RegisterUsage(node.Name, targetNode, timestamp)
+				}
+			}
+		}
+		if !together || (findProblems != nil && !replace) {
+			newNodeInfos, findProblems = placePods(node.Name, removedNodes, podsToRemove, allNodes, nodeNameToNodeInfo, predicateChecker,
+				oldHints, newHints, usageTracker, timestamp)
+		}
 
 		if findProblems == nil {
+			if together {
+				nodeNameToNodeInfo = newNodeInfos
+			}
 			result = append(result, NodeToBeRemoved{
 				Node:             node,
 				PodsToReschedule: podsToRemove,
@@ -130,6 +209,43 @@ candidateloop:
 	return result, unremovable, newHints, nil
 }
 
+// ReschedulePodsOfRemovedNodes simulates the rescheduling of the pods that weren't evicted yet from
+// removedNodes on the other nodes. Returns pods with copies of the rescheduled pods bound to their new
+// nodes, so that the capacity they are going to take isn't given to pods of other nodes. DaemonSet,
+// mirror and terminating pods aren't rescheduled, neither are pods that don't fit on any node.
+func ReschedulePodsOfRemovedNodes(removedNodes map[string]bool, nodes []*apiv1.Node, pods []*apiv1.Pod,
+	predicateChecker *PredicateChecker, timestamp time.Time) []*apiv1.Pod {
+	result := make([]*apiv1.Pod, 0, len(pods))
+	var toReschedule []*apiv1.Pod
+	for _, pod := range pods {
+		if removedNodes[pod.Spec.NodeName] && pod.DeletionTimestamp == nil && !isDaemonSet(pod) && !drain.IsMirrorPod(pod) {
+			toReschedule = append(toReschedule, pod)
+		} else {
+			result = append(result, pod)
+		}
+	}
+	if len(toReschedule) == 0 {
+		return pods
+	}
+
+	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(result, nodes)
+	for _, pod := range toReschedule {
+		hints := make(map[string]string, 1)
+		newNodeInfos, err := placePods(pod.Spec.NodeName, removedNodes, []*apiv1.Pod{pod}, nodes, nodeNameToNodeInfo, predicateChecker,
+			map[string]string{}, hints, NewUsageTracker(), timestamp)
+		if err != nil {
+			klog.V(2).Infof("Pod %s/%s of removed node %s can't be rescheduled: %v", pod.Namespace, pod.Name, pod.Spec.NodeName, err)
+			result = append(result, pod)
+			continue
+		}
+		nodeNameToNodeInfo = newNodeInfos
+		rescheduled := pod.DeepCopy()
+		rescheduled.Spec.NodeName = hints[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)]
+		result = append(result, rescheduled)
+	}
+	return result
+}
+
 // FindEmptyNodesToRemove finds empty nodes that can be removed.
 func FindEmptyNodesToRemove(candidates []*apiv1.Node, pods []*apiv1.Pod) []*apiv1.Node {
 	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(pods, candidates)
@@ -149,6 +265,12 @@ func FindEmptyNodesToRemove(candidates []*apiv1.Node, pods []*apiv1.Pod) []*apiv
 	return result
 }
 
+// GetPodsToMove returns the pods that have to be moved elsewhere before the node can be removed,
+// using the same fast checks as FindNodesToRemove. Returns an error if any pod blocks the removal.
+func GetPodsToMove(nodeInfo *schedulernodeinfo.NodeInfo, podDisruptionBudgets []*policyv1.PodDisruptionBudget) ([]*apiv1.Pod, error) {
+	return FastGetPodsToMove(nodeInfo, *skipNodesWithSystemPods, *skipNodesWithLocalStorage, podDisruptionBudgets)
+}
+
 // CalculateUtilization calculates utilization of a node, defined as maximum of (cpu, memory) utilization.
 // Per resource utilization is the sum of requests for it divided by allocatable. It also returns the individual
 // cpu and memory utilization.
@@ -195,6 +317,16 @@ func calculateUtilizationOfResource(node *apiv1.Node, nodeInfo *schedulernodeinf
 func findPlaceFor(removedNode string, pods []*apiv1.Pod, nodes []*apiv1.Node, nodeInfos map[string]*schedulernodeinfo.NodeInfo,
 	predicateChecker *PredicateChecker, oldHints map[string]string, newHints map[string]string, usageTracker *UsageTracker,
 	timestamp time.Time) error {
+	_, err := placePods(removedNode, map[string]bool{removedNode: true}, pods, nodes, nodeInfos, predicateChecker, oldHints, newHints,
+		usageTracker, timestamp)
+	return err
+}
+
+// placePods finds a place for pods of removedNode on the nodes other than removedNodes. Returns
+// the node infos updated with the moved pods.
+func placePods(removedNode string, removedNodes map[string]bool, pods []*apiv1.Pod, nodes []*apiv1.Node,
+	nodeInfos map[string]*schedulernodeinfo.NodeInfo, predicateChecker *PredicateChecker, oldHints map[string]string,
+	newHints map[string]string, usageTracker *UsageTracker, timestamp time.Time) (map[string]*schedulernodeinfo.NodeInfo, error) {
 
 	newNodeInfos := make(map[string]*schedulernodeinfo.NodeInfo)
 	for k, v := range nodeInfos {
@@ -254,14 +386,14 @@ func findPlaceFor(removedNode string, pods []*apiv1.Pod, nodes []*apiv1.Node, no
 
 		hintedNode, hasHint := oldHints[podKey(pod)]
 		if hasHint {
-			if hintedNode != removedNode && tryNodeForPod(hintedNode, pod, predicateMeta) {
+			if !removedNodes[hintedNode] && tryNodeForPod(hintedNode, pod, predicateMeta) {
 				foundPlace = true
 				targetNode = hintedNode
 			}
 		}
 		if !foundPlace {
 			for _, node := range shuffledNodes {
-				if node.Name == removedNode {
+				if removedNodes[node.Name] {
 					continue
 				}
 				if tryNodeForPod(node.Name, pod, predicateMeta) {
@@ -272,13 +404,13 @@ func findPlaceFor(removedNode string, pods []*apiv1.Pod, nodes []*apiv1.Node, no
 			}
 			if !foundPlace {
 				glogx.V(4).Over(loggingQuota).Infof("%v other nodes evaluated for %s/%s", -loggingQuota.Left(), pod.Namespace, pod.Name)
-				return fmt.Errorf("failed to find place for %s", podKey(pod))
+				return nil, fmt.Errorf("failed to find place for %s", podKey(pod))
 			}
 		}
 
 		usageTracker.RegisterUsage(removedNode, targetNode, timestamp)
 	}
-	return nil
+	return newNodeInfos, nil
 }
 
 func shuffleNodes(nodes []*apiv1.Node) []*apiv1.Node {
@@ -21,8 +21,11 @@ import (
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/kubernetes/pkg/kubelet/types"
 	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
@@ -321,3 +324,161 @@ func TestFindNodesToRemove(t *testing.T) {
 	}
 
 }
+
+func TestFindNodesToRemoveTogether(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 2000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	nodes := []*apiv1.Node{n1, n2, n3}
+	for _, node := range nodes {
+		SetNodeReadyState(node, true, time.Time{})
+	}
+
+	ownerRefs := GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+	pod1 := BuildTestPod("p1", 600, 100000)
+	pod1.OwnerReferences = ownerRefs
+	pod1.Spec.NodeName = "n1"
+	pod2 := BuildTestPod("p2", 600, 100000)
+	pod2.OwnerReferences = ownerRefs
+	pod2.Spec.NodeName = "n2"
+	pods := []*apiv1.Pod{pod1, pod2}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{rs})
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, rsLister, nil, nil, nil)
+	predicateChecker := NewTestPredicateChecker()
+
+	// Checked one by one, each node could be removed.
+	toRemove, _, _, err := FindNodesToRemove(nodes, nodes, pods, registry, predicateChecker, len(nodes), false,
+		map[string]string{}, NewUsageTracker(), time.Now(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(toRemove))
+
+	// Only n1 can be removed together with other nodes: its pod takes n3 and p2 has nowhere to go.
+	toRemove, unremovable, _, err := FindNodesToRemoveTogether(nodes, nodes, pods, registry, predicateChecker, len(nodes),
+		map[string]string{}, NewUsageTracker(), time.Now(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []NodeToBeRemoved{{Node: n1, PodsToReschedule: []*apiv1.Pod{pod1}}}, toRemove)
+	assert.Equal(t, []*apiv1.Node{n2}, unremovable)
+
+	// The limit is respected.
+	toRemove, _, _, err = FindNodesToRemoveTogether([]*apiv1.Node{n3, n1}, nodes, pods, registry, predicateChecker, 1,
+		map[string]string{}, NewUsageTracker(), time.Now(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []NodeToBeRemoved{{Node: n3, PodsToReschedule: []*apiv1.Pod{}}}, toRemove)
+}
+
+func TestReschedulePodsOfRemovedNodes(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 2000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	for _, node := range []*apiv1.Node{n1, n2, n3} {
+		SetNodeReadyState(node, true, time.Time{})
+	}
+
+	pod1 := BuildTestPod("p1", 500, 100000)
+	pod1.Spec.NodeName = "n1"
+	pod2 := BuildTestPod("p2", 600, 100000)
+	pod2.Spec.NodeName = "n1"
+	ds := BuildTestPod("ds", 100, 100000)
+	ds.OwnerReferences = GenerateOwnerReferences("ds", "DaemonSet", "apps/v1", "")
+	ds.Spec.NodeName = "n1"
+	pod3 := BuildTestPod("p3", 500, 100000)
+	pod3.Spec.NodeName = "n2"
+	pod4 := BuildTestPod("p4", 500, 100000)
+	pod4.Spec.NodeName = "n3"
+	pods := []*apiv1.Pod{pod1, pod2, ds, pod3, pod4}
+
+	// p1 takes the free capacity of one of the other nodes, p2 doesn't fit anywhere.
+	result := ReschedulePodsOfRemovedNodes(map[string]bool{"n1": true}, []*apiv1.Node{n2, n3}, pods, NewTestPredicateChecker(), time.Now())
+	assert.Len(t, result, 5)
+	nodeNames := make(map[string]string)
+	for _, pod := range result {
+		nodeNames[pod.Name] = pod.Spec.NodeName
+	}
+	assert.Contains(t, []string{"n2", "n3"}, nodeNames["p1"])
+	assert.Equal(t, "n1", nodeNames["p2"])
+	assert.Equal(t, "n1", nodeNames["ds"])
+	assert.Equal(t, "n1", pod1.Spec.NodeName)
+
+	// Nothing changes without pods on removed nodes.
+	assert.Equal(t, pods, ReschedulePodsOfRemovedNodes(map[string]bool{"n4": true}, []*apiv1.Node{n2, n3}, pods,
+		NewTestPredicateChecker(), time.Now()))
+}
+
+func TestFindNodesToRemoveTogetherFailedAttempt(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 2000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	nodes := []*apiv1.Node{n1, n2, n3}
+	for _, node := range nodes {
+		SetNodeReadyState(node, true, time.Time{})
+	}
+
+	ownerRefs := GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+	pod1 := BuildTestPod("p1", 300, 100000)
+	pod1.OwnerReferences = ownerRefs
+	pod1.Spec.NodeName = "n1"
+	pod2 := BuildTestPod("p2", 500, 100000)
+	pod2.OwnerReferences = ownerRefs
+	pod2.Spec.NodeName = "n1"
+	pod3 := BuildTestPod("p3", 700, 100000)
+	pod3.OwnerReferences = ownerRefs
+	pod3.Spec.NodeName = "n3"
+	pods := []*apiv1.Pod{pod1, pod2, pod3}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{rs})
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, rsLister, nil, nil, nil)
+
+	// Avoiding the other candidate n2, p1 fits on n3 but p2 has nowhere to go. The pods of n1
+	// are then moved to n2, leaving no trace of the failed attempt.
+	oldHints := map[string]string{"default/p1": "n2", "default/p2": "n2"}
+	tracker := NewUsageTracker()
+	toRemove, _, newHints, err := FindNodesToRemoveTogether([]*apiv1.Node{n1, n2}, nodes, pods, registry, NewTestPredicateChecker(),
+		len(nodes), oldHints, tracker, time.Now(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []NodeToBeRemoved{{Node: n1, PodsToReschedule: []*apiv1.Pod{pod1, pod2}}}, toRemove)
+	assert.Equal(t, map[string]string{"default/p1": "n2", "default/p2": "n2"}, newHints)
+	_, found := tracker.Get("n3")
+	assert.False(t, found)
+	_, found = tracker.Get("n2")
+	assert.True(t, found)
+}
+
+func TestFindNodesToReplace(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 2000000)
+	n2 := BuildTestNode("n2", 1000, 2000000)
+	n3 := BuildTestNode("n3", 1000, 2000000)
+	nodes := []*apiv1.Node{n1, n2, n3}
+	for _, node := range nodes {
+		SetNodeReadyState(node, true, time.Time{})
+	}
+
+	ownerRefs := GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+	pod1 := BuildTestPod("p1", 300, 100000)
+	pod1.OwnerReferences = ownerRefs
+	pod1.Spec.NodeName = "n1"
+	pod2 := BuildTestPod("p2", 500, 100000)
+	pod2.OwnerReferences = ownerRefs
+	pod2.Spec.NodeName = "n1"
+	pod3 := BuildTestPod("p3", 700, 100000)
+	pod3.OwnerReferences = ownerRefs
+	pod3.Spec.NodeName = "n3"
+	pods := []*apiv1.Pod{pod1, pod2, pod3}
+
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "default"}}
+	rsLister, err := kube_util.NewTestReplicaSetLister([]*appsv1.ReplicaSet{rs})
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, rsLister, nil, nil, nil)
+
+	// Unlike in FindNodesToRemoveTogether, the pods of n1 aren't moved to the other candidate n2
+	// when they don't fit elsewhere, so n1 is unremovable.
+	toRemove, unremovable, _, err := FindNodesToReplace([]*apiv1.Node{n1, n2}, nodes, pods, registry, NewTestPredicateChecker(),
+		len(nodes), map[string]string{}, NewUsageTracker(), time.Now(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []NodeToBeRemoved{{Node: n2, PodsToReschedule: []*apiv1.Pod{}}}, toRemove)
+	assert.Equal(t, []*apiv1.Node{n1}, unremovable)
+}
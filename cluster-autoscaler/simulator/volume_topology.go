@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	volumehelpers "k8s.io/cloud-provider/volume/helpers"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	"k8s.io/klog"
+)
+
+// VolumeTopologyPredicateName is the name reported in errors of CheckVolumeTopology.
+const VolumeTopologyPredicateName = "VolumeTopology"
+
+// CheckVolumeTopology checks whether the persistent volumes already bound to the claims of
+// the pod can be attached to the given node, based on their zone labels and node affinity.
+// Unlike the scheduler volume predicates it only needs the node labels, so it works for
+// template nodes of node groups that don't have any nodes yet. Claims that aren't bound
+// yet don't restrict the node. Returns nil if the pod can use the node.
+func CheckVolumeTopology(pod *apiv1.Pod, node *apiv1.Node, listers kube_util.ListerRegistry) *PredicateError {
+	if listers == nil || listers.PersistentVolumeClaimLister() == nil || listers.PersistentVolumeLister() == nil {
+		return nil
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claimName := volume.PersistentVolumeClaim.ClaimName
+		pvc, err := listers.PersistentVolumeClaimLister().PersistentVolumeClaims(pod.Namespace).Get(claimName)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				klog.Warningf("Failed to get claim %s/%s of pod %s: %v", pod.Namespace, claimName, pod.Name, err)
+			}
+			continue
+		}
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := listers.PersistentVolumeLister().Get(pvc.Spec.VolumeName)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				klog.Warningf("Failed to get volume %s bound to claim %s/%s: %v", pvc.Spec.VolumeName, pod.Namespace, claimName, err)
+			}
+			continue
+		}
+		if reason := volumeTopologyMismatch(pv, node); reason != "" {
+			return NewPredicateError(VolumeTopologyPredicateName, nil, []string{reason}, nil)
+		}
+	}
+	return nil
+}
+
+// volumeTopologyMismatch returns the reason why the volume can't be attached to the node,
+// or an empty string if it can.
+func volumeTopologyMismatch(pv *apiv1.PersistentVolume, node *apiv1.Node) string {
+	if zoneLabel, found := pv.Labels[apiv1.LabelZoneFailureDomain]; found {
+		zones, err := volumehelpers.LabelZonesToSet(zoneLabel)
+		if err != nil {
+			klog.Warningf("Invalid zone label %q of volume %s: %v", zoneLabel, pv.Name, err)
+		} else if !zones.Has(node.Labels[apiv1.LabelZoneFailureDomain]) {
+			return fmt.Sprintf("volume %s is bound to zone %s", pv.Name, zoneLabel)
+		}
+	}
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+		terms := pv.Spec.NodeAffinity.Required.NodeSelectorTerms
+		if !v1helper.MatchNodeSelectorTerms(terms, labels.Set(node.Labels), nil) {
+			return fmt.Sprintf("volume %s node affinity doesn't match the node", pv.Name)
+		}
+	}
+	return ""
+}
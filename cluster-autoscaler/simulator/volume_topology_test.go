@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestClaim(name, volumeName string) *apiv1.PersistentVolumeClaim {
+	return &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       apiv1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+	}
+}
+
+func buildTestVolume(name, zone string) *apiv1.PersistentVolume {
+	pv := &apiv1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+	if zone != "" {
+		pv.Labels[apiv1.LabelZoneFailureDomain] = zone
+	}
+	return pv
+}
+
+func addClaimToPod(pod *apiv1.Pod, claimName string) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, apiv1.Volume{
+		Name: claimName,
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	})
+}
+
+func TestCheckVolumeTopology(t *testing.T) {
+	affinityPV := buildTestVolume("pv-affinity", "")
+	affinityPV.Spec.NodeAffinity = &apiv1.VolumeNodeAffinity{
+		Required: &apiv1.NodeSelector{
+			NodeSelectorTerms: []apiv1.NodeSelectorTerm{{
+				MatchExpressions: []apiv1.NodeSelectorRequirement{{
+					Key:      apiv1.LabelZoneFailureDomain,
+					Operator: apiv1.NodeSelectorOpIn,
+					Values:   []string{"zone-b"},
+				}},
+			}},
+		},
+	}
+	pvcLister, err := kube_util.NewTestPersistentVolumeClaimLister([]*apiv1.PersistentVolumeClaim{
+		buildTestClaim("claim-a", "pv-a"),
+		buildTestClaim("claim-ab", "pv-ab"),
+		buildTestClaim("claim-affinity", "pv-affinity"),
+		buildTestClaim("claim-unbound", ""),
+		buildTestClaim("claim-missing-pv", "pv-missing"),
+	})
+	assert.NoError(t, err)
+	pvLister, err := kube_util.NewTestPersistentVolumeLister([]*apiv1.PersistentVolume{
+		buildTestVolume("pv-a", "zone-a"),
+		buildTestVolume("pv-ab", "zone-a__zone-b"),
+		affinityPV,
+	})
+	assert.NoError(t, err)
+	listers := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, pvcLister, pvLister)
+
+	nodeA := BuildTestNode("node-a", 1000, 1000)
+	nodeA.Labels[apiv1.LabelZoneFailureDomain] = "zone-a"
+	nodeB := BuildTestNode("node-b", 1000, 1000)
+	nodeB.Labels[apiv1.LabelZoneFailureDomain] = "zone-b"
+
+	testCases := []struct {
+		claim    string
+		node     *apiv1.Node
+		expected bool
+	}{
+		{"claim-a", nodeA, true},
+		{"claim-a", nodeB, false},
+		{"claim-ab", nodeA, true},
+		{"claim-ab", nodeB, true},
+		{"claim-affinity", nodeA, false},
+		{"claim-affinity", nodeB, true},
+		{"claim-unbound", nodeA, true},
+		{"claim-missing-pv", nodeA, true},
+		{"claim-not-found", nodeA, true},
+	}
+	for _, tc := range testCases {
+		pod := BuildTestPod("p1", 100, 0)
+		addClaimToPod(pod, tc.claim)
+		predicateErr := CheckVolumeTopology(pod, tc.node, listers)
+		assert.Equal(t, tc.expected, predicateErr == nil, "claim %s on node %s", tc.claim, tc.node.Name)
+	}
+
+	pod := BuildTestPod("p1", 100, 0)
+	addClaimToPod(pod, "claim-a")
+	predicateErr := CheckVolumeTopology(pod, nodeB, listers)
+	assert.NotNil(t, predicateErr)
+	assert.Equal(t, []string{"volume pv-a is bound to zone zone-a"}, predicateErr.Reasons())
+
+	// Without volume listers the volumes are not checked.
+	assert.Nil(t, CheckVolumeTopology(pod, nodeB, nil))
+	assert.Nil(t, CheckVolumeTopology(pod, nodeB, kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)))
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	podv1 "k8s.io/kubernetes/pkg/api/v1/pod"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+
+	"k8s.io/klog"
+)
+
+// Environment replays a ClusterSnapshot against fake Kubernetes listers and the
+// test cloud provider. Scale-ups and scale-downs executed by the autoscaler are
+// applied to the environment, so that consecutive autoscaler iterations observe
+// the effects of previous decisions: nodes removed by scale-down disappear together
+// with their pods, scale-ups create new ready nodes from node group templates and
+// SchedulePods places pending pods the way the scheduler would. Pods evicted from
+// removed nodes are not recreated.
+type Environment struct {
+	sync.Mutex
+	state         *snapshot.ClusterSnapshot
+	templates     map[string]*schedulernodeinfo.NodeInfo
+	createdNodes  int
+	deletedNodes  map[string]bool
+	cloudProvider *testprovider.TestCloudProvider
+	clientSet     *fake.Clientset
+}
+
+// NewEnvironment builds an environment from the given snapshot. The snapshot is
+// copied and not modified afterwards.
+func NewEnvironment(clusterSnapshot *snapshot.ClusterSnapshot) (*Environment, error) {
+	if err := clusterSnapshot.Validate(); err != nil {
+		return nil, err
+	}
+	state := &snapshot.ClusterSnapshot{Version: clusterSnapshot.Version, Timestamp: clusterSnapshot.Timestamp}
+	if err := state.Merge(clusterSnapshot); err != nil {
+		return nil, err
+	}
+	env := &Environment{
+		state:        state,
+		templates:    make(map[string]*schedulernodeinfo.NodeInfo),
+		deletedNodes: make(map[string]bool),
+	}
+
+	nodesByName := make(map[string]*apiv1.Node)
+	objects := make([]runtime.Object, 0, len(state.Nodes))
+	for _, node := range state.Nodes {
+		nodesByName[node.Name] = node
+		objects = append(objects, node.DeepCopy())
+	}
+	env.clientSet = fake.NewSimpleClientset(objects...)
+	// Evictions always succeed. Pods are not stored in the fake client, so drains
+	// finish as soon as all evictions are created.
+	env.clientSet.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return action.GetSubresource() == "eviction", nil, nil
+	})
+
+	env.cloudProvider = testprovider.NewTestAutoprovisioningCloudProvider(env.scaleUp, env.scaleDown,
+		nil, nil, nil, env.templates)
+	for _, nodeGroup := range state.NodeGroups {
+		env.cloudProvider.AddNodeGroup(nodeGroup.Id, nodeGroup.MinSize, nodeGroup.MaxSize, nodeGroup.TargetSize)
+		for _, name := range nodeGroup.Nodes {
+			env.cloudProvider.AddNode(nodeGroup.Id, nodesByName[name])
+		}
+		env.templates[nodeGroup.Id] = buildTemplateNodeInfo(nodeGroup, nodesByName)
+	}
+	return env, nil
+}
+
+// CloudProvider returns the cloud provider exposing node groups from the snapshot.
+func (e *Environment) CloudProvider() *testprovider.TestCloudProvider {
+	return e.cloudProvider
+}
+
+// ClientSet returns the fake client used to taint and drain nodes.
+func (e *Environment) ClientSet() *fake.Clientset {
+	return e.clientSet
+}
+
+// ListerRegistry returns listers reflecting the current state of the environment.
+func (e *Environment) ListerRegistry() (kube_util.ListerRegistry, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	readyNodes := make([]*apiv1.Node, 0, len(e.state.Nodes))
+	for _, node := range e.state.Nodes {
+		if kube_util.IsNodeReadyAndSchedulable(node) {
+			readyNodes = append(readyNodes, node)
+		}
+	}
+	var scheduledPods, unschedulablePods []*apiv1.Pod
+	for _, pod := range e.state.Pods {
+		if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+		if pod.Spec.NodeName != "" {
+			scheduledPods = append(scheduledPods, pod)
+			continue
+		}
+		_, condition := podv1.GetPodCondition(&pod.Status, apiv1.PodScheduled)
+		if condition != nil && condition.Status == apiv1.ConditionFalse && condition.Reason == apiv1.PodReasonUnschedulable {
+			unschedulablePods = append(unschedulablePods, pod)
+		}
+	}
+
+	daemonSetLister, err := kube_util.NewTestDaemonSetLister(e.state.DaemonSets)
+	if err != nil {
+		return nil, err
+	}
+	replicationControllerLister, err := kube_util.NewTestReplicationControllerLister(e.state.ReplicationControllers)
+	if err != nil {
+		return nil, err
+	}
+	jobLister, err := kube_util.NewTestJobLister(e.state.Jobs)
+	if err != nil {
+		return nil, err
+	}
+	replicaSetLister, err := kube_util.NewTestReplicaSetLister(e.state.ReplicaSets)
+	if err != nil {
+		return nil, err
+	}
+	statefulSetLister, err := kube_util.NewTestStatefulSetLister(e.state.StatefulSets)
+	if err != nil {
+		return nil, err
+	}
+	return kube_util.NewListerRegistry(
+		kube_util.NewTestNodeLister(append([]*apiv1.Node{}, e.state.Nodes...)),
+		kube_util.NewTestNodeLister(readyNodes),
+		kube_util.NewTestPodLister(scheduledPods),
+		kube_util.NewTestPodLister(unschedulablePods),
+		kube_util.NewTestPodDisruptionBudgetLister(e.state.PodDisruptionBudgets),
+		daemonSetLister, replicationControllerLister, jobLister, replicaSetLister, statefulSetLister, nil, nil), nil
+}
+
+// SchedulePods binds pending pods to ready nodes they fit on and returns the bound pods.
+func (e *Environment) SchedulePods(predicateChecker *simulator.PredicateChecker) []*apiv1.Pod {
+	e.Lock()
+	defer e.Unlock()
+
+	readyNodes := make([]*apiv1.Node, 0, len(e.state.Nodes))
+	for _, node := range e.state.Nodes {
+		if kube_util.IsNodeReadyAndSchedulable(node) {
+			readyNodes = append(readyNodes, node)
+		}
+	}
+	scheduledPods := make([]*apiv1.Pod, 0, len(e.state.Pods))
+	for _, pod := range e.state.Pods {
+		if pod.Spec.NodeName != "" {
+			scheduledPods = append(scheduledPods, pod)
+		}
+	}
+	nodeNameToNodeInfo := scheduler_util.CreateNodeNameToInfoMap(scheduledPods, readyNodes)
+
+	var bound []*apiv1.Pod
+	for i, pod := range e.state.Pods {
+		if pod.Spec.NodeName != "" || pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+		nodeName, err := predicateChecker.FitsAny(pod, nodeNameToNodeInfo)
+		if err != nil {
+			continue
+		}
+		boundPod := pod.DeepCopy()
+		boundPod.Spec.NodeName = nodeName
+		boundPod.Status.Conditions = nil
+		nodeNameToNodeInfo[nodeName].AddPod(boundPod)
+		e.state.Pods[i] = boundPod
+		bound = append(bound, boundPod)
+	}
+	return bound
+}
+
+// IsNodeDeleted returns true if the node was removed by a scale-down.
+func (e *Environment) IsNodeDeleted(name string) bool {
+	e.Lock()
+	defer e.Unlock()
+	return e.deletedNodes[name]
+}
+
+// scaleUp creates delta new ready nodes in the given node group.
+func (e *Environment) scaleUp(nodeGroupId string, delta int) error {
+	if delta <= 0 {
+		return nil
+	}
+
+	e.Lock()
+	defer e.Unlock()
+	template, found := e.templates[nodeGroupId]
+	if !found {
+		return fmt.Errorf("no template for node group %s", nodeGroupId)
+	}
+	for i := 0; i < delta; i++ {
+		e.createdNodes++
+		name := fmt.Sprintf("%s-simulated-%d", nodeGroupId, e.createdNodes)
+		node := template.Node().DeepCopy()
+		node.Name = name
+		node.UID = ""
+		node.CreationTimestamp = metav1.NewTime(time.Now())
+		node.Spec.ProviderID = name
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+		node.Labels[apiv1.LabelHostname] = name
+		setNodeReady(node)
+		for _, templatePod := range template.Pods() {
+			pod := templatePod.DeepCopy()
+			pod.Name = fmt.Sprintf("%s-%s", pod.Name, name)
+			pod.UID = ""
+			pod.Spec.NodeName = name
+			e.state.Pods = append(e.state.Pods, pod)
+		}
+		e.state.Nodes = append(e.state.Nodes, node)
+		e.cloudProvider.AddNode(nodeGroupId, node)
+		if _, err := e.clientSet.CoreV1().Nodes().Create(node.DeepCopy()); err != nil {
+			return err
+		}
+		klog.V(2).Infof("Simulated node %s created in node group %s", name, nodeGroupId)
+	}
+	return nil
+}
+
+// scaleDown removes the node and all pods running on it.
+func (e *Environment) scaleDown(nodeGroupId string, nodeName string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	nodes := make([]*apiv1.Node, 0, len(e.state.Nodes))
+	for _, node := range e.state.Nodes {
+		if node.Name != nodeName {
+			nodes = append(nodes, node)
+		}
+	}
+	pods := make([]*apiv1.Pod, 0, len(e.state.Pods))
+	for _, pod := range e.state.Pods {
+		if pod.Spec.NodeName != nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	e.state.Nodes = nodes
+	e.state.Pods = pods
+	e.deletedNodes[nodeName] = true
+	e.cloudProvider.RemoveNode(nodeName)
+	klog.V(2).Infof("Simulated node %s deleted from node group %s", nodeName, nodeGroupId)
+	return nil
+}
+
+// buildTemplateNodeInfo returns the explicit node group template or, if there is none,
+// a template based on one of the node group nodes, preferably a ready one.
+func buildTemplateNodeInfo(nodeGroup *snapshot.NodeGroup, nodesByName map[string]*apiv1.Node) *schedulernodeinfo.NodeInfo {
+	if nodeGroup.Template != nil {
+		nodeInfo := schedulernodeinfo.NewNodeInfo(nodeGroup.Template.Pods...)
+		nodeInfo.SetNode(nodeGroup.Template.Node)
+		return nodeInfo
+	}
+	var sample *apiv1.Node
+	for _, name := range nodeGroup.Nodes {
+		node := nodesByName[name]
+		if sample == nil || kube_util.IsNodeReadyAndSchedulable(node) {
+			sample = node
+		}
+		if kube_util.IsNodeReadyAndSchedulable(node) {
+			break
+		}
+	}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(sample)
+	return nodeInfo
+}
+
+func setNodeReady(node *apiv1.Node) {
+	now := metav1.NewTime(time.Now())
+	node.Spec.Unschedulable = false
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == apiv1.NodeReady {
+			node.Status.Conditions[i].Status = apiv1.ConditionTrue
+			node.Status.Conditions[i].LastTransitionTime = now
+			return
+		}
+	}
+	node.Status.Conditions = append(node.Status.Conditions, apiv1.NodeCondition{
+		Type:               apiv1.NodeReady,
+		Status:             apiv1.ConditionTrue,
+		LastTransitionTime: now,
+	})
+}
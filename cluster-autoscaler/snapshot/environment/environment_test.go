@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestSnapshot() *snapshot.ClusterSnapshot {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	p1 := BuildTestPod("p1", 600, 100)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 600, 100)
+	p2.Status.Conditions = []apiv1.PodCondition{{
+		Type:   apiv1.PodScheduled,
+		Status: apiv1.ConditionFalse,
+		Reason: apiv1.PodReasonUnschedulable,
+	}}
+
+	clusterSnapshot := snapshot.NewClusterSnapshot(now)
+	clusterSnapshot.Nodes = []*apiv1.Node{n1, n2}
+	clusterSnapshot.Pods = []*apiv1.Pod{p1, p2}
+	clusterSnapshot.NodeGroups = []*snapshot.NodeGroup{{Id: "ng1", MinSize: 1, MaxSize: 10, TargetSize: 2, Nodes: []string{"n1", "n2"}}}
+	return clusterSnapshot
+}
+
+func TestEnvironment(t *testing.T) {
+	env, err := NewEnvironment(buildTestSnapshot())
+	assert.NoError(t, err)
+
+	registry, err := env.ListerRegistry()
+	assert.NoError(t, err)
+	readyNodes, err := registry.ReadyNodeLister().List()
+	assert.NoError(t, err)
+	assert.Len(t, readyNodes, 2)
+	scheduledPods, err := registry.ScheduledPodLister().List()
+	assert.NoError(t, err)
+	assert.Len(t, scheduledPods, 1)
+	unschedulablePods, err := registry.UnschedulablePodLister().List()
+	assert.NoError(t, err)
+	assert.Len(t, unschedulablePods, 1)
+
+	nodeGroup := env.CloudProvider().GetNodeGroup("ng1")
+	assert.NoError(t, nodeGroup.IncreaseSize(1))
+	nodes, err := nodeGroup.Nodes()
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 3)
+
+	bound := env.SchedulePods(simulator.NewTestPredicateChecker())
+	assert.Len(t, bound, 1)
+	assert.Equal(t, "p2", bound[0].Name)
+
+	assert.NoError(t, nodeGroup.DeleteNodes([]*apiv1.Node{BuildTestNode("n1", 1000, 1000)}))
+	assert.True(t, env.IsNodeDeleted("n1"))
+	registry, err = env.ListerRegistry()
+	assert.NoError(t, err)
+	allNodes, err := registry.AllNodeLister().List()
+	assert.NoError(t, err)
+	assert.Len(t, allNodes, 2)
+	scheduledPods, err = registry.ScheduledPodLister().List()
+	assert.NoError(t, err)
+	assert.Len(t, scheduledPods, 1)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"net/http"
+	"sync"
+
+	"k8s.io/klog"
+)
+
+// Exporter produces snapshots of the cluster as seen by the autoscaler.
+type Exporter interface {
+	// ExportSnapshot returns the state observed during the last autoscaler iteration.
+	ExportSnapshot() (*ClusterSnapshot, error)
+}
+
+// Handler serves snapshots produced by an Exporter over HTTP. The exporter is
+// set once the autoscaler is running, until then the handler responds with
+// 503 Service Unavailable.
+type Handler struct {
+	mutex    sync.Mutex
+	exporter Exporter
+}
+
+// NewHandler creates a handler without an exporter.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// SetExporter sets the exporter used to serve subsequent requests.
+func (h *Handler) SetExporter(exporter Exporter) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.exporter = exporter
+}
+
+// ServeHTTP writes the current snapshot as JSON.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	exporter := h.exporter
+	h.mutex.Unlock()
+
+	if exporter == nil {
+		http.Error(w, "autoscaler is not running", http.StatusServiceUnavailable)
+		return
+	}
+	snapshot, err := exporter.ExportSnapshot()
+	if err != nil {
+		klog.Errorf("Failed to export snapshot: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := snapshot.Write(w); err != nil {
+		klog.Errorf("Failed to write snapshot: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testExporter struct {
+	snapshot *ClusterSnapshot
+	err      error
+}
+
+func (e *testExporter) ExportSnapshot() (*ClusterSnapshot, error) {
+	return e.snapshot, e.err
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/snapshot", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	handler.SetExporter(&testExporter{err: fmt.Errorf("broken")})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/snapshot", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	handler.SetExporter(&testExporter{snapshot: buildTestSnapshot()})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/snapshot", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	loaded, err := Load(recorder.Body)
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Nodes, 2)
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command simulate runs Cluster Autoscaler against a recorded cluster snapshot
+// instead of a live cluster and prints the scale-up and scale-down decisions.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	ca_utils "k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/factory"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot/environment"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+// MultiStringFlag is a flag for passing multiple parameters using same flag
+type MultiStringFlag []string
+
+// String returns string representation of the flag values.
+func (flag *MultiStringFlag) String() string {
+	return "[" + strings.Join(*flag, " ") + "]"
+}
+
+// Set adds a new value.
+func (flag *MultiStringFlag) Set(value string) error {
+	*flag = append(*flag, value)
+	return nil
+}
+
+func multiStringFlag(name string, usage string) *MultiStringFlag {
+	value := new(MultiStringFlag)
+	flag.Var(value, name, usage)
+	return value
+}
+
+var (
+	snapshotFiles = multiStringFlag("snapshot",
+		"Path to a JSON or YAML cluster snapshot. Can be used multiple times, snapshots are merged.")
+	iterations        = flag.Int("iterations", 1, "Number of autoscaler iterations to run.")
+	iterationInterval = flag.Duration("iteration-interval", 10*time.Second,
+		"Simulated time between consecutive iterations. Scale-down needs the simulated time to exceed scale-down-unneeded-time.")
+	printStatus = flag.Bool("print-status", false, "Print the cluster-autoscaler-status content after each iteration.")
+	namespace   = flag.String("namespace", "kube-system", "Namespace in which cluster-autoscaler would run.")
+
+	scaleDownEnabled              = flag.Bool("scale-down-enabled", true, "Should CA scale down the cluster")
+	scaleDownDelayAfterAdd        = flag.Duration("scale-down-delay-after-add", 10*time.Minute, "How long after scale up that scale down evaluation resumes")
+	scaleDownDelayAfterDelete     = flag.Duration("scale-down-delay-after-delete", 10*time.Second, "How long after node deletion that scale down evaluation resumes")
+	scaleDownDelayAfterFailure    = flag.Duration("scale-down-delay-after-failure", 3*time.Minute, "How long after scale down failure that scale down evaluation resumes")
+	scaleDownUnneededTime         = flag.Duration("scale-down-unneeded-time", 10*time.Minute, "How long a node should be unneeded before it is eligible for scale down")
+	scaleDownUnreadyTime          = flag.Duration("scale-down-unready-time", 20*time.Minute, "How long an unready node should be unneeded before it is eligible for scale down")
+	scaleDownUtilizationThreshold = flag.Float64("scale-down-utilization-threshold", 0.5,
+		"Node utilization level, defined as sum of requested resources divided by capacity, below which a node can be considered for scale down")
+	scaleDownNonEmptyCandidatesCount = flag.Int("scale-down-non-empty-candidates-count", 30,
+		"Maximum number of non empty nodes considered in one iteration as candidates for scale down with drain.")
+	maxEmptyBulkDelete           = flag.Int("max-empty-bulk-delete", 10, "Maximum number of empty nodes that can be deleted at the same time.")
+	maxNodesTotal                = flag.Int("max-nodes-total", 0, "Maximum number of nodes in all node groups.")
+	maxNodeProvisionTime         = flag.Duration("max-node-provision-time", 15*time.Minute, "Maximum time CA waits for node to be provisioned")
+	estimatorFlag                = flag.String("estimator", estimator.BinpackingEstimatorName, "Type of resource estimator to be used in scale up. Available values: ["+strings.Join(estimator.AvailableEstimators, ",")+"]")
+	expanderFlag                 = flag.String("expander", expander.RandomExpanderName, "Comma separated list of node group expanders to be used in scale up. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]")
+	balanceSimilarNodeGroups     = flag.Bool("balance-similar-node-groups", false, "Detect similar node groups and balance the number of nodes between them")
+	expendablePodsPriorityCutoff = flag.Int("expendable-pods-priority-cutoff", -10, "Pods with priority below cutoff will be expendable.")
+	ignoreDaemonSetsUtilization  = flag.Bool("ignore-daemonsets-utilization", false, "Should CA ignore DaemonSet pods when calculating resource utilization for scaling down")
+	ignoreMirrorPodsUtilization  = flag.Bool("ignore-mirror-pods-utilization", false, "Should CA ignore Mirror pods when calculating resource utilization for scaling down")
+	newPodScaleUpDelay           = flag.Duration("new-pod-scale-up-delay", 0*time.Second, "Pods less than this old will not be considered for scale-up.")
+)
+
+// How long to wait for a started drain to finish before the next iteration.
+const nodeDeletionTimeout = 30 * time.Second
+
+func createAutoscalingOptions() config.AutoscalingOptions {
+	return config.AutoscalingOptions{
+		CloudProviderName:                   "simulated",
+		MaxTotalUnreadyPercentage:           45,
+		OkTotalUnreadyCount:                 3,
+		EstimatorName:                       *estimatorFlag,
+		ExpanderNames:                       *expanderFlag,
+		IgnoreDaemonSetsUtilization:         *ignoreDaemonSetsUtilization,
+		IgnoreMirrorPodsUtilization:         *ignoreMirrorPodsUtilization,
+		MaxEmptyBulkDelete:                  *maxEmptyBulkDelete,
+		MaxGracefulTerminationSec:           0,
+		MaxNodeProvisionTime:                *maxNodeProvisionTime,
+		MaxNodesTotal:                       *maxNodesTotal,
+		MaxCoresTotal:                       config.DefaultMaxClusterCores,
+		MaxMemoryTotal:                      config.DefaultMaxClusterMemory,
+		ScaleDownDelayAfterAdd:              *scaleDownDelayAfterAdd,
+		ScaleDownDelayAfterDelete:           *scaleDownDelayAfterDelete,
+		ScaleDownDelayAfterFailure:          *scaleDownDelayAfterFailure,
+		ScaleDownEnabled:                    *scaleDownEnabled,
+		ScaleDownUnneededTime:               *scaleDownUnneededTime,
+		ScaleDownUnreadyTime:                *scaleDownUnreadyTime,
+		ScaleDownUtilizationThreshold:       *scaleDownUtilizationThreshold,
+		ScaleDownNonEmptyCandidatesCount:    *scaleDownNonEmptyCandidatesCount,
+		ScaleDownCandidatesPoolRatio:        0.1,
+		ScaleDownCandidatesPoolMinCount:     50,
+		WriteStatusConfigMap:                false,
+		BalanceSimilarNodeGroups:            *balanceSimilarNodeGroups,
+		ConfigNamespace:                     *namespace,
+		UnremovableNodeRecheckTimeout:       5 * time.Minute,
+		ExpendablePodsPriorityCutoff:        *expendablePodsPriorityCutoff,
+		NewPodScaleUpDelay:                  *newPodScaleUpDelay,
+		FilterOutSchedulablePodsUsesPacking: true,
+	}
+}
+
+// decisionPrinter prints autoscaler decisions as they are reported to status processors.
+type decisionPrinter struct {
+	out          io.Writer
+	printStatus  bool
+	deletedNodes []string
+}
+
+// Process prints the result of a scale-up.
+func (p *decisionPrinter) Process(context *context.AutoscalingContext, scaleUpStatus *status.ScaleUpStatus) {
+	if scaleUpStatus.Result == status.ScaleUpNotTried || scaleUpStatus.Result == status.ScaleUpNotNeeded {
+		return
+	}
+	for _, info := range scaleUpStatus.ScaleUpInfos {
+		fmt.Fprintf(p.out, "  Scale-up: node group %s %d->%d (max: %d)\n", info.Group.Id(), info.CurrentSize, info.NewSize, info.MaxSize)
+	}
+	if len(scaleUpStatus.PodsTriggeredScaleUp) > 0 {
+		fmt.Fprintf(p.out, "  Pods triggering scale-up: %s\n", podNames(scaleUpStatus.PodsTriggeredScaleUp))
+	}
+	for _, noScaleUp := range scaleUpStatus.PodsRemainUnschedulable {
+		fmt.Fprintf(p.out, "  Pod %s/%s does not trigger scale-up:\n", noScaleUp.Pod.Namespace, noScaleUp.Pod.Name)
+		for nodeGroup, reasons := range noScaleUp.RejectedNodeGroups {
+			fmt.Fprintf(p.out, "    %s: %s\n", nodeGroup, strings.Join(reasons.Reasons(), "; "))
+		}
+		for nodeGroup, reasons := range noScaleUp.SkippedNodeGroups {
+			fmt.Fprintf(p.out, "    %s (skipped): %s\n", nodeGroup, strings.Join(reasons.Reasons(), "; "))
+		}
+	}
+	if len(scaleUpStatus.ScaleUpInfos) == 0 {
+		fmt.Fprintf(p.out, "  Scale-up: %s\n", scaleUpResultString(scaleUpStatus.Result))
+	}
+}
+
+// scaleDownPrinter prints the result of a scale-down.
+type scaleDownPrinter struct {
+	*decisionPrinter
+}
+
+// Process prints the result of a scale-down.
+func (p scaleDownPrinter) Process(context *context.AutoscalingContext, scaleDownStatus *status.ScaleDownStatus) {
+	for _, node := range scaleDownStatus.ScaledDownNodes {
+		group := "<none>"
+		if node.NodeGroup != nil {
+			group = node.NodeGroup.Id()
+		}
+		fmt.Fprintf(p.out, "  Scale-down: removing node %s from node group %s, utilization: %.2f, pods to reschedule: %s\n",
+			node.Node.Name, group, node.UtilInfo.Utilization, podNames(node.EvictedPods))
+		p.deletedNodes = append(p.deletedNodes, node.Node.Name)
+	}
+	for nodeName, err := range scaleDownStatus.NodeDeleteResults {
+		if err != nil {
+			fmt.Fprintf(p.out, "  Scale-down of node %s failed: %v\n", nodeName, err)
+		}
+	}
+}
+
+// autoscalingStatusPrinter prints the readable cluster state at the end of an iteration.
+type autoscalingStatusPrinter struct {
+	*decisionPrinter
+}
+
+// Process prints the cluster-autoscaler-status content.
+func (p autoscalingStatusPrinter) Process(context *context.AutoscalingContext, csr *clusterstate.ClusterStateRegistry, now time.Time) error {
+	if p.printStatus {
+		fmt.Fprintf(p.out, "%s\n", csr.GetStatus(now).GetReadableString())
+	}
+	return nil
+}
+
+// CleanUp cleans up the printer's internal structures.
+func (p *decisionPrinter) CleanUp() {
+}
+
+func podNames(pods []*apiv1.Pod) string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Namespace+"/"+pod.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func scaleUpResultString(result status.ScaleUpResult) string {
+	switch result {
+	case status.ScaleUpSuccessful:
+		return "successful"
+	case status.ScaleUpError:
+		return "error"
+	case status.ScaleUpNoOptionsAvailable:
+		return "no options available"
+	case status.ScaleUpInCooldown:
+		return "in cooldown"
+	}
+	return "not needed"
+}
+
+func simulate(clusterSnapshot *snapshot.ClusterSnapshot, opts config.AutoscalingOptions, out io.Writer) error {
+	env, err := environment.NewEnvironment(clusterSnapshot)
+	if err != nil {
+		return err
+	}
+	listerRegistry, err := env.ListerRegistry()
+	if err != nil {
+		return err
+	}
+	recorder := kube_util.CreateEventRecorder(env.ClientSet())
+	logRecorder, err := ca_utils.NewStatusMapRecorder(env.ClientSet(), opts.ConfigNamespace, recorder, false)
+	if err != nil {
+		return err
+	}
+	kubeClients := &context.AutoscalingKubeClients{
+		ListerRegistry: listerRegistry,
+		ClientSet:      env.ClientSet(),
+		Recorder:       recorder,
+		LogRecorder:    logRecorder,
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	predicateChecker, err := simulator.NewPredicateChecker(env.ClientSet(), stop)
+	if err != nil {
+		return err
+	}
+	processors := ca_processors.DefaultProcessors()
+	expanderStrategy, err := factory.ExpanderStrategyFromStrings(strings.Split(opts.ExpanderNames, ","), env.CloudProvider(), kubeClients, env.ClientSet(), opts.ConfigNamespace,
+		processors.NodeGroupConfigProcessor, opts.GRPCExpander)
+	if err != nil {
+		return err
+	}
+	estimatorBuilder, err := estimator.NewEstimatorBuilder(opts.EstimatorName)
+	if err != nil {
+		return err
+	}
+
+	printer := &decisionPrinter{out: out, printStatus: *printStatus}
+	processors.ScaleUpStatusProcessor = printer
+	processors.ScaleDownStatusProcessor = scaleDownPrinter{printer}
+	processors.AutoscalingStatusProcessor = autoscalingStatusPrinter{printer}
+
+	autoscaler := core.NewStaticAutoscaler(opts, predicateChecker, kubeClients, processors, env.CloudProvider(),
+		expanderStrategy, estimatorBuilder,
+		backoff.NewIdBasedExponentialBackoff(clusterstate.InitialNodeGroupBackoffDuration, clusterstate.MaxNodeGroupBackoffDuration, clusterstate.NodeGroupBackoffResetTimeout))
+
+	startTime := time.Now()
+	for i := 0; i < *iterations; i++ {
+		currentTime := startTime.Add(time.Duration(i) * *iterationInterval)
+		fmt.Fprintf(out, "Iteration %d (+%v):\n", i+1, currentTime.Sub(startTime))
+
+		if i > 0 {
+			if bound := env.SchedulePods(predicateChecker); len(bound) > 0 {
+				fmt.Fprintf(out, "  Scheduled pending pods: %s\n", podNames(bound))
+			}
+		}
+		listerRegistry, err := env.ListerRegistry()
+		if err != nil {
+			return err
+		}
+		autoscaler.ListerRegistry = listerRegistry
+		if typedErr := autoscaler.RunOnce(currentTime); typedErr != nil {
+			fmt.Fprintf(out, "  Error: %v\n", typedErr)
+		}
+		waitForNodeDeletions(env, printer.deletedNodes, out)
+		printer.deletedNodes = nil
+	}
+	return nil
+}
+
+// waitForNodeDeletions waits until nodes removed by scale-down are gone from the environment,
+// so that the next iteration observes the result of the drain.
+func waitForNodeDeletions(env *environment.Environment, nodes []string, out io.Writer) {
+	deadline := time.Now().Add(nodeDeletionTimeout)
+	for _, node := range nodes {
+		for !env.IsNodeDeleted(node) {
+			if time.Now().After(deadline) {
+				fmt.Fprintf(out, "  Node %s was not deleted within %v\n", node, nodeDeletionTimeout)
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	clusterSnapshot, err := snapshot.LoadFiles(*snapshotFiles)
+	if err != nil {
+		klog.Fatalf("Failed to load snapshot: %v", err)
+	}
+	fmt.Fprintf(os.Stdout, "Loaded snapshot taken at %v: %d nodes, %d pods, %d node groups\n",
+		clusterSnapshot.Timestamp, len(clusterSnapshot.Nodes), len(clusterSnapshot.Pods), len(clusterSnapshot.NodeGroups))
+
+	if err := simulate(clusterSnapshot, createAutoscalingOptions(), os.Stdout); err != nil {
+		klog.Fatalf("Simulation failed: %v", err)
+	}
+}
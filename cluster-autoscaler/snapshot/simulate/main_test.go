@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/snapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateScaleUp(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	p1 := BuildTestPod("p1", 600, 100)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 600, 100)
+	p2.Status.Conditions = []apiv1.PodCondition{{
+		Type:   apiv1.PodScheduled,
+		Status: apiv1.ConditionFalse,
+		Reason: apiv1.PodReasonUnschedulable,
+	}}
+
+	clusterSnapshot := snapshot.NewClusterSnapshot(now)
+	clusterSnapshot.Nodes = []*apiv1.Node{n1}
+	clusterSnapshot.Pods = []*apiv1.Pod{p1, p2}
+	clusterSnapshot.NodeGroups = []*snapshot.NodeGroup{{Id: "ng1", MinSize: 1, MaxSize: 10, TargetSize: 1, Nodes: []string{"n1"}}}
+
+	var out bytes.Buffer
+	err := simulate(clusterSnapshot, createAutoscalingOptions(), &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Iteration 1")
+	assert.Contains(t, out.String(), "Scale-up: node group ng1 1->2 (max: 10)")
+	assert.Contains(t, out.String(), "Pods triggering scale-up: "+p2.Namespace+"/p2")
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// CurrentVersion is the version of the snapshot format written and understood by this package.
+	CurrentVersion = "v1"
+)
+
+// ClusterSnapshot is a serializable view of everything Cluster Autoscaler needs to
+// make a scaling decision: the Kubernetes objects it lists and the node groups
+// exposed by the cloud provider.
+type ClusterSnapshot struct {
+	// Version of the snapshot format. Must be equal to CurrentVersion.
+	Version string `json:"version"`
+	// Timestamp is the time at which the snapshot was taken.
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	// Nodes are all nodes registered in the cluster, both ready and unready.
+	Nodes []*apiv1.Node `json:"nodes,omitempty"`
+	// Pods are both scheduled and unschedulable pods.
+	Pods []*apiv1.Pod `json:"pods,omitempty"`
+	// PodDisruptionBudgets are the budgets respected when draining nodes.
+	PodDisruptionBudgets []*policyv1.PodDisruptionBudget `json:"podDisruptionBudgets,omitempty"`
+	// DaemonSets are used to predict pods running on nodes created from templates.
+	DaemonSets []*appsv1.DaemonSet `json:"daemonSets,omitempty"`
+	// ReplicationControllers, Jobs, ReplicaSets and StatefulSets are used to verify
+	// that pods evicted during scale-down will be recreated.
+	ReplicationControllers []*apiv1.ReplicationController `json:"replicationControllers,omitempty"`
+	Jobs                   []*batchv1.Job                 `json:"jobs,omitempty"`
+	ReplicaSets            []*appsv1.ReplicaSet           `json:"replicaSets,omitempty"`
+	StatefulSets           []*appsv1.StatefulSet          `json:"statefulSets,omitempty"`
+	// NodeGroups are the node groups exposed by the cloud provider.
+	NodeGroups []*NodeGroup `json:"nodeGroups,omitempty"`
+	// Autoscaler is the internal state of the autoscaler at the time the snapshot
+	// was taken. It is only set in snapshots exported by a running autoscaler and
+	// is informational, the simulator starts from a clean state.
+	Autoscaler *AutoscalerState `json:"autoscaler,omitempty"`
+}
+
+// NodeGroup describes a single cloud provider node group.
+type NodeGroup struct {
+	// Id is the unique identifier of the node group.
+	Id string `json:"id"`
+	// MinSize is the minimum size of the node group.
+	MinSize int `json:"minSize"`
+	// MaxSize is the maximum size of the node group.
+	MaxSize int `json:"maxSize"`
+	// TargetSize is the current target size of the node group.
+	TargetSize int `json:"targetSize"`
+	// Nodes are the names of nodes belonging to the node group.
+	Nodes []string `json:"nodes,omitempty"`
+	// Template describes a node that would be created on scale-up. Optional
+	// if the node group has at least one node.
+	Template *NodeTemplate `json:"template,omitempty"`
+}
+
+// NodeTemplate describes a node created by a node group scale-up together with
+// pods that are expected to run on it from the beginning.
+type NodeTemplate struct {
+	Node *apiv1.Node  `json:"node"`
+	Pods []*apiv1.Pod `json:"pods,omitempty"`
+}
+
+// AutoscalerState describes what a running autoscaler knew about the cluster
+// at the end of its last iteration.
+type AutoscalerState struct {
+	// LastIterationTime is the time at which the last iteration started.
+	LastIterationTime time.Time `json:"lastIterationTime,omitempty"`
+	// NodeGroups contains the cluster state registry view of every node group.
+	NodeGroups []*NodeGroupState `json:"nodeGroups,omitempty"`
+	// UnregisteredNodes are cloud provider instances that did not register in Kubernetes.
+	UnregisteredNodes []*UnregisteredNode `json:"unregisteredNodes,omitempty"`
+	// UnneededNodes are nodes considered for scale-down, with the time since which they are unneeded.
+	UnneededNodes []*UnneededNode `json:"unneededNodes,omitempty"`
+	// UnschedulablePods are pods marked as unschedulable by the scheduler, in namespace/name form.
+	UnschedulablePods []string `json:"unschedulablePods,omitempty"`
+	// PodsToHelp are unschedulable pods that did not fit on any existing node and
+	// were considered for scale-up, in namespace/name form.
+	PodsToHelp []string `json:"podsToHelp,omitempty"`
+}
+
+// NodeGroupState is the cluster state registry view of a node group.
+type NodeGroupState struct {
+	// Id is the node group id.
+	Id string `json:"id"`
+	// Healthy is false if the node group has too many unready nodes.
+	Healthy bool `json:"healthy"`
+	// BackedOff is true if scale-ups of the node group are backed off after a failure.
+	BackedOff bool `json:"backedOff"`
+	// ScaleUpInProgress is true if there are requested nodes that did not register yet.
+	ScaleUpInProgress bool `json:"scaleUpInProgress"`
+	// Readiness counts nodes of the node group by their state.
+	Readiness Readiness `json:"readiness"`
+	// MinNodes and MaxNodes is the acceptable number of nodes, taking ongoing scale operations into account.
+	MinNodes int `json:"minNodes"`
+	MaxNodes int `json:"maxNodes"`
+	// CurrentTarget is the target size reported by the cloud provider.
+	CurrentTarget int `json:"currentTarget"`
+}
+
+// Readiness counts the nodes of a node group by their state.
+type Readiness struct {
+	Ready            int `json:"ready"`
+	Unready          int `json:"unready"`
+	Deleted          int `json:"deleted"`
+	LongNotStarted   int `json:"longNotStarted"`
+	NotStarted       int `json:"notStarted"`
+	Registered       int `json:"registered"`
+	LongUnregistered int `json:"longUnregistered"`
+	Unregistered     int `json:"unregistered"`
+}
+
+// UnregisteredNode is a cloud provider instance without a corresponding Kubernetes node.
+type UnregisteredNode struct {
+	Name              string    `json:"name"`
+	UnregisteredSince time.Time `json:"unregisteredSince"`
+}
+
+// UnneededNode is a node considered for scale-down.
+type UnneededNode struct {
+	Name          string    `json:"name"`
+	UnneededSince time.Time `json:"unneededSince"`
+}
+
+// NewClusterSnapshot returns an empty snapshot in the current version.
+func NewClusterSnapshot(timestamp time.Time) *ClusterSnapshot {
+	return &ClusterSnapshot{
+		Version:   CurrentVersion,
+		Timestamp: timestamp,
+	}
+}
+
+// Load reads a single snapshot, either in JSON or in YAML format.
+func Load(r io.Reader) (*ClusterSnapshot, error) {
+	snapshot := &ClusterSnapshot{}
+	if err := yaml.NewYAMLOrJSONDecoder(r, 4096).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	if snapshot.Version != CurrentVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %q, expected %q", snapshot.Version, CurrentVersion)
+	}
+	return snapshot, nil
+}
+
+// LoadFiles reads snapshots from the given files and merges them into one.
+// This allows keeping e.g. node group definitions apart from the recorded objects.
+func LoadFiles(paths []string) (*ClusterSnapshot, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshot files given")
+	}
+	result := &ClusterSnapshot{Version: CurrentVersion}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open snapshot file %s: %v", path, err)
+		}
+		snapshot, err := Load(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot file %s: %v", path, err)
+		}
+		if err := result.Merge(snapshot); err != nil {
+			return nil, fmt.Errorf("failed to merge snapshot file %s: %v", path, err)
+		}
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Merge appends all objects from other to the snapshot. The latest timestamp wins.
+func (s *ClusterSnapshot) Merge(other *ClusterSnapshot) error {
+	for _, nodeGroup := range other.NodeGroups {
+		if s.nodeGroup(nodeGroup.Id) != nil {
+			return fmt.Errorf("node group %s defined more than once", nodeGroup.Id)
+		}
+	}
+	if other.Timestamp.After(s.Timestamp) {
+		s.Timestamp = other.Timestamp
+	}
+	s.Nodes = append(s.Nodes, other.Nodes...)
+	s.Pods = append(s.Pods, other.Pods...)
+	s.PodDisruptionBudgets = append(s.PodDisruptionBudgets, other.PodDisruptionBudgets...)
+	s.DaemonSets = append(s.DaemonSets, other.DaemonSets...)
+	s.ReplicationControllers = append(s.ReplicationControllers, other.ReplicationControllers...)
+	s.Jobs = append(s.Jobs, other.Jobs...)
+	s.ReplicaSets = append(s.ReplicaSets, other.ReplicaSets...)
+	s.StatefulSets = append(s.StatefulSets, other.StatefulSets...)
+	s.NodeGroups = append(s.NodeGroups, other.NodeGroups...)
+	if other.Autoscaler != nil {
+		s.Autoscaler = other.Autoscaler
+	}
+	return nil
+}
+
+// Validate checks that the snapshot is self-consistent.
+func (s *ClusterSnapshot) Validate() error {
+	nodes := make(map[string]bool)
+	for _, node := range s.Nodes {
+		if nodes[node.Name] {
+			return fmt.Errorf("node %s defined more than once", node.Name)
+		}
+		nodes[node.Name] = true
+	}
+	owners := make(map[string]string)
+	for _, nodeGroup := range s.NodeGroups {
+		if nodeGroup.Id == "" {
+			return fmt.Errorf("node group without id")
+		}
+		if nodeGroup.MinSize < 0 || nodeGroup.MaxSize < nodeGroup.MinSize {
+			return fmt.Errorf("node group %s has invalid size limits %d:%d", nodeGroup.Id, nodeGroup.MinSize, nodeGroup.MaxSize)
+		}
+		if nodeGroup.Template != nil && nodeGroup.Template.Node == nil {
+			return fmt.Errorf("node group %s has a template without node", nodeGroup.Id)
+		}
+		if nodeGroup.Template == nil && len(nodeGroup.Nodes) == 0 {
+			return fmt.Errorf("node group %s has neither template nor nodes", nodeGroup.Id)
+		}
+		for _, name := range nodeGroup.Nodes {
+			if !nodes[name] {
+				return fmt.Errorf("node %s of node group %s not found in snapshot", name, nodeGroup.Id)
+			}
+			if owner, found := owners[name]; found {
+				return fmt.Errorf("node %s belongs to both %s and %s", name, owner, nodeGroup.Id)
+			}
+			owners[name] = nodeGroup.Id
+		}
+	}
+	return nil
+}
+
+// Write serializes the snapshot as JSON.
+func (s *ClusterSnapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+func (s *ClusterSnapshot) nodeGroup(id string) *NodeGroup {
+	for _, nodeGroup := range s.NodeGroups {
+		if nodeGroup.Id == id {
+			return nodeGroup
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestSnapshot() *ClusterSnapshot {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, now.Add(-time.Hour))
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, now.Add(-time.Hour))
+
+	p1 := BuildTestPod("p1", 600, 100)
+	p1.Spec.NodeName = "n1"
+	p2 := BuildTestPod("p2", 600, 100)
+	p2.Status.Conditions = []apiv1.PodCondition{{
+		Type:   apiv1.PodScheduled,
+		Status: apiv1.ConditionFalse,
+		Reason: apiv1.PodReasonUnschedulable,
+	}}
+
+	snapshot := NewClusterSnapshot(now)
+	snapshot.Nodes = []*apiv1.Node{n1, n2}
+	snapshot.Pods = []*apiv1.Pod{p1, p2}
+	snapshot.NodeGroups = []*NodeGroup{{Id: "ng1", MinSize: 1, MaxSize: 10, TargetSize: 2, Nodes: []string{"n1", "n2"}}}
+	return snapshot
+}
+
+func TestWriteAndLoad(t *testing.T) {
+	snapshot := buildTestSnapshot()
+	var buf bytes.Buffer
+	assert.NoError(t, snapshot.Write(&buf))
+
+	loaded, err := Load(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, CurrentVersion, loaded.Version)
+	assert.Len(t, loaded.Nodes, 2)
+	assert.Len(t, loaded.Pods, 2)
+	assert.Equal(t, []string{"n1", "n2"}, loaded.NodeGroups[0].Nodes)
+	assert.NoError(t, loaded.Validate())
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	_, err := Load(strings.NewReader("version: v0\n"))
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	snapshot := buildTestSnapshot()
+	later := NewClusterSnapshot(snapshot.Timestamp.Add(time.Minute))
+	later.NodeGroups = []*NodeGroup{{Id: "ng2", MaxSize: 1, Template: &NodeTemplate{Node: BuildTestNode("t", 1000, 1000)}}}
+	assert.NoError(t, snapshot.Merge(later))
+	assert.Equal(t, later.Timestamp, snapshot.Timestamp)
+	assert.Len(t, snapshot.NodeGroups, 2)
+	assert.NoError(t, snapshot.Validate())
+
+	assert.Error(t, snapshot.Merge(later))
+}
+
+func TestValidate(t *testing.T) {
+	snapshot := buildTestSnapshot()
+	snapshot.NodeGroups[0].Nodes = append(snapshot.NodeGroups[0].Nodes, "n3")
+	assert.Error(t, snapshot.Validate())
+
+	snapshot = buildTestSnapshot()
+	snapshot.NodeGroups = append(snapshot.NodeGroups, &NodeGroup{Id: "ng2", MaxSize: 1, Nodes: []string{"n2"}})
+	assert.Error(t, snapshot.Validate())
+
+	snapshot = buildTestSnapshot()
+	snapshot.NodeGroups[0].MaxSize = 0
+	assert.Error(t, snapshot.Validate())
+
+	snapshot = buildTestSnapshot()
+	snapshot.NodeGroups[0].Nodes = nil
+	assert.Error(t, snapshot.Validate())
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed standard cron expression with five fields:
+// minute, hour, day of month, month and day of week.
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// Restricted day fields are combined with OR, like in classic cron.
+	dayOfMonthRestricted bool
+	dayOfWeekRestricted  bool
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 mean Sunday.
+	dayOfWeekField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Parse parses a cron expression. Each field accepts '*', single values,
+// ranges ('a-b'), steps ('*/n', 'a-b/n', 'a/n') and comma-separated lists.
+// Months and days of week may also be given as three-letter English names.
+func Parse(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression %q, got %d", spec, len(fields))
+	}
+	schedule := &Schedule{}
+	var err error
+	if schedule.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfMonth, err = parseField(fields[2], dayOfMonthField); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek, err = parseField(fields[4], dayOfWeekField); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+	schedule.dayOfMonthRestricted = fields[2] != "*"
+	schedule.dayOfWeekRestricted = fields[4] != "*"
+	return schedule, nil
+}
+
+// Matches returns true if the minute containing t is matched by the schedule.
+// The time is evaluated in its own location.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.minute&(1<<uint(t.Minute())) != 0 && s.hour&(1<<uint(t.Hour())) != 0 &&
+		s.month&(1<<uint(t.Month())) != 0 && s.matchesDay(t)
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthRestricted && s.dayOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+// LastActivation returns the latest minute not after t matched by the schedule,
+// looking back at most lookBack. The second return value is false if there is none.
+func (s *Schedule) LastActivation(t time.Time, lookBack time.Duration) (time.Time, bool) {
+	earliest := t.Add(-lookBack)
+	// Non-matching months, days and hours are skipped as a whole, by moving to their
+	// previous unit's last minute.
+	candidate := t.Truncate(time.Minute)
+	for !candidate.Before(earliest) {
+		year, month, day := candidate.Date()
+		hourStart := candidate.Add(-time.Duration(candidate.Minute()) * time.Minute)
+		switch {
+		case s.month&(1<<uint(month)) == 0:
+			candidate = time.Date(year, month, 1, 0, 0, 0, 0, candidate.Location()).Add(-time.Minute)
+		case !s.matchesDay(candidate):
+			candidate = time.Date(year, month, day, 0, 0, 0, 0, candidate.Location()).Add(-time.Minute)
+		case s.hour&(1<<uint(candidate.Hour())) == 0:
+			candidate = hourStart.Add(-time.Minute)
+		default:
+			for minute := candidate.Minute(); minute >= 0; minute-- {
+				if s.minute&(1<<uint(minute)) != 0 {
+					activation := hourStart.Add(time.Duration(minute) * time.Minute)
+					return activation, !activation.Before(earliest)
+				}
+			}
+			candidate = hourStart.Add(-time.Minute)
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseField(expr string, f field) (uint64, error) {
+	var result uint64
+	for _, part := range strings.Split(expr, ",") {
+		bits, err := parsePart(part, f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %v", f.name, expr, err)
+		}
+		result |= bits
+	}
+	return result, nil
+}
+
+func parsePart(part string, f field) (uint64, error) {
+	rangeExpr, step := part, 1
+	if i := strings.Index(part, "/"); i >= 0 {
+		var err error
+		rangeExpr = part[:i]
+		if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", part[i+1:])
+		}
+	}
+
+	var low, high int
+	switch {
+	case rangeExpr == "*":
+		low, high = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		bounds := strings.SplitN(rangeExpr, "-", 2)
+		var err error
+		if low, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if high, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("invalid range %q", rangeExpr)
+		}
+	default:
+		var err error
+		if low, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+		high = low
+		// 'a/n' means every n starting at a.
+		if step > 1 || strings.Contains(part, "/") {
+			high = f.max
+		}
+	}
+
+	var bits uint64
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if number, found := f.names[strings.ToLower(value)]; found {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if number < f.min || number > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", number, f.min, f.max)
+	}
+	return number, nil
+}
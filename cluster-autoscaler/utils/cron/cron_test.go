@@ -0,0 +1,140 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, "spec %q", spec)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2019-06-03 is a Monday.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2019, 6, 3, hour, minute, 30, 0, time.UTC)
+	}
+	testCases := []struct {
+		spec    string
+		time    time.Time
+		matches bool
+	}{
+		{"* * * * *", monday(0, 0), true},
+		{"30 9 * * *", monday(9, 30), true},
+		{"30 9 * * *", monday(9, 31), false},
+		{"*/15 * * * *", monday(10, 45), true},
+		{"*/15 * * * *", monday(10, 50), false},
+		{"5/20 * * * *", monday(10, 45), true},
+		{"0-10/5,30 * * * *", monday(10, 30), true},
+		{"0-10/5,30 * * * *", monday(10, 7), false},
+		{"* 9-17 * * mon-fri", monday(12, 0), true},
+		{"* 9-17 * * MON-FRI", monday(18, 0), false},
+		{"* * * * 6,0", monday(12, 0), false},
+		{"* * * * 7", time.Date(2019, 6, 2, 12, 0, 0, 0, time.UTC), true},
+		{"* * * jun *", monday(12, 0), true},
+		{"* * * 7 *", monday(12, 0), false},
+		// Day of month and day of week are combined with OR when both are restricted.
+		{"* * 15 * 1", monday(12, 0), true},
+		{"* * 3 * 5", monday(12, 0), true},
+		{"* * 15 * 5", monday(12, 0), false},
+		{"* * 15 * *", monday(12, 0), false},
+	}
+	for _, tc := range testCases {
+		schedule, err := Parse(tc.spec)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.matches, schedule.Matches(tc.time), "spec %q at %v", tc.spec, tc.time)
+	}
+}
+
+func TestLastActivation(t *testing.T) {
+	schedule, err := Parse("0 9 * * *")
+	assert.NoError(t, err)
+	now := time.Date(2019, 6, 3, 12, 15, 10, 0, time.UTC)
+
+	activation, found := schedule.LastActivation(now, 4*time.Hour)
+	assert.True(t, found)
+	assert.Equal(t, time.Date(2019, 6, 3, 9, 0, 0, 0, time.UTC), activation)
+
+	_, found = schedule.LastActivation(now, 3*time.Hour)
+	assert.False(t, found)
+}
+
+func TestLastActivationMatchesMinuteScan(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		location = time.FixedZone("CET", 3600)
+	}
+	// Every result is compared with a scan of all minutes, the times include
+	// month ends, a leap day and DST transitions.
+	times := []time.Time{
+		time.Date(2019, 6, 3, 12, 15, 10, 0, time.UTC),
+		time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 3, 1, 0, 30, 0, 0, time.UTC),
+		time.Date(2019, 3, 31, 3, 30, 0, 0, location),
+		time.Date(2019, 10, 27, 2, 30, 0, 0, location),
+		time.Date(2019, 1, 1, 0, 0, 0, 0, location),
+	}
+	specs := []string{
+		"* * * * *",
+		"0 9 * * *",
+		"*/7 */5 * * *",
+		"30 2 * * *",
+		"59 23 29 2 *",
+		"0 0 1 * *",
+		"15 10 * * mon-fri",
+		"0 12 15 * sat",
+		"0 0 31 jan-mar *",
+		"0 0 30 2 *",
+	}
+	lookBack := 40 * 24 * time.Hour
+	for _, spec := range specs {
+		schedule, err := Parse(spec)
+		assert.NoError(t, err)
+		for _, now := range times {
+			expected, expectedFound := time.Time{}, false
+			for candidate := now.Truncate(time.Minute); !candidate.Before(now.Add(-lookBack)); candidate = candidate.Add(-time.Minute) {
+				if schedule.Matches(candidate) {
+					expected, expectedFound = candidate, true
+					break
+				}
+			}
+			activation, found := schedule.LastActivation(now, lookBack)
+			assert.Equal(t, expectedFound, found, "spec %q at %v", spec, now)
+			assert.True(t, expected.Equal(activation), "spec %q at %v: expected %v, got %v", spec, now, expected, activation)
+		}
+	}
+}
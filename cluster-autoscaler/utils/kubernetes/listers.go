@@ -46,6 +46,8 @@ type ListerRegistry interface {
 	JobLister() v1batchlister.JobLister
 	ReplicaSetLister() v1appslister.ReplicaSetLister
 	StatefulSetLister() v1appslister.StatefulSetLister
+	PersistentVolumeClaimLister() v1lister.PersistentVolumeClaimLister
+	PersistentVolumeLister() v1lister.PersistentVolumeLister
 }
 
 type listerRegistryImpl struct {
@@ -59,6 +61,8 @@ type listerRegistryImpl struct {
 	jobLister                   v1batchlister.JobLister
 	replicaSetLister            v1appslister.ReplicaSetLister
 	statefulSetLister           v1appslister.StatefulSetLister
+	pvcLister                   v1lister.PersistentVolumeClaimLister
+	pvLister                    v1lister.PersistentVolumeLister
 }
 
 // NewListerRegistry returns a registry providing various listers to list pods or nodes matching conditions
@@ -66,7 +70,8 @@ func NewListerRegistry(allNode NodeLister, readyNode NodeLister, scheduledPod Po
 	unschedulablePod PodLister, podDisruptionBudgetLister PodDisruptionBudgetLister,
 	daemonSetLister v1appslister.DaemonSetLister, replicationControllerLister v1lister.ReplicationControllerLister,
 	jobLister v1batchlister.JobLister, replicaSetLister v1appslister.ReplicaSetLister,
-	statefulSetLister v1appslister.StatefulSetLister) ListerRegistry {
+	statefulSetLister v1appslister.StatefulSetLister, pvcLister v1lister.PersistentVolumeClaimLister,
+	pvLister v1lister.PersistentVolumeLister) ListerRegistry {
 	return listerRegistryImpl{
 		allNodeLister:               allNode,
 		readyNodeLister:             readyNode,
@@ -78,6 +83,8 @@ func NewListerRegistry(allNode NodeLister, readyNode NodeLister, scheduledPod Po
 		jobLister:                   jobLister,
 		replicaSetLister:            replicaSetLister,
 		statefulSetLister:           statefulSetLister,
+		pvcLister:                   pvcLister,
+		pvLister:                    pvLister,
 	}
 }
 
@@ -93,9 +100,12 @@ func NewListerRegistryWithDefaultListers(kubeClient client.Interface, stopChanne
 	jobLister := NewJobLister(kubeClient, stopChannel)
 	replicaSetLister := NewReplicaSetLister(kubeClient, stopChannel)
 	statefulSetLister := NewStatefulSetLister(kubeClient, stopChannel)
+	pvcLister := NewPersistentVolumeClaimLister(kubeClient, stopChannel)
+	pvLister := NewPersistentVolumeLister(kubeClient, stopChannel)
 	return NewListerRegistry(allNodeLister, readyNodeLister, scheduledPodLister,
 		unschedulablePodLister, podDisruptionBudgetLister, daemonSetLister,
-		replicationControllerLister, jobLister, replicaSetLister, statefulSetLister)
+		replicationControllerLister, jobLister, replicaSetLister, statefulSetLister,
+		pvcLister, pvLister)
 }
 
 // AllNodeLister returns the AllNodeLister registered to this registry
@@ -148,6 +158,16 @@ func (r listerRegistryImpl) StatefulSetLister() v1appslister.StatefulSetLister {
 	return r.statefulSetLister
 }
 
+// PersistentVolumeClaimLister returns the pvcLister registered to this registry
+func (r listerRegistryImpl) PersistentVolumeClaimLister() v1lister.PersistentVolumeClaimLister {
+	return r.pvcLister
+}
+
+// PersistentVolumeLister returns the pvLister registered to this registry
+func (r listerRegistryImpl) PersistentVolumeLister() v1lister.PersistentVolumeLister {
+	return r.pvLister
+}
+
 // PodLister lists pods.
 type PodLister interface {
 	List() ([]*apiv1.Pod, error)
@@ -360,3 +380,23 @@ func NewStatefulSetLister(kubeClient client.Interface, stopchannel <-chan struct
 	go reflector.Run(stopchannel)
 	return lister
 }
+
+// NewPersistentVolumeClaimLister builds a persistent volume claim lister.
+func NewPersistentVolumeClaimLister(kubeClient client.Interface, stopchannel <-chan struct{}) v1lister.PersistentVolumeClaimLister {
+	listWatcher := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "persistentvolumeclaims", apiv1.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	lister := v1lister.NewPersistentVolumeClaimLister(store)
+	reflector := cache.NewReflector(listWatcher, &apiv1.PersistentVolumeClaim{}, store, time.Hour)
+	go reflector.Run(stopchannel)
+	return lister
+}
+
+// NewPersistentVolumeLister builds a persistent volume lister.
+func NewPersistentVolumeLister(kubeClient client.Interface, stopchannel <-chan struct{}) v1lister.PersistentVolumeLister {
+	listWatcher := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "persistentvolumes", apiv1.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	lister := v1lister.NewPersistentVolumeLister(store)
+	reflector := cache.NewReflector(listWatcher, &apiv1.PersistentVolume{}, store, time.Hour)
+	go reflector.Run(stopchannel)
+	return lister
+}
@@ -22,6 +22,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
 	v1appslister "k8s.io/client-go/listers/apps/v1"
 	v1batchlister "k8s.io/client-go/listers/batch/v1"
 	v1lister "k8s.io/client-go/listers/core/v1"
@@ -102,3 +103,57 @@ func NewTestStatefulSetLister(sss []*appsv1.StatefulSet) (v1appslister.StatefulS
 	}
 	return v1appslister.NewStatefulSetLister(store), nil
 }
+
+// NewTestPersistentVolumeClaimLister returns a lister that returns provided PersistentVolumeClaims
+func NewTestPersistentVolumeClaimLister(pvcs []*apiv1.PersistentVolumeClaim) (v1lister.PersistentVolumeClaimLister, error) {
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pvc := range pvcs {
+		err := store.Add(pvc)
+		if err != nil {
+			return nil, fmt.Errorf("Error adding object to cache: %v", err)
+		}
+	}
+	return v1lister.NewPersistentVolumeClaimLister(store), nil
+}
+
+// NewTestPersistentVolumeLister returns a lister that returns provided PersistentVolumes
+func NewTestPersistentVolumeLister(pvs []*apiv1.PersistentVolume) (v1lister.PersistentVolumeLister, error) {
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pv := range pvs {
+		err := store.Add(pv)
+		if err != nil {
+			return nil, fmt.Errorf("Error adding object to cache: %v", err)
+		}
+	}
+	return v1lister.NewPersistentVolumeLister(store), nil
+}
+
+// TestNodeLister is used in tests involving listers
+type TestNodeLister struct {
+	nodes []*apiv1.Node
+}
+
+// List returns all nodes in test lister.
+func (lister TestNodeLister) List() ([]*apiv1.Node, error) {
+	return lister.nodes, nil
+}
+
+// NewTestNodeLister returns a lister that returns provided nodes
+func NewTestNodeLister(nodes []*apiv1.Node) NodeLister {
+	return TestNodeLister{nodes: nodes}
+}
+
+// TestPodDisruptionBudgetLister is used in tests involving listers
+type TestPodDisruptionBudgetLister struct {
+	pdbs []*policyv1.PodDisruptionBudget
+}
+
+// List returns all pod disruption budgets in test lister.
+func (lister TestPodDisruptionBudgetLister) List() ([]*policyv1.PodDisruptionBudget, error) {
+	return lister.pdbs, nil
+}
+
+// NewTestPodDisruptionBudgetLister returns a lister that returns provided PodDisruptionBudgets
+func NewTestPodDisruptionBudgetLister(pdbs []*policyv1.PodDisruptionBudget) PodDisruptionBudgetLister {
+	return TestPodDisruptionBudgetLister{pdbs: pdbs}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"math"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+)
+
+// ResourcePrices are hourly prices in USD of node resources. Price models use them for
+// machine types without a known price and for pods.
+type ResourcePrices struct {
+	// CPU is the price of a core.
+	CPU float64
+	// Memory is the price of a GiB of memory.
+	Memory float64
+	// GPU is the price of an nvidia GPU.
+	GPU float64
+}
+
+// DefaultResourcePrices roughly match general purpose on-demand machines of AWS and Azure,
+// e.g. m5 instances in us-east-1 and Dsv3 VMs in eastus.
+var DefaultResourcePrices = ResourcePrices{
+	CPU:    0.03,
+	Memory: 0.0045,
+	GPU:    0.9,
+}
+
+// GetHours returns the number of hours between startTime and endTime, rounded up to full minutes.
+func GetHours(startTime time.Time, endTime time.Time) float64 {
+	minutes := math.Ceil(float64(endTime.Sub(startTime)) / float64(time.Minute))
+	return minutes / 60.0
+}
+
+// ResourcesPrice returns the price of running the given resources between startTime and endTime.
+func (p ResourcePrices) ResourcesPrice(resources apiv1.ResourceList, startTime time.Time, endTime time.Time) float64 {
+	if len(resources) == 0 {
+		return 0
+	}
+	cpu := resources[apiv1.ResourceCPU]
+	mem := resources[apiv1.ResourceMemory]
+	gpus := resources[gpu.ResourceNvidiaGPU]
+	pricePerHour := float64(cpu.MilliValue())/1000.0*p.CPU +
+		float64(mem.Value())/float64(units.GiB)*p.Memory +
+		float64(gpus.MilliValue())/1000.0*p.GPU
+	return pricePerHour * GetHours(startTime, endTime)
+}
+
+// PodPrice returns the price of running the resources requested by the pod's containers
+// between startTime and endTime.
+func (p ResourcePrices) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) float64 {
+	price := 0.0
+	for _, container := range pod.Spec.Containers {
+		price += p.ResourcesPrice(container.Resources.Requests, startTime, endTime)
+	}
+	return price
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHours(t *testing.T) {
+	now := time.Now()
+	assert.Equal(t, 1.0, GetHours(now, now.Add(time.Hour)))
+	assert.Equal(t, 0.5, GetHours(now, now.Add(29*time.Minute+time.Second)))
+	assert.Equal(t, 0.0, GetHours(now, now))
+}
+
+func TestResourcesPrice(t *testing.T) {
+	prices := ResourcePrices{CPU: 1, Memory: 0.5, GPU: 10}
+	now := time.Now()
+
+	node := BuildTestNode("n1", 2000, 4*units.GiB)
+	assert.InDelta(t, 4.0, prices.ResourcesPrice(node.Status.Capacity, now, now.Add(time.Hour)), 1e-9)
+	assert.InDelta(t, 8.0, prices.ResourcesPrice(node.Status.Capacity, now, now.Add(2*time.Hour)), 1e-9)
+	node.Status.Capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(2, resource.DecimalSI)
+	assert.InDelta(t, 24.0, prices.ResourcesPrice(node.Status.Capacity, now, now.Add(time.Hour)), 1e-9)
+	assert.Equal(t, 0.0, prices.ResourcesPrice(nil, now, now.Add(time.Hour)))
+
+	pod := BuildTestPod("p1", 500, units.GiB)
+	pod.Spec.Containers = append(pod.Spec.Containers, pod.Spec.Containers[0])
+	assert.InDelta(t, 2.0, prices.PodPrice(pod, now, now.Add(time.Hour)), 1e-9)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+// GetZone returns the zone of the node, or an empty string if the node has no zone label.
+func GetZone(node *apiv1.Node) string {
+	if node == nil {
+		return ""
+	}
+	return node.Labels[apiv1.LabelZoneFailureDomain]
+}
+
+// HasZoneAntiAffinity returns true if the pod has a required pod anti-affinity term with
+// the zone topology key, i.e. it has to be spread across zones.
+func HasZoneAntiAffinity(pod *apiv1.Pod) bool {
+	return len(zoneAntiAffinityTerms(pod)) > 0
+}
+
+// PodsConflictInZone returns true if the pods cannot run in the same zone because of the
+// required pod anti-affinity of either of them with the zone topology key.
+func PodsConflictInZone(pod, other *apiv1.Pod) bool {
+	return antiAffinityMatches(pod, other) || antiAffinityMatches(other, pod)
+}
+
+// PodConflictsInZone returns true if the pod cannot run in the same zone as any of the given pods.
+func PodConflictsInZone(pod *apiv1.Pod, others []*apiv1.Pod) bool {
+	for _, other := range others {
+		if PodsConflictInZone(pod, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func zoneAntiAffinityTerms(pod *apiv1.Pod) []apiv1.PodAffinityTerm {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	var terms []apiv1.PodAffinityTerm
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey == apiv1.LabelZoneFailureDomain {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// antiAffinityMatches returns true if any of the zone anti-affinity terms of the pod selects the other pod.
+func antiAffinityMatches(pod, other *apiv1.Pod) bool {
+	for _, term := range zoneAntiAffinityTerms(pod) {
+		if !termNamespacesMatch(term, pod, other) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			klog.Warningf("Invalid anti-affinity label selector of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if selector.Matches(labels.Set(other.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func termNamespacesMatch(term apiv1.PodAffinityTerm, pod, other *apiv1.Pod) bool {
+	if len(term.Namespaces) == 0 {
+		return pod.Namespace == other.Namespace
+	}
+	for _, namespace := range term.Namespaces {
+		if namespace == other.Namespace {
+			return true
+		}
+	}
+	return false
+}
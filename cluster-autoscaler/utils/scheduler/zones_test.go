@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setAntiAffinity(pod *apiv1.Pod, topologyKey string, app string, namespaces ...string) {
+	pod.Spec.Affinity = &apiv1.Affinity{
+		PodAntiAffinity: &apiv1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				Namespaces:    namespaces,
+				TopologyKey:   topologyKey,
+			}},
+		},
+	}
+}
+
+func TestPodsConflictInZone(t *testing.T) {
+	web1 := BuildTestPod("web1", 100, 0)
+	web1.Labels = map[string]string{"app": "web"}
+	setAntiAffinity(web1, apiv1.LabelZoneFailureDomain, "web")
+	web2 := BuildTestPod("web2", 100, 0)
+	web2.Labels = map[string]string{"app": "web"}
+	setAntiAffinity(web2, apiv1.LabelZoneFailureDomain, "web")
+	db := BuildTestPod("db", 100, 0)
+	db.Labels = map[string]string{"app": "db"}
+	hostSpread := BuildTestPod("host", 100, 0)
+	hostSpread.Labels = map[string]string{"app": "web"}
+	setAntiAffinity(hostSpread, apiv1.LabelHostname, "web")
+	otherNamespace := BuildTestPod("other", 100, 0)
+	otherNamespace.Namespace = "other"
+	otherNamespace.Labels = map[string]string{"app": "web"}
+
+	assert.True(t, HasZoneAntiAffinity(web1))
+	assert.False(t, HasZoneAntiAffinity(db))
+	assert.False(t, HasZoneAntiAffinity(hostSpread))
+
+	assert.True(t, PodsConflictInZone(web1, web2))
+	assert.False(t, PodsConflictInZone(web1, db))
+	// The anti-affinity of either pod is enough.
+	assert.True(t, PodsConflictInZone(hostSpread, web1))
+	assert.False(t, PodsConflictInZone(web1, otherNamespace))
+	setAntiAffinity(web2, apiv1.LabelZoneFailureDomain, "web", "other")
+	assert.True(t, PodsConflictInZone(web2, otherNamespace))
+
+	assert.True(t, PodConflictsInZone(web1, []*apiv1.Pod{db, web2}))
+	assert.False(t, PodConflictsInZone(web1, []*apiv1.Pod{db}))
+
+	node := BuildTestNode("n1", 1000, 1000)
+	assert.Equal(t, "", GetZone(node))
+	node.Labels[apiv1.LabelZoneFailureDomain] = "zone-a"
+	assert.Equal(t, "zone-a", GetZone(node))
+}